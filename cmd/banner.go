@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// applyBanner applies a banner setting: config.BannerOff hides the banner, a file
+// path replaces it with the file's contents, and "" keeps the default
+func applyBanner(setting string) error {
+	switch setting {
+	case "":
+		return nil
+	case config.BannerOff:
+		ui.DisableBanner()
+		return nil
+	}
+
+	data, err := os.ReadFile(setting)
+	if err != nil {
+		return fmt.Errorf("failed to read banner file: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return fmt.Errorf("banner file %s is empty; use %q to hide the banner", setting, config.BannerOff)
+	}
+	ui.SetBanner(string(data))
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bench"
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchPrompt     string
+	benchIterations int
+	benchMaxTokens  int
+	benchTemp       = newTemperatureFlag(0.7)
+	benchModel      string
+	benchExecCmd    string
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench <provider> [provider...]",
+	Short: "Measure streaming throughput of one or more providers",
+	Long: `Send a fixed prompt to each provider and measure streaming performance.
+
+For every iteration the time to first token (TTFT) and the tokens per second
+between the first and last chunk are recorded. Streamed chunks are counted as
+tokens, which matches most providers closely. Results are reported as
+min/median/max across iterations.
+
+Examples:
+  # Compare two providers with their default models
+  chat-bridge bench openai anthropic
+
+  # Five iterations with a custom prompt
+  chat-bridge bench openai --iterations 5 --prompt "Explain TCP in one paragraph"
+
+  # Benchmark a specific model
+  chat-bridge bench openrouter --model meta-llama/llama-3-8b-instruct
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchPrompt, "prompt", bench.DefaultPrompt, "Prompt sent on every iteration")
+	benchCmd.Flags().IntVar(&benchIterations, "iterations", 3, "Number of requests per provider")
+	benchCmd.Flags().IntVar(&benchMaxTokens, "max-tokens", bridge.DefaultMaxTokens, "Maximum tokens per response")
+	benchCmd.Flags().Var(&benchTemp, "temp", `Sampling temperature, or "default" to omit it`)
+	benchCmd.Flags().StringVar(&benchModel, "model", "", "Model to use for every provider (default: provider default)")
+	benchCmd.Flags().StringVar(&benchExecCmd, "exec-cmd", "", "Command to run when benchmarking the exec provider")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	ui.PrintBanner()
+
+	for _, provider := range args {
+		if cfg.NeedsAPIKey(provider) && cfg.GetAPIKey(provider) == "" {
+			return fmt.Errorf("no API key configured for %s", provider)
+		}
+		if provider == "exec" && benchExecCmd == "" {
+			return fmt.Errorf("--exec-cmd is required to benchmark the exec provider")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ui.PrintSectionHeader("Benchmark", "⏱️")
+	fmt.Printf("  %s: %d\n", ui.Colorize("Iterations", ui.Blue, false), benchIterations)
+	fmt.Printf("  %s: %s\n", ui.Colorize("Prompt", ui.White, false), benchPrompt)
+	fmt.Println()
+
+	type row struct {
+		provider string
+		model    string
+		report   *bench.Report
+	}
+	var rows []row
+
+	for _, provider := range args {
+		agent, err := bridge.NewAgent(cfg, bridge.AgentConfig{
+			Name:        provider,
+			Provider:    provider,
+			Model:       benchModel,
+			Temperature: benchTemp.value,
+			Command:     benchExecCmd,
+		})
+		if err != nil {
+			return err
+		}
+
+		ui.PrintInfo(fmt.Sprintf("Benchmarking %s (%s)...", provider, agent.Model))
+		report, err := bench.Run(ctx, agent.Provider, &providers.ChatRequest{
+			Model:       agent.Model,
+			Messages:    []providers.Message{{Role: "user", Content: benchPrompt}},
+			Temperature: benchTemp.value,
+			MaxTokens:   benchMaxTokens,
+		}, benchIterations)
+		if err != nil {
+			return fmt.Errorf("%s: %w", provider, err)
+		}
+		rows = append(rows, row{provider: provider, model: agent.Model, report: report})
+	}
+
+	fmt.Println()
+	ui.PrintSectionHeader("Results", "📊")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PROVIDER\tMODEL\tTTFT MIN\tTTFT MED\tTTFT MAX\tTOK/S MIN\tTOK/S MED\tTOK/S MAX")
+	for _, r := range rows {
+		ttft, tps := r.report.TimeToFirstToken, r.report.TokensPerSecond
+		fmt.Fprintf(w, "  %s\t%s\t%.2fs\t%.2fs\t%.2fs\t%.1f\t%.1f\t%.1f\n",
+			r.provider, r.model, ttft.Min, ttft.Median, ttft.Max, tps.Min, tps.Median, tps.Max)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// branchCmd represents the branch command
+var branchCmd = &cobra.Command{
+	Use:   "branch <checkpoint>",
+	Short: "Explore an alternate continuation from a checkpoint",
+	Long: `Continue a conversation from a checkpoint or transcript into a new branch.
+
+The recorded history is replayed as context and the conversation continues
+from the next round. Agent settings come from the checkpoint unless overridden
+with flags, so you can try different providers, models, or temperatures from
+the same point. The branch is written to a new transcript (default:
+<checkpoint>-branch-<time>.jsonl) and the original file is left untouched.
+
+Examples:
+  # Save checkpoints every 2 rounds during a run
+  chat-bridge start --checkpoint-every 2
+
+  # Replay round 4 onwards with a hotter Agent B
+  chat-bridge branch checkpoints/checkpoint-004.jsonl --temp-b 1.2
+
+  # Swap Agent A's model and write the branch somewhere specific
+  chat-bridge branch checkpoints/checkpoint-004.jsonl --model-a gpt-4o --transcript alt.jsonl
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resumePath = args[0]
+		branching = true
+		return runStart(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(branchCmd)
+
+	addConversationFlags(branchCmd)
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+var (
+	diffSideBySide bool
+	diffAll        bool
+	diffWidth      int
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <transcriptA> <transcriptB>",
+	Short: "Show how two conversations diverged",
+	Long: `Compare two saved transcripts or checkpoints turn by turn.
+
+Turns are aligned by round and speaker, and each pair that differs is shown as
+a line diff: lines only in the first transcript are marked -, lines only in the
+second +. The first turn that differs is flagged, and settings that differ
+between the runs (starter, models, temperatures, system prompts) are listed
+first. Identical turns are collapsed unless --all is given.
+
+Examples:
+  # Did two seeded runs stay deterministic?
+  chat-bridge diff run-1.jsonl run-2.jsonl
+
+  # Two prompt variants, side by side
+  chat-bridge diff terse.jsonl verbose.jsonl --side-by-side
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffWidth < 0 {
+			return fmt.Errorf("--width must be 0 or more")
+		}
+		a, err := transcript.Load(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := transcript.Load(args[1])
+		if err != nil {
+			return err
+		}
+
+		width := diffWidth
+		if width == 0 {
+			width = 120
+			if w, ok := ui.TerminalWidth(os.Stdout); ok && w > 0 {
+				width = w
+			}
+		}
+
+		fmt.Println(ui.Colorize("--- "+args[0], ui.Red, true))
+		fmt.Println(ui.Colorize("+++ "+args[1], ui.Green, true))
+		if changes := transcript.DiffHeaders(a.Header, b.Header); len(changes) > 0 {
+			fmt.Println()
+			fmt.Println(ui.Colorize("Settings that differ:", ui.Yellow, true))
+			for _, c := range changes {
+				fmt.Printf("  %s\n", c.Field)
+				fmt.Println(ui.Colorize("    - "+oneLine(c.A), ui.Red, false))
+				fmt.Println(ui.Colorize("    + "+oneLine(c.B), ui.Green, false))
+			}
+		}
+
+		diffs := transcript.Diff(a, b)
+		first := transcript.FirstDivergence(diffs)
+		for i, d := range diffs {
+			printTurnDiff(d, a, b, i == first, width)
+		}
+
+		fmt.Println()
+		switch {
+		case len(diffs) == 0:
+			ui.PrintInfo("Neither transcript has any turns")
+		case first < 0:
+			ui.PrintSuccess(fmt.Sprintf("The conversations match turn for turn (%d turns)", len(diffs)))
+		default:
+			d := diffs[first]
+			ui.PrintWarning(fmt.Sprintf("The conversations first diverged at round %d (%s); %d of %d turns differ",
+				d.Round, diffAgentName(d, a, b), countDiffering(diffs), len(diffs)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVarP(&diffSideBySide, "side-by-side", "y", false, "Show differing turns in two columns instead of a unified diff")
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Also print the content of identical turns")
+	diffCmd.Flags().IntVar(&diffWidth, "width", 0, "Output width for --side-by-side (default: the terminal width, else 120)")
+}
+
+// printTurnDiff prints one aligned turn: collapsed when identical, otherwise as a
+// unified or side-by-side line diff
+func printTurnDiff(d transcript.TurnDiff, a, b *transcript.Transcript, first bool, width int) {
+	title := fmt.Sprintf("Round %d · %s", d.Round, diffAgentName(d, a, b))
+	fmt.Println()
+	if d.Same() {
+		fmt.Println(ui.Colorize("= "+title+" (identical)", ui.Dim, false))
+		if diffAll {
+			for _, line := range strings.Split(d.A.Content, "\n") {
+				fmt.Println("  " + line)
+			}
+		}
+		return
+	}
+
+	header := "@@ " + title + " @@"
+	switch {
+	case d.B == nil:
+		header += " only in the first transcript"
+	case d.A == nil:
+		header += " only in the second transcript"
+	}
+	if first {
+		header += "  ← first divergence"
+	}
+	fmt.Println(ui.Colorize(header, ui.Cyan, true))
+
+	if diffSideBySide {
+		printSideBySide(d.Lines, width)
+		return
+	}
+	for _, line := range d.Lines {
+		switch line.Op {
+		case transcript.LineRemoved:
+			fmt.Println(ui.Colorize("- "+line.Text, ui.Red, false))
+		case transcript.LineAdded:
+			fmt.Println(ui.Colorize("+ "+line.Text, ui.Green, false))
+		default:
+			fmt.Println("  " + line.Text)
+		}
+	}
+}
+
+// printSideBySide prints a line diff in two columns, pairing each run of removed
+// lines with the added lines that replace it; long lines wrap within their column
+func printSideBySide(lines []transcript.DiffLine, width int) {
+	column := max((width-3)/2, 10)
+	row := func(left, right string, mark string) {
+		l := strings.Split(lipgloss.NewStyle().Width(column).Render(left), "\n")
+		r := strings.Split(lipgloss.NewStyle().Width(column).Render(right), "\n")
+		for i := 0; i < max(len(l), len(r)); i++ {
+			lc, rc := strings.Repeat(" ", column), ""
+			if i < len(l) {
+				lc = l[i]
+			}
+			if i < len(r) {
+				rc = r[i]
+			}
+			switch mark {
+			case "<":
+				lc = ui.Colorize(lc, ui.Red, false)
+			case ">":
+				rc = ui.Colorize(rc, ui.Green, false)
+			case "|":
+				lc, rc = ui.Colorize(lc, ui.Red, false), ui.Colorize(rc, ui.Green, false)
+			}
+			fmt.Println(lc + " " + ui.Colorize(mark, ui.Yellow, true) + " " + rc)
+			mark = " " // Only the first row of a wrapped line is marked
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Op == transcript.LineEqual {
+			row(lines[i].Text, lines[i].Text, " ")
+			i++
+			continue
+		}
+		var removed, added []string
+		for ; i < len(lines) && lines[i].Op == transcript.LineRemoved; i++ {
+			removed = append(removed, lines[i].Text)
+		}
+		for ; i < len(lines) && lines[i].Op == transcript.LineAdded; i++ {
+			added = append(added, lines[i].Text)
+		}
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			switch {
+			case j >= len(added):
+				row(removed[j], "", "<")
+			case j >= len(removed):
+				row("", added[j], ">")
+			default:
+				row(removed[j], added[j], "|")
+			}
+		}
+	}
+}
+
+// diffAgentName names the agent that spoke a turn, as either transcript records it
+func diffAgentName(d transcript.TurnDiff, a, b *transcript.Transcript) string {
+	switch {
+	case d.A != nil && d.A.Agent != "":
+		return d.A.Agent
+	case d.B != nil && d.B.Agent != "":
+		return d.B.Agent
+	case d.Speaker >= 0 && d.Speaker < 2 && a.Header.Agents[d.Speaker].Name != "":
+		return a.Header.Agents[d.Speaker].Name
+	case d.Speaker >= 0 && d.Speaker < 2:
+		return b.Header.Agents[d.Speaker].Name
+	}
+	return fmt.Sprintf("speaker %d", d.Speaker)
+}
+
+// countDiffering counts the aligned turns whose content differs
+func countDiffering(diffs []transcript.TurnDiff) int {
+	n := 0
+	for _, d := range diffs {
+		if !d.Same() {
+			n++
+		}
+	}
+	return n
+}
+
+// oneLine collapses a multi-line setting onto one line for the settings summary
+func oneLine(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
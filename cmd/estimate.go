@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// printEstimate shows what the run would cost with replies of --estimate-reply-tokens,
+// and at most, with every reply using all of --max-tokens
+func printEstimate(agents [2]*bridge.Agent, opts bridge.Options) {
+	typical := bridge.Estimate(agents[0], agents[1], opts, estimateReply)
+	ceiling := bridge.Estimate(agents[0], agents[1], opts, opts.MaxTokens)
+
+	rounds := opts.MaxRounds - bridge.PriorRounds(opts.Prior, opts.Mode)
+	runs := ""
+	if totalRuns() > 1 {
+		runs = fmt.Sprintf(" × %d runs", totalRuns())
+	}
+
+	lines := []string{
+		fmt.Sprintf("Starter:   ~%d tokens", agents[0].Counter().CountTokens(opts.Starter)),
+		fmt.Sprintf("Rounds:    %d%s", rounds, runs),
+		"",
+		fmt.Sprintf("With ~%d-token replies:", min(estimateReply, opts.MaxTokens)),
+	}
+	for _, agent := range typical.Agents {
+		if agent.Messages == 0 {
+			continue
+		}
+		line := fmt.Sprintf("  %s (%s · %s): %d replies, ~%d in + ~%d out tokens",
+			agent.Name, agent.Provider, agent.Model, agent.Messages*totalRuns(), agent.InputTokens*totalRuns(), agent.OutputTokens*totalRuns())
+		if agent.Priced {
+			line += ", ~" + formatCost(agent.Cost*float64(totalRuns()))
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines,
+		"",
+		"Total:     "+estimateTotal(typical),
+		"At most:   "+estimateTotal(ceiling),
+		"",
+		ui.Colorize(fmt.Sprintf("At most: every reply uses all %d of --max-tokens", opts.MaxTokens), ui.Dim, false),
+		ui.Colorize("Tools, memory, the director, and reinforced system prompts are not counted", ui.Dim, false),
+	)
+
+	fmt.Println()
+	ui.PrintBox("🧮 Estimate", lines)
+}
+
+// estimateTotal describes an estimate's tokens and cost across every run (see totalRuns)
+func estimateTotal(s bridge.Summary) string {
+	tokens := 0
+	for _, agent := range s.Agents {
+		tokens += agent.Tokens()
+	}
+	total := fmt.Sprintf("~%d tokens", tokens*totalRuns())
+	if cost, ok := s.Cost(); ok {
+		return total + ", ~" + formatCost(cost*float64(totalRuns()))
+	}
+	return total + ui.Colorize(" (cost unknown: no pricing for a model)", ui.Dim, false)
+}
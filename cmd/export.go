@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/export"
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportCmdFormat string
+	exportOutput    string
+	exportCmdTmpl   string
+	exportCmdAsst   string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <transcript>",
+	Short: "Render a transcript as Markdown, a standalone HTML page, or fine-tuning data",
+	Long: `Render a saved transcript or checkpoint as a shareable document.
+
+The HTML format produces a single self-contained page styled after the terminal
+theme, with agent-colored message bubbles and highlighted code blocks. All model
+output is escaped, so a transcript can't inject markup into the page.
+
+The openai-ft format writes OpenAI fine-tuning JSONL, one {"messages": [...]}
+example per line, with --assistant's turns as the assistant messages. A
+transcript whose roles don't alternate (e.g. after a skipped turn) or that has
+an empty reply is rejected rather than written.
+
+Examples:
+  # Write conversation.html next to the transcript
+  chat-bridge export conversation.jsonl
+
+  # Markdown to a specific file
+  chat-bridge export conversation.jsonl --format markdown -o notes/chat.md
+
+  # Training data from both sides of the conversation (writes conversation.ft.jsonl)
+  chat-bridge export conversation.jsonl --format openai-ft --assistant both
+
+  # Any other format from a Go text/template (writes conversation.csv)
+  chat-bridge export conversation.jsonl --template turns.csv.tmpl
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var tmpl *export.Template
+		if exportCmdTmpl != "" {
+			var err error
+			if tmpl, err = export.LoadTemplate(exportCmdTmpl); err != nil {
+				return err
+			}
+		} else if err := export.ValidateFormat(exportCmdFormat); err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("assistant") && exportCmdFormat != export.FormatOpenAIFT {
+			return fmt.Errorf("--assistant only applies with --format %s", export.FormatOpenAIFT)
+		}
+		if err := export.ValidateAssistant(exportCmdAsst); err != nil {
+			return err
+		}
+
+		t, err := transcript.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		ext := export.Extension(exportCmdFormat)
+		if tmpl != nil {
+			ext = tmpl.Extension()
+		}
+		path := exportOutput
+		if path == "" {
+			path = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ext
+		}
+		if tmpl != nil {
+			err = tmpl.WriteFile(path, t)
+		} else {
+			err = export.WriteFile(path, exportCmdFormat, t, export.Options{Assistant: exportCmdAsst})
+		}
+		if err != nil {
+			return err
+		}
+
+		ui.PrintSuccess(fmt.Sprintf("Exported %d turns to %s", len(t.Turns), path))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportCmdFormat, "format", "f", export.FormatHTML, "Output format (markdown, html, or openai-ft)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output path (default: transcript name with the format's extension)")
+	exportCmd.Flags().StringVarP(&exportCmdTmpl, "template", "t", "", "Render with this Go text/template file instead of --format")
+	exportCmd.Flags().StringVar(&exportCmdAsst, "assistant", export.AssistantA, "With --format openai-ft, whose turns are the assistant's: a, b, or both (an example from each side)")
+}
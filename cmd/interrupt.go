@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// parseInterruptKey resolves --interrupt-key: a single character, space, tab,
+// enter, or ctrl+<letter>. Keys the terminal itself acts on (ctrl+c, ctrl+z and
+// the ctrl+s/ctrl+q flow control) are rejected.
+func parseInterruptKey(name string) (byte, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	switch lower {
+	case "space":
+		return ' ', nil
+	case "tab":
+		return '\t', nil
+	case "enter", "return":
+		return '\n', nil
+	}
+	if letter, ok := strings.CutPrefix(lower, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		if strings.Contains("czsq", letter) {
+			return 0, fmt.Errorf("%s is handled by the terminal; pick another key", name)
+		}
+		return letter[0] - 'a' + 1, nil
+	}
+	if len(name) == 1 && name[0] > ' ' && name[0] < 0x7f {
+		return name[0], nil
+	}
+	return 0, fmt.Errorf("unknown key %q; use a single character, space, tab, enter, or ctrl+<letter>", name)
+}
+
+// watchKey calls onKey each time key is pressed until stop is called, reading the
+// terminal a key at a time without echo. Other keys are discarded. Signals such
+// as Ctrl+C still reach the process. stop restores the terminal.
+func watchKey(ctx context.Context, key byte, onKey func()) (stop func(), err error) {
+	restore, err := keyMode(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 64)
+		for ctx.Err() == nil {
+			// keyMode makes reads return empty after keyPoll, so ctx is checked regularly
+			n, err := os.Stdin.Read(buf)
+			if err != nil && err != io.EOF {
+				return
+			}
+			// Enter arrives as a carriage return in key mode on some terminals
+			if bytes.IndexByte(buf[:n], key) >= 0 || (key == '\n' && bytes.IndexByte(buf[:n], '\r') >= 0) {
+				onKey()
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+		restore()
+	}, nil
+}
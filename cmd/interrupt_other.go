@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package cmd
+
+import (
+	"errors"
+	"os"
+)
+
+// keyMode isn't available on this platform, so --interrupt-key is ignored with a warning
+func keyMode(f *os.File) (restore func(), err error) {
+	return nil, errors.New("reading single keys isn't supported on this platform")
+}
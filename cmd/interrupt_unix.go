@@ -0,0 +1,34 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// keyPoll is how long a read waits for a key before returning empty
+const keyPoll = 100 * time.Millisecond
+
+// keyMode switches the terminal to reading single keys without echo, keeping
+// output processing and signal keys as they were, and returns how to undo it
+func keyMode(f *os.File) (restore func(), err error) {
+	fd := int(f.Fd())
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	termios := *saved
+	termios.Lflag &^= unix.ICANON | unix.ECHO
+	termios.Cc[unix.VMIN] = 0
+	termios.Cc[unix.VTIME] = uint8(keyPoll / (100 * time.Millisecond))
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &termios); err != nil {
+		return nil, err
+	}
+	return func() {
+		unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+	}, nil
+}
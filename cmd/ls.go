@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsTags     []string
+	lsProvider string
+)
+
+// lsCmd represents the ls command
+var lsCmd = &cobra.Command{
+	Use:   "ls [dir]",
+	Short: "List the transcripts in a directory",
+	Long: `List the transcripts in a directory (default: the working directory), newest
+first, with when each session started, the providers of both agents, its tags,
+and how many rounds it recorded. Each file is scanned for its header, end record
+and turn rounds, without parsing the replies; nothing is indexed ahead of time.
+
+Examples:
+  # Everything recorded with --log-dir logs
+  chat-bridge ls logs
+
+  # Sessions tagged both research and draft that used ollama on either side
+  chat-bridge ls logs --tag research,draft --provider ollama
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		entries, err := transcript.Index(dir)
+		if err != nil {
+			return err
+		}
+
+		ui.PrintSectionHeader("Transcripts", "📚")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  DATE\tPROVIDERS\tTAGS\tROUNDS\tFILE")
+		shown := 0
+		for _, e := range entries {
+			if !e.HasTags(lsTags...) || (lsProvider != "" && !e.UsesProvider(lsProvider)) {
+				continue
+			}
+			a, b := e.Header.Agents[0], e.Header.Agents[1]
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%s\n",
+				e.Header.Started.Local().Format("2006-01-02 15:04"), a.ProviderName()+", "+b.ProviderName(),
+				strings.Join(e.Header.Tags, ","), e.Rounds, filepath.Base(e.Path))
+			shown++
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if shown < len(entries) {
+			fmt.Printf("\n  %s\n", ui.Colorize(fmt.Sprintf("%d of %d transcripts match", shown, len(entries)), ui.Dim, false))
+		}
+		return nil
+	},
+}
+
+func init() {
+	lsCmd.Flags().StringSliceVar(&lsTags, "tag", nil, "Only sessions with this tag (repeatable or comma-separated; all must match)")
+	lsCmd.Flags().StringVar(&lsProvider, "provider", "", "Only sessions where either agent used this provider or alias")
+	rootCmd.AddCommand(lsCmd)
+}
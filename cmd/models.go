@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// modelsTimeout bounds how long a provider gets to list its models
+const modelsTimeout = 15 * time.Second
+
+// modelsCmd represents the models command
+var modelsCmd = &cobra.Command{
+	Use:   "models [provider|alias]",
+	Short: "List the models a provider or alias offers",
+	Long: `List models. Without arguments, shows the known models of every registered
+provider and the model each alias uses. With a provider or alias, asks that
+provider for its models, falling back to the known models if it can't be
+reached; the default model is marked with *. Context window
+and output limits are shown for models whose limits are known.
+
+Examples:
+  chat-bridge models
+  chat-bridge models openai
+  chat-bridge models local
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			for _, spec := range providers.ListProviders() {
+				printModels(spec.Key, spec.Models, cfg.GetDefaultModel(spec.Key))
+			}
+			if len(cfg.Aliases) > 0 {
+				ui.PrintSectionHeader("Aliases", "🏷️")
+				for _, name := range cfg.AliasNames() {
+					alias, _ := cfg.ResolveAlias(name)
+					fmt.Printf("  %s → %s / %s\n", ui.Colorize(name, ui.Cyan, true), alias.Provider, cfg.GetDefaultModel(name))
+				}
+			}
+			return nil
+		}
+
+		agent, err := bridge.NewAgent(cfg, bridge.AgentConfig{Name: args[0], Provider: args[0]})
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), modelsTimeout)
+		defer cancel()
+		spec, _ := providers.GetProviderSpec(cfg.ProviderKey(args[0]))
+		ids, err := agent.Provider.Models(ctx)
+		if err != nil {
+			if len(spec.Models) == 0 {
+				return fmt.Errorf("%s: failed to list models: %w", args[0], err)
+			}
+			ui.PrintWarning(fmt.Sprintf("%s: failed to list models (%v); showing the known models instead", args[0], err))
+			ids = spec.ModelIDs()
+		}
+
+		// Live lists only name models; fill in the limits the spec knows
+		models := make([]providers.ModelInfo, len(ids))
+		for i, id := range ids {
+			if models[i], _ = spec.Model(id); models[i].ID == "" {
+				models[i].ID = id
+			}
+		}
+		printModels(describeProvider(cfg, args[0]), models, agent.Model)
+		return nil
+	},
+}
+
+// printModels prints one provider's models with their known limits, marking the default
+func printModels(title string, models []providers.ModelInfo, defaultModel string) {
+	ui.PrintSectionHeader(title, "🤖")
+	if len(models) == 0 {
+		fmt.Printf("  %s\n", ui.Colorize("No models listed", ui.Dim, false))
+		return
+	}
+
+	width := 0
+	for _, model := range models {
+		width = max(width, len(model.ID))
+	}
+	for _, model := range models {
+		line := model.ID
+		if limits := modelLimits(model); limits != "" {
+			line = fmt.Sprintf("%-*s  %s", width, model.ID, ui.Colorize(limits, ui.Dim, false))
+		}
+		if model.ID == defaultModel {
+			fmt.Printf("  %s %s\n", ui.Colorize("*", ui.Yellow, true), line)
+		} else {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+// modelLimits describes a model's context window and output limit, e.g. "128K context, 16K output"
+func modelLimits(m providers.ModelInfo) string {
+	var parts []string
+	if m.ContextWindow > 0 {
+		parts = append(parts, formatTokens(m.ContextWindow)+" context")
+	}
+	if m.MaxOutputTokens > 0 {
+		parts = append(parts, formatTokens(m.MaxOutputTokens)+" output")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTokens abbreviates a token count to thousands, e.g. 128000 → "128K" and 16385 → "16K"
+func formatTokens(n int) string {
+	if n < 1000 {
+		return strconv.Itoa(n)
+	}
+	return strconv.Itoa(n/1000) + "K"
+}
+
+func init() {
+	rootCmd.AddCommand(modelsCmd)
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/persona"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// personasCmd lists the persona files start can pick from
+var personasCmd = &cobra.Command{
+	Use:   "personas",
+	Short: "List available personas",
+	Long: `List the persona files in ./personas and chat-bridge/personas in the user config
+directory (or only BRIDGE_PERSONA_DIR, if set). Run 'chat-bridge start' without flags
+on a terminal to pick a persona for each agent from this list.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		entries, err := persona.List(persona.Dirs(), personaChecks(cfg))
+		if err != nil {
+			return err
+		}
+
+		ui.PrintSectionHeader("Personas", "🎭")
+		if len(entries) == 0 {
+			ui.PrintInfo(fmt.Sprintf("No personas found in %s", strings.Join(persona.Dirs(), " or ")))
+			return nil
+		}
+		printPersonaMenu(entries)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(personasCmd)
+}
+
+// printPersonaMenu numbers the personas from 1, pointing files with problems at validate
+func printPersonaMenu(entries []persona.Entry) {
+	for i, e := range entries {
+		description := e.Path
+		switch {
+		case e.Err != nil:
+			description = fmt.Sprintf("⚠️ invalid; see 'chat-bridge validate persona %s'", e.Path)
+		case e.Persona.Description != "":
+			description = fmt.Sprintf("%s (%s)", e.Persona.Description, e.Path)
+		}
+		ui.PrintMenuOption(strconv.Itoa(i+1), e.Name(), description)
+	}
+}
+
+// pickPersonas asks which listed persona each agent should use, setting --persona-a
+// and --persona-b. Files with problems aren't offered, and with nothing to offer
+// it returns without asking.
+func pickPersonas(cfg *config.Config) error {
+	entries, err := persona.List(persona.Dirs(), personaChecks(cfg))
+	if err != nil {
+		return err
+	}
+	var valid []persona.Entry
+	for _, e := range entries {
+		if e.Err == nil {
+			valid = append(valid, e)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	ui.PrintSectionHeader("Choose Personas", "🎭")
+	printPersonaMenu(valid)
+	fmt.Println()
+	sides := []struct {
+		name   string
+		target *string
+	}{{nameA, &personaA}, {nameB, &personaB}}
+	for _, side := range sides {
+		for {
+			fmt.Print(ui.Colorize(fmt.Sprintf("Persona for %s (1-%d, Enter for none): ", side.name, len(valid)), ui.Yellow, false))
+			answer, err := readLine(context.Background())
+			if err != nil {
+				fmt.Println()
+				return nil // Out of input: carry on with what was picked
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				break
+			}
+			n, err := strconv.Atoi(answer)
+			if err != nil || n < 1 || n > len(valid) {
+				ui.PrintWarning(fmt.Sprintf("Pick a number from 1 to %d", len(valid)))
+				continue
+			}
+			*side.target = valid[n-1].Path
+			break
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// personaChecks validates the providers and temperatures persona files name
+// against the registered providers and the config file's aliases
+func personaChecks(cfg *config.Config) persona.Checks {
+	return persona.Checks{
+		Provider: func(name string) error {
+			if name == autoProvider {
+				return nil
+			}
+			if _, ok := cfg.ResolveAlias(name); ok {
+				return nil
+			}
+			if _, ok := providers.GetProviderFactory(name); ok {
+				return nil
+			}
+			available := append(providerKeys(), cfg.AliasNames()...)
+			return fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(available, ", "))
+		},
+		Temperature: func(provider string, value float64) error {
+			spec, ok := providers.GetProviderSpec(cfg.ProviderKey(provider))
+			if !ok {
+				return nil
+			}
+			return spec.ValidateTemperature(value)
+		},
+	}
+}
+
+// providerKeys returns the providers that can be instantiated, sorted by key
+func providerKeys() []string {
+	var keys []string
+	for _, spec := range providers.ListProviders() {
+		if _, ok := providers.GetProviderFactory(spec.Key); ok {
+			keys = append(keys, spec.Key)
+		}
+	}
+	return keys
+}
+
+// applyPersonas loads --template, then --persona-a and --persona-b, using their
+// settings for every flag that wasn't given explicitly
+func applyPersonas(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	checks := personaChecks(cfg)
+
+	var agents [2]*persona.Persona
+	if templatePath != "" {
+		t, err := persona.LoadTemplate(templatePath, checks)
+		if err != nil {
+			return fmt.Errorf("invalid --template:\n%w", err)
+		}
+		if !flags.Changed("starter") {
+			starter = t.Starter
+		}
+		if !flags.Changed("max-rounds") && t.MaxRounds > 0 {
+			maxRounds = t.MaxRounds
+		}
+		if !flags.Changed("mode") && t.Mode != "" {
+			mode = t.Mode
+		}
+		agents = t.Agents
+	}
+
+	for i, path := range []string{personaA, personaB} {
+		if path == "" {
+			continue
+		}
+		p, err := persona.LoadPersona(path, checks)
+		if err != nil {
+			return fmt.Errorf("invalid --persona-%s:\n%w", []string{"a", "b"}[i], err)
+		}
+		agents[i] = p
+	}
+
+	set := func(name string, target *string, value string) {
+		if !flags.Changed(name) && value != "" {
+			*target = value
+		}
+	}
+	sides := [2]struct {
+		suffix                               string
+		name, system, provider, model, color *string
+		temp                                 *temperatureFlag
+	}{
+		{"a", &nameA, &systemA, &providerA, &modelA, &colorA, &tempA},
+		{"b", &nameB, &systemB, &providerB, &modelB, &colorB, &tempB},
+	}
+	for i, p := range agents {
+		if p == nil {
+			continue
+		}
+		side := sides[i]
+		set("name-"+side.suffix, side.name, p.Name)
+		set("system-"+side.suffix, side.system, p.SystemPrompt)
+		set("provider-"+side.suffix, side.provider, p.Provider)
+		set("model-"+side.suffix, side.model, p.Model)
+		set("color-"+side.suffix, side.color, p.Color)
+		if !flags.Changed("temp-"+side.suffix) && p.Temperature != nil {
+			side.temp.value, side.temp.set = p.Temperature, true
+		}
+	}
+	return nil
+}
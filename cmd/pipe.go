@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// loadPipeScript reads the --pipe script and deals its turns out alternately,
+// Agent A first. Without --max-rounds the run lasts as long as the script.
+func loadPipeScript(cmd *cobra.Command) ([2][]string, error) {
+	var script [2][]string
+	switch {
+	case resumePath != "":
+		return script, fmt.Errorf("--pipe replays a script from the start, so it can't be combined with --resume")
+	case director != "":
+		return script, fmt.Errorf("--pipe can't be combined with --director, which needs a provider")
+	case repeat > 1:
+		return script, fmt.Errorf("--pipe plays its script once, so it can't be combined with --repeat")
+	case tempSweepA != "" || tempSweepB != "":
+		return script, fmt.Errorf("--pipe plays its script once, so it can't be combined with --temp-sweep-a/-b")
+	case healthMode == healthVerbose:
+		return script, fmt.Errorf("--health verbose would use up a scripted turn; it doesn't apply with --pipe")
+	// Anything that can make a follow-up request would take the next scripted turn
+	// and throw the rest of the script out of step
+	case minChars > 0:
+		return script, fmt.Errorf("--min-response-chars asks for more, which would use up a scripted turn; it doesn't apply with --pipe")
+	case jsonModeA || jsonModeB || schemaFileA != "" || schemaFileB != "":
+		return script, fmt.Errorf("--json-mode-a/-b and --schema-file-a/-b retry invalid replies, which would use up a scripted turn; they don't apply with --pipe")
+	case onRefusal == string(bridge.RefusalNudge):
+		return script, fmt.Errorf("--on-refusal nudge asks again, which would use up a scripted turn; it doesn't apply with --pipe")
+	case retryCooler:
+		return script, fmt.Errorf("--retry-cooler asks again, which would use up a scripted turn; it doesn't apply with --pipe")
+	case humanEvery > 0:
+		return script, fmt.Errorf("--human-every can regenerate a reply, which would use up a scripted turn; it doesn't apply with --pipe")
+	case pipeDelimiter == "":
+		return script, fmt.Errorf("--pipe-delimiter must not be empty")
+	case pipeDelay < 0:
+		return script, fmt.Errorf("--pipe-delay must be 0 or more")
+	}
+
+	var r io.Reader = os.Stdin
+	if pipePath != "-" {
+		f, err := os.Open(pipePath)
+		if err != nil {
+			return script, fmt.Errorf("failed to open --pipe script: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	turns, err := providers.ParseScript(r, pipeDelimiter)
+	if err != nil {
+		return script, fmt.Errorf("failed to read --pipe script: %w", err)
+	}
+	if len(turns) == 0 {
+		return script, fmt.Errorf("--pipe script %s has no turns", pipePath)
+	}
+
+	for i, turn := range turns {
+		script[i%2] = append(script[i%2], turn)
+	}
+	if !cmd.Flags().Changed("max-rounds") {
+		maxRounds = len(turns)
+		if mode == string(bridge.ModeSimultaneous) {
+			maxRounds = len(script[0])
+		}
+	}
+	return script, nil
+}
+
+// newAgent creates an agent from its flags or, with --pipe, one replaying its
+// share of the script in place of the provider
+func newAgent(cfg *config.Config, ac bridge.AgentConfig, script []string) (*bridge.Agent, error) {
+	if pipePath == "" {
+		return bridge.NewAgent(cfg, ac)
+	}
+	p := providers.NewScriptProvider(script, pipeDelay)
+	return &bridge.Agent{
+		Name:         ac.Name,
+		Provider:     p,
+		Model:        p.DefaultModel(),
+		SystemPrompt: ac.SystemPrompt,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// profileHeaders holds the headers of the profiles applied to Agent A and Agent B,
+// which --header-a and --header-b add to
+var profileHeaders [2]map[string]string
+
+// applyProfiles applies --profile-a and --profile-b from the config file to every
+// flag that wasn't given explicitly. Personas are applied after them, so their
+// settings take precedence too.
+func applyProfiles(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	set := func(name string, target *string, value string) {
+		if !flags.Changed(name) && value != "" {
+			*target = value
+		}
+	}
+	sides := [2]struct {
+		suffix                  string
+		provider, model, system *string
+		temp                    *temperatureFlag
+	}{
+		{"a", &providerA, &modelA, &systemA, &tempA},
+		{"b", &providerB, &modelB, &systemB, &tempB},
+	}
+
+	for i, name := range []string{profileA, profileB} {
+		if name == "" {
+			continue
+		}
+		side := sides[i]
+		p, ok := cfg.ResolveProfile(name)
+		if !ok {
+			return fmt.Errorf("unknown --profile-%s %q (%s)", side.suffix, name, profileHint(cfg))
+		}
+
+		set("provider-"+side.suffix, side.provider, p.Provider)
+		set("model-"+side.suffix, side.model, p.Model)
+		set("system-"+side.suffix, side.system, p.SystemPrompt)
+		if !flags.Changed("temp-"+side.suffix) && p.Temperature != nil {
+			side.temp.value, side.temp.set = p.Temperature, true
+		}
+		profileHeaders[i] = make(map[string]string, len(p.Headers))
+		for header, value := range p.Headers {
+			header, value, err := providers.ParseHeader(header + "=" + value)
+			if err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+			profileHeaders[i][header] = value
+		}
+	}
+	return nil
+}
+
+// withProfileHeaders adds a profile's headers to those given by flag, which win
+func withProfileHeaders(profile, flags map[string]string) map[string]string {
+	if len(profile) == 0 {
+		return flags
+	}
+	headers := make(map[string]string, len(profile)+len(flags))
+	for name, value := range profile {
+		headers[name] = value
+	}
+	for name, value := range flags {
+		headers[name] = value
+	}
+	return headers
+}
+
+// profileHint lists the configured profiles for an error message
+func profileHint(cfg *config.Config) string {
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		return fmt.Sprintf("no profiles are configured; define them under \"profiles\" in %s", config.DefaultFileName)
+	}
+	return "available: " + strings.Join(names, ", ")
+}
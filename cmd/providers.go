@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// providersCmd represents the providers command
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "List registered providers, configured aliases and profiles",
+	Long: `List the registered providers with their default models and whether an API
+key is configured, followed by the aliases and profiles defined in the config file.
+
+Aliases can be used anywhere a provider is accepted, e.g. --provider-a local.
+Profiles bundle an agent's settings, e.g. --profile-a critic.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		ui.PrintSectionHeader("Providers", "🔌")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  PROVIDER\tNAME\tDEFAULT MODEL\tAPI KEY\tDESCRIPTION")
+		for _, spec := range providers.ListProviders() {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+				spec.Key, spec.Name, cfg.GetDefaultModel(spec.Key), keyStatus(cfg, spec.Key, spec.NeedsAPIKey), spec.Description)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if err := printAliases(cfg); err != nil {
+			return err
+		}
+		return printProfiles(cfg)
+	},
+}
+
+// printAliases lists the config file's aliases, or how to define them
+func printAliases(cfg *config.Config) error {
+	ui.PrintSectionHeader("Aliases", "🏷️")
+	if len(cfg.Aliases) == 0 {
+		fmt.Printf("  No aliases configured. Define them under \"aliases\" in %s.\n", config.DefaultFileName)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ALIAS\tPROVIDER\tBASE URL\tMODEL\tAPI KEY\tDESCRIPTION")
+	for _, name := range cfg.AliasNames() {
+		alias, _ := cfg.ResolveAlias(name)
+		spec, _ := providers.GetProviderSpec(alias.Provider)
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+			name, alias.Provider, cfg.GetProviderBaseURL(name), cfg.GetDefaultModel(name),
+			keyStatus(cfg, name, spec.NeedsAPIKey && alias.BaseURL == ""), alias.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n  %s\n", ui.Colorize("From "+cfg.ConfigFile, ui.Dim, false))
+	return nil
+}
+
+// printProfiles lists the config file's profiles, if any
+func printProfiles(cfg *config.Config) error {
+	if len(cfg.Profiles) == 0 {
+		return nil
+	}
+	ui.PrintSectionHeader("Profiles", "🎛️")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PROFILE\tPROVIDER\tMODEL\tTEMPERATURE\tHEADERS\tDESCRIPTION")
+	for _, name := range cfg.ProfileNames() {
+		p, _ := cfg.ResolveProfile(name)
+		provider, model, temperature := p.Provider, p.Model, "-"
+		if provider == "" {
+			provider = "-"
+		}
+		if model == "" {
+			model = "-"
+		}
+		if p.Temperature != nil {
+			temperature = strconv.FormatFloat(*p.Temperature, 'g', -1, 64)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%d\t%s\n", name, provider, model, temperature, len(p.Headers), p.Description)
+	}
+	return w.Flush()
+}
+
+// keyStatus describes whether a provider or alias has the credentials it needs
+func keyStatus(cfg *config.Config, name string, needsKey bool) string {
+	switch {
+	case cfg.GetAPIKey(name) != "":
+		return "configured"
+	case needsKey:
+		return "missing"
+	default:
+		return "not needed"
+	}
+}
+
+// readyProviders lists the providers and aliases whose API keys are configured
+func readyProviders(cfg *config.Config) []string {
+	var ready []string
+	for _, spec := range providers.ListProviders() {
+		if spec.NeedsAPIKey && cfg.GetAPIKey(spec.Key) != "" {
+			ready = append(ready, spec.Key)
+		}
+	}
+	for _, name := range cfg.AliasNames() {
+		alias, _ := cfg.ResolveAlias(name)
+		spec, ok := providers.GetProviderSpec(alias.Provider)
+		if ok && keyStatus(cfg, name, spec.NeedsAPIKey && alias.BaseURL == "") != "missing" {
+			ready = append(ready, name)
+		}
+	}
+	return ready
+}
+
+// autoProvider as --provider-a/--provider-b picks a ready provider
+const autoProvider = "auto"
+
+// autoProviderOrder ranks providers for auto-selection; other ready providers and
+// aliases follow in readyProviders order
+var autoProviderOrder = []string{"openai", "anthropic", "gemini", "deepseek", "openrouter"}
+
+// resolveAutoProviders replaces auto selections with ready providers, choosing two
+// different ones when possible so the conversation is cross-model
+func resolveAutoProviders(cfg *config.Config, a, b *string) error {
+	if *a != autoProvider && *b != autoProvider {
+		return nil
+	}
+
+	ready := readyProviders(cfg)
+	candidates := make([]string, 0, len(ready))
+	for _, key := range autoProviderOrder {
+		if slices.Contains(ready, key) {
+			candidates = append(candidates, key)
+		}
+	}
+	for _, name := range ready {
+		if !slices.Contains(candidates, name) {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no provider has an API key configured to auto-select; set one (e.g. OPENAI_API_KEY) or choose a provider explicitly")
+	}
+
+	pick := func(other string) string {
+		for _, name := range candidates {
+			if name != other {
+				return name
+			}
+		}
+		return candidates[0]
+	}
+	if *a == autoProvider {
+		*a = pick(*b)
+		ui.PrintInfo(fmt.Sprintf("Auto-selected %s for Agent A", *a))
+	}
+	if *b == autoProvider {
+		*b = pick(*a)
+		ui.PrintInfo(fmt.Sprintf("Auto-selected %s for Agent B", *b))
+	}
+	if *a == *b && len(candidates) == 1 {
+		ui.PrintWarning(fmt.Sprintf("Only %s is configured, so both agents use it", *a))
+	}
+	return nil
+}
+
+// suggestReadyProviders points at the providers that would work after a missing-key error
+func suggestReadyProviders(cfg *config.Config) {
+	if ready := readyProviders(cfg); len(ready) > 0 {
+		ui.PrintInfo("Providers ready to use: " + strings.Join(ready, ", "))
+		return
+	}
+	ui.PrintInfo("No registered provider has an API key configured; run 'chat-bridge providers' to see what each one needs")
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// runPath gives each --repeat or sweep run its own transcript file ("debate.jsonl"
+// becomes "debate-run2.jsonl" or "debate-temp-a0.7.jsonl"; see runSuffix); single
+// runs keep the path as given
+func runPath(path string, run int) string {
+	suffix := runSuffix(run)
+	if suffix == "" || path == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), suffix, ext)
+}
+
+// repeatSummaryLines aggregates the runs of --repeat for the closing box; like the
+// per-run summary, token counts and costs are estimates
+func repeatSummaryLines(results []*bridge.Result) []string {
+	n := len(results)
+	rounds, minRounds, maxRounds := 0, results[0].Rounds, results[0].Rounds
+	farewells, farewellRounds := 0, 0
+	tokens := 0
+	var elapsed time.Duration
+	cost, priced := 0.0, true
+
+	for _, result := range results {
+		rounds += result.Rounds
+		minRounds = min(minRounds, result.Rounds)
+		maxRounds = max(maxRounds, result.Rounds)
+		if result.Reason == bridge.StopFarewell {
+			farewells++
+			farewellRounds += result.Rounds
+		}
+		for _, agent := range result.Summary.Agents {
+			tokens += agent.Tokens()
+		}
+		elapsed += result.Elapsed
+		runCost, ok := result.Summary.Cost()
+		cost += runCost
+		priced = priced && ok
+	}
+
+	lines := []string{
+		fmt.Sprintf("Runs:      %d", n),
+		fmt.Sprintf("Rounds:    %.1f avg (min %d, max %d)", float64(rounds)/float64(n), minRounds, maxRounds),
+	}
+	farewell := fmt.Sprintf("Farewells: %d of %d runs", farewells, n)
+	if farewells > 0 {
+		farewell += fmt.Sprintf(", after %.1f rounds on average", float64(farewellRounds)/float64(farewells))
+	}
+	lines = append(lines,
+		farewell,
+		fmt.Sprintf("Duration:  %s avg", (elapsed/time.Duration(n)).Round(100*time.Millisecond)),
+		"",
+		fmt.Sprintf("Tokens:    ~%d avg per run, ~%d total", tokens/n, tokens),
+	)
+
+	if priced {
+		lines = append(lines, fmt.Sprintf("Cost:      ~%s avg per run, ~%s total", formatCost(cost/float64(n)), formatCost(cost)))
+	} else {
+		lines = append(lines, ui.Colorize("Cost:      unknown (no pricing for a model)", ui.Dim, false))
+	}
+	return lines
+}
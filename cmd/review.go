@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// stdin is shared by every review so buffered input isn't lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// reviewPrompt shows the prompt an agent is about to receive and asks whether to
+// accept, edit, regenerate, or skip it (--human-every). Once input runs out, the
+// rest of the run proceeds unattended.
+func reviewPrompt(ctx context.Context, ev bridge.Event) bridge.Decision {
+	fmt.Println()
+	ui.PrintInfo(fmt.Sprintf("Round %d: %s is about to receive:", ev.Round, ev.Agent.Name))
+	for _, line := range strings.Split(ev.Review.Prompt, "\n") {
+		fmt.Println(ui.Colorize("  │ ", ui.Dim, false) + line)
+	}
+
+	question := "[a]ccept, [e]dit, or [s]kip reviews for the rest of the run? [a] "
+	if ev.Review.Regenerable {
+		question = "[a]ccept, [e]dit, [r]egenerate it (optionally at a temperature, e.g. r 1.2), or [s]kip reviews for the rest of the run? [a] "
+	}
+	for {
+		fmt.Print(ui.Colorize(question, ui.Yellow, false))
+		answer, err := readLine(ctx)
+		if err != nil {
+			return unattended(ctx, err)
+		}
+
+		choice, arg, _ := strings.Cut(strings.TrimSpace(answer), " ")
+		switch strings.ToLower(choice) {
+		case "r", "regen", "/regen", "regenerate":
+			if !ev.Review.Regenerable {
+				ui.PrintWarning("Only replies from this run can be regenerated")
+				continue
+			}
+			d := bridge.Decision{Action: bridge.ReviewRegenerate}
+			if arg = strings.TrimSpace(arg); arg != "" {
+				temp, err := strconv.ParseFloat(arg, 64)
+				if err != nil || temp < 0 {
+					ui.PrintWarning(fmt.Sprintf("Invalid temperature %q", arg))
+					continue
+				}
+				d.Temperature = &temp
+			}
+			ui.PrintInfo("Regenerating the reply; the new one replaces it in the history and transcript")
+			return d
+		case "", "a", "accept":
+			return bridge.Decision{Action: bridge.ReviewAccept}
+		case "s", "skip":
+			ui.PrintInfo("Running the rest of the conversation without pausing")
+			return bridge.Decision{Action: bridge.ReviewSkip}
+		case "e", "edit":
+			prompt, err := readPrompt(ctx)
+			if err != nil {
+				return unattended(ctx, err)
+			}
+			if prompt == "" {
+				ui.PrintInfo("Nothing entered; sending the prompt as proposed")
+				return bridge.Decision{Action: bridge.ReviewAccept}
+			}
+			return bridge.Decision{Action: bridge.ReviewEdit, Prompt: prompt}
+		default:
+			ui.PrintWarning(fmt.Sprintf("Unknown choice %q", answer))
+		}
+	}
+}
+
+// readPrompt reads a replacement prompt, one or more lines ended by an empty line
+func readPrompt(ctx context.Context) (string, error) {
+	fmt.Println(ui.Colorize("New prompt (finish with an empty line):", ui.Yellow, false))
+	var lines []string
+	for {
+		line, err := readLine(ctx)
+		if err != nil {
+			return "", err
+		}
+		if line == "" {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// readLine reads a line from stdin, giving up when ctx is cancelled
+func readLine(ctx context.Context) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := stdin.ReadString('\n')
+		if line != "" {
+			err = nil // A last line without a newline still counts
+		}
+		read <- result{strings.TrimRight(line, "\r\n"), err}
+	}()
+
+	select {
+	case r := <-read:
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// unattended skips the remaining reviews when stdin can't answer them; a cancelled
+// ctx stops the conversation anyway
+func unattended(ctx context.Context, err error) bridge.Decision {
+	fmt.Println()
+	if ctx.Err() == nil {
+		ui.PrintWarning(fmt.Sprintf("No answer (%v); running the rest of the conversation without pausing", err))
+	}
+	return bridge.Decision{Action: bridge.ReviewSkip}
+}
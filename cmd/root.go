@@ -2,15 +2,28 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/markjamesm/chat-bridge-go/internal/logging"
 	"github.com/markjamesm/chat-bridge-go/internal/version"
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
 	"github.com/markjamesm/chat-bridge-go/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	showVersion bool
+	proxyURL    string
+	logLevel    string
+	logJSON     bool
+	traceDir    string
+	configPath  string
+	envFile     string
+	themeName   string
+	noBanner    bool
+	bannerFile  string
 )
 
 // rootCmd represents the base command
@@ -31,15 +44,38 @@ Features:
   • 🧠 Optional MCP memory integration
   • 🔄 Support for multiple AI providers
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		theme, err := ui.LookupTheme(themeName)
+		if err != nil {
+			return fmt.Errorf("invalid --theme: %w", err)
+		}
+		ui.SetTheme(theme)
+
+		switch {
+		case noBanner && bannerFile != "":
+			return fmt.Errorf("--banner and --no-banner can't be combined")
+		case noBanner:
+			ui.DisableBanner()
+		case bannerFile != "":
+			if err := applyBanner(bannerFile); err != nil {
+				return fmt.Errorf("invalid --banner: %w", err)
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if showVersion {
 			fmt.Printf("Chat Bridge v%s\n", version.GetVersion())
-			return
+			return nil
 		}
 
-		// Show banner and help if no subcommand
+		// Show banner and help if no subcommand, loading the config first so
+		// its banner setting applies here too
+		if _, err := loadConfig(); err != nil {
+			return err
+		}
 		ui.PrintBanner()
-		cmd.Help()
+		return cmd.Help()
 	},
 }
 
@@ -53,4 +89,42 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Log verbosity on stderr: debug, info, warn, or error")
+	rootCmd.PersistentFlags().BoolVar(&logJSON, "log-json", false, "Write logs as JSON")
+	rootCmd.PersistentFlags().StringVar(&traceDir, "trace-dir", "", "Write raw provider requests and responses to this directory")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default: BRIDGE_CONFIG, ./chat-bridge.json, or the user config directory)")
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", "", "File to load environment variables from (default: CHAT_BRIDGE_ENV, or ./.env if present)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "auto", "Color theme: auto (light or retro, by terminal background), "+strings.Join(ui.ThemeNames(), ", "))
+	rootCmd.PersistentFlags().BoolVar(&noBanner, "no-banner", false, "Don't print the welcome banner (it is also skipped when stdout isn't a terminal)")
+	rootCmd.PersistentFlags().StringVar(&bannerFile, "banner", "", "File holding a banner to print instead of the default one")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "Proxy URL for provider requests (overrides HTTP(S)_PROXY and BRIDGE_PROXY)")
+}
+
+// loadConfig loads configuration, applies global flag overrides, and installs
+// the default logger with every configured credential redacted
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadFiles(configPath, envFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if proxyURL != "" {
+		cfg.Proxy = proxyURL
+	}
+	cfg.TraceDir = traceDir
+
+	// The banner flags take precedence over BRIDGE_BANNER and the config file
+	if !noBanner && bannerFile == "" {
+		if err := applyBanner(cfg.Banner); err != nil {
+			return nil, fmt.Errorf("invalid banner setting: %w", err)
+		}
+	}
+
+	logger, err := logging.New(os.Stderr, logLevel, logJSON, cfg.Secrets())
+	if err != nil {
+		return nil, fmt.Errorf("invalid --log-level: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	return cfg, nil
 }
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/server"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr      string
+	serveHealthTTL time.Duration
+)
+
+// shutdownTimeout bounds how long in-flight requests get to finish on SIGTERM
+const shutdownTimeout = 10 * time.Second
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve conversations over HTTP with Server-Sent Events",
+	Long: `Run an HTTP server that drives conversations remotely.
+
+Endpoints:
+  GET  /health          Liveness check with version information
+  POST /conversations   Start a conversation and stream turns as Server-Sent Events
+
+Request body (all fields optional):
+  {"provider_a": "openai", "provider_b": "anthropic", "model_a": "", "model_b": "",
+   "temp_a": 0.7, "temp_b": 0.7, "name_a": "Agent A", "name_b": "Agent B",
+   "starter": "Hello! How are you today?", "max_rounds": 10, "stop_on_farewell": false}
+
+Events: turn_start, token, turn, done, error
+
+Examples:
+  # Listen on the default address
+  chat-bridge serve
+
+  # Stream a conversation with curl
+  curl -N -X POST localhost:8080/conversations -d '{"provider_a":"openai","provider_b":"openai","max_rounds":3}'
+`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveHealthTTL, "health-cache-ttl", bridge.DefaultHealthCacheTTL, "Reuse a provider's passed health check for this long across sessions (0 checks every session)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Cancelled on SIGINT/SIGTERM; request contexts derive from it so streams stop on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if serveHealthTTL < 0 {
+		return fmt.Errorf("--health-cache-ttl must be 0 or more")
+	}
+	handler := server.New(cfg)
+	handler.SetHealthCacheTTL(serveHealthTTL)
+
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	ui.PrintSuccess(fmt.Sprintf("Chat Bridge server listening on %s", serveAddr))
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	ui.PrintInfo("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	ui.PrintSuccess("Server stopped")
+
+	return nil
+}
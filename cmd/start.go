@@ -2,27 +2,178 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
 	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/conversation"
+	"github.com/markjamesm/chat-bridge-go/pkg/export"
+	"github.com/markjamesm/chat-bridge-go/pkg/mcp"
 	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/tools"
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
 	"github.com/markjamesm/chat-bridge-go/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	providerA   string
-	providerB   string
-	modelA      string
-	modelB      string
-	tempA       float64
-	tempB       float64
-	starter     string
-	maxRounds   int
+	providerA        string
+	providerB        string
+	modelA           string
+	modelB           string
+	modelScheduleA   string
+	modelScheduleB   string
+	agentA           string
+	agentB           string
+	tempA            temperatureFlag
+	tempB            temperatureFlag
+	starter          string
+	maxRounds        int
+	additionalRounds int
+	countRoundsBy    string
+	nameA            string
+	nameB            string
+	colorA           string
+	colorB           string
+
+	stopOnFarewell   bool
+	farewellPatterns []string
+	stripPreamble    bool
+	preamblePatterns []string
+	onLoop           string
+	loopThreshold    float64
+	loopWindow       int
+	loopRepeats      int
+	humanEvery       int
+	interruptKey     string
+	interruptCode    byte // Parsed from --interrupt-key at startup
+
+	director       string
+	directorEvery  int
+	directorPrompt string
+
+	execCmdA string
+	execCmdB string
+
+	useMemory bool
+
+	systemA          string
+	systemB          string
+	seed             int
+	topP             float64
+	frequencyPenalty float64
+	presencePenalty  float64
+
+	transcriptPath  string
+	resumePath      string
+	branching       bool
+	checkpointEvery int
+	checkpointDir   string
+	exportFormat    string
+	exportAssistant string
+	exportTmplPath  string
+	quiet           bool
+	reinforceEvery  int
+	imageRefs       []string
+	tags            []string
+	turnPrefix      string
+	turnSuffix      string
+	injectRound     bool
+	roundTemplate   string
+	compactHistory  bool
+	compactMax      int
+	contextFile     string
+	minChars        int
+	maxChars        int
+	elaborate       string
+	healthTTL       time.Duration
+	healthMode      string
+	repeat          int
+	tempSweepA      string
+	tempSweepB      string
+	maxTokens       int
+	estimate        bool
+	estimateReply   int
+	outPath         string
+	outColor        bool
+	personaA        string
+	personaB        string
+	profileA        string
+	profileB        string
+	templatePath    string
+
+	toolsA     []string
+	toolsB     []string
+	toolChoice string
+
+	allowUnknownModel bool
+	mode              string
+	emptyRetries      int
+	continueOnError   bool
+	onRefusal         string
+	retryCooler       bool
+	coolerFactor      float64
+
+	maxDuration time.Duration
+
+	pipePath      string
+	pipeDelimiter string
+	pipeDelay     time.Duration
+
+	jsonModeA   bool
+	jsonModeB   bool
+	schemaFileA string
+	schemaFileB string
+
+	headersA            []string
+	headersB            []string
+	allowHeaderOverride bool
+
+	logitBiasA []string
+	logitBiasB []string
+
+	appendLog  bool
+	logDir     string
+	logMaxSize string
+	logKeep    int
+
+	exportTmpl     *export.Template // Loaded from --transcript-template at startup
+	sessionContext string           // From --context-file or the resumed transcript
+	turnsPerRound  = 1              // Engine rounds per counted round; 2 when counting exchanges
+)
+
+// Units --count-rounds-by accepts
+const (
+	roundsByTurn     = "turn"
+	roundsByExchange = "exchange"
+)
+
+// Modes --health accepts
+const (
+	healthBasic   = "basic"
+	healthVerbose = "verbose"
 )
 
+// stopInterrupted is the transcript stop reason for a run cancelled by a signal
+const stopInterrupted = "interrupted"
+
+// partialFlushEvery is how often a streaming reply is rewritten to the transcript
+const partialFlushEvery = time.Second
+
+// errInterrupted ends start when a signal cancels it
+var errInterrupted = errors.New("interrupted")
+
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
@@ -39,11 +190,32 @@ Examples:
   # Specify providers and models
   chat-bridge start --provider-a openai --provider-b anthropic
 
+  # Provider and model in one flag
+  chat-bridge start --agent-a openai:gpt-4o --agent-b openai:gpt-4o-mini
+
+  # Use whichever providers have API keys configured
+  chat-bridge start --provider-a auto --provider-b auto
+
   # Custom conversation starter
   chat-bridge start --starter "Discuss the nature of consciousness"
 
   # Limit rounds
   chat-bridge start --max-rounds 5
+
+  # Named agents with custom colors
+  chat-bridge start --name-a Socrates --color-a cyan --name-b Nietzsche --color-b 208
+
+  # End early once both agents say goodbye
+  chat-bridge start --stop-on-farewell
+
+  # Drop openers like "Sure! Here's my take:" from every reply
+  chat-bridge start --strip-preamble
+
+  # Bridge to a custom model behind a local script
+  chat-bridge start --provider-a exec --exec-cmd-a "python3 my_model.py"
+
+  # Let Agent A use the built-in calculator and clock
+  chat-bridge start --tools-a calculator,current_time
 `,
 	RunE: runStart,
 }
@@ -51,196 +223,1821 @@ Examples:
 func init() {
 	rootCmd.AddCommand(startCmd)
 
-	startCmd.Flags().StringVar(&providerA, "provider-a", "openai", "Provider for Agent A")
-	startCmd.Flags().StringVar(&providerB, "provider-b", "anthropic", "Provider for Agent B")
-	startCmd.Flags().StringVar(&modelA, "model-a", "", "Model for Agent A (default: provider default)")
-	startCmd.Flags().StringVar(&modelB, "model-b", "", "Model for Agent B (default: provider default)")
-	startCmd.Flags().Float64Var(&tempA, "temp-a", 0.7, "Temperature for Agent A")
-	startCmd.Flags().Float64Var(&tempB, "temp-b", 0.7, "Temperature for Agent B")
-	startCmd.Flags().StringVar(&starter, "starter", "Hello! How are you today?", "Conversation starter")
-	startCmd.Flags().IntVar(&maxRounds, "max-rounds", 10, "Maximum conversation rounds")
+	addConversationFlags(startCmd)
+	startCmd.Flags().StringVar(&resumePath, "resume", "", "Continue the conversation recorded in a transcript or checkpoint file")
+}
+
+// addConversationFlags registers the flags shared by start and branch
+func addConversationFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&providerA, "provider-a", "openai", "Provider for Agent A, or auto to pick a configured one")
+	f.StringVar(&providerB, "provider-b", "anthropic", "Provider for Agent B, or auto to pick a configured one")
+	f.StringVar(&modelA, "model-a", "", "Model for Agent A (default: provider default)")
+	f.StringVar(&modelB, "model-b", "", "Model for Agent B (default: provider default)")
+	f.StringVar(&modelScheduleA, "model-schedule-a", "", `Models for Agent A by round, e.g. "1-8:gpt-4o-mini,9-10:gpt-4o"; must cover every round`)
+	f.StringVar(&modelScheduleB, "model-schedule-b", "", `Models for Agent B by round, e.g. "1-8:gpt-4o-mini,9-10:gpt-4o"; must cover every round`)
+	f.StringVar(&agentA, "agent-a", "", "Provider and model for Agent A as provider:model (--provider-a/--model-a take precedence)")
+	f.StringVar(&agentB, "agent-b", "", "Provider and model for Agent B as provider:model (--provider-b/--model-b take precedence)")
+	tempA, tempB = newTemperatureFlag(0.7), newTemperatureFlag(0.7)
+	f.Var(&tempA, "temp-a", `Temperature for Agent A, or "default" to omit it; models that take none (e.g. o1) default to omitting it`)
+	f.Var(&tempB, "temp-b", `Temperature for Agent B, or "default" to omit it; models that take none (e.g. o1) default to omitting it`)
+	f.StringVar(&starter, "starter", "Hello! How are you today?", "Conversation starter")
+	f.IntVar(&maxRounds, "max-rounds", 10, "Maximum conversation rounds (the total, including rounds loaded with --resume)")
+	f.IntVar(&additionalRounds, "additional-rounds", 0, "With --resume, run N more rounds on top of the transcript's")
+	f.StringVar(&countRoundsBy, "count-rounds-by", roundsByTurn, "What --max-rounds, --additional-rounds, --model-schedule-a/-b and the --*-every intervals count: turn (one agent's reply) or exchange (a reply from each agent)")
+	f.DurationVar(&maxDuration, "max-duration", 0, "Stop after this much wall-clock time, e.g. 30m (the current round may finish first; 0 disables)")
+	f.StringVar(&nameA, "name-a", "Agent A", "Display name for Agent A")
+	f.StringVar(&nameB, "name-b", "Agent B", "Display name for Agent B")
+	f.StringVar(&colorA, "color-a", "green", "Color for Agent A (palette name or ANSI index)")
+	f.StringVar(&colorB, "color-b", "magenta", "Color for Agent B (palette name or ANSI index)")
+	f.StringVar(&personaA, "persona-a", "", "Persona file for Agent A (see 'chat-bridge validate persona'); flags override its settings")
+	f.StringVar(&personaB, "persona-b", "", "Persona file for Agent B (see 'chat-bridge validate persona'); flags override its settings")
+	f.StringVar(&profileA, "profile-a", "", "Config file profile for Agent A (see 'chat-bridge providers'); flags and --persona-a override its settings")
+	f.StringVar(&profileB, "profile-b", "", "Config file profile for Agent B (see 'chat-bridge providers'); flags and --persona-b override its settings")
+	f.StringVar(&templatePath, "template", "", "Template file with a starter and both agents' personas; flags override its settings")
+	f.BoolVar(&stopOnFarewell, "stop-on-farewell", false, "End early when consecutive turns both say goodbye")
+	f.StringArrayVar(&farewellPatterns, "farewell-pattern", nil, "Regex marking a closing signal (repeatable, replaces defaults)")
+	f.BoolVar(&stripPreamble, "strip-preamble", false, "Remove opening filler such as \"Sure! Here's...\" from replies (transcripts keep the original)")
+	f.StringArrayVar(&preamblePatterns, "preamble-pattern", nil, "Regex matching opening filler to strip (repeatable, replaces defaults)")
+	f.StringVar(&onLoop, "on-loop", "off", "When replies keep repeating each other: off, stop, or nudge (ask the agents to change the subject)")
+	f.Float64Var(&loopThreshold, "loop-threshold", conversation.DefaultLoopThreshold, "Similarity (0-1) at which a reply counts as repeating a recent one")
+	f.IntVar(&loopWindow, "loop-window", conversation.DefaultLoopWindow, "Recent replies each new reply is compared against")
+	f.IntVar(&loopRepeats, "loop-repeats", conversation.DefaultLoopRepeats, "Consecutive repeating replies that count as a loop")
+	f.IntVar(&humanEvery, "human-every", 0, "Pause every N rounds to accept, edit, or skip the next prompt (0 runs unattended)")
+	f.StringVar(&interruptKey, "interrupt-key", "", "Key that cuts the streaming reply short and moves on to the next turn: a character, space, tab, enter or ctrl+<letter> (terminal only)")
+	f.StringVar(&director, "director", "", "Provider or provider:model for a hidden director that steers both agents every --director-every rounds")
+	f.IntVar(&directorEvery, "director-every", bridge.DefaultDirectorEvery, "Rounds between the director's instructions")
+	f.StringVar(&directorPrompt, "director-prompt", bridge.DefaultDirectorPrompt, "System prompt asking the director for its instruction")
+	f.StringVar(&execCmdA, "exec-cmd-a", "", "Command to run for Agent A when --provider-a is exec")
+	f.StringVar(&execCmdB, "exec-cmd-b", "", "Command to run for Agent B when --provider-b is exec")
+	f.StringArrayVar(&headersA, "header-a", nil, "Extra HTTP header (Name=value) for Agent A's requests; repeatable")
+	f.StringArrayVar(&headersB, "header-b", nil, "Extra HTTP header (Name=value) for Agent B's requests; repeatable")
+	f.StringArrayVar(&logitBiasA, "logit-bias-a", nil, "Token bias (tokenID=bias, -100 to 100) for Agent A; repeatable, OpenAI only")
+	f.StringArrayVar(&logitBiasB, "logit-bias-b", nil, "Token bias (tokenID=bias, -100 to 100) for Agent B; repeatable, OpenAI only")
+	f.BoolVar(&allowHeaderOverride, "allow-header-override", false, "Let --header-a/--header-b replace headers the provider sets itself, such as Authorization")
+	f.BoolVar(&useMemory, "memory", false, "Store turns in and recall context from the MCP memory server")
+	f.StringVar(&systemA, "system-a", "", "System prompt for Agent A")
+	f.StringVar(&systemB, "system-b", "", "System prompt for Agent B")
+	f.IntVar(&seed, "seed", 0, "Sampling seed for both agents (if the provider supports it)")
+	f.Float64Var(&topP, "top-p", 1, "Nucleus sampling top_p for both agents (if the provider supports it)")
+	f.Float64Var(&frequencyPenalty, "frequency-penalty", 0, "Frequency penalty for both agents (if the provider supports it)")
+	f.Float64Var(&presencePenalty, "presence-penalty", 0, "Presence penalty for both agents (if the provider supports it)")
+	f.StringVar(&transcriptPath, "transcript", "", "Record the conversation to this JSONL transcript file")
+	f.BoolVar(&appendLog, "append-log", false, "Add this session to the end of the --transcript file instead of overwriting it")
+	f.StringVar(&logDir, "log-dir", "", "Record each session to a new timestamped transcript in this directory")
+	f.StringVar(&logMaxSize, "log-max-size", "", "With --append-log, archive the transcript as a .gz once it reaches this size (e.g. 10MB)")
+	f.IntVar(&logKeep, "log-keep", 0, "Keep only the N newest sessions in --log-dir, or archives of --transcript (0 keeps all)")
+	f.IntVar(&checkpointEvery, "checkpoint-every", 0, "Save a checkpoint transcript every N rounds (0 disables)")
+	f.StringVar(&checkpointDir, "checkpoint-dir", "checkpoints", "Directory for checkpoint files")
+	f.StringVar(&exportFormat, "export", "", "Export the finished conversation as markdown, html, or openai-ft (fine-tuning JSONL)")
+	f.StringVar(&exportAssistant, "export-assistant", export.AssistantA, "With --export openai-ft, whose turns are the assistant's: a, b, or both (an example from each side)")
+	f.StringVar(&exportTmplPath, "transcript-template", "", "Also render the finished conversation with this Go text/template file (e.g. turns.csv.tmpl)")
+	f.StringArrayVar(&imageRefs, "image", nil, "Attach an image (file path, http(s) URL, or data: URL) to the starter; repeatable")
+	f.StringSliceVar(&tags, "tag", nil, "Label the session in its transcript (repeatable or comma-separated; see 'chat-bridge ls')")
+	f.IntVar(&reinforceEvery, "reinforce-system-every", 0, "Re-send each agent's system prompt every N rounds (0 = only at the start)")
+	f.StringVar(&turnPrefix, "turn-prefix", "", "Text prepended to each message passed to the next agent (sent only, not kept in history)")
+	f.StringVar(&turnSuffix, "turn-suffix", "", "Text appended to each message passed to the next agent, e.g. \"Respond in one paragraph.\" (sent only, not kept in history)")
+	f.BoolVar(&injectRound, "inject-round", false, "Tell each agent the round in a system note sent with every request (not kept in history)")
+	f.StringVar(&roundTemplate, "round-template", bridge.DefaultRoundNote, "Text/template for the --inject-round note, with .Round, .MaxRounds, .Remaining and .Agent")
+	f.BoolVar(&compactHistory, "compact-history", false, "Send earlier turns with whitespace collapsed (code blocks kept) to save tokens; the display and transcripts keep the full text")
+	f.StringVar(&healthMode, "health", healthBasic, "Provider check before the run: basic (reachability) or verbose (also time a one-token request per agent)")
+	f.DurationVar(&healthTTL, "health-cache-ttl", bridge.DefaultHealthCacheTTL, "Reuse a passed health check for agents sharing a provider endpoint and key for this long (0 checks each agent)")
+	f.IntVar(&minChars, "min-response-chars", 0, "Ask an agent once to elaborate when its reply is shorter than N characters (0 disables)")
+	f.IntVar(&maxChars, "max-response-chars", 0, "Cut every reply off at N characters, stopping the provider's stream there (0 disables)")
+	f.StringVar(&elaborate, "elaborate-prompt", "", "Message sent with --min-response-chars to ask for a longer reply (default: a generic request to elaborate)")
+	f.StringVar(&contextFile, "context-file", "", "Send this file's contents to both agents as a system message with every request; unlike --starter it isn't a turn")
+	f.IntVar(&compactMax, "compact-max-chars", 0, "With --compact-history, also cut earlier turns longer than N characters (0 keeps them whole)")
+	f.StringSliceVar(&toolsA, "tools-a", nil, "Tools Agent A may call (comma-separated; see 'chat-bridge tools')")
+	f.StringSliceVar(&toolsB, "tools-b", nil, "Tools Agent B may call (comma-separated; see 'chat-bridge tools')")
+	f.BoolVar(&jsonModeA, "json-mode-a", false, "Require Agent A to reply with a single JSON object (invalid replies are retried once)")
+	f.BoolVar(&jsonModeB, "json-mode-b", false, "Require Agent B to reply with a single JSON object (invalid replies are retried once)")
+	f.StringVar(&schemaFileA, "schema-file-a", "", "JSON schema file Agent A's replies must match; implies --json-mode-a (mismatches are retried once)")
+	f.StringVar(&schemaFileB, "schema-file-b", "", "JSON schema file Agent B's replies must match; implies --json-mode-b (mismatches are retried once)")
+	f.StringVar(&toolChoice, "tool-choice", "", "Tool choice for agents with tools: auto, none, required, or a tool name")
+	f.StringVar(&mode, "mode", "alternating", "Turn-taking mode: alternating, or simultaneous (both agents answer each round at once)")
+	f.BoolVar(&continueOnError, "continue-on-error", false, "Skip a turn that fails after retries and pass its prompt to the other agent, instead of ending the run (three failures in a row still end it)")
+	f.StringVar(&onRefusal, "on-refusal", string(bridge.RefusalSkip), "When an agent declines to respond or its reply is filtered: skip (pass its prompt to the other agent), nudge (ask once more), or stop")
+	f.BoolVar(&retryCooler, "retry-cooler", false, "Ask an agent once more at a lower temperature when its reply is declined or empty")
+	f.Float64Var(&coolerFactor, "cooler-factor", bridge.DefaultCoolerFactor, "Temperature multiplier for --retry-cooler's retry, e.g. 0.5 retries a 0.8 agent at 0.4")
+	f.IntVar(&emptyRetries, "empty-retries", bridge.DefaultEmptyStreamRetries, "Retries when a provider's stream closes without any data, e.g. while a local model loads (0 disables)")
+	f.StringVar(&pipePath, "pipe", "", "Replay a scripted conversation from this file (- for stdin) instead of calling providers; turns alternate A, B, ...")
+	f.StringVar(&pipeDelimiter, "pipe-delimiter", providers.DefaultScriptDelimiter, "Line separating the turns of a --pipe script")
+	f.DurationVar(&pipeDelay, "pipe-delay", providers.DefaultScriptChunkDelay, "Pause between streamed words of --pipe turns (0 prints each turn at once)")
+	f.BoolVar(&allowUnknownModel, "allow-unknown-model", false, "Skip checking that each model is offered by its provider (e.g. for newly released models)")
+	f.StringVar(&outPath, "out", "", "Also write the conversation as printed to this file (plain text; see --out-color)")
+	f.BoolVar(&outColor, "out-color", false, "Keep colors and other escape codes in the --out file")
+	f.IntVar(&repeat, "repeat", 1, "Run the conversation N times, each from a fresh history, and summarize the runs")
+	f.StringVar(&tempSweepA, "temp-sweep-a", "", `Run one conversation per listed temperature for Agent A (e.g. "0.2,0.7,1.2") and tabulate the results`)
+	f.StringVar(&tempSweepB, "temp-sweep-b", "", "Like --temp-sweep-a for Agent B; with both, every combination is run")
+	f.IntVar(&maxTokens, "max-tokens", bridge.DefaultMaxTokens, "Maximum tokens per response")
+	f.BoolVar(&estimate, "estimate", false, "Print the projected tokens and cost of the run, then exit without calling any provider")
+	f.IntVar(&estimateReply, "estimate-reply-tokens", 300, "Average reply length --estimate assumes, in tokens")
+	f.BoolVarP(&quiet, "quiet", "q", false, "Hide the thinking and live throughput indicators")
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
-	// Show banner
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Show banner, once the config has had its say on it
 	ui.PrintBanner()
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Continue from an earlier transcript or checkpoint
+	var prior *transcript.Transcript
+	if resumePath != "" {
+		prior, err = transcript.Load(resumePath)
+		if err != nil {
+			return err
+		}
+		applyTranscriptSettings(cmd, prior.Header)
+		if err := applyRoundUnit(cmd, true); err != nil {
+			return err
+		}
+		if err := applyAdditionalRounds(cmd, prior.Rounds()); err != nil {
+			return err
+		}
+		if prior.Rounds() >= maxRounds {
+			return fmt.Errorf("%s already has %s; raise --max-rounds or use --additional-rounds to continue it", resumePath, countRounds(prior.Rounds()))
+		}
+	} else if cmd.Flags().Changed("additional-rounds") {
+		return fmt.Errorf("--additional-rounds only applies with --resume; use --max-rounds for a new conversation")
+	}
+
+	// Run without any flags on a terminal, offer the persona menu
+	if prior == nil && cmd.Flags().NFlag() == 0 && ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout) {
+		if err := pickPersonas(cfg); err != nil {
+			return err
+		}
+	}
+
+	// Profiles, personas and templates set up new conversations; a resumed one
+	// keeps its recorded agents
+	if profileA != "" || profileB != "" {
+		if prior != nil {
+			return fmt.Errorf("--profile-a and --profile-b can't be combined with --resume; the transcript records the agents")
+		}
+		if err := applyProfiles(cmd, cfg); err != nil {
+			return err
+		}
+	}
+	if personaA != "" || personaB != "" || templatePath != "" {
+		if prior != nil {
+			return fmt.Errorf("--persona-a, --persona-b and --template can't be combined with --resume; the transcript records the agents")
+		}
+		if err := applyPersonas(cmd, cfg); err != nil {
+			return err
+		}
+	}
+	if prior == nil {
+		if err := applyRoundUnit(cmd, false); err != nil {
+			return err
+		}
+	}
+	tags = transcript.NormalizeTags(tags)
+
+	if err := applyAgentFlag(cmd, "a", agentA, &providerA, &modelA); err != nil {
+		return err
+	}
+	if err := applyAgentFlag(cmd, "b", agentB, &providerB, &modelB); err != nil {
+		return err
+	}
+
+	// A piped script stands in for both providers
+	var script [2][]string
+	if pipePath != "" {
+		if script, err = loadPipeScript(cmd); err != nil {
+			return err
+		}
+		providerA, providerB = providers.ScriptProviderName, providers.ScriptProviderName
+	}
+	if err := resolveAutoProviders(cfg, &providerA, &providerB); err != nil {
+		return err
+	}
+
+	// Check that both selected providers have the credentials they need
+	if pipePath == "" {
+		if err := cfg.ValidateForProviders(providerA, providerB); err != nil {
+			ui.PrintError("Configuration error:")
+			ui.PrintWarning(err.Error())
+			ui.PrintInfo("Please set API keys in .env file or environment variables")
+			return err
+		}
+	}
+
+	if providerA == "exec" && execCmdA == "" {
+		return fmt.Errorf("--exec-cmd-a is required when --provider-a is exec")
+	}
+	if providerB == "exec" && execCmdB == "" {
+		return fmt.Errorf("--exec-cmd-b is required when --provider-b is exec")
+	}
+
+	// Load and validate images before contacting any provider
+	images := make([]providers.Image, 0, len(imageRefs))
+	for _, ref := range imageRefs {
+		img, err := providers.LoadImage(ref)
+		if err != nil {
+			return fmt.Errorf("invalid --image: %w", err)
+		}
+		images = append(images, img)
+	}
+
+	extraHeadersA, err := parseHeaders(headersA)
+	if err != nil {
+		return fmt.Errorf("invalid --header-a: %w", err)
+	}
+	extraHeadersB, err := parseHeaders(headersB)
+	if err != nil {
+		return fmt.Errorf("invalid --header-b: %w", err)
+	}
+	extraHeadersA = withProfileHeaders(profileHeaders[0], extraHeadersA)
+	extraHeadersB = withProfileHeaders(profileHeaders[1], extraHeadersB)
+	biasA, err := providers.ParseLogitBias(logitBiasA)
+	if err != nil {
+		return fmt.Errorf("invalid --logit-bias-a: %w", err)
+	}
+	biasB, err := providers.ParseLogitBias(logitBiasB)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return fmt.Errorf("invalid --logit-bias-b: %w", err)
+	}
+	schemaA, err := agentSchema("schema-file-a", schemaFileA, prior, 0)
+	if err != nil {
+		return err
+	}
+	schemaB, err := agentSchema("schema-file-b", schemaFileB, prior, 1)
+	if err != nil {
+		return err
 	}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		ui.PrintError("Configuration error:")
-		ui.PrintWarning(err.Error())
-		ui.PrintInfo("Please set API keys in .env file or environment variables")
+	convMode, err := bridge.ParseMode(mode)
+	if err != nil {
+		return fmt.Errorf("invalid --mode: %w", err)
+	}
+	scheduleA, err := parseModelSchedule("model-schedule-a", modelScheduleA, maxRounds, prior != nil && !cmd.Flags().Changed("model-schedule-a"))
+	if err != nil {
+		return err
+	}
+	scheduleB, err := parseModelSchedule("model-schedule-b", modelScheduleB, maxRounds, prior != nil && !cmd.Flags().Changed("model-schedule-b"))
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("round-template") && !injectRound {
+		return fmt.Errorf("--round-template only applies with --inject-round")
+	}
+	var roundNote *bridge.RoundNote
+	if injectRound {
+		if roundNote, err = bridge.ParseRoundNote(roundTemplate); err != nil {
+			return fmt.Errorf("--round-template: %w", err)
+		}
+	}
+	if emptyRetries < 0 {
+		return fmt.Errorf("--empty-retries must be 0 or more")
+	}
+	if reinforceEvery < 0 {
+		return fmt.Errorf("--reinforce-system-every must be 0 or more")
+	}
+	if humanEvery < 0 {
+		return fmt.Errorf("--human-every must be 0 or more")
+	}
+	if humanEvery > 0 && convMode == bridge.ModeSimultaneous {
+		return fmt.Errorf("--human-every needs alternating mode, where each round has a single prompt to review")
+	}
+	if interruptKey != "" {
+		if interruptCode, err = parseInterruptKey(interruptKey); err != nil {
+			return fmt.Errorf("invalid --interrupt-key: %w", err)
+		}
+		if humanEvery > 0 {
+			return fmt.Errorf("--interrupt-key and --human-every both read from the terminal; use one or the other")
+		}
+	}
+	if director == "" && (cmd.Flags().Changed("director-every") || cmd.Flags().Changed("director-prompt")) {
+		return fmt.Errorf("--director-every and --director-prompt only apply with --director")
+	}
+	if directorEvery < 1 {
+		return fmt.Errorf("--director-every must be 1 or more")
+	}
+	if compactMax < 0 {
+		return fmt.Errorf("--compact-max-chars must be 0 or more")
+	}
+	if cmd.Flags().Changed("compact-max-chars") && !compactHistory {
+		return fmt.Errorf("--compact-max-chars only applies with --compact-history")
+	}
+	if healthMode != healthBasic && healthMode != healthVerbose {
+		return fmt.Errorf("--health must be %s or %s", healthBasic, healthVerbose)
+	}
+	if healthTTL < 0 {
+		return fmt.Errorf("--health-cache-ttl must be 0 or more")
+	}
+	if minChars < 0 {
+		return fmt.Errorf("--min-response-chars must be 0 or more")
+	}
+	if maxChars < 0 {
+		return fmt.Errorf("--max-response-chars must be 0 or more")
+	}
+	if maxChars > 0 && minChars > maxChars {
+		return fmt.Errorf("--min-response-chars can't be more than --max-response-chars")
+	}
+	if elaborate != "" && minChars == 0 {
+		return fmt.Errorf("--elaborate-prompt only applies with --min-response-chars")
+	}
+	if cmd.Flags().Changed("cooler-factor") && !retryCooler {
+		return fmt.Errorf("--cooler-factor only applies with --retry-cooler")
+	}
+	if coolerFactor <= 0 || coolerFactor >= 1 {
+		return fmt.Errorf("--cooler-factor must be between 0 and 1")
+	}
+	if contextFile != "" {
+		data, err := os.ReadFile(contextFile)
+		if err != nil {
+			return fmt.Errorf("--context-file: %w", err)
+		}
+		if sessionContext = strings.TrimSpace(string(data)); sessionContext == "" {
+			return fmt.Errorf("--context-file %s is empty", contextFile)
+		}
+	}
+	if logDir != "" && transcriptPath != "" {
+		return fmt.Errorf("--log-dir and --transcript cannot be used together")
+	}
+	if logMaxSize != "" && !appendLog {
+		return fmt.Errorf("--log-max-size only applies with --append-log")
+	}
+	if _, err := parseSize(logMaxSize); err != nil {
+		return fmt.Errorf("invalid --log-max-size: %w", err)
+	}
+	if maxDuration < 0 {
+		return fmt.Errorf("--max-duration must be 0 or more")
+	}
+	if logKeep < 0 {
+		return fmt.Errorf("--log-keep must be 0 or more")
+	}
+	if outColor && outPath == "" {
+		return fmt.Errorf("--out-color only applies with --out")
+	}
+	if repeat < 1 {
+		return fmt.Errorf("--repeat must be 1 or more")
+	}
+	sweepA, err := parseTempSweep(cfg, "temp-sweep-a", tempSweepA, providerA)
+	if err != nil {
+		return err
+	}
+	sweepB, err := parseTempSweep(cfg, "temp-sweep-b", tempSweepB, providerB)
+	if err != nil {
 		return err
 	}
+	if sweepA != nil && cmd.Flags().Changed("temp-a") {
+		return fmt.Errorf("--temp-sweep-a sets Agent A's temperature for each run, so it can't be combined with --temp-a")
+	}
+	if sweepB != nil && cmd.Flags().Changed("temp-b") {
+		return fmt.Errorf("--temp-sweep-b sets Agent B's temperature for each run, so it can't be combined with --temp-b")
+	}
+	sweep = sweepSettings(sweepA, sweepB)
+	if maxTokens < 1 {
+		return fmt.Errorf("--max-tokens must be 1 or more")
+	}
+	if estimateReply < 1 {
+		return fmt.Errorf("--estimate-reply-tokens must be 1 or more")
+	}
+	if cmd.Flags().Changed("estimate-reply-tokens") && !estimate {
+		return fmt.Errorf("--estimate-reply-tokens only applies with --estimate")
+	}
+	if totalRuns() > 1 {
+		switch {
+		case prior != nil && !branching:
+			return fmt.Errorf("%s can't be combined with --resume; branch the transcript instead", multiRunFlag())
+		case appendLog:
+			return fmt.Errorf("%s records each run to its own file, so it can't be combined with --append-log", multiRunFlag())
+		case useMemory:
+			return fmt.Errorf("--memory carries context from one run to the next, so it can't be combined with %s", multiRunFlag())
+		}
+	}
+	if exportFormat != "" {
+		if err := export.ValidateFormat(exportFormat); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("export-assistant") && exportFormat != export.FormatOpenAIFT {
+		return fmt.Errorf("--export-assistant only applies with --export %s", export.FormatOpenAIFT)
+	}
+	if err := export.ValidateAssistant(exportAssistant); err != nil {
+		return fmt.Errorf("--export-assistant: %w", err)
+	}
+	if exportTmplPath != "" {
+		if exportTmpl, err = export.LoadTemplate(exportTmplPath); err != nil {
+			return fmt.Errorf("--transcript-template: %w", err)
+		}
+		if exportFormat != "" && exportTmpl.Extension() == export.Extension(exportFormat) {
+			return fmt.Errorf("--transcript-template writes %s files, as --export %s does; give the template another extension", exportTmpl.Extension(), exportFormat)
+		}
+	}
+
+	// Resolve agent display colors
+	agentColorA, err := ui.ParseColor(colorA)
+	if err != nil {
+		return fmt.Errorf("invalid --color-a: %w", err)
+	}
+	agentColorB, err := ui.ParseColor(colorB)
+	if err != nil {
+		return fmt.Errorf("invalid --color-b: %w", err)
+	}
+
+	// Set up farewell detection
+	var farewell *conversation.FarewellDetector
+	if stopOnFarewell {
+		farewell, err = conversation.NewFarewellDetector(farewellPatterns)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Set up preamble stripping
+	var preamble *conversation.PreambleStripper
+	if stripPreamble {
+		preamble, err = conversation.NewPreambleStripper(preamblePatterns)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Set up loop detection
+	var loop *conversation.LoopDetector
+	var loopAction bridge.LoopAction
+	if onLoop != "off" {
+		loopAction, err = bridge.ParseLoopAction(onLoop)
+		if err != nil {
+			return fmt.Errorf("invalid --on-loop %q (use off, %s, or %s)", onLoop, bridge.LoopStop, bridge.LoopNudge)
+		}
+		loop, err = conversation.NewLoopDetector(loopThreshold, loopWindow, loopRepeats)
+		if err != nil {
+			return fmt.Errorf("invalid loop settings: %w", err)
+		}
+	}
+
+	refusalAction, err := bridge.ParseRefusalAction(onRefusal)
+	if err != nil {
+		return fmt.Errorf("invalid --on-refusal %q (use %s, %s, or %s)", onRefusal, bridge.RefusalSkip, bridge.RefusalNudge, bridge.RefusalStop)
+	}
+
+	tempA.fitDefault(cfg, providerA, modelA)
+	tempB.fitDefault(cfg, providerB, modelB)
 
 	// Show session configuration
 	ui.PrintSectionHeader("Session Configuration", "⚙️")
-	fmt.Printf("  %s: %s\n", ui.Colorize("Agent A", ui.Green, true), providerA)
+	fmt.Printf("  %s: %s\n", ui.Colorize(nameA, agentColorA, true), describeProvider(cfg, providerA))
 	if modelA != "" {
 		fmt.Printf("  %s: %s\n", ui.Colorize("Model A", ui.Yellow, false), modelA)
 	}
-	fmt.Printf("  %s: %.1f\n", ui.Colorize("Temperature A", ui.Cyan, false), tempA)
+	if scheduleA != nil {
+		fmt.Printf("  %s: %s%s\n", ui.Colorize("Schedule A", ui.Yellow, false), scheduleA, scheduleUnit())
+	}
+	fmt.Printf("  %s: %s\n", ui.Colorize("Temperature A", ui.Cyan, false), describeTemperature(&tempA, sweepA))
 	fmt.Println()
-	fmt.Printf("  %s: %s\n", ui.Colorize("Agent B", ui.Magenta, true), providerB)
+	fmt.Printf("  %s: %s\n", ui.Colorize(nameB, agentColorB, true), describeProvider(cfg, providerB))
 	if modelB != "" {
 		fmt.Printf("  %s: %s\n", ui.Colorize("Model B", ui.Yellow, false), modelB)
 	}
-	fmt.Printf("  %s: %.1f\n", ui.Colorize("Temperature B", ui.Cyan, false), tempB)
+	if scheduleB != nil {
+		fmt.Printf("  %s: %s%s\n", ui.Colorize("Schedule B", ui.Yellow, false), scheduleB, scheduleUnit())
+	}
+	fmt.Printf("  %s: %s\n", ui.Colorize("Temperature B", ui.Cyan, false), describeTemperature(&tempB, sweepB))
 	fmt.Println()
-	fmt.Printf("  %s: %d\n", ui.Colorize("Max Rounds", ui.Blue, false), maxRounds)
+	if prior != nil {
+		fmt.Printf("  %s: %s (%s more)\n", ui.Colorize("Max Rounds", ui.Blue, false), roundNumber(maxRounds), roundNumber(maxRounds-prior.Rounds()))
+	} else {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Max Rounds", ui.Blue, false), roundNumber(maxRounds))
+	}
+	if maxDuration > 0 {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Max Duration", ui.Blue, false), maxDuration)
+	}
+	if convMode != bridge.ModeAlternating {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Mode", ui.Blue, false), convMode)
+	}
+	if loop != nil {
+		fmt.Printf("  %s: %s after %d replies %.0f%% similar to one of the last %d\n",
+			ui.Colorize("On Loop", ui.Blue, false), loopAction, loopRepeats, loopThreshold*100, loopWindow)
+	}
+	if humanEvery > 0 {
+		fmt.Printf("  %s: every %s\n", ui.Colorize("Human Review", ui.Blue, false), countRounds(humanEvery))
+	}
+	if interruptKey != "" {
+		fmt.Printf("  %s: %s cuts a reply short\n", ui.Colorize("Interrupt Key", ui.Blue, false), interruptKey)
+	}
+	if minChars > 0 {
+		fmt.Printf("  %s: %d characters, re-prompted once\n", ui.Colorize("Min Reply", ui.Blue, false), minChars)
+	}
+	if maxChars > 0 {
+		fmt.Printf("  %s: %d characters, cut off beyond\n", ui.Colorize("Max Reply", ui.Blue, false), maxChars)
+	}
+	if director != "" {
+		fmt.Printf("  %s: %s, every %s\n", ui.Colorize("Director", ui.Blue, false), director, countRounds(directorEvery))
+	}
+	if sessionContext != "" {
+		fmt.Printf("  %s: %d characters, sent as a system message\n", ui.Colorize("Context", ui.White, false), len(sessionContext))
+	}
 	fmt.Printf("  %s: %s\n", ui.Colorize("Starter", ui.White, false), starter)
+	if len(imageRefs) > 0 {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Images", ui.White, false), strings.Join(imageRefs, ", "))
+	}
+	if len(tags) > 0 {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Tags", ui.White, false), strings.Join(tags, ", "))
+	}
+	if len(toolsA) > 0 {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Tools A", ui.White, false), strings.Join(toolsA, ", "))
+	}
+	if len(toolsB) > 0 {
+		fmt.Printf("  %s: %s\n", ui.Colorize("Tools B", ui.White, false), strings.Join(toolsB, ", "))
+	}
+	if prior != nil {
+		fmt.Printf("  %s: %s (%s)\n", ui.Colorize("Resuming", ui.Blue, false), resumePath, countRounds(prior.Rounds()))
+	}
 	fmt.Println()
 
 	// Create providers
 	ui.PrintInfo("Initializing providers...")
 
-	// Get API keys
-	apiKeyA := cfg.GetAPIKey(providerA)
-	apiKeyB := cfg.GetAPIKey(providerB)
+	sampling := samplingFromFlags(cmd)
 
-	// Get models
-	if modelA == "" {
-		modelA = cfg.GetDefaultModel(providerA)
-	}
-	if modelB == "" {
-		modelB = cfg.GetDefaultModel(providerB)
-	}
+	agentA, err := newAgent(cfg, bridge.AgentConfig{
+		Name:         nameA,
+		Provider:     providerA,
+		Model:        modelA,
+		Temperature:  tempA.value,
+		Command:      execCmdA,
+		SystemPrompt: systemA,
+		Sampling:     sampling,
+		Tools:        toolsA,
+		ToolChoice:   toolChoice,
+		JSONMode:     jsonModeA,
+		Schema:       schemaA,
+		LogitBias:    biasA,
 
-	agentA, err := buildProvider(cfg, providerA, apiKeyA, modelA, tempA)
+		ModelSchedule:   scheduleA,
+		Headers:         extraHeadersA,
+		OverrideHeaders: allowHeaderOverride,
+	}, script[0])
+	if errors.Is(err, bridge.ErrNoAPIKey) {
+		suggestReadyProviders(cfg)
+	}
 	if err != nil {
 		return err
 	}
 
-	agentB, err := buildProvider(cfg, providerB, apiKeyB, modelB, tempB)
+	agentB, err := newAgent(cfg, bridge.AgentConfig{
+		Name:         nameB,
+		Provider:     providerB,
+		Model:        modelB,
+		Temperature:  tempB.value,
+		Command:      execCmdB,
+		SystemPrompt: systemB,
+		Sampling:     sampling,
+		Tools:        toolsB,
+		ToolChoice:   toolChoice,
+		JSONMode:     jsonModeB,
+		Schema:       schemaB,
+		LogitBias:    biasB,
+
+		ModelSchedule:   scheduleB,
+		Headers:         extraHeadersB,
+		OverrideHeaders: allowHeaderOverride,
+	}, script[1])
+	if errors.Is(err, bridge.ErrNoAPIKey) {
+		suggestReadyProviders(cfg)
+	}
 	if err != nil {
 		return err
 	}
 
-	// Health check
-	ui.PrintInfo("Checking provider connectivity...")
-	ctx := context.Background()
-
-	if err := agentA.Health(ctx); err != nil {
-		return fmt.Errorf("Agent A health check failed: %w", err)
+	var directorAgent *bridge.Agent
+	if director != "" {
+		directorAgent, err = newDirector(cfg)
+		if err != nil {
+			return err
+		}
 	}
-	ui.PrintSuccess(fmt.Sprintf("Agent A (%s) ready", providerA))
 
-	if err := agentB.Health(ctx); err != nil {
-		return fmt.Errorf("Agent B health check failed: %w", err)
+	// Warn up front about parameters a provider would silently drop
+	for _, agent := range []*bridge.Agent{agentA, agentB} {
+		for _, param := range agent.UnsupportedParams() {
+			ui.PrintWarning(fmt.Sprintf("%s: %s does not support %s; it will be ignored", agent.Name, agent.Provider.Name(), param))
+		}
+	}
+	warnFixedTemperature(agentA, "temp-a", &tempA)
+	warnFixedTemperature(agentB, "temp-b", &tempB)
+	for i, swept := range [][]float64{sweepA, sweepB} {
+		agent := []*bridge.Agent{agentA, agentB}[i]
+		if swept != nil && !agent.AcceptsTemperature(agent.Model) {
+			return fmt.Errorf("%s only runs at its default temperature, so --temp-sweep-%c can't vary it", agent.Model, 'a'+i)
+		}
+	}
+	cooler := 0.0
+	if retryCooler {
+		cooler = coolerFactor
+		for _, agent := range []*bridge.Agent{agentA, agentB} {
+			if agent.Temperature == nil || *agent.Temperature == 0 || !agent.AcceptsTemperature(agent.Model) {
+				ui.PrintWarning(fmt.Sprintf("%s has no temperature to lower; --retry-cooler won't retry its replies", agent.Name))
+			}
+		}
+	}
+	if len(images) > 0 {
+		for _, agent := range []*bridge.Agent{agentA, agentB} {
+			if !agent.SupportsImages() {
+				ui.PrintWarning(fmt.Sprintf("%s: %s does not support images; it will only see the text", agent.Name, agent.Provider.Name()))
+			}
+		}
+	}
+	for _, agent := range []*bridge.Agent{agentA, agentB} {
+		switch {
+		case !agent.JSONMode:
+		case agent.Schema != nil && agent.SupportsJSONSchema():
+		case agent.Schema != nil:
+			ui.PrintWarning(fmt.Sprintf("%s: %s has no strict JSON schema support; the schema will be sent as an instruction and replies validated", agent.Name, agent.Provider.Name()))
+		case !agent.SupportsJSONMode():
+			ui.PrintWarning(fmt.Sprintf("%s: %s has no JSON mode; JSON will be requested by instruction and validated", agent.Name, agent.Provider.Name()))
+		case !bridge.MentionsJSON(agent.SystemPrompt):
+			ui.PrintWarning(fmt.Sprintf("%s: JSON mode needs a system prompt that mentions JSON; a generic JSON instruction will be added", agent.Name))
+		}
+	}
+	if interruptKey != "" && !ui.IsTerminal(os.Stdin) {
+		ui.PrintWarning("--interrupt-key has no effect when stdin isn't a terminal")
+	}
+	if reinforceEvery > 0 && agentA.SystemPrompt == "" && agentB.SystemPrompt == "" {
+		ui.PrintWarning("--reinforce-system-every has no effect without --system-a or --system-b")
 	}
-	ui.PrintSuccess(fmt.Sprintf("Agent B (%s) ready", providerB))
-
-	fmt.Println()
 
-	// Start conversation
-	ui.PrintSectionHeader("Conversation", "💬")
+	opts := bridge.Options{
+		Mode:      convMode,
+		Starter:   starter,
+		MaxRounds: maxRounds,
+		MaxTokens: maxTokens,
+		Farewell:  farewell,
+		Preamble:  preamble,
+		Images:    images,
+		Context:   sessionContext,
+		Loop:      loop,
+		OnLoop:    loopAction,
 
-	// Initialize conversation history
-	messages := []providers.Message{}
+		ReinforceEvery:     reinforceEvery,
+		ReviewEvery:        humanEvery,
+		TurnPrefix:         turnPrefix,
+		TurnSuffix:         turnSuffix,
+		RoundNote:          roundNote,
+		RoundNoteTurns:     turnsPerRound,
+		CompactHistory:     compactHistory,
+		CompactMaxChars:    compactMax,
+		MinResponseChars:   minChars,
+		MaxResponseChars:   maxChars,
+		ElaboratePrompt:    elaborate,
+		EmptyStreamRetries: engineRetries(emptyRetries),
+		MaxDuration:        maxDuration,
+		ContinueOnError:    continueOnError,
+		OnRefusal:          refusalAction,
+		RetryCooler:        cooler,
 
-	currentText := starter
-	currentAgent := agentA
-	agentName := "Agent A"
-	agentColor := ui.Green
+		Director:      directorAgent,
+		DirectorEvery: directorEvery,
+	}
+	agents := [2]*bridge.Agent{agentA, agentB}
+	colors := [2]lipgloss.Color{agentColorA, agentColorB}
 
-	for round := 1; round <= maxRounds; round++ {
-		// Add user message to history
-		messages = append(messages, providers.Message{
-			Role:    "user",
-			Content: currentText,
-		})
+	// Estimates need nothing from the providers, so they stop short of the health check
+	if estimate {
+		if prior != nil {
+			opts.Prior = prior.BridgeTurns()
+		}
+		printEstimate(agents, opts)
+		return nil
+	}
 
-		// Show round number
-		fmt.Printf("\n%s\n", ui.Colorize(fmt.Sprintf("═══ Round %d/%d ═══", round, maxRounds), ui.Dim, false))
-		fmt.Println()
+	// Cancelled on SIGINT/SIGTERM, which stops health checks, round delays, and streams
+	// alike; once cancelled a second signal kills the process as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	// The flags are fine by now, so failures from here on shouldn't print usage
+	cmd.SilenceUsage = true
 
-		// Show typing indicator
-		fmt.Printf("%s %s\n",
-			ui.Colorize(agentName, agentColor, true),
-			ui.Colorize("is thinking...", ui.Dim, false),
-		)
+	// Health check
+	ui.PrintInfo("Checking provider connectivity...")
+	health := bridge.NewHealthCache(healthTTL)
+	opts.HealthCache = health
 
-		// Stream response
-		currentTemp := tempA
-		if currentAgent == agentB {
-			currentTemp = tempB
-		}
+	if err := checkAgent(ctx, health, agentA); err != nil {
+		return err
+	}
+	if err := reportReady(ctx, fmt.Sprintf("%s (%s)", nameA, providerA), agentA); err != nil {
+		return err
+	}
 
-		textChan, errChan := currentAgent.StreamChat(ctx, &providers.ChatRequest{
-			Model:       currentAgent.DefaultModel(),
-			Messages:    messages,
-			Temperature: currentTemp,
-			MaxTokens:   800,
-		})
+	if err := checkAgent(ctx, health, agentB); err != nil {
+		return err
+	}
+	if err := reportReady(ctx, fmt.Sprintf("%s (%s)", nameB, providerB), agentB); err != nil {
+		return err
+	}
 
-		var fullResponse strings.Builder
-		fmt.Print(ui.Colorize(agentName+": ", agentColor, true))
+	if directorAgent != nil {
+		if err := checkAgent(ctx, health, directorAgent); err != nil {
+			return err
+		}
+		if err := reportReady(ctx, fmt.Sprintf("Director (%s)", directorAgent.ProviderName()), directorAgent); err != nil {
+			return err
+		}
+	}
 
-		for {
-			select {
-			case text, ok := <-textChan:
-				if !ok {
-					goto StreamDone
-				}
-				fmt.Print(text)
-				fullResponse.WriteString(text)
+	// Connect to MCP memory (optional, never fatal)
+	var memory mcp.Memory
+	if useMemory {
+		memory = connectMemory(ctx, cfg)
+		if memory != nil {
+			defer memory.Close()
+		}
+	}
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	opts.Memory = memory
 
-			case err := <-errChan:
-				if err != nil {
-					ui.PrintError(fmt.Sprintf("Stream error: %v", err))
-					return err
-				}
+	fmt.Println()
 
-			case <-time.After(30 * time.Second):
-				return fmt.Errorf("stream timeout")
-			}
+	results := make([]*bridge.Result, 0, totalRuns())
+	for run := 1; run <= totalRuns(); run++ {
+		applySetting(agents, run)
+		result, err := runConversation(ctx, agents, colors, opts, prior, run)
+		if err != nil {
+			return err
 		}
-
-	StreamDone:
+		printCompletion(result)
+		results = append(results, result)
+	}
+	switch {
+	case len(sweep) > 0:
 		fmt.Println()
+		ui.PrintBox(fmt.Sprintf("🌡️ Temperature Sweep of %d Settings", len(sweep)), sweepSummaryLines(results))
+	case repeat > 1:
+		fmt.Println()
+		ui.PrintBox(fmt.Sprintf("📈 Summary of %d Runs", repeat), repeatSummaryLines(results))
+	}
+	return nil
+}
 
-		// Add assistant response to history
-		responseText := fullResponse.String()
-		messages = append(messages, providers.Message{
-			Role:    "assistant",
-			Content: responseText,
-		})
+// runConversation runs one conversation, streaming it to the terminal and recording it.
+// run numbers the conversation within --repeat and --temp-sweep-a/-b, starting at 1.
+func runConversation(ctx context.Context, agents [2]*bridge.Agent, colors [2]lipgloss.Color, opts bridge.Options, prior *transcript.Transcript, run int) (*bridge.Result, error) {
+	// Record the session
+	header := transcript.Header{
+		ID:        bridge.NewID(),
+		Started:   time.Now(),
+		Starter:   opts.Starter,
+		MaxRounds: opts.MaxRounds,
+		Agents:    [2]transcript.AgentInfo{agentInfo(agents[0], colors[0]), agentInfo(agents[1], colors[1])},
+		Images:    imageRefs,
+		Context:   opts.Context,
+		Tags:      tags,
 
-		// Prepare for next round
-		currentText = responseText
+		ReinforceEvery: opts.ReinforceEvery,
+		TurnPrefix:     opts.TurnPrefix,
+		TurnSuffix:     opts.TurnSuffix,
 
-		// Switch agents
-		if currentAgent == agentA {
-			currentAgent = agentB
-			agentName = "Agent B"
-			agentColor = ui.Magenta
-		} else {
-			currentAgent = agentA
-			agentName = "Agent A"
-			agentColor = ui.Green
-		}
+		CompactHistory:  opts.CompactHistory,
+		CompactMaxChars: opts.CompactMaxChars,
 
-		// Small delay between rounds
-		time.Sleep(500 * time.Millisecond)
+		MinResponseChars: opts.MinResponseChars,
+		ElaboratePrompt:  opts.ElaboratePrompt,
+		MaxResponseChars: opts.MaxResponseChars,
+	}
+	if opts.Mode != bridge.ModeAlternating {
+		header.Mode = string(opts.Mode)
+	}
+	if countRoundsBy != roundsByTurn {
+		header.CountRoundsBy = countRoundsBy
+	}
+	if opts.RoundNote != nil {
+		header.RoundNote = opts.RoundNote.String()
+	}
+	var turns []transcript.Turn
+	if prior != nil {
+		turns = prior.Turns
+		opts.Prior = prior.BridgeTurns()
+		// Resuming continues the recorded conversation under its ID; a branch is a new one
+		if !branching && prior.Header.ID != "" {
+			header.ID = prior.Header.ID
+		}
+	}
+	opts.ID = header.ID
+	// Each run starts from a clean slate, including the detectors' memory of recent turns
+	if opts.Farewell != nil {
+		opts.Farewell.Reset()
+	}
+	if opts.Loop != nil {
+		opts.Loop.Reset()
 	}
 
-	// Show completion message
-	ui.PrintSuccess(fmt.Sprintf("Conversation completed! %d rounds", maxRounds))
-
-	return nil
-}
+	record, err := openTranscript(&header, prior, run)
+	if err != nil {
+		return nil, err
+	}
+	stopRecording := func(err error) {
+		ui.PrintWarning(fmt.Sprintf("Transcript disabled: %v", err))
+		record.Close()
+		record = nil
+	}
 
-func buildProvider(cfg *config.Config, provider, apiKey, model string, temp float64) (providers.Provider, error) {
-	return providers.NewProvider(provider, providers.ProviderConfig{
-		APIKey:      apiKey,
-		BaseURL:     cfg.GetProviderBaseURL(provider),
-		Model:       model,
-		Temperature: temp,
-	})
+	// Replies are recorded as they stream, marked incomplete until they finish, so a
+	// crash or a failed request mid-turn loses at most the last partialFlushEvery
+	var partials [2]*transcript.Turn
+	var partialText [2]strings.Builder
+	var flushed time.Time
+	flushPartials := func() {
+		flushed = time.Now()
+		for i, turn := range partials {
+			if turn == nil || record == nil {
+				continue
+			}
+			turn.Content = partialText[i].String()
+			if err := record.WritePartial(*turn); err != nil {
+				stopRecording(err)
+			}
+		}
+	}
+	// The reply an error or interruption cut short stays, marked incomplete
+	writeEnd := func(end transcript.End) {
+		if record == nil {
+			return
+		}
+		flushPartials()
+		partials = [2]*transcript.Turn{}
+		// A failed flush above has already stopped recording
+		if record != nil {
+			if err := record.WriteEnd(end); err != nil {
+				stopRecording(err)
+			}
+		}
+	}
+	// Deferred so the file is closed, with the reply in progress, even on a panic
+	defer func() {
+		if record == nil {
+			return
+		}
+		flushPartials()
+		if record != nil {
+			if err := record.Close(); err != nil {
+				ui.PrintWarning(fmt.Sprintf("Transcript may be incomplete: %v", err))
+			}
+		}
+	}()
+
+	// --out copies what is printed below; the status line and typing indicators stay on the terminal
+	out, echo := io.Writer(os.Stdout), io.Discard
+	var tee *ui.Tee
+	if outPath != "" {
+		tee, err = ui.CreateTee(runPath(outPath, run), outColor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create --out file: %w", err)
+		}
+		defer tee.Close()
+		out, echo = io.MultiWriter(os.Stdout, tee), tee
+		ui.PrintInfo(fmt.Sprintf("Copying output to %s", tee.Path()))
+	}
+
+	// Start conversation
+	title := "Conversation"
+	if totalRuns() > 1 {
+		title += fmt.Sprintf(" · Run %d/%d", run, totalRuns())
+	}
+	if setting, ok := runSetting(run); ok {
+		title += " · " + setting.describe()
+	}
+	ui.PrintSectionHeader(title, "💬")
+
+	conv := bridge.New(agents[0], agents[1], opts)
+	var result *bridge.Result
+
+	// --interrupt-key cuts the streaming reply short; the other agent answers what arrived
+	if interruptKey != "" && ui.IsTerminal(os.Stdin) {
+		stopKeys, err := watchKey(ctx, interruptCode, func() {
+			conv.CancelTurn(0)
+			conv.CancelTurn(1)
+		})
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("--interrupt-key is unavailable: %v", err))
+		} else {
+			defer stopKeys()
+		}
+	}
+
+	// The live status line needs cursor control, so it's only drawn on a terminal
+	var status *ui.StreamStatus
+	// Simultaneous replies stream at once, so they're printed as labeled lines instead
+	var lanes *ui.Interleaver
+	if opts.Mode == bridge.ModeSimultaneous {
+		width, _ := ui.TerminalWidth(os.Stdout)
+		lanes = ui.NewInterleaver(out, width, laneLabels([2]string{agents[0].Name, agents[1].Name}, colors))
+	} else if width, ok := ui.TerminalWidth(os.Stdout); ok && !quiet {
+		status = ui.NewStreamStatus(os.Stdout, width)
+	}
+
+	for ev := range conv.Run(ctx) {
+		switch ev.Type {
+		case bridge.EventTurnStart:
+			model := ev.Agent.Model
+			if scheduled, ok := ev.Agent.ModelSchedule.Model(ev.Round); ok {
+				model = scheduled
+			}
+			partials[ev.Speaker] = &transcript.Turn{Round: ev.Round, Speaker: ev.Speaker, Agent: ev.Agent.Name, Provider: ev.Agent.Provider.Name(), Model: model, Started: time.Now()}
+			partialText[ev.Speaker].Reset()
+
+			// Show round number (once per round when both agents start together, and
+			// once per exchange when counting exchanges)
+			if lanes == nil && turnsPerRound > 1 {
+				if (ev.Round-1)%turnsPerRound == 0 || ev.Round == bridge.PriorRounds(opts.Prior, opts.Mode)+1 {
+					exchange, total := (ev.Round+1)/turnsPerRound, opts.MaxRounds/turnsPerRound
+					fmt.Fprintf(out, "\n%s\n\n", ui.Colorize(fmt.Sprintf("═══ Exchange %d/%d ═══", exchange, total), ui.Dim, false))
+				}
+			} else if lanes == nil || ev.Speaker == 0 {
+				fmt.Fprintf(out, "\n%s\n\n", ui.Colorize(fmt.Sprintf("═══ Round %d/%d ═══", ev.Round, opts.MaxRounds), ui.Dim, false))
+			}
+
+			// Show typing indicator; on a terminal the live status replaces it
+			if status == nil && !quiet {
+				fmt.Printf("%s %s\n",
+					ui.Colorize(ev.Agent.Name, colors[ev.Speaker], true),
+					ui.Colorize("is thinking...", ui.Dim, false),
+				)
+			}
+			if lanes != nil {
+				break
+			}
+			label := ev.Agent.Name + ": "
+			fmt.Fprint(out, ui.Colorize(label, colors[ev.Speaker], true))
+			if status != nil {
+				status.Start(lipgloss.Width(label))
+			}
+
+		case bridge.EventToken:
+			// Retries and follow-ups add to the text; the completed turn replaces it
+			partialText[ev.Speaker].WriteString(ev.Text)
+			if time.Since(flushed) >= partialFlushEvery {
+				flushPartials()
+			}
+			if lanes != nil {
+				lanes.Write(ev.Speaker, ev.Text)
+			} else if status != nil {
+				status.Print(ev.Text)
+				io.WriteString(echo, ev.Text)
+			} else {
+				fmt.Fprint(out, ev.Text)
+			}
+
+		case bridge.EventTurnComplete:
+			if lanes != nil {
+				lanes.Flush(ev.Speaker)
+			} else {
+				if status != nil {
+					status.Finish()
+				}
+				fmt.Fprintln(out)
+			}
+			if ev.Turn.Cancelled {
+				note := ui.Colorize("⏭️  Reply cut short; moving on", ui.Dim, false)
+				if lanes != nil {
+					lanes.Println(ev.Speaker, note)
+				} else {
+					fmt.Fprintln(out, note)
+				}
+			}
+
+			turn := transcript.FromBridgeTurn(ev.Turn)
+			turns = transcript.AppendTurn(turns, turn)
+			partials[ev.Speaker] = nil
+			if record != nil {
+				if err := record.WriteTurn(turn); err != nil {
+					stopRecording(err)
+				}
+			}
+			// A simultaneous round is complete once Agent B's turn is in
+			roundDone := lanes == nil || ev.Speaker == 1
+			if checkpointEvery > 0 && ev.Round%checkpointEvery == 0 && roundDone {
+				saveCheckpoint(header, turns, ev.Round, run)
+			}
+			if tee != nil && roundDone {
+				if err := tee.Flush(); err != nil {
+					ui.PrintWarning(fmt.Sprintf("--out copy stopped: %v", err))
+					tee = nil
+				}
+			}
+
+		case bridge.EventToolCall:
+			if lanes != nil {
+				lanes.Println(ev.Speaker, ui.Colorize(toolCallLine(ev.Tool), ui.Dim, false))
+				break
+			}
+			if status != nil {
+				status.Finish()
+			}
+			fmt.Fprintf(out, "\n%s\n", ui.Colorize(toolCallLine(ev.Tool), ui.Dim, false))
+			if status != nil {
+				status.Start(0)
+			}
+
+		case bridge.EventWarning:
+			message := fmt.Sprintf("%s: %v", ev.Text, ev.Err)
+			// Warnings with an agent arrive mid-turn, and its reply continues after them
+			if lanes != nil && ev.Agent != nil {
+				lanes.Println(ev.Speaker, ui.Warning.Render("⚠️ "+message))
+				break
+			}
+			if status != nil {
+				status.Finish()
+			}
+			if ev.Agent != nil {
+				fmt.Println()
+			}
+			ui.PrintWarning(message)
+			if ev.Agent != nil && status != nil {
+				status.Start(0)
+			}
+
+		case bridge.EventReview:
+			ev.Review.Decide(reviewPrompt(ctx, ev))
+
+		case bridge.EventDirection:
+			fmt.Fprintf(out, "\n%s\n", ui.Colorize("🎬 Director: "+ev.Text, ui.Dim, false))
+
+		case bridge.EventDone:
+			if ctx.Err() != nil {
+				// A turn cut off by the signal; wrapped up below like any other interruption
+				continue
+			}
+			if status != nil {
+				status.Finish()
+			}
+			if lanes != nil {
+				lanes.Flush(0)
+				lanes.Flush(1)
+			}
+			end := transcript.End{Rounds: ev.Result.Rounds, Reason: string(ev.Result.Reason), ElapsedMS: ev.Result.Elapsed.Milliseconds()}
+			if ev.Err != nil {
+				end.Error = ev.Err.Error()
+			}
+			writeEnd(end)
+			if (exportFormat != "" || exportTmpl != nil) && len(turns) > 0 {
+				exportSession(&transcript.Transcript{Header: header, Turns: turns, End: &end}, record)
+			}
+			if ev.Err != nil {
+				fmt.Println()
+				ui.PrintError(fmt.Sprintf("Stream error: %v", ev.Err))
+				return nil, ev.Err
+			}
+			result = ev.Result
+		}
+	}
+
+	if result == nil {
+		// Cancelled: the engine closes the stream without a Done event, so finish the
+		// session here with what was completed
+		if status != nil {
+			status.Finish()
+		}
+		if lanes != nil {
+			lanes.Flush(0)
+			lanes.Flush(1)
+		}
+		end := transcript.End{Rounds: (&transcript.Transcript{Turns: turns}).Rounds(), Reason: stopInterrupted}
+		writeEnd(end)
+		if (exportFormat != "" || exportTmpl != nil) && len(turns) > 0 {
+			exportSession(&transcript.Transcript{Header: header, Turns: turns, End: &end}, record)
+		}
+		fmt.Println()
+		ui.PrintWarning(fmt.Sprintf("Interrupted after %d rounds", end.Rounds))
+		return nil, errInterrupted
+	}
+	return result, nil
+}
+
+// printCompletion reports why a run ended, followed by its summary
+func printCompletion(result *bridge.Result) {
+	fmt.Println()
+	switch result.Reason {
+	case bridge.StopFarewell:
+		ui.PrintSuccess(fmt.Sprintf("Conversation ended naturally after %s", countRounds(result.Rounds)))
+	case bridge.StopLoop:
+		ui.PrintSuccess(fmt.Sprintf("Conversation stopped after %s: the agents were repeating each other", countRounds(result.Rounds)))
+	case bridge.StopRefusal:
+		ui.PrintSuccess(fmt.Sprintf("Conversation stopped after %s: an agent declined to respond", countRounds(result.Rounds)))
+	case bridge.StopMaxDuration:
+		ui.PrintSuccess(fmt.Sprintf("Time limit reached: completed %s in %s", countRounds(result.Rounds), result.Elapsed.Round(time.Second)))
+	default:
+		ui.PrintSuccess(fmt.Sprintf("Conversation completed! Reached the limit of %s", countRounds(result.Rounds)))
+	}
+	fmt.Println()
+	ui.PrintBox("📊 Session Summary", summaryLines(result))
+}
+
+// summaryLines describes a finished run for the end-of-run box; token counts and
+// costs are estimates, so they are marked with ~
+func summaryLines(result *bridge.Result) []string {
+	lines := []string{
+		fmt.Sprintf("Duration:  %s", result.Elapsed.Round(time.Second)),
+		fmt.Sprintf("Rounds:    %s", roundNumber(result.Rounds)),
+		"",
+	}
+
+	tokens, saved, failed, refused := 0, 0, 0, 0
+	for _, agent := range result.Summary.Agents {
+		failed += agent.Errors
+		refused += agent.Refusals
+		if agent.Messages == 0 {
+			continue
+		}
+		tokens += agent.Tokens()
+		saved += agent.SavedTokens
+		messages := "messages"
+		if agent.Messages == 1 {
+			messages = "message"
+		}
+		line := fmt.Sprintf("%s (%s · %s): %d %s, %d chars, ~%d tokens",
+			agent.Name, agent.Provider, agent.Model, agent.Messages, messages, agent.Characters, agent.Tokens())
+		if agent.Priced {
+			line += ", ~" + formatCost(agent.Cost)
+		}
+		lines = append(lines, line)
+	}
+
+	total := fmt.Sprintf("Total:     ~%d tokens", tokens)
+	if cost, ok := result.Summary.Cost(); ok {
+		total += ", ~" + formatCost(cost)
+	} else {
+		total += ui.Colorize(" (cost unknown: no pricing for a model)", ui.Dim, false)
+	}
+	lines = append(lines, "", total)
+	if saved > 0 {
+		input := saved
+		for _, agent := range result.Summary.Agents {
+			input += agent.InputTokens
+		}
+		lines = append(lines, fmt.Sprintf("Compacted: ~%d input tokens saved (%.0f%%)", saved, float64(saved)*100/float64(input)))
+	}
+	if failed > 0 {
+		lines = append(lines, fmt.Sprintf("Errors:    %d failed turns skipped", failed))
+	}
+	if refused > 0 {
+		lines = append(lines, fmt.Sprintf("Refusals:  %d declined turns", refused))
+	}
+	return lines
+}
+
+// formatCost renders a USD amount, keeping small amounts readable
+func formatCost(usd float64) string {
+	if usd < 0.01 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// newDirector builds the --director agent, which takes --director-prompt as its
+// system prompt and the provider's default temperature
+func newDirector(cfg *config.Config) (*bridge.Agent, error) {
+	provider, model, err := providers.ParseAgentSpec(director)
+	if err != nil {
+		return nil, fmt.Errorf("--director: %w", err)
+	}
+	agent, err := bridge.NewAgent(cfg, bridge.AgentConfig{
+		Name:         "Director",
+		Provider:     provider,
+		Model:        model,
+		SystemPrompt: directorPrompt,
+	})
+	if errors.Is(err, bridge.ErrNoAPIKey) {
+		suggestReadyProviders(cfg)
+	}
+	return agent, err
+}
+
+// applyAgentFlag fills an agent's provider and model from its combined --agent-<side>
+// value. It overrides the defaults and any resumed transcript's settings, while an
+// explicit --provider-<side> or --model-<side> wins over its half.
+func applyAgentFlag(cmd *cobra.Command, side, value string, provider, model *string) error {
+	if value == "" {
+		return nil
+	}
+	p, m, err := providers.ParseAgentSpec(value)
+	if err != nil {
+		return fmt.Errorf("--agent-%s: %w", side, err)
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("provider-" + side) {
+		*provider = p
+	}
+	// Without a model, use the provider's default rather than one resumed for another provider
+	if !flags.Changed("model-" + side) {
+		*model = m
+	}
+	return nil
+}
+
+// applyRoundUnit validates --count-rounds-by and, when counting exchanges, converts
+// --max-rounds, --additional-rounds and the --*-every intervals to the engine's
+// rounds of one turn each (model schedules are converted as they're parsed). A
+// simultaneous round is already an exchange, and settings restored from a resumed
+// transcript are recorded in turns, so neither is converted.
+func applyRoundUnit(cmd *cobra.Command, resumed bool) error {
+	switch countRoundsBy {
+	case roundsByTurn:
+		return nil
+	case roundsByExchange:
+	default:
+		return fmt.Errorf("--count-rounds-by must be %s or %s", roundsByTurn, roundsByExchange)
+	}
+	if mode == string(bridge.ModeSimultaneous) {
+		return nil
+	}
+
+	turnsPerRound = 2
+	if !resumed || cmd.Flags().Changed("max-rounds") {
+		maxRounds *= turnsPerRound
+	}
+	if !resumed || cmd.Flags().Changed("reinforce-system-every") {
+		reinforceEvery *= turnsPerRound
+	}
+	additionalRounds *= turnsPerRound
+	humanEvery *= turnsPerRound
+	directorEvery *= turnsPerRound
+	checkpointEvery *= turnsPerRound
+	return nil
+}
+
+// countRounds describes n engine rounds in the unit --count-rounds-by selected
+func countRounds(n int) string {
+	count, unit := n, "round"
+	if turnsPerRound > 1 {
+		count, unit = n/turnsPerRound, "exchange"
+	}
+	counted := fmt.Sprintf("%d %ss", count, unit)
+	if count == 1 {
+		counted = "1 " + unit
+	}
+	if n%turnsPerRound != 0 {
+		counted += " and a turn"
+	}
+	return counted
+}
+
+// scheduleUnit notes that model schedules are shown in turns when rounds are counted otherwise
+func scheduleUnit() string {
+	if turnsPerRound > 1 {
+		return " (by turn)"
+	}
+	return ""
+}
+
+// roundNumber is countRounds for labelled values, where counting turns needs no unit
+func roundNumber(n int) string {
+	if turnsPerRound == 1 {
+		return strconv.Itoa(n)
+	}
+	return countRounds(n)
+}
+
+// applyAdditionalRounds turns --additional-rounds into the absolute round limit for a
+// resumed run. An explicit --max-rounds stays a cap, so it must leave room for them.
+func applyAdditionalRounds(cmd *cobra.Command, loaded int) error {
+	if !cmd.Flags().Changed("additional-rounds") {
+		return nil
+	}
+	if additionalRounds < 1 {
+		return fmt.Errorf("--additional-rounds must be 1 or more")
+	}
+
+	total := loaded + additionalRounds
+	if cmd.Flags().Changed("max-rounds") && maxRounds < total {
+		return fmt.Errorf("--additional-rounds %d needs %d rounds in total (the transcript has %d), but --max-rounds is %d",
+			additionalRounds, total, loaded, maxRounds)
+	}
+	maxRounds = total
+	return nil
+}
+
+// applyTranscriptSettings reuses the recorded agent settings for any flag the user didn't set,
+// so a resumed or branched run continues with the same providers unless overridden
+func applyTranscriptSettings(cmd *cobra.Command, h transcript.Header) {
+	flags := cmd.Flags()
+	set := func(name string, target *string, value string) {
+		if !flags.Changed(name) && value != "" {
+			*target = value
+		}
+	}
+	setTemp := func(name string, target *temperatureFlag, value *float64) {
+		if !flags.Changed(name) {
+			target.value = value
+		}
+	}
+
+	a, b := h.Agents[0], h.Agents[1]
+	set("provider-a", &providerA, a.ProviderName())
+	set("provider-b", &providerB, b.ProviderName())
+	set("model-a", &modelA, a.Model)
+	set("model-b", &modelB, b.Model)
+	set("model-schedule-a", &modelScheduleA, a.ModelSchedule)
+	set("model-schedule-b", &modelScheduleB, b.ModelSchedule)
+	set("name-a", &nameA, a.Name)
+	set("name-b", &nameB, b.Name)
+	set("system-a", &systemA, a.SystemPrompt)
+	set("system-b", &systemB, b.SystemPrompt)
+	setTemp("temp-a", &tempA, a.Temperature)
+	setTemp("temp-b", &tempB, b.Temperature)
+	if !flags.Changed("max-rounds") && h.MaxRounds > 0 {
+		maxRounds = h.MaxRounds
+	}
+	if !flags.Changed("image") {
+		imageRefs = h.Images
+	}
+	if !flags.Changed("tag") {
+		tags = h.Tags
+	}
+	if !flags.Changed("tools-a") {
+		toolsA = a.Tools
+	}
+	if !flags.Changed("tools-b") {
+		toolsB = b.Tools
+	}
+	if !flags.Changed("json-mode-a") {
+		jsonModeA = a.JSONMode
+	}
+	if !flags.Changed("json-mode-b") {
+		jsonModeB = b.JSONMode
+	}
+	if !flags.Changed("mode") && h.Mode != "" {
+		mode = h.Mode
+	}
+	set("count-rounds-by", &countRoundsBy, h.CountRoundsBy)
+	if !flags.Changed("reinforce-system-every") {
+		reinforceEvery = h.ReinforceEvery
+	}
+	if !flags.Changed("turn-prefix") {
+		turnPrefix = h.TurnPrefix
+	}
+	if !flags.Changed("turn-suffix") {
+		turnSuffix = h.TurnSuffix
+	}
+	if !flags.Changed("inject-round") && !flags.Changed("round-template") && h.RoundNote != "" {
+		injectRound, roundTemplate = true, h.RoundNote
+	}
+	if !flags.Changed("compact-history") {
+		compactHistory = h.CompactHistory
+	}
+	if !flags.Changed("compact-max-chars") {
+		compactMax = h.CompactMaxChars
+	}
+	if !flags.Changed("context-file") {
+		sessionContext = h.Context
+	}
+	if !flags.Changed("min-response-chars") {
+		minChars = h.MinResponseChars
+	}
+	if !flags.Changed("elaborate-prompt") {
+		elaborate = h.ElaboratePrompt
+	}
+	if !flags.Changed("max-response-chars") {
+		maxChars = h.MaxResponseChars
+	}
+
+	// The recorded history starts from the original starter, so it can't change
+	starter = h.Starter
+}
+
+// temperatureFlag is a temperature flag value: a number, or "default" (nil) to leave the
+// temperature to the provider
+type temperatureFlag struct {
+	value *float64
+	set   bool // Given on the command line or by a persona rather than defaulted
+}
+
+func newTemperatureFlag(v float64) temperatureFlag {
+	return temperatureFlag{value: &v}
+}
+
+func (t *temperatureFlag) String() string {
+	if t.value == nil {
+		return "default"
+	}
+	return strconv.FormatFloat(*t.value, 'g', -1, 64)
+}
+
+func (t *temperatureFlag) Set(s string) error {
+	t.set = true
+	if strings.EqualFold(s, "default") {
+		t.value = nil
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf(`expected a number or "default"`)
+	}
+	t.value = &v
+	return nil
+}
+
+// fitDefault leaves the temperature to a model that only runs at its default
+// unless one was asked for, so the 0.7 default doesn't apply to o1-style models
+func (t *temperatureFlag) fitDefault(cfg *config.Config, provider, model string) {
+	if t.set || t.value == nil {
+		return
+	}
+	if model == "" {
+		model = cfg.GetDefaultModel(provider)
+	}
+	if spec, ok := providers.GetProviderSpec(cfg.ProviderKey(provider)); ok && !spec.AcceptsTemperature(model) {
+		t.value = nil
+	}
+}
+
+// warnFixedTemperature warns when a temperature was asked of an agent whose
+// models (any of them, with a schedule) only run at their default; the engine
+// leaves it out of their requests
+func warnFixedTemperature(agent *bridge.Agent, flag string, t *temperatureFlag) {
+	if !t.set || t.value == nil {
+		return
+	}
+	for _, model := range append([]string{agent.Model}, agent.ModelSchedule.Models()...) {
+		if !agent.AcceptsTemperature(model) {
+			ui.PrintWarning(fmt.Sprintf("%s: %s only runs at its default temperature; --%s %s will be ignored for it", agent.Name, model, flag, t))
+		}
+	}
+}
+
+func (t *temperatureFlag) Type() string {
+	return "temperature"
+}
+
+// engineRetries maps --empty-retries to bridge.Options, where zero means the default
+func engineRetries(n int) int {
+	if n == 0 {
+		return -1
+	}
+	return n
+}
+
+// laneLabels builds the aligned, colored line prefixes for simultaneous mode
+func laneLabels(names [2]string, colors [2]lipgloss.Color) [2]string {
+	width := max(lipgloss.Width(names[0]), lipgloss.Width(names[1]))
+	var labels [2]string
+	for i, name := range names {
+		padded := name + strings.Repeat(" ", width-lipgloss.Width(name))
+		labels[i] = ui.Colorize(padded, colors[i], true) + ui.Colorize(" │ ", ui.Dim, false)
+	}
+	return labels
+}
+
+// checkAgent runs an agent's connectivity check (unless health recently passed one
+// for the same endpoint) and model check, reporting a cancelled ctx as an
+// interruption rather than a failed check
+func checkAgent(ctx context.Context, health *bridge.HealthCache, agent *bridge.Agent) error {
+	err := health.Check(ctx, agent)
+	if err == nil {
+		err = checkModel(ctx, agent)
+	}
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	return err
+}
+
+// reportReady prints that an agent passed its checks. With --health verbose it first
+// times a one-token request, so a slow but reachable provider shows before the run.
+func reportReady(ctx context.Context, label string, agent *bridge.Agent) error {
+	if healthMode != healthVerbose {
+		ui.PrintSuccess(label + " ready")
+		return nil
+	}
+
+	ttft, err := agent.Warmup(ctx)
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+	if err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("%s ready, first token in %s", label, ttft.Round(time.Millisecond)))
+	return nil
+}
+
+// checkModel rejects a model the provider doesn't offer unless --allow-unknown-model is set
+func checkModel(ctx context.Context, agent *bridge.Agent) error {
+	if allowUnknownModel {
+		return nil
+	}
+	err := agent.CheckModel(ctx)
+	if errors.Is(err, providers.ErrModelNotFound) {
+		return fmt.Errorf("%w\nRun 'chat-bridge models %s' to list models, or pass --allow-unknown-model to use it anyway", err, agent.ProviderName())
+	}
+	return err
+}
+
+// agentInfo describes an agent for the transcript header
+func agentInfo(a *bridge.Agent, color lipgloss.Color) transcript.AgentInfo {
+	info := transcript.AgentInfo{
+		Name:         a.Name,
+		Provider:     a.Provider.Name(),
+		Alias:        a.Alias,
+		Model:        a.Model,
+		Temperature:  a.Temperature,
+		SystemPrompt: a.SystemPrompt,
+		Color:        string(color),
+		Tools:        toolNames(a.Tools),
+		JSONMode:     a.JSONMode,
+
+		ModelSchedule: a.ModelSchedule.String(),
+	}
+	if a.Schema != nil {
+		info.Schema, info.SchemaName = a.Schema.Document, a.Schema.Name
+	}
+	return info
+}
+
+// agentSchema loads an agent's --schema-file or, resuming without one, the schema
+// the transcript recorded for it
+func agentSchema(flag, path string, prior *transcript.Transcript, speaker int) (*providers.JSONSchema, error) {
+	if path != "" {
+		schema, err := providers.LoadJSONSchema(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flag, err)
+		}
+		return schema, nil
+	}
+	if prior == nil || len(prior.Header.Agents[speaker].Schema) == 0 {
+		return nil, nil
+	}
+	info := prior.Header.Agents[speaker]
+	schema, err := providers.ParseJSONSchema(info.SchemaName, info.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("%s's schema in %s: %w", info.Name, resumePath, err)
+	}
+	return schema, nil
+}
+
+// parseModelSchedule parses a --model-schedule flag, which must cover every round
+// of the run; empty means no schedule. The flag counts rounds by --count-rounds-by;
+// a schedule recorded in a resumed transcript is already in turns, and is extended
+// to any rounds added since, keeping its last model.
+func parseModelSchedule(flag, spec string, maxRounds int, recorded bool) (bridge.ModelSchedule, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	schedule, err := bridge.ParseModelSchedule(spec)
+	if err == nil && recorded {
+		schedule = schedule.Extend(maxRounds)
+	} else if err == nil {
+		schedule = schedule.InTurns(turnsPerRound)
+	}
+	if err == nil {
+		err = schedule.Validate(maxRounds)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flag, err)
+	}
+	return schedule, nil
+}
+
+func toolNames(list []tools.Tool) []string {
+	var names []string
+	for _, tool := range list {
+		names = append(names, tool.Spec.Name)
+	}
+	return names
+}
+
+// toolCallLine summarizes a tool call for the conversation view
+func toolCallLine(use *bridge.ToolUse) string {
+	result := use.Result
+	if use.Error != "" {
+		result = "error: " + use.Error
+	}
+	oneLine := func(text string, width int) string {
+		return ansi.Truncate(strings.Join(strings.Fields(text), " "), width, "…")
+	}
+	return fmt.Sprintf("🔧 %s(%s) → %s", use.Name, oneLine(use.Arguments, 60), oneLine(result, 80))
+}
+
+// exportSession writes the --export and --transcript-template documents next to the
+// transcript (or a timestamped file)
+func exportSession(t *transcript.Transcript, record *transcript.Writer) {
+	base := ""
+	if record != nil {
+		base = strings.TrimSuffix(record.Path(), filepath.Ext(record.Path()))
+	}
+	if base == "" {
+		base = "conversation-" + t.Header.Started.Format("20060102-150405")
+	}
+
+	if exportFormat != "" {
+		path := base + export.Extension(exportFormat)
+		if err := export.WriteFile(path, exportFormat, t, export.Options{Assistant: exportAssistant}); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Export failed: %v", err))
+		} else {
+			ui.PrintSuccess(fmt.Sprintf("Exported conversation to %s", path))
+		}
+	}
+	if exportTmpl != nil {
+		path := base + exportTmpl.Extension()
+		if err := exportTmpl.WriteFile(path, t); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Template export failed: %v", err))
+		} else {
+			ui.PrintSuccess(fmt.Sprintf("Rendered conversation to %s", path))
+		}
+	}
+}
+
+// openTranscript opens the transcript this run records to, or returns nil when not recording.
+// Resuming appends to the resumed file unless --transcript or --log-dir names another; branching
+// always starts a new file that copies the prior turns.
+func openTranscript(header *transcript.Header, prior *transcript.Transcript, run int) (*transcript.Writer, error) {
+	if transcriptPath == "" && logDir == "" {
+		switch {
+		case prior != nil && !branching:
+			return transcript.Append(resumePath)
+		case prior == nil:
+			return nil, nil
+		}
+	}
+
+	if prior != nil {
+		header.Started = prior.Header.Started
+		if branching {
+			header.Started = time.Now()
+			header.BranchedFrom = resumePath
+		}
+	}
+
+	w, err := createTranscript(*header, run)
+	if err != nil {
+		return nil, err
+	}
+	if prior != nil {
+		for _, turn := range prior.Turns {
+			// Asked for again, like on resume
+			if turn.Incomplete {
+				continue
+			}
+			if err := w.WriteTurn(turn); err != nil {
+				w.Close()
+				return nil, err
+			}
+		}
+	}
+	ui.PrintInfo(fmt.Sprintf("Recording transcript to %s", w.Path()))
+	pruneLogs()
+	return w, nil
+}
+
+// createTranscript starts the file for a new recording per --transcript, --log-dir, and
+// --append-log. When another session holds --transcript, this one records to a fresh file
+// beside it instead.
+func createTranscript(header transcript.Header, run int) (*transcript.Writer, error) {
+	switch {
+	case logDir != "":
+		return transcript.CreateInDir(logDir, header)
+	case transcriptPath == "":
+		path := strings.TrimSuffix(resumePath, filepath.Ext(resumePath)) + "-branch-" + time.Now().Format("20060102-150405") + ".jsonl"
+		return transcript.Open(runPath(path, run), header, transcript.OpenOptions{})
+	}
+
+	maxSize, _ := parseSize(logMaxSize)
+	w, err := transcript.Open(runPath(transcriptPath, run), header, transcript.OpenOptions{Append: appendLog, MaxSize: maxSize})
+	if errors.Is(err, transcript.ErrInUse) {
+		ui.PrintWarning(fmt.Sprintf("%v; recording this session to a separate file", err))
+		return transcript.CreateInDir(filepath.Dir(transcriptPath), header)
+	}
+	return w, err
+}
+
+// pruneLogs applies --log-keep to the sessions in --log-dir or the rotated archives of
+// --transcript; failures only warn
+func pruneLogs() {
+	if logKeep == 0 {
+		return
+	}
+	pattern := filepath.Join(logDir, transcript.SessionPrefix+"*.jsonl")
+	if logDir == "" {
+		if transcriptPath == "" {
+			return
+		}
+		pattern = strings.TrimSuffix(transcriptPath, filepath.Ext(transcriptPath)) + "-*.jsonl.gz"
+	}
+
+	removed, err := transcript.Prune(pattern, logKeep)
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Failed to prune old transcripts: %v", err))
+	}
+	if len(removed) > 0 {
+		ui.PrintInfo(fmt.Sprintf("Removed %d old transcript(s)", len(removed)))
+	}
+}
+
+// parseHeaders turns repeated Name=value flags into a header map
+func parseHeaders(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(flags))
+	for _, raw := range flags {
+		name, value, err := providers.ParseHeader(raw)
+		if err != nil {
+			return nil, err
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+// parseSize reads a byte count such as 512KB, 10MB, or 1GB (1024-based); "" is 0
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	units := []struct {
+		suffix string
+		scale  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}}
+	scale := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, scale = strings.TrimSuffix(s, u.suffix), u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a positive size such as 10MB")
+	}
+	return n * scale, nil
+}
+
+// saveCheckpoint writes a snapshot of the conversation so far; failures only warn
+func saveCheckpoint(header transcript.Header, turns []transcript.Turn, round, run int) {
+	name := fmt.Sprintf("checkpoint-%03d.jsonl", round)
+	if suffix := runSuffix(run); suffix != "" {
+		name = fmt.Sprintf("checkpoint-%s-%03d.jsonl", suffix, round)
+	}
+	path := filepath.Join(checkpointDir, name)
+	err := os.MkdirAll(checkpointDir, 0o755)
+	if err == nil {
+		err = transcript.Save(path, &transcript.Transcript{Header: header, Turns: turns})
+	}
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Failed to save checkpoint: %v", err))
+		return
+	}
+	ui.PrintInfo(fmt.Sprintf("Checkpoint saved: %s", path))
+}
+
+// samplingFromFlags collects the sampling flags the user explicitly set
+func samplingFromFlags(cmd *cobra.Command) providers.Sampling {
+	var sampling providers.Sampling
+	flags := cmd.Flags()
+	if flags.Changed("seed") {
+		sampling.Seed = &seed
+	}
+	if flags.Changed("top-p") {
+		sampling.TopP = &topP
+	}
+	if flags.Changed("frequency-penalty") {
+		sampling.FrequencyPenalty = &frequencyPenalty
+	}
+	if flags.Changed("presence-penalty") {
+		sampling.PresencePenalty = &presencePenalty
+	}
+	return sampling
+}
+
+// connectMemory creates the MCP memory client, returning nil (with a warning) if it is unavailable
+func connectMemory(ctx context.Context, cfg *config.Config) mcp.Memory {
+	memory, err := mcp.New(ctx, cfg)
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Memory disabled: %v", err))
+		return nil
+	}
+
+	if err := memory.Health(ctx); err != nil {
+		ui.PrintWarning(fmt.Sprintf("MCP memory unavailable, continuing without it: %v", err))
+		memory.Close()
+		return nil
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("MCP memory connected (%s)", cfg.MCPMode))
+	return memory
+}
+
+// describeProvider names a provider for display, showing what an alias resolves to
+func describeProvider(cfg *config.Config, name string) string {
+	alias, ok := cfg.ResolveAlias(name)
+	if !ok {
+		return name
+	}
+	if alias.BaseURL != "" {
+		return fmt.Sprintf("%s (%s @ %s)", name, alias.Provider, alias.BaseURL)
+	}
+	return fmt.Sprintf("%s (%s)", name, alias.Provider)
 }
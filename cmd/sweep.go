@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// sweepSetting is one combination of --temp-sweep-a and --temp-sweep-b
+// temperatures; a nil temperature leaves that agent at its own
+type sweepSetting struct {
+	temps [2]*float64
+}
+
+// sweep holds the settings a temperature sweep runs through, A's temperatures
+// varying slowest; it is empty without --temp-sweep-a or --temp-sweep-b
+var sweep []sweepSetting
+
+// parseTempSweep parses a comma-separated --temp-sweep-a/-b list, checking each
+// temperature against the provider's range
+func parseTempSweep(cfg *config.Config, flag, value, provider string) ([]float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	spec, known := providers.GetProviderSpec(cfg.ProviderKey(provider))
+	var temps []float64
+	for _, field := range strings.Split(value, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || t < 0 {
+			return nil, fmt.Errorf("--%s: expected a comma-separated list of temperatures, got %q", flag, field)
+		}
+		if known {
+			if err := spec.ValidateTemperature(t); err != nil {
+				return nil, fmt.Errorf("--%s: %w", flag, err)
+			}
+		}
+		for _, seen := range temps {
+			if seen == t {
+				return nil, fmt.Errorf("--%s lists %g twice", flag, t)
+			}
+		}
+		temps = append(temps, t)
+	}
+	return temps, nil
+}
+
+// sweepSettings combines the swept temperatures: one setting per listed value, or
+// per combination when both agents are swept
+func sweepSettings(tempsA, tempsB []float64) []sweepSetting {
+	if len(tempsA) == 0 && len(tempsB) == 0 {
+		return nil
+	}
+	// An unswept agent contributes a single nil to every combination
+	valuesA, valuesB := sweepValues(tempsA), sweepValues(tempsB)
+	settings := make([]sweepSetting, 0, len(valuesA)*len(valuesB))
+	for _, a := range valuesA {
+		for _, b := range valuesB {
+			settings = append(settings, sweepSetting{temps: [2]*float64{a, b}})
+		}
+	}
+	return settings
+}
+
+func sweepValues(temps []float64) []*float64 {
+	if len(temps) == 0 {
+		return []*float64{nil}
+	}
+	values := make([]*float64, len(temps))
+	for i := range temps {
+		values[i] = &temps[i]
+	}
+	return values
+}
+
+// describeTemperature shows an agent's temperature for the session configuration:
+// the swept values, if any, else its flag
+func describeTemperature(t *temperatureFlag, swept []float64) string {
+	if len(swept) == 0 {
+		return t.String()
+	}
+	values := make([]string, len(swept))
+	for i, v := range swept {
+		values[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return "sweep of " + strings.Join(values, ", ")
+}
+
+// totalRuns is how many conversations the command runs: --repeat of each sweep setting
+func totalRuns() int {
+	return repeat * max(1, len(sweep))
+}
+
+// multiRunFlag names the flag that made this a multi-run command, for error messages
+func multiRunFlag() string {
+	if len(sweep) > 0 {
+		return "--temp-sweep-a/-b"
+	}
+	return "--repeat"
+}
+
+// runSetting returns the sweep setting that run (counting from 1) uses
+func runSetting(run int) (sweepSetting, bool) {
+	if len(sweep) == 0 {
+		return sweepSetting{}, false
+	}
+	return sweep[(run-1)/repeat], true
+}
+
+// applySetting sets the agents' temperatures for a run of the sweep
+func applySetting(agents [2]*bridge.Agent, run int) {
+	setting, ok := runSetting(run)
+	if !ok {
+		return
+	}
+	for i, t := range setting.temps {
+		if t != nil {
+			agents[i].Temperature = t
+		}
+	}
+}
+
+// runSuffix tells a run's files apart: "run2" with --repeat, "temp-a0.2-temp-b1.2"
+// in a sweep (plus the run number when both apply), or "" for a single run
+func runSuffix(run int) string {
+	var parts []string
+	if setting, ok := runSetting(run); ok {
+		for i, t := range setting.temps {
+			if t != nil {
+				parts = append(parts, fmt.Sprintf("temp-%c%s", 'a'+i, strconv.FormatFloat(*t, 'g', -1, 64)))
+			}
+		}
+	}
+	if repeat > 1 {
+		parts = append(parts, fmt.Sprintf("run%d", (run-1)%repeat+1))
+	}
+	return strings.Join(parts, "-")
+}
+
+// describe labels the setting in run titles and the summary, e.g. "Temp A 0.2, Temp B 1.2"
+func (s sweepSetting) describe() string {
+	var parts []string
+	for i, t := range s.temps {
+		if t != nil {
+			parts = append(parts, fmt.Sprintf("Temp %c %s", 'A'+i, strconv.FormatFloat(*t, 'g', -1, 64)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sweepSummaryLines tabulates the sweep for the closing box: rounds, tokens and cost
+// per setting, averaged over its --repeat runs. Token counts and costs are estimates.
+func sweepSummaryLines(results []*bridge.Result) []string {
+	width := len("Setting")
+	for _, setting := range sweep {
+		width = max(width, len(setting.describe()))
+	}
+	lines := []string{
+		ui.Colorize(fmt.Sprintf("%-*s  %7s  %8s  %9s  %s", width, "Setting", "Rounds", "Tokens", "Cost", "Ending"), ui.Dim, false),
+	}
+	for i, setting := range sweep {
+		runs := results[i*repeat : min((i+1)*repeat, len(results))]
+		if len(runs) == 0 {
+			break
+		}
+		rounds, tokens, farewells := 0, 0, 0
+		cost, priced := 0.0, true
+		for _, result := range runs {
+			rounds += result.Rounds
+			for _, agent := range result.Summary.Agents {
+				tokens += agent.Tokens()
+			}
+			runCost, ok := result.Summary.Cost()
+			cost += runCost
+			priced = priced && ok
+			if result.Reason == bridge.StopFarewell {
+				farewells++
+			}
+		}
+		n := len(runs)
+		costText := "unknown"
+		if priced {
+			costText = "~" + formatCost(cost/float64(n))
+		}
+		ending := string(runs[0].Reason)
+		if n > 1 {
+			ending = fmt.Sprintf("%d/%d farewells", farewells, n)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %7s  %8s  %9s  %s", width, setting.describe(),
+			fmt.Sprintf("%.1f", float64(rounds)/float64(n)), fmt.Sprintf("~%d", tokens/n), costText, ending))
+	}
+	if repeat > 1 {
+		lines = append(lines, "", ui.Colorize(fmt.Sprintf("Rounds, tokens and cost are averages over %d runs per setting", repeat), ui.Dim, false))
+	}
+	return lines
+}
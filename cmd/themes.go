@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var themesNoColor bool
+
+// themesCmd represents the themes command
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "Preview the color themes",
+	Long: `Render a short sample conversation in every theme, with agent labels, status
+messages and a section header, so you can compare how readable each one is on
+your terminal before picking one with --theme.
+
+With --no-color (or NO_COLOR set) only the theme names are listed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if themesNoColor || os.Getenv("NO_COLOR") != "" {
+			for _, name := range ui.ThemeNames() {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		defer ui.SetTheme(ui.CurrentTheme())
+		for _, name := range ui.ThemeNames() {
+			ui.SetTheme(ui.Themes[name])
+			printThemeSample(name)
+		}
+		return nil
+	},
+}
+
+// printThemeSample renders a sample conversation in the current theme
+func printThemeSample(name string) {
+	ui.PrintSectionHeader("Theme: "+name, "🎨")
+	fmt.Printf("%s %s\n", ui.AgentA.Render("Agent A:"), "What makes a bridge stand up?")
+	fmt.Printf("%s %s\n", ui.AgentB.Render("Agent B:"), "Tension and compression, kept in balance.")
+	fmt.Println()
+	ui.PrintSuccess("Transcript saved")
+	ui.PrintWarning("Agent B's reply was cut short")
+	ui.PrintError("Provider request failed")
+	ui.PrintInfo("Round 2 of 10")
+	fmt.Printf("%s\n", ui.Colorize("Use it with --theme "+name, ui.Dim, false))
+}
+
+func init() {
+	themesCmd.Flags().BoolVar(&themesNoColor, "no-color", false, "List the theme names without rendering samples")
+	rootCmd.AddCommand(themesCmd)
+}
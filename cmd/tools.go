@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/tools"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// toolsCmd represents the tools command
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List the tools agents can call",
+	Long: `List the registered tools that can be given to agents with --tools-a and
+--tools-b. Tool calling requires a provider that supports it (e.g. openai).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ui.PrintSectionHeader("Tools", "🔧")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  TOOL\tDESCRIPTION")
+		for _, tool := range tools.List() {
+			fmt.Fprintf(w, "  %s\t%s\n", tool.Spec.Name, tool.Spec.Description)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/persona"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd groups the file checks
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check persona and template files before a run",
+	Long: `Check persona and template files without calling any provider. Every problem is
+reported with its file and line: unknown or misspelled fields, missing required
+fields, and colors, providers, temperatures, or modes that a run would reject.`,
+}
+
+var validatePersonaCmd = &cobra.Command{
+	Use:   "persona <file>...",
+	Short: "Check persona files",
+	Example: `  chat-bridge validate persona personas/socrates.yaml
+  chat-bridge validate persona personas/*.yaml`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true, // Failures are about the files, not the command line
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateFiles(args, func(path string, checks persona.Checks) (string, error) {
+			p, err := persona.LoadPersona(path, checks)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("persona %q", p.Name), nil
+		})
+	},
+}
+
+var validateTemplateCmd = &cobra.Command{
+	Use:          "template <file>...",
+	Short:        "Check template files and the personas they use",
+	Example:      `  chat-bridge validate template templates/debate.yaml`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateFiles(args, func(path string, checks persona.Checks) (string, error) {
+			t, err := persona.LoadTemplate(path, checks)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("template with %s and %s", t.Agents[0].Name, t.Agents[1].Name), nil
+		})
+	},
+}
+
+func init() {
+	validateCmd.AddCommand(validatePersonaCmd, validateTemplateCmd)
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateFiles loads each file, printing a pass or its problems, and fails if any file did
+func validateFiles(paths []string, load func(path string, checks persona.Checks) (string, error)) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	checks := personaChecks(cfg)
+
+	failed := 0
+	for _, path := range paths {
+		what, err := load(path, checks)
+		if err == nil {
+			ui.PrintSuccess(fmt.Sprintf("%s: valid %s", path, what))
+			continue
+		}
+
+		failed++
+		var perr *persona.Error
+		if !errors.As(err, &perr) {
+			ui.PrintError(fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		problems := "problems"
+		if len(perr.Problems) == 1 {
+			problems = "problem"
+		}
+		ui.PrintError(fmt.Sprintf("%s: %d %s", path, len(perr.Problems), problems))
+		for _, p := range perr.Problems {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed validation", failed, len(paths))
+	}
+	return nil
+}
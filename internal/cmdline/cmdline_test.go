@@ -0,0 +1,24 @@
+package cmdline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	args, err := Split(`python3 "my model.py" --name 'a b' c\ d`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"python3", "my model.py", "--name", "a b", "c d"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", args, want)
+	}
+
+	if _, err := Split(`"unterminated`); err == nil {
+		t.Fatal("expected error for unterminated quote")
+	}
+	if _, err := Split("   "); err == nil {
+		t.Fatal("expected error for empty command")
+	}
+}
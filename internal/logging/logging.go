@@ -0,0 +1,79 @@
+// Package logging configures the process-wide slog logger.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces secret values in log output
+const Redacted = "[REDACTED]"
+
+// Levels lists the accepted --log-level values
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// ParseLevel converts a --log-level value into a slog level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (use one of: %s)", level, strings.Join(Levels, ", "))
+	}
+}
+
+// New builds a text or JSON logger writing to w at the given level.
+// Every occurrence of a secret in a message or attribute is replaced with Redacted,
+// so credentials can't leak even through error strings returned by an API.
+func New(w io.Writer, level string, json bool, secrets []string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redactor(secrets),
+	}
+
+	var handler slog.Handler
+	if json {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(handler), nil
+}
+
+// redactor returns a ReplaceAttr hook scrubbing secrets from string-like values
+func redactor(secrets []string) func([]string, slog.Attr) slog.Attr {
+	var pairs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, Redacted)
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	return func(_ []string, a slog.Attr) slog.Attr {
+		switch a.Value.Kind() {
+		case slog.KindString, slog.KindAny:
+			s := a.Value.String()
+			if redacted := replacer.Replace(s); redacted != s {
+				return slog.String(a.Key, redacted)
+			}
+		}
+		return a
+	}
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewRedactsSecrets(t *testing.T) {
+	for _, json := range []bool{false, true} {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "debug", json, []string{"sk-secret-123", ""})
+		if err != nil {
+			t.Fatalf("new: %v", err)
+		}
+
+		logger.Debug("calling with sk-secret-123",
+			"url", "https://api.example.com/v1?key=sk-secret-123",
+			"err", errors.New("Incorrect API key provided: sk-secret-123"),
+		)
+
+		out := buf.String()
+		if strings.Contains(out, "sk-secret-123") {
+			t.Fatalf("json=%v: secret leaked: %s", json, out)
+		}
+		if strings.Count(out, Redacted) != 3 {
+			t.Fatalf("json=%v: expected 3 redactions: %s", json, out)
+		}
+	}
+}
+
+func TestNewFiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", false, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range append(Levels, "DEBUG", "warning") {
+		if _, err := ParseLevel(level); err != nil {
+			t.Errorf("%s: %v", level, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+}
@@ -0,0 +1,114 @@
+// Package bench measures streaming throughput of chat providers.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// DefaultPrompt is sent when no prompt is given
+const DefaultPrompt = "Write a short paragraph about the history of bridges."
+
+// Sample is the timing of a single streamed response.
+// Streamed chunks are counted as tokens; most providers emit roughly one token per chunk.
+type Sample struct {
+	TimeToFirstToken time.Duration
+	Duration         time.Duration
+	Tokens           int
+}
+
+// TokensPerSecond is the generation rate between the first and last chunk
+func (s Sample) TokensPerSecond() float64 {
+	gen := s.Duration - s.TimeToFirstToken
+	if s.Tokens < 2 || gen <= 0 {
+		return 0
+	}
+	// The first chunk starts the clock, so it is excluded from the rate
+	return float64(s.Tokens-1) / gen.Seconds()
+}
+
+// Stats summarises a metric across iterations
+type Stats struct {
+	Min    float64
+	Median float64
+	Max    float64
+}
+
+// Report holds every sample for one provider and the derived statistics
+type Report struct {
+	Samples          []Sample
+	TimeToFirstToken Stats // seconds
+	TokensPerSecond  Stats
+}
+
+// Measure streams req from p once and times the first and last chunk
+func Measure(ctx context.Context, p providers.Provider, req *providers.ChatRequest) (Sample, error) {
+	var sample Sample
+	start := time.Now()
+	var last time.Time
+
+	textChan, errChan := p.StreamChat(ctx, req)
+	for text := range textChan {
+		if text == "" {
+			continue
+		}
+		last = time.Now()
+		if sample.Tokens == 0 {
+			sample.TimeToFirstToken = last.Sub(start)
+		}
+		sample.Tokens++
+	}
+	if err := <-errChan; err != nil {
+		return sample, err
+	}
+	if sample.Tokens == 0 {
+		return sample, errors.New("empty response stream")
+	}
+
+	sample.Duration = last.Sub(start)
+	return sample, nil
+}
+
+// Run measures req iterations times and summarises the results
+func Run(ctx context.Context, p providers.Provider, req *providers.ChatRequest, iterations int) (*Report, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("iterations must be at least 1, got %d", iterations)
+	}
+
+	report := &Report{}
+	for i := 0; i < iterations; i++ {
+		sample, err := Measure(ctx, p, req)
+		if err != nil {
+			return nil, fmt.Errorf("iteration %d: %w", i+1, err)
+		}
+		report.Samples = append(report.Samples, sample)
+	}
+
+	ttft := make([]float64, len(report.Samples))
+	tps := make([]float64, len(report.Samples))
+	for i, s := range report.Samples {
+		ttft[i] = s.TimeToFirstToken.Seconds()
+		tps[i] = s.TokensPerSecond()
+	}
+	report.TimeToFirstToken = summarize(ttft)
+	report.TokensPerSecond = summarize(tps)
+	return report, nil
+}
+
+// summarize returns min/median/max of values (which must be non-empty)
+func summarize(values []float64) Stats {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return Stats{Min: sorted[0], Median: median, Max: sorted[n-1]}
+}
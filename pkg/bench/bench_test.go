@@ -0,0 +1,86 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// pacedProvider streams a fixed number of chunks with a delay before each one
+type pacedProvider struct {
+	chunks int
+	delay  time.Duration
+	err    error
+}
+
+func (p *pacedProvider) Name() string                                 { return "paced" }
+func (p *pacedProvider) DefaultModel() string                         { return "paced-model" }
+func (p *pacedProvider) Health(ctx context.Context) error             { return nil }
+func (p *pacedProvider) Models(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *pacedProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+		if p.err != nil {
+			errChan <- p.err
+			return
+		}
+		for i := 0; i < p.chunks; i++ {
+			time.Sleep(p.delay)
+			textChan <- "tok "
+		}
+	}()
+	return textChan, errChan
+}
+
+func TestRunMeasuresThroughput(t *testing.T) {
+	p := &pacedProvider{chunks: 11, delay: 5 * time.Millisecond}
+
+	report, err := Run(context.Background(), p, &providers.ChatRequest{}, 3)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(report.Samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(report.Samples))
+	}
+	for _, s := range report.Samples {
+		if s.Tokens != 11 || s.TimeToFirstToken < 5*time.Millisecond || s.Duration < s.TimeToFirstToken {
+			t.Fatalf("unexpected sample: %+v", s)
+		}
+	}
+
+	// 10 chunks over >= 50ms caps the rate at 200 tokens/s
+	tps := report.TokensPerSecond
+	if tps.Min <= 0 || tps.Max > 200 || tps.Min > tps.Median || tps.Median > tps.Max {
+		t.Fatalf("unexpected tokens/s stats: %+v", tps)
+	}
+}
+
+func TestRunPropagatesErrors(t *testing.T) {
+	boom := errors.New("boom")
+	if _, err := Run(context.Background(), &pacedProvider{err: boom}, &providers.ChatRequest{}, 2); !errors.Is(err, boom) {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+
+	_, err := Run(context.Background(), &pacedProvider{}, &providers.ChatRequest{}, 1)
+	if err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Fatalf("expected empty stream error, got %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := summarize([]float64{4, 1, 3, 2})
+	if got != (Stats{Min: 1, Median: 2.5, Max: 4}) {
+		t.Fatalf("got %+v", got)
+	}
+	if got := summarize([]float64{7}); got.Median != 7 {
+		t.Fatalf("single value median: %+v", got)
+	}
+}
@@ -0,0 +1,312 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bench"
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/tools"
+)
+
+// ErrNoAPIKey is returned by NewAgent when the provider needs an API key that isn't configured
+var ErrNoAPIKey = errors.New("no API key configured")
+
+// AgentConfig describes an agent before its provider is instantiated
+type AgentConfig struct {
+	Name        string   // Display name
+	Provider    string   // Registered provider key (e.g., "openai") or config alias
+	Model       string   // Model ID; empty uses the configured default
+	Temperature *float64 // Sampling temperature; nil leaves it to the provider
+	Command     string   // Command line for the exec provider
+	API         string   // Wire API (providers.APIResponses); empty uses the configured one
+
+	SystemPrompt string             // Optional system prompt
+	Sampling     providers.Sampling // Optional sampling parameters
+	Tools        []string           // Registered tool names the agent may call
+	ToolChoice   string             // Tool choice: auto (default), none, required, or a tool name
+
+	JSONMode bool                  // Require replies to be a single JSON object (see Agent.JSONMode)
+	Schema   *providers.JSONSchema // Schema replies must match; implies JSONMode
+
+	// LogitBias nudges or forbids tokens (see providers.ChatRequest.LogitBias)
+	LogitBias map[string]float64
+
+	// ModelSchedule switches Model by round; its model IDs are resolved like Model
+	ModelSchedule ModelSchedule
+
+	// Headers are sent with every request, on top of (and replacing) any the alias
+	// configures. See providers.ProviderConfig.Headers for OverrideHeaders.
+	Headers         map[string]string
+	OverrideHeaders bool
+}
+
+// NewAgent instantiates the agent's provider using credentials and defaults from cfg
+func NewAgent(cfg *config.Config, ac AgentConfig) (*Agent, error) {
+	if cfg.Proxy != "" {
+		if _, err := providers.ParseProxy(cfg.Proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+	}
+
+	key := cfg.ProviderKey(ac.Provider)
+	if spec, ok := providers.GetProviderSpec(key); ok {
+		if ac.Temperature != nil {
+			if err := spec.ValidateTemperature(*ac.Temperature); err != nil {
+				return nil, fmt.Errorf("%s: %w", ac.Name, err)
+			}
+		}
+		if err := providers.ValidateLogitBias(ac.LogitBias); err != nil {
+			return nil, fmt.Errorf("%s: %w", ac.Name, err)
+		}
+		if err := checkAPIKey(cfg, ac.Provider); err != nil {
+			return nil, fmt.Errorf("%s: %w", ac.Name, err)
+		}
+	}
+
+	api := ac.API
+	if api == "" {
+		api = cfg.GetProviderAPI(ac.Provider)
+	}
+	if err := providers.ValidateAPI(api); err != nil {
+		return nil, fmt.Errorf("%s: %w", ac.Name, err)
+	}
+
+	agentTools, err := tools.Lookup(ac.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ac.Name, err)
+	}
+	if err := validateToolChoice(ac.ToolChoice, ac.Tools); err != nil {
+		return nil, fmt.Errorf("%s: %w", ac.Name, err)
+	}
+
+	model := ac.Model
+	if model == "" {
+		model = cfg.GetDefaultModel(ac.Provider)
+	}
+	// Aliases may point at any endpoint, so their model IDs are passed through as-is
+	schedule := slices.Clone(ac.ModelSchedule)
+	if spec, ok := providers.GetProviderSpec(key); ok && key == ac.Provider {
+		if model, err = spec.ResolveModel(model); err != nil {
+			return nil, fmt.Errorf("%s: %w", ac.Name, err)
+		}
+		for i := range schedule {
+			if schedule[i].Model, err = spec.ResolveModel(schedule[i].Model); err != nil {
+				return nil, fmt.Errorf("%s: model schedule: %w", ac.Name, err)
+			}
+		}
+	}
+
+	apiKey, baseURL := cfg.GetAPIKey(ac.Provider), cfg.GetProviderBaseURL(ac.Provider)
+	p, err := providers.NewProvider(key, providers.ProviderConfig{
+		APIKey:      apiKey,
+		BaseURL:     baseURL,
+		Model:       model,
+		Temperature: ac.Temperature,
+		Command:     ac.Command,
+		Proxy:       cfg.Proxy,
+		TraceDir:    cfg.TraceDir,
+
+		Headers:         mergeHeaders(cfg.GetProviderHeaders(ac.Provider), ac.Headers),
+		OverrideHeaders: ac.OverrideHeaders,
+		API:             api,
+
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
+		DisableKeepAlives:   cfg.DisableKeepAlives,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var alias string
+	if key != ac.Provider {
+		alias = ac.Provider
+	}
+
+	return &Agent{
+		Name:         ac.Name,
+		Alias:        alias,
+		Provider:     p,
+		Model:        p.DefaultModel(),
+		Temperature:  ac.Temperature,
+		SystemPrompt: ac.SystemPrompt,
+		Sampling:     ac.Sampling,
+		Tools:        agentTools,
+		ToolChoice:   ac.ToolChoice,
+		JSONMode:     ac.JSONMode || ac.Schema != nil,
+		Schema:       ac.Schema,
+		LogitBias:    ac.LogitBias,
+
+		ModelSchedule: schedule,
+		endpoint:      endpointKey(key, baseURL, ac.Command, apiKey),
+	}, nil
+}
+
+// mergeHeaders combines configured and per-agent headers, the agent's winning on
+// a name clash (compared case-insensitively, as HTTP does)
+func mergeHeaders(configured, agent map[string]string) map[string]string {
+	if len(configured) == 0 {
+		return agent
+	}
+	merged := make(map[string]string, len(configured)+len(agent))
+	for _, headers := range []map[string]string{configured, agent} {
+		for name, value := range headers {
+			merged[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return merged
+}
+
+// checkAPIKey fails when a provider needs an API key and none is configured, so
+// the run stops before a health check fails with an unhelpful auth error
+func checkAPIKey(cfg *config.Config, provider string) error {
+	if !cfg.NeedsAPIKey(provider) || cfg.GetAPIKey(provider) != "" {
+		return nil
+	}
+
+	hint := "set " + cfg.APIKeyEnv(provider)
+	if cfg.APIKeyEnv(provider) == "" {
+		hint = fmt.Sprintf("set api_key or api_key_env for %s in the config file", provider)
+	}
+	return fmt.Errorf("%w for provider %s; %s or choose a provider you've configured", ErrNoAPIKey, provider, hint)
+}
+
+// validateToolChoice checks that a tool choice is a known mode or one of the agent's tools
+func validateToolChoice(choice string, names []string) error {
+	switch choice {
+	case "", providers.ToolChoiceAuto, providers.ToolChoiceNone, providers.ToolChoiceRequired:
+		return nil
+	}
+	for _, name := range names {
+		if name == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("tool choice %q must be auto, none, required, or one of the agent's tools", choice)
+}
+
+// UnsupportedParams names the agent's configured parameters that its provider would ignore
+func (a *Agent) UnsupportedParams() []string {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	if !ok {
+		return nil
+	}
+	return spec.UnsupportedParams(&providers.ChatRequest{
+		SystemPrompt: a.SystemPrompt,
+		Sampling:     a.Sampling,
+		Tools:        tools.Specs(a.Tools),
+		LogitBias:    a.LogitBias,
+	})
+}
+
+// SupportsImages reports whether the agent's provider accepts image attachments
+func (a *Agent) SupportsImages() bool {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	return ok && spec.SupportsImages
+}
+
+// StrictAlternation reports whether the agent's provider requires user and
+// assistant messages to alternate
+func (a *Agent) StrictAlternation() bool {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	return ok && spec.StrictAlternation
+}
+
+// AcceptsTemperature reports whether requests for model may set a temperature;
+// models that only run at their default get none, whatever the agent's is
+func (a *Agent) AcceptsTemperature(model string) bool {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	return !ok || spec.AcceptsTemperature(model)
+}
+
+// Counter returns the agent's token counter: its own TokenCounter if set, else the
+// one its provider prefers for the model
+func (a *Agent) Counter() providers.TokenCounter {
+	return a.counterFor(a.Model)
+}
+
+// counterFor is Counter for one of the agent's scheduled models
+func (a *Agent) counterFor(model string) providers.TokenCounter {
+	if a.TokenCounter != nil {
+		return a.TokenCounter
+	}
+	return providers.CounterFor(a.Provider.Name(), model)
+}
+
+// ProviderName returns the name the agent's provider was selected by: its alias, if any
+func (a *Agent) ProviderName() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	return a.Provider.Name()
+}
+
+// CheckModel verifies that the provider offers the agent's model (and any scheduled
+// ones), suggesting close matches when it doesn't. The returned error wraps
+// providers.ErrModelNotFound. If the provider can't list its models, the check falls
+// back to the provider's known models; since those aren't exhaustive, a model
+// missing from them is only warned about rather than blocking the run.
+func (a *Agent) CheckModel(ctx context.Context) error {
+	wanted := append([]string{a.Model}, a.ModelSchedule.Models()...)
+	models, err := a.Provider.Models(ctx)
+	if err != nil {
+		spec, _ := providers.GetProviderSpec(a.Provider.Name())
+		for _, model := range wanted {
+			if _, known := spec.Model(model); !known && len(spec.Models) > 0 {
+				slog.Warn("couldn't verify model", "agent", a.Name, "model", model, "provider", a.ProviderName(), "error", err)
+			}
+		}
+		slog.Debug("model checked against known models", "agent", a.Name, "provider", a.Provider.Name(), "error", err)
+		return nil
+	}
+	if len(models) == 0 {
+		slog.Debug("model check skipped", "agent", a.Name, "provider", a.Provider.Name())
+		return nil
+	}
+	for _, model := range wanted {
+		if slices.Contains(models, model) {
+			continue
+		}
+		err = fmt.Errorf("%s: %w: %q is not offered by %s", a.Name, providers.ErrModelNotFound, model, a.ProviderName())
+		if suggestions := providers.SuggestModels(model, models); len(suggestions) > 0 {
+			err = fmt.Errorf("%w (did you mean %s?)", err, strings.Join(suggestions, ", "))
+		}
+		return err
+	}
+	return nil
+}
+
+// Health checks that the agent's provider is reachable
+func (a *Agent) Health(ctx context.Context) error {
+	if err := a.Provider.Health(ctx); err != nil {
+		return fmt.Errorf("%s health check failed: %w", a.Name, err)
+	}
+	return nil
+}
+
+// WarmupPrompt is the message Warmup sends
+const WarmupPrompt = "Reply with OK."
+
+// Warmup sends the agent's first-round model a one-token request and returns how
+// long the first token took. Where Health only shows the provider is reachable,
+// this surfaces a slow one (a cold local model, an overloaded endpoint) before a
+// long run. Unlike Health it is billed, if only for a few tokens.
+func (a *Agent) Warmup(ctx context.Context) (time.Duration, error) {
+	sample, err := bench.Measure(ctx, a.Provider, &providers.ChatRequest{
+		Model:     a.modelFor(1),
+		Messages:  []providers.Message{{Role: "user", Content: WarmupPrompt}},
+		MaxTokens: 1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s warm-up request failed: %w", a.Name, err)
+	}
+	return sample.TimeToFirstToken, nil
+}
@@ -0,0 +1,1324 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/conversation"
+	"github.com/markjamesm/chat-bridge-go/pkg/mcp"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/tools"
+)
+
+// Default engine settings, matching the original CLI loop
+const (
+	DefaultMaxTokens    = 800
+	DefaultChunkTimeout = 30 * time.Second
+	DefaultRoundDelay   = 500 * time.Millisecond
+	DefaultMemoryLimit  = 3
+	DefaultMaxToolCalls = 8
+	DefaultToolTimeout  = 30 * time.Second
+
+	DefaultEmptyStreamRetries = 2
+	DefaultEmptyStreamDelay   = time.Second
+
+	DefaultDurationGrace = time.Minute
+)
+
+// ErrToolLimit ends a turn whose model keeps calling tools after they were withheld
+var ErrToolLimit = errors.New("model kept calling tools past the limit")
+
+// Agent is one side of the bridge
+type Agent struct {
+	Name         string                // Display name (e.g., "Agent A")
+	Provider     providers.Provider    // Provider serving this agent
+	Alias        string                // Config alias the provider was chosen by, if any
+	Model        string                // Model ID sent with every request
+	Temperature  *float64              // Sampling temperature; nil leaves it to the provider
+	SystemPrompt string                // Optional system prompt sent with every request
+	Sampling     providers.Sampling    // Optional sampling parameters
+	Tools        []tools.Tool          // Tools the agent may call (providers implementing ToolStreamer only)
+	ToolChoice   string                // providers.ChatRequest.ToolChoice
+	JSONMode     bool                  // Require every reply to be a single JSON object
+	Schema       *providers.JSONSchema // With JSONMode, the schema replies must also match
+	LogitBias    map[string]float64    // Token ID biases, for providers with SupportsLogitBias
+
+	// ModelSchedule overrides Model for the rounds it covers
+	ModelSchedule ModelSchedule
+
+	// TokenCounter overrides the provider's preferred counter; see Counter
+	TokenCounter providers.TokenCounter
+
+	endpoint string // Identifies the provider endpoint for HealthCache; set by NewAgent
+}
+
+// Mode selects how the agents take turns
+type Mode string
+
+const (
+	ModeAlternating  Mode = "alternating"  // Agents answer each other in turn (default)
+	ModeSimultaneous Mode = "simultaneous" // Both agents answer the same context at once each round
+)
+
+// ParseMode validates a mode name; empty selects ModeAlternating
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case "", ModeAlternating:
+		return ModeAlternating, nil
+	case ModeSimultaneous:
+		return ModeSimultaneous, nil
+	}
+	return "", fmt.Errorf("unknown mode %q (use %s or %s)", name, ModeAlternating, ModeSimultaneous)
+}
+
+// Options control how a conversation runs
+type Options struct {
+	ID           string                         // Identifies the run in logs, traces and transcripts; empty generates one
+	Mode         Mode                           // Turn-taking mode; empty means ModeAlternating
+	Starter      string                         // First message sent to Agent A (to both agents in simultaneous mode)
+	Images       []providers.Image              // Optional images attached to the starter
+	MaxRounds    int                            // Maximum number of rounds
+	MaxTokens    int                            // Maximum tokens per response
+	ChunkTimeout time.Duration                  // Maximum wait between streamed chunks
+	RoundDelay   time.Duration                  // Pause between turns
+	Farewell     *conversation.FarewellDetector // Optional early stop on mutual farewells
+	Memory       mcp.Memory                     // Optional MCP memory for storing and recalling turns
+	MemoryLimit  int                            // Maximum snippets recalled per round
+	MaxToolCalls int                            // Maximum tool calls per turn before tools are withheld
+	ToolTimeout  time.Duration                  // Maximum time a single tool call may run
+	Prior        []Turn                         // Turns from an earlier run to continue from (resume/branch)
+
+	// Context is background both agents receive as a system message at the start of
+	// every request, e.g. a scenario or a document to discuss. Unlike Starter it is
+	// never a turn: it isn't shown, stored in history, or sent to memory.
+	Context string
+
+	// EmptyStreamRetries is how often a stream that closes without any data
+	// (providers.ErrEmptyStream, e.g. a local model still loading) is retried after
+	// EmptyStreamDelay. Zero uses the default; a negative value disables retries.
+	EmptyStreamRetries int
+	EmptyStreamDelay   time.Duration
+
+	// ReinforceEvery re-sends each agent's system prompt as a fresh system message
+	// once N rounds have passed since the agent last received it (0 disables)
+	ReinforceEvery int
+
+	// TurnPrefix and TurnSuffix wrap the incoming message of every request, e.g. to
+	// append "Respond in one paragraph." They are added to a copy for that request
+	// only, so history, transcripts and memory keep the unwrapped text and the
+	// boilerplate never accumulates across rounds.
+	TurnPrefix string
+	TurnSuffix string
+
+	// RoundNote, when set, tells each agent where the run stands (e.g. "Round 3 of
+	// 10") in a system message sent just before its incoming message. Like TurnPrefix
+	// it is added per request only and never stored. RoundNoteTurns counts its
+	// rounds in units of that many turns, e.g. 2 for exchanges; 0 counts turns.
+	RoundNote      *RoundNote
+	RoundNoteTurns int
+
+	// CompactHistory sends earlier turns with their whitespace collapsed (code blocks
+	// excepted) and, with CompactMaxChars > 0, turns longer than that cut short. Like
+	// TurnPrefix it only changes what is sent: history, events, and transcripts keep
+	// the full text. The tokens saved are reported in Turn.SavedTokens and the summary.
+	CompactHistory  bool
+	CompactMaxChars int
+
+	// MinResponseChars re-prompts an agent once with ElaboratePrompt
+	// (DefaultElaboratePrompt if empty) when its reply is shorter than this many
+	// characters, and the turn keeps the second reply (0 disables). Both replies
+	// stream as EventToken, with an EventWarning between them. JSON-mode agents are
+	// exempt, since a short object is a complete answer.
+	MinResponseChars int
+	ElaboratePrompt  string
+
+	// MaxResponseChars cuts every reply off at this many characters (0 disables),
+	// cancelling the provider's stream so it stops generating. The turn keeps what
+	// was received up to the limit, with FinishReason set to FinishMaxChars.
+	MaxResponseChars int
+
+	// Loop watches for agents echoing each other. When it reports a loop the
+	// conversation ends with StopLoop, or with OnLoop set to LoopNudge the next
+	// requests carry a one-off system message asking the agents to change the subject.
+	Loop   *conversation.LoopDetector
+	OnLoop LoopAction
+
+	// ReviewEvery pauses every N rounds with an EventReview, so a person can accept,
+	// edit, or skip the prompt before it is sent (0 never pauses). The conversation
+	// waits for Review.Decide, so the caller must answer every EventReview.
+	// Alternating mode only.
+	ReviewEvery int
+
+	// Director, when set, is a hidden third voice: every DirectorEvery rounds (zero
+	// uses the default) it reads the recent turns and writes a short instruction that
+	// both agents receive as a system message with their next request. Its
+	// SystemPrompt asks for the instruction (DefaultDirectorPrompt if empty). The
+	// director's own requests aren't counted in Result.Summary.
+	Director      *Agent
+	DirectorEvery int
+
+	// MaxDuration caps the session's wall-clock time (0 disables). No round starts
+	// once it has passed; a round still streaming gets DurationGrace to finish
+	// before its requests are cancelled and the unfinished reply is dropped.
+	MaxDuration   time.Duration
+	DurationGrace time.Duration
+
+	// ContinueOnError skips a turn that fails (after the usual retries) instead of
+	// ending the run: the failure is reported as an EventWarning and counted in the
+	// summary, and the prompt the failed agent was given passes to the other agent.
+	// In simultaneous mode the whole round is skipped and the next one asks both
+	// agents again. Three failures in a row still end the run with StopError.
+	ContinueOnError bool
+
+	// OnRefusal decides what happens when an agent declines to respond, i.e. the
+	// model sends a refusal or the provider's content filter withholds the reply.
+	// The turn is still emitted, with Turn.Refusal set and an EventWarning after
+	// it, but never joins the history: as under ContinueOnError its prompt passes
+	// to the other agent, and in simultaneous mode the round is asked again.
+	OnRefusal RefusalAction
+
+	// RetryCooler asks an agent once more, at its temperature times this factor
+	// (see DefaultCoolerFactor), when its reply is declined or empty; an empty
+	// stream is retried too once EmptyStreamRetries run out (0 disables). The turn
+	// keeps the second reply, which OnRefusal then handles. Requests without a
+	// temperature above 0 aren't retried.
+	RetryCooler float64
+
+	// Preamble, when set, strips opening filler such as "Sure! Here's my take:" from
+	// every reply. The stripped reply is what's displayed, sent to the other agent,
+	// and stored as Turn.Content; Turn.RawContent keeps the original. While the
+	// opening is undecided (see conversation.PreambleStripper.Decided) its tokens are
+	// held back rather than streamed.
+	Preamble *conversation.PreambleStripper
+
+	// HealthCache, when set, forgets an agent's passed health check once one of its
+	// requests is rejected with providers.ErrInvalidCredentials
+	HealthCache *HealthCache
+}
+
+// StopReason explains why a conversation ended
+type StopReason string
+
+const (
+	StopMaxRounds StopReason = "max_rounds" // Hit the round limit
+	StopFarewell  StopReason = "farewell"   // Both agents signed off
+	StopError     StopReason = "error"      // A turn failed
+	StopLoop      StopReason = "loop"       // Replies kept repeating each other
+	StopRefusal   StopReason = "refusal"    // An agent declined to respond under RefusalStop
+
+	StopMaxDuration StopReason = "max_duration" // Ran out of Options.MaxDuration
+)
+
+// Turn is a single completed response from one agent
+type Turn struct {
+	Round    int           // 1-based round number
+	Speaker  int           // 0 for Agent A, 1 for Agent B
+	Agent    string        // Speaking agent's display name
+	Provider string        // Provider that served the response
+	Model    string        // Model that produced the response
+	Content  string        // Full response text
+	Started  time.Time     // When the request was sent
+	Duration time.Duration // Time from request to end of stream
+
+	Reinforced bool      // The agent's system prompt was re-injected before this turn
+	ToolCalls  []ToolUse // Tools the agent called while producing this turn
+	Prompt     string    // Incoming message as edited by a reviewer; empty if sent as proposed
+	Direction  string    // Director instruction sent with this turn's request, if any
+	Cancelled  bool      // Stopped by CancelTurn; Content holds the text received until then
+
+	Regenerated bool   // Replaces the previous turn of the same round, discarded at a review
+	RawContent  string // The reply as received, when Options.Preamble stripped filler from Content
+	Refusal     string // Why the agent declined to respond, if it did; see Options.OnRefusal
+
+	// Reported by providers implementing providers.MetaStreamer; empty otherwise
+	ServedModel  string // Model that served the response, which may differ from Model
+	FinishReason string // Why the reply ended, e.g. providers.FinishLength when cut off by MaxTokens, or FinishMaxChars
+
+	// Estimated with the agent's token counter; zero for turns loaded from a transcript
+	InputTokens  int
+	OutputTokens int
+	SavedTokens  int // Input tokens CompactHistory saved
+}
+
+// ToolUse records one tool call made during a turn
+type ToolUse struct {
+	Name      string // Tool name
+	Arguments string // JSON arguments from the model
+	Result    string // Text returned to the model
+	Error     string // Failure message, if the call failed (also returned to the model)
+}
+
+// Result summarizes a finished conversation
+type Result struct {
+	Rounds  int           // Number of completed rounds (one turn each, or two in simultaneous mode)
+	Reason  StopReason    // Why the conversation stopped
+	Elapsed time.Duration // Wall-clock time the run took
+	Summary Summary       // Totals for the turns completed by this run
+}
+
+// EventType identifies what an Event carries
+type EventType int
+
+const (
+	EventTurnStart    EventType = iota // An agent is about to respond
+	EventToken                         // A streamed chunk of text
+	EventTurnComplete                  // An agent finished its response
+	EventDone                          // The conversation ended (Result and maybe Err set)
+	EventWarning                       // A non-fatal problem (Err set); the conversation continues
+	EventToolCall                      // An agent called a tool (Tool set)
+	EventReview                        // A prompt awaits approval (Review set); answer with Review.Decide
+	EventDirection                     // The director wrote an instruction (Text set, Agent is the director)
+)
+
+// Event is emitted on the channel returned by Run
+type Event struct {
+	Type    EventType
+	Round   int      // Current round
+	Speaker int      // 0 for Agent A, 1 for Agent B
+	Agent   *Agent   // Speaking agent
+	Text    string   // Chunk text for EventToken, message for EventWarning
+	Turn    *Turn    // Completed turn for EventTurnComplete
+	Tool    *ToolUse // Completed tool call for EventToolCall
+	Review  *Review  // Paused prompt for EventReview
+	Result  *Result  // Final result for EventDone
+	Err     error    // Failure for EventDone, if any
+}
+
+// Conversation orchestrates turns between two agents. In simultaneous mode the
+// history is kept from Agent A's side (its replies as assistant messages, B's as
+// user messages) and mirrored for Agent B in agentView.
+type Conversation struct {
+	agents  [2]*Agent
+	opts    Options
+	history []providers.Message
+	memory  mcp.Memory
+	log     *slog.Logger // Tags every line with the conversation ID
+
+	reinforced [2][]int // History indexes each agent's system prompt is re-injected before
+	prompted   [2]int   // Round each agent last received its system prompt
+	summary    Summary  // Running totals for Result.Summary
+	nudge      [2]bool  // Agents whose next request carries the loop nudge
+	unreviewed bool     // A reviewer chose ReviewSkip, so no more pauses
+
+	recent     []Turn    // Latest turns, for the director
+	directions [2]string // Director instruction each agent's next request carries
+
+	failures int       // Consecutive failed rounds skipped under ContinueOnError
+	last     *lastTurn // Latest alternating turn, for ReviewRegenerate
+
+	streamsMu sync.Mutex
+	streams   [2]context.CancelCauseFunc // In-flight turns, for CancelTurn
+
+	subsMu     sync.Mutex
+	subs       []*Subscription
+	subsClosed bool // The run ended, so new subscriptions start closed
+}
+
+// New creates a conversation between two agents, filling in default options
+func New(a, b *Agent, opts Options) *Conversation {
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = DefaultMaxTokens
+	}
+	if opts.ChunkTimeout == 0 {
+		opts.ChunkTimeout = DefaultChunkTimeout
+	}
+	if opts.RoundDelay == 0 {
+		opts.RoundDelay = DefaultRoundDelay
+	}
+	if opts.MemoryLimit == 0 {
+		opts.MemoryLimit = DefaultMemoryLimit
+	}
+	if opts.MaxToolCalls == 0 {
+		opts.MaxToolCalls = DefaultMaxToolCalls
+	}
+	if opts.ToolTimeout == 0 {
+		opts.ToolTimeout = DefaultToolTimeout
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeAlternating
+	}
+	if opts.EmptyStreamRetries == 0 {
+		opts.EmptyStreamRetries = DefaultEmptyStreamRetries
+	}
+	if opts.EmptyStreamDelay == 0 {
+		opts.EmptyStreamDelay = DefaultEmptyStreamDelay
+	}
+	if opts.DurationGrace == 0 {
+		opts.DurationGrace = DefaultDurationGrace
+	}
+	if opts.DirectorEvery == 0 {
+		opts.DirectorEvery = DefaultDirectorEvery
+	}
+	if opts.ID == "" {
+		opts.ID = NewID()
+	}
+
+	c := &Conversation{
+		agents: [2]*Agent{a, b},
+		opts:   opts,
+		memory: opts.Memory,
+		log:    slog.With("conversation", opts.ID),
+	}
+	if opts.Director != nil {
+		c.recent = append(c.recent, opts.Prior[max(0, len(opts.Prior)-directorTurns):]...)
+	}
+
+	if opts.Mode == ModeSimultaneous {
+		c.restoreSimultaneous()
+		return c
+	}
+
+	// Rebuild history from prior turns exactly as Run would have recorded it
+	incoming := opts.Starter
+	for _, turn := range opts.Prior {
+		if turn.Prompt != "" {
+			incoming = turn.Prompt
+		}
+		if turn.Refusal != "" {
+			continue // Skipped, so its prompt passed to the other agent
+		}
+		c.restoreReinforcement(turn, len(c.history))
+		c.history = append(c.history,
+			c.userMessage(incoming),
+			providers.Message{Role: "assistant", Content: turn.Content},
+		)
+		incoming = turn.Content
+		if opts.Farewell != nil {
+			opts.Farewell.Observe(turn.Content)
+		}
+		if opts.Loop != nil {
+			opts.Loop.Observe(turn.Content)
+		}
+	}
+
+	return c
+}
+
+// restoreSimultaneous rebuilds a simultaneous-mode history from prior turns, which
+// come in (A, B) pairs per round. A trailing unpaired turn is dropped, so that round
+// is asked again.
+func (c *Conversation) restoreSimultaneous() {
+	c.history = append(c.history, c.userMessage(c.opts.Starter))
+	prior := c.opts.Prior
+	c.opts.Prior = prior[:len(prior)/2*2]
+
+	for i := 0; i+1 < len(prior); i += 2 {
+		a, b := prior[i], prior[i+1]
+		if a.Refusal != "" || b.Refusal != "" {
+			continue // The round was asked again
+		}
+		c.restoreReinforcement(a, len(c.history)-1)
+		c.restoreReinforcement(b, len(c.history)-1)
+		c.history = append(c.history,
+			providers.Message{Role: "assistant", Content: a.Content},
+			providers.Message{Role: "user", Content: b.Content},
+		)
+		if c.opts.Farewell != nil {
+			c.opts.Farewell.Observe(a.Content)
+			c.opts.Farewell.Observe(b.Content)
+		}
+		if c.opts.Loop != nil {
+			c.opts.Loop.Observe(a.Content)
+			c.opts.Loop.Observe(b.Content)
+		}
+	}
+}
+
+// restoreReinforcement replays a prior turn's system prompt bookkeeping; at is the
+// history index its incoming message has
+func (c *Conversation) restoreReinforcement(turn Turn, at int) {
+	if turn.Reinforced {
+		c.reinforced[turn.Speaker] = append(c.reinforced[turn.Speaker], at)
+		c.prompted[turn.Speaker] = turn.Round
+	} else if c.prompted[turn.Speaker] == 0 {
+		c.prompted[turn.Speaker] = turn.Round
+	}
+}
+
+// Agent returns the agent for a speaker index (0 or 1)
+func (c *Conversation) Agent(speaker int) *Agent {
+	return c.agents[speaker]
+}
+
+// History returns a copy of the messages exchanged so far
+func (c *Conversation) History() []providers.Message {
+	history := make([]providers.Message, len(c.history))
+	copy(history, c.history)
+	return history
+}
+
+// Run starts the conversation and streams events until it ends.
+// The channel is closed after the EventDone event, or early if ctx is cancelled.
+// Subscribe adds further consumers.
+func (c *Conversation) Run(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		defer c.closeSubscribers()
+
+		emit := func(ev Event) bool {
+			c.publish(ev)
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ctx := providers.WithConversationID(ctx, c.opts.ID)
+		started := time.Now()
+		reqCtx, cancel := c.withDeadline(ctx, started)
+		defer cancel()
+
+		if c.opts.Mode == ModeSimultaneous {
+			c.runSimultaneous(ctx, reqCtx, started, emit)
+			return
+		}
+
+		result := &Result{Reason: StopMaxRounds}
+		currentText := c.opts.Starter
+		speaker := 0
+
+		// Continue where prior turns left off
+		if len(c.opts.Prior) > 0 {
+			result.Rounds = PriorRounds(c.opts.Prior, c.opts.Mode)
+			currentText = resumeText(c.opts.Starter, c.opts.Prior)
+			speaker = nextSpeaker(c.opts.Prior)
+		}
+
+		for round := result.Rounds + 1; round <= c.opts.MaxRounds; round++ {
+			if c.timeUp(started) {
+				result.Reason = StopMaxDuration
+				break
+			}
+			agent := c.agents[speaker]
+
+			edited, ok := c.review(ctx, reqCtx, round, speaker, &currentText, emit)
+			if !ok {
+				return
+			}
+
+			// Add the incoming message to history
+			c.history = append(c.history, c.userMessage(currentText))
+
+			reinforced := c.reinforce(round, speaker)
+			messages, direction := c.withDirection(speaker, c.withNudge(speaker, c.requestMessages(reqCtx, speaker, currentText, emit)))
+			messages = c.withRoundNote(round, speaker, messages)
+
+			c.log.Debug("round started", "round", round, "agent", agent.Name, "provider", agent.Provider.Name(), "model", agent.modelFor(round), "messages", len(messages))
+			if !emit(Event{Type: EventTurnStart, Round: round, Speaker: speaker, Agent: agent}) {
+				return
+			}
+
+			turnCtx, release := c.turnContext(reqCtx, speaker)
+			turn, err := c.streamTurn(turnCtx, round, speaker, messages, nil, emit)
+			release()
+			if c.outOfTime(ctx, reqCtx, round, emit) {
+				result.Reason = StopMaxDuration
+				break
+			}
+			if err != nil && c.skipFailure(ctx, round, []int{speaker}, err, emit) {
+				c.dropIncoming(speaker, reinforced)
+				result.Rounds = round
+				speaker = 1 - speaker
+				if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				c.log.Debug("round failed", "round", round, "agent", agent.Name, "error", err)
+				result.Reason = StopError
+				c.finish(result, started)
+				emit(Event{Type: EventDone, Round: round, Result: result, Err: err})
+				return
+			}
+
+			c.failures = 0
+			turn.Reinforced = reinforced
+			turn.Direction = direction
+			if edited {
+				turn.Prompt = currentText
+			}
+			if turn.Refusal != "" {
+				stop, ok := c.reportRefusals(round, []*Turn{turn}, emit)
+				if !ok {
+					return
+				}
+				result.Rounds = round
+				if stop {
+					result.Reason = StopRefusal
+					break
+				}
+				c.dropIncoming(speaker, reinforced)
+				speaker = 1 - speaker
+				if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+					return
+				}
+				continue
+			}
+
+			// Add assistant response to history
+			c.history = append(c.history, providers.Message{
+				Role:    "assistant",
+				Content: turn.Content,
+			})
+			result.Rounds = round
+			c.tally(turn)
+			c.last = &lastTurn{turn, messages}
+			c.log.Debug("round completed", "round", round, "agent", agent.Name, "chars", len(turn.Content), "duration", turn.Duration)
+			c.remember(reqCtx, turn, emit)
+
+			if !emit(Event{Type: EventTurnComplete, Round: round, Speaker: speaker, Agent: agent, Turn: turn}) {
+				return
+			}
+
+			// Stop once both agents have signed off
+			if c.opts.Farewell != nil && c.opts.Farewell.Observe(turn.Content) {
+				result.Reason = StopFarewell
+				break
+			}
+			if c.checkLoop(round, []*Turn{turn}, emit, 1-speaker) {
+				result.Reason = StopLoop
+				break
+			}
+			c.direct(reqCtx, round, []*Turn{turn}, emit)
+
+			// Prepare for next round
+			currentText = turn.Content
+			speaker = 1 - speaker
+
+			// Small delay between rounds
+			if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+				return
+			}
+		}
+
+		c.finish(result, started)
+		c.log.Debug("conversation finished", "rounds", result.Rounds, "reason", result.Reason, "elapsed", result.Elapsed)
+		emit(Event{Type: EventDone, Round: result.Rounds, Result: result})
+	}()
+
+	return events
+}
+
+// withDeadline derives the context requests run under, which expires DurationGrace
+// after MaxDuration so a round that started in time can still finish
+func (c *Conversation) withDeadline(ctx context.Context, started time.Time) (context.Context, context.CancelFunc) {
+	if c.opts.MaxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, started.Add(c.opts.MaxDuration+c.opts.DurationGrace))
+}
+
+// timeUp reports whether the session has used up MaxDuration
+func (c *Conversation) timeUp(started time.Time) bool {
+	return c.opts.MaxDuration > 0 && time.Since(started) >= c.opts.MaxDuration
+}
+
+// pause waits out the delay between rounds, returning false if ctx is cancelled first
+func pause(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// outOfTime reports whether the round's requests were cut off by the session
+// deadline rather than by the caller, warning that the unfinished reply is dropped.
+// A stream that closes quietly when cancelled still counts, since its reply may be partial.
+func (c *Conversation) outOfTime(ctx, reqCtx context.Context, round int, emit func(Event) bool) bool {
+	if ctx.Err() != nil || !errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	c.log.Debug("session deadline reached mid-round", "round", round)
+	emit(Event{
+		Type:  EventWarning,
+		Round: round,
+		Text:  fmt.Sprintf("Time limit reached; round %d was cut off and not recorded", round),
+		Err:   reqCtx.Err(),
+	})
+	return true
+}
+
+// runSimultaneous asks both agents the same round at once, streaming their tokens
+// interleaved. The round's turns are completed together, A first, and each agent
+// receives the other's reply as the next round's incoming message.
+// Requests run under reqCtx, which carries the session deadline.
+func (c *Conversation) runSimultaneous(ctx, reqCtx context.Context, started time.Time, emit func(Event) bool) {
+	result := &Result{Reason: StopMaxRounds, Rounds: PriorRounds(c.opts.Prior, c.opts.Mode)}
+
+	for round := result.Rounds + 1; round <= c.opts.MaxRounds; round++ {
+		if c.timeUp(started) {
+			result.Reason = StopMaxDuration
+			break
+		}
+		var reinforced [2]bool
+		var messages [2][]providers.Message
+		var directions [2]string
+		for speaker, agent := range c.agents {
+			reinforced[speaker] = c.reinforce(round, speaker)
+			view := c.perspective(speaker)
+			messages[speaker], directions[speaker] = c.withDirection(speaker, c.withNudge(speaker, c.requestMessages(reqCtx, speaker, view[len(view)-1].Content, emit)))
+			messages[speaker] = c.withRoundNote(round, speaker, messages[speaker])
+
+			c.log.Debug("round started", "round", round, "agent", agent.Name, "provider", agent.Provider.Name(), "model", agent.modelFor(round), "messages", len(messages[speaker]))
+			if !emit(Event{Type: EventTurnStart, Round: round, Speaker: speaker, Agent: agent}) {
+				return
+			}
+		}
+
+		turns, failed, err := c.streamBoth(reqCtx, round, messages, emit)
+		if c.outOfTime(ctx, reqCtx, round, emit) {
+			result.Reason = StopMaxDuration
+			break
+		}
+		if err != nil && c.skipFailure(ctx, round, failed, err, emit) {
+			result.Rounds = round
+			if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+				return
+			}
+			continue
+		}
+		if err != nil {
+			c.log.Debug("round failed", "round", round, "error", err)
+			result.Reason = StopError
+			c.finish(result, started)
+			emit(Event{Type: EventDone, Round: round, Result: result, Err: err})
+			return
+		}
+
+		c.failures = 0
+		for speaker, turn := range turns {
+			turn.Reinforced = reinforced[speaker]
+			turn.Direction = directions[speaker]
+		}
+		if turns[0].Refusal != "" || turns[1].Refusal != "" {
+			// Ask both again rather than answer one side of the round
+			stop, ok := c.reportRefusals(round, turns[:], emit)
+			if !ok {
+				return
+			}
+			result.Rounds = round
+			if stop {
+				result.Reason = StopRefusal
+				break
+			}
+			if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+				return
+			}
+			continue
+		}
+		c.history = append(c.history,
+			providers.Message{Role: "assistant", Content: turns[0].Content},
+			providers.Message{Role: "user", Content: turns[1].Content},
+		)
+		result.Rounds = round
+
+		farewell := false
+		for speaker, turn := range turns {
+			c.tally(turn)
+			c.log.Debug("round completed", "round", round, "agent", turn.Agent, "chars", len(turn.Content), "duration", turn.Duration)
+			c.remember(reqCtx, turn, emit)
+			if !emit(Event{Type: EventTurnComplete, Round: round, Speaker: speaker, Agent: c.agents[speaker], Turn: turn}) {
+				return
+			}
+			if c.opts.Farewell != nil && c.opts.Farewell.Observe(turn.Content) {
+				farewell = true
+			}
+		}
+		if farewell {
+			result.Reason = StopFarewell
+			break
+		}
+		if c.checkLoop(round, turns[:], emit, 0, 1) {
+			result.Reason = StopLoop
+			break
+		}
+		c.direct(reqCtx, round, turns[:], emit)
+
+		if round < c.opts.MaxRounds && !pause(ctx, c.opts.RoundDelay) {
+			return
+		}
+	}
+
+	c.finish(result, started)
+	c.log.Debug("conversation finished", "rounds", result.Rounds, "reason", result.Reason, "elapsed", result.Elapsed)
+	emit(Event{Type: EventDone, Round: result.Rounds, Result: result})
+}
+
+// streamBoth runs both agents' turns concurrently, each under its own context so
+// CancelTurn can stop one. If either fails, the other is cancelled and the first
+// failure is returned, along with the speakers that failed before the cancellation.
+func (c *Conversation) streamBoth(ctx context.Context, round int, messages [2][]providers.Message, emit func(Event) bool) ([2]*Turn, []int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var turns [2]*Turn
+	var (
+		mu       sync.Mutex
+		firstErr error
+		failed   []int
+	)
+	var wg sync.WaitGroup
+	for speaker := range c.agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			turnCtx, release := c.turnContext(ctx, speaker)
+			defer release()
+			turn, err := c.streamTurn(turnCtx, round, speaker, messages[speaker], nil, emit)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", c.agents[speaker].Name, err)
+				}
+				// A turn cut short by the other's failure didn't fail itself
+				if ctx.Err() == nil {
+					failed = append(failed, speaker)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			turns[speaker] = turn
+		}()
+	}
+	wg.Wait()
+
+	return turns, failed, firstErr
+}
+
+// retryFunc decides whether a reply needs a follow-up request, such as elaborate,
+// and returns the reply the turn keeps
+type retryFunc func(ctx context.Context, round, speaker int, req *providers.ChatRequest, content string, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error)
+
+// streamTurn requests one response and forwards its chunks as token events. When the
+// agent has tools and the model calls them, the results are fed back as tool
+// messages and the response continues until the model answers without calling one.
+// Tool exchanges stay within the turn; only the final text joins the shared history.
+// A non-nil temperature overrides the agent's.
+func (c *Conversation) streamTurn(ctx context.Context, round, speaker int, messages []providers.Message, temperature *float64, emit func(Event) bool) (*Turn, error) {
+	agent := c.agents[speaker]
+	model := agent.modelFor(round)
+	started := time.Now()
+
+	sent := c.wrapTurn(c.compactHistory(messages))
+	req := &providers.ChatRequest{
+		Model:        model,
+		Messages:     sent,
+		Temperature:  agent.Temperature,
+		MaxTokens:    c.opts.MaxTokens,
+		SystemPrompt: agent.SystemPrompt,
+		Sampling:     agent.Sampling,
+		LogitBias:    agent.LogitBias,
+	}
+	if temperature != nil {
+		req.Temperature = temperature
+	}
+	if !agent.AcceptsTemperature(model) {
+		req.Temperature = nil
+	}
+	if _, ok := agent.Provider.(providers.ToolStreamer); ok && len(agent.Tools) > 0 {
+		req.Tools = tools.Specs(agent.Tools)
+		req.ToolChoice = agent.ToolChoice
+	}
+	if agent.JSONMode {
+		c.requestJSON(agent, req)
+	}
+
+	var meta providers.ResponseMeta
+	content, uses, err := c.respond(ctx, round, speaker, req, &meta, emit)
+	cancelled := turnCancelled(ctx)
+	if cancelled {
+		// Keep what arrived; there is no point retrying or elaborating on it
+		c.log.Info("turn cancelled", "round", round, "agent", agent.Name, "received", len(content))
+	} else if errors.Is(err, providers.ErrEmptyStream) && c.coolerApplies(req) {
+		// Left to retryCooler, which gives up with the retry's error
+		content, err = "", nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		followUp := c.elaborate
+		if agent.JSONMode {
+			followUp = c.enforceJSON
+		}
+		retries := []retryFunc{c.retryCooler, c.nudgeRefusal, followUp}
+		for i, retry := range retries {
+			// A reply still declined after the cooler retry and the nudge isn't worth elaborating on
+			if i == len(retries)-1 && refusal(meta) != "" {
+				break
+			}
+			retried, retryUses, err := retry(ctx, round, speaker, req, content, &meta, emit)
+			uses = append(uses, retryUses...)
+			switch {
+			case err == nil:
+				content = retried
+			case turnCancelled(ctx):
+				// Cancelled during the follow-up request, so the reply before it stands
+				cancelled = true
+			default:
+				return nil, err
+			}
+			if cancelled {
+				break
+			}
+		}
+	}
+	reason := ""
+	if !cancelled {
+		reason = refusal(meta)
+	}
+
+	if meta.FinishReason == FinishMaxChars {
+		err := fmt.Errorf("finish reason %q at %d characters", meta.FinishReason, c.opts.MaxResponseChars)
+		if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: agent.Name + "'s reply was cut off at the character limit", Err: err}) {
+			return nil, ctx.Err()
+		}
+	}
+	if meta.FinishReason == providers.FinishLength {
+		c.log.Info("reply truncated", "round", round, "agent", agent.Name, "max_tokens", c.opts.MaxTokens)
+		err := fmt.Errorf("finish reason %q at max tokens %d", meta.FinishReason, c.opts.MaxTokens)
+		if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: agent.Name + "'s reply was cut off by the max tokens limit", Err: err}) {
+			return nil, ctx.Err()
+		}
+	}
+
+	counter := agent.counterFor(model)
+	outputTokens := counter.CountTokens(content) // The whole reply was billed
+	raw := ""
+	if c.opts.Preamble != nil {
+		if stripped, preamble := c.opts.Preamble.Strip(content); preamble != "" {
+			c.log.Debug("stripped preamble", "round", round, "agent", agent.Name, "preamble", preamble)
+			raw, content = content, stripped
+		}
+	}
+
+	saved := 0
+	if c.opts.CompactHistory {
+		saved = providers.CountMessages(counter, req.SystemPrompt, c.wrapTurn(messages)) - providers.CountMessages(counter, req.SystemPrompt, sent)
+	}
+	return &Turn{
+		Round:     round,
+		Speaker:   speaker,
+		Agent:     agent.Name,
+		Provider:  agent.Provider.Name(),
+		Model:     model,
+		Content:   content,
+		Started:   started,
+		Duration:  time.Since(started),
+		ToolCalls: uses,
+		Cancelled: cancelled,
+
+		RawContent: raw,
+		Refusal:    reason,
+
+		ServedModel:  meta.Model,
+		FinishReason: meta.FinishReason,
+
+		InputTokens:  providers.CountMessages(counter, req.SystemPrompt, req.Messages),
+		OutputTokens: outputTokens,
+		SavedTokens:  saved,
+	}, nil
+}
+
+// respond streams the model's answer to req, running any tools it calls along the
+// way, and records the final response's metadata in meta. Tool exchanges are
+// appended to a copy of req.Messages, so the caller's history is untouched but a
+// follow-up request on req keeps them. A failed stream returns the text received
+// before it broke along with the error.
+func (c *Conversation) respond(ctx context.Context, round, speaker int, req *providers.ChatRequest, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error) {
+	agent := c.agents[speaker]
+	var fullResponse strings.Builder
+	var uses []ToolUse
+	withheld := false
+	for {
+		segment := fullResponse.Len()
+		calls, err := c.streamWithRetry(ctx, round, speaker, req, &fullResponse, meta, emit)
+		if err != nil {
+			return fullResponse.String(), uses, err
+		}
+		if len(calls) == 0 {
+			return fullResponse.String(), uses, nil
+		}
+		// A provider that ignores tool_choice "none" would otherwise be asked forever
+		if withheld {
+			return fullResponse.String(), uses, fmt.Errorf("%w: %d calls, then %d more with tools withheld", ErrToolLimit, len(uses), len(calls))
+		}
+
+		// Answer the calls on a copy of the messages
+		req.Messages = append(req.Messages[:len(req.Messages):len(req.Messages)], providers.Message{
+			Role:      "assistant",
+			Content:   fullResponse.String()[segment:],
+			ToolCalls: calls,
+		})
+		for _, call := range calls {
+			use := c.runTool(ctx, agent, call)
+			uses = append(uses, use)
+			content := use.Result
+			if use.Error != "" {
+				content = "error: " + use.Error
+			}
+			req.Messages = append(req.Messages, providers.Message{Role: "tool", Content: content, ToolCallID: call.ID})
+			if !emit(Event{Type: EventToolCall, Round: round, Speaker: speaker, Agent: agent, Tool: &use}) {
+				return "", nil, ctx.Err()
+			}
+		}
+
+		// Past the limit, ask for an answer without further calls
+		if len(uses) >= c.opts.MaxToolCalls {
+			req.ToolChoice = providers.ToolChoiceNone
+			withheld = true
+		}
+	}
+}
+
+// streamWithRetry sends one request, fitted to the provider's role rules, retrying
+// while the stream closes without any data, and records the response's metadata in
+// meta when the provider reports it
+func (c *Conversation) streamWithRetry(ctx context.Context, round, speaker int, req *providers.ChatRequest, response *strings.Builder, meta *providers.ResponseMeta, emit func(Event) bool) ([]providers.ToolCall, error) {
+	agent := c.agents[speaker]
+	if agent.StrictAlternation() {
+		if messages, changed := alternateRoles(req.Messages); changed {
+			c.log.Debug("merged messages for role alternation", "round", round, "agent", agent.Name, "messages", len(req.Messages), "sent", len(messages))
+			fitted := *req
+			fitted.Messages = messages
+			req = &fitted
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		var textChan <-chan string
+		var errChan <-chan error
+		var callsChan <-chan []providers.ToolCall
+		var metaChan <-chan providers.ResponseMeta
+		// Cancelled once the reply reaches MaxResponseChars, so the provider stops generating
+		streamCtx, cancel := context.WithCancel(ctx)
+		if streamer, ok := agent.Provider.(providers.MetaStreamer); ok {
+			textChan, errChan, callsChan, metaChan = streamer.StreamChatMeta(streamCtx, req)
+		} else if streamer, ok := agent.Provider.(providers.ToolStreamer); ok && len(req.Tools) > 0 {
+			textChan, errChan, callsChan = streamer.StreamChatTools(streamCtx, req)
+		} else {
+			textChan, errChan = agent.Provider.StreamChat(streamCtx, req)
+		}
+
+		calls, err := c.readStream(ctx, round, speaker, textChan, errChan, callsChan, response, emit)
+		cancel()
+		if errors.Is(err, errMaxChars) {
+			c.log.Debug("reply capped, stream cancelled", "round", round, "agent", agent.Name, "max_chars", c.opts.MaxResponseChars)
+			*meta = providers.ResponseMeta{FinishReason: FinishMaxChars}
+			return nil, nil
+		}
+		if err == nil && metaChan != nil {
+			// Closed before textChan, like callsChan
+			if m, ok := <-metaChan; ok {
+				*meta = m
+			}
+		}
+		if errors.Is(err, providers.ErrInvalidCredentials) {
+			c.opts.HealthCache.Invalidate(agent)
+		}
+		if !errors.Is(err, providers.ErrEmptyStream) {
+			return calls, err
+		}
+		if attempt > c.opts.EmptyStreamRetries {
+			if attempt > 1 {
+				err = fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+			}
+			return nil, err
+		}
+
+		c.log.Debug("empty stream, retrying", "round", round, "agent", agent.Name, "attempt", attempt, "delay", c.opts.EmptyStreamDelay)
+		select {
+		case <-time.After(c.opts.EmptyStreamDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// runTool executes one tool call; failures are reported to the model rather than ending the turn
+func (c *Conversation) runTool(ctx context.Context, agent *Agent, call providers.ToolCall) ToolUse {
+	use := ToolUse{Name: call.Name, Arguments: call.Arguments}
+
+	var handler tools.Handler
+	for _, tool := range agent.Tools {
+		if tool.Spec.Name == call.Name {
+			handler = tool.Handler
+		}
+	}
+	if handler == nil {
+		use.Error = fmt.Sprintf("unknown tool %q", call.Name)
+		return use
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.ToolTimeout)
+	defer cancel()
+
+	result, err := handler(ctx, json.RawMessage(call.Arguments))
+	c.log.Debug("tool called", "agent", agent.Name, "tool", call.Name, "arguments", call.Arguments, "error", err)
+	if err != nil {
+		use.Error = err.Error()
+	} else {
+		use.Result = result
+	}
+	return use
+}
+
+// readStream forwards one response stream's chunks as token events into response,
+// returning any tool calls the model made. With Options.Preamble set, the opening
+// of a reply is held until it can be stripped; response still gets every chunk.
+func (c *Conversation) readStream(ctx context.Context, round, speaker int, textChan <-chan string, errChan <-chan error, callsChan <-chan []providers.ToolCall, response *strings.Builder, emit func(Event) bool) ([]providers.ToolCall, error) {
+	agent := c.agents[speaker]
+	pending := "" // Incomplete UTF-8 rune held back from the last chunk
+	holding := c.opts.Preamble != nil && response.Len() == 0
+	opening := "" // Start of the reply held back while holding
+	// flush emits whatever is held back once the reply ends
+	flush := func() bool {
+		if holding {
+			// Shorter than the preamble window; still stripped here
+			pending, _ = c.opts.Preamble.Strip(opening)
+		}
+		// A rune the stream never completed is passed on as-is rather than lost
+		return pending == "" || emit(Event{Type: EventToken, Round: round, Speaker: speaker, Agent: agent, Text: pending})
+	}
+	for {
+		select {
+		case text, ok := <-textChan:
+			if !ok {
+				// The provider closes errChan (and callsChan) before textChan, so anything sent is already buffered
+				if errChan != nil {
+					if err := <-errChan; err != nil {
+						return nil, err
+					}
+				}
+				if !flush() {
+					return nil, ctx.Err()
+				}
+				if callsChan != nil {
+					return <-callsChan, nil
+				}
+				return nil, nil
+			}
+			text, capped := c.capResponse(response, text)
+			if holding {
+				opening += text
+				if !capped && !c.opts.Preamble.Decided(opening) {
+					continue
+				}
+				text, _ = c.opts.Preamble.Strip(opening)
+				holding = false
+			}
+
+			// Chunks can split a multi-byte rune; only complete runes go to the terminal
+			text, pending = splitPartialRune(pending + text)
+			if text != "" && !emit(Event{Type: EventToken, Round: round, Speaker: speaker, Agent: agent, Text: text}) {
+				return nil, ctx.Err()
+			}
+			if capped {
+				if !flush() {
+					return nil, ctx.Err()
+				}
+				return nil, errMaxChars
+			}
+
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+		case <-time.After(c.opts.ChunkTimeout):
+			c.log.Debug("stream timed out", "round", round, "agent", agent.Name, "timeout", c.opts.ChunkTimeout)
+			return nil, fmt.Errorf("stream timeout")
+		}
+	}
+}
+
+// splitPartialRune separates an incomplete UTF-8 sequence at the end of s from the
+// complete text before it
+func splitPartialRune(s string) (complete, partial string) {
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			if !utf8.FullRuneInString(s[i:]) {
+				return s[:i], s[i:]
+			}
+			break
+		}
+	}
+	return s, ""
+}
+
+// userMessage builds the next incoming message; the starter carries the attached images
+func (c *Conversation) userMessage(content string) providers.Message {
+	msg := providers.Message{Role: "user", Content: content}
+	if len(c.history) == 0 {
+		msg.Images = c.opts.Images
+	}
+	return msg
+}
+
+// reinforce marks the speaker's system prompt for re-injection before the incoming
+// message once ReinforceEvery rounds have passed since the agent last received it.
+// The provider sends the prompt with the agent's first request, which starts the count.
+func (c *Conversation) reinforce(round, speaker int) bool {
+	if c.opts.ReinforceEvery <= 0 || c.agents[speaker].SystemPrompt == "" {
+		return false
+	}
+	if c.prompted[speaker] == 0 {
+		c.prompted[speaker] = round
+		return false
+	}
+	if round-c.prompted[speaker] < c.opts.ReinforceEvery {
+		return false
+	}
+
+	c.prompted[speaker] = round
+	c.reinforced[speaker] = append(c.reinforced[speaker], len(c.history)-1)
+	return true
+}
+
+// agentView returns the history as the speaker sees it, with its re-injected system
+// prompts in place. Other agents' prompts never appear in it, and images are
+// dropped for providers that can't accept them.
+func (c *Conversation) agentView(speaker int) []providers.Message {
+	prompt := c.agents[speaker].SystemPrompt
+	points := c.reinforced[speaker]
+	images := c.agents[speaker].SupportsImages()
+	messages := make([]providers.Message, 0, len(c.history)+len(points))
+
+	for i, msg := range c.perspective(speaker) {
+		if len(points) > 0 && points[0] == i {
+			points = points[1:]
+			// Skip a copy that would directly follow an identical system message
+			last := len(messages) - 1
+			if prompt != "" && (last < 0 || messages[last].Role != "system" || messages[last].Content != prompt) {
+				messages = append(messages, providers.Message{Role: "system", Content: prompt})
+			}
+		}
+		if !images {
+			msg.Images = nil
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// perspective returns the history from the speaker's side. Only Agent B in
+// simultaneous mode needs a transformed copy: each round's (A reply, B reply) pair
+// becomes (B reply as assistant, A reply as user), keeping the indexes aligned.
+func (c *Conversation) perspective(speaker int) []providers.Message {
+	if c.opts.Mode != ModeSimultaneous || speaker == 0 {
+		return c.history
+	}
+
+	mirrored := make([]providers.Message, len(c.history))
+	copy(mirrored, c.history)
+	for i := 1; i+1 < len(mirrored); i += 2 {
+		mirrored[i] = providers.Message{Role: "assistant", Content: c.history[i+1].Content}
+		mirrored[i+1] = providers.Message{Role: "user", Content: c.history[i].Content}
+	}
+	return mirrored
+}
+
+// wrapTurn returns messages with TurnPrefix and TurnSuffix applied to the final
+// incoming message. The caller's slice is left untouched.
+func (c *Conversation) wrapTurn(messages []providers.Message) []providers.Message {
+	if c.opts.TurnPrefix == "" && c.opts.TurnSuffix == "" {
+		return messages
+	}
+	if len(messages) == 0 || messages[len(messages)-1].Role != "user" {
+		return messages
+	}
+
+	wrapped := append([]providers.Message(nil), messages...)
+	last := &wrapped[len(wrapped)-1]
+	parts := make([]string, 0, 3)
+	for _, part := range []string{c.opts.TurnPrefix, last.Content, c.opts.TurnSuffix} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	last.Content = strings.Join(parts, "\n\n")
+	return wrapped
+}
+
+// requestMessages returns the speaker's view of the history to send, with Options.Context
+// and recalled memory prepended as system messages. Both are only injected into this
+// request, never stored in history.
+func (c *Conversation) requestMessages(ctx context.Context, speaker int, query string, emit func(Event) bool) []providers.Message {
+	messages := c.recallMemory(ctx, c.agentView(speaker), query, emit)
+	if c.opts.Context == "" {
+		return messages
+	}
+	return append([]providers.Message{{Role: "system", Content: c.opts.Context}}, messages...)
+}
+
+// recallMemory prepends the snippets memory recalls for query to messages
+func (c *Conversation) recallMemory(ctx context.Context, messages []providers.Message, query string, emit func(Event) bool) []providers.Message {
+	if c.memory == nil {
+		return messages
+	}
+
+	snippets, err := c.memory.Recall(ctx, query, c.opts.MemoryLimit)
+	if err != nil {
+		c.disableMemory(err, emit)
+		return messages
+	}
+	if len(snippets) == 0 {
+		return messages
+	}
+
+	var memory strings.Builder
+	memory.WriteString("Relevant memory from previous conversations:")
+	for _, snippet := range snippets {
+		memory.WriteString("\n- ")
+		memory.WriteString(snippet)
+	}
+
+	return append([]providers.Message{{Role: "system", Content: memory.String()}}, messages...)
+}
+
+// remember stores a completed turn in memory
+func (c *Conversation) remember(ctx context.Context, turn *Turn, emit func(Event) bool) {
+	if c.memory == nil {
+		return
+	}
+
+	err := c.memory.Store(ctx, mcp.Entry{
+		Agent:     turn.Agent,
+		Round:     turn.Round,
+		Content:   turn.Content,
+		Timestamp: turn.Started,
+	})
+	if err != nil {
+		c.disableMemory(err, emit)
+	}
+}
+
+// disableMemory warns once and continues the conversation without memory
+func (c *Conversation) disableMemory(err error, emit func(Event) bool) {
+	c.memory = nil
+	c.log.Debug("memory disabled", "error", err)
+	emit(Event{
+		Type: EventWarning,
+		Text: "MCP memory unavailable, continuing without it",
+		Err:  err,
+	})
+}
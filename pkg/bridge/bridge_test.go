@@ -0,0 +1,1134 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/conversation"
+	"github.com/markjamesm/chat-bridge-go/pkg/mcp"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/tools"
+)
+
+// fakeProvider replays scripted replies and records every request it receives
+type fakeProvider struct {
+	mu       sync.Mutex
+	name     string // Defaults to "fake"
+	models   []string
+	modelErr error // Returned by Models instead of the list
+	empty    int   // Requests answered with an empty stream before replying
+	replies  []string
+	err      error
+	hang     bool
+	chunks   []string // Sent verbatim as the stream, instead of a reply split by word
+	requests []*providers.ChatRequest
+}
+
+func (p *fakeProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "fake"
+}
+
+func (p *fakeProvider) DefaultModel() string                         { return "fake-model" }
+func (p *fakeProvider) Health(ctx context.Context) error             { return nil }
+func (p *fakeProvider) Models(ctx context.Context) ([]string, error) { return p.models, p.modelErr }
+
+func (p *fakeProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	empty := p.empty > 0
+	reply := ""
+	if empty {
+		p.empty--
+	} else if len(p.replies) > 0 {
+		reply = p.replies[0]
+		p.replies = p.replies[1:]
+	}
+	p.mu.Unlock()
+
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+		if empty {
+			errChan <- providers.ErrEmptyStream
+			return
+		}
+		if p.err != nil {
+			errChan <- p.err
+			return
+		}
+		if p.hang {
+			<-ctx.Done()
+			return
+		}
+		words := strings.SplitAfter(reply, " ")
+		if p.chunks != nil {
+			words = p.chunks
+		}
+		for _, word := range words {
+			select {
+			case textChan <- word:
+			case <-ctx.Done():
+				errChan <- providers.ErrContextCancelled
+				return
+			}
+		}
+	}()
+	return textChan, errChan
+}
+
+func testOptions(maxRounds int) Options {
+	return Options{
+		Starter:    "Hello there",
+		MaxRounds:  maxRounds,
+		RoundDelay: time.Millisecond,
+	}
+}
+
+func collect(t *testing.T, events <-chan Event) ([]Event, *Event) {
+	t.Helper()
+	var all []Event
+	var done *Event
+	for ev := range events {
+		all = append(all, ev)
+		if ev.Type == EventDone {
+			ev := ev
+			done = &ev
+		}
+	}
+	return all, done
+}
+
+func TestConversationAlternatesAgents(t *testing.T) {
+	a := &fakeProvider{replies: []string{"first from A", "second from A"}}
+	b := &fakeProvider{replies: []string{"first from B"}}
+
+	conv := New(
+		&Agent{Name: "Ada", Provider: a, Model: "model-a", Temperature: providers.Float(0.2)},
+		&Agent{Name: "Bob", Provider: b, Model: "model-b", Temperature: providers.Float(0.9)},
+		testOptions(3),
+	)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+	if done.Result.Rounds != 3 || done.Result.Reason != StopMaxRounds {
+		t.Fatalf("unexpected result: %+v", done.Result)
+	}
+
+	var turns []*Turn
+	var streamed strings.Builder
+	for _, ev := range events {
+		switch ev.Type {
+		case EventToken:
+			streamed.WriteString(ev.Text)
+		case EventTurnComplete:
+			turns = append(turns, ev.Turn)
+		}
+	}
+
+	wantSpeakers := []string{"Ada", "Bob", "Ada"}
+	wantContent := []string{"first from A", "first from B", "second from A"}
+	for i, turn := range turns {
+		if turn.Agent != wantSpeakers[i] || turn.Content != wantContent[i] || turn.Round != i+1 {
+			t.Fatalf("turn %d: got %+v", i, turn)
+		}
+	}
+	if streamed.String() != strings.Join(wantContent, "") {
+		t.Fatalf("tokens did not reassemble turns: %q", streamed.String())
+	}
+
+	// Each agent gets its own model/temperature and the full history so far
+	if req := b.requests[0]; req.Model != "model-b" || *req.Temperature != 0.9 || len(req.Messages) != 3 {
+		t.Fatalf("unexpected request to B: %+v", req)
+	}
+	if last := a.requests[1].Messages; last[len(last)-1].Content != "first from B" {
+		t.Fatalf("expected A to receive B's reply, got %q", last[len(last)-1].Content)
+	}
+
+	history := conv.History()
+	if len(history) != 6 || history[0].Content != "Hello there" || history[5].Role != "assistant" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestConversationStopsOnMutualFarewell(t *testing.T) {
+	farewell, err := conversation.NewFarewellDetector(nil)
+	if err != nil {
+		t.Fatalf("detector: %v", err)
+	}
+
+	a := &fakeProvider{replies: []string{"Let's talk tides.", "Goodbye!"}}
+	b := &fakeProvider{replies: []string{"Take care, friend.", "unused"}}
+
+	opts := testOptions(10)
+	opts.Farewell = farewell
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Result.Reason != StopFarewell || done.Result.Rounds != 3 {
+		t.Fatalf("expected farewell stop after 3 rounds, got %+v", done.Result)
+	}
+}
+
+func TestConversationReportsProviderError(t *testing.T) {
+	boom := errors.New("boom")
+	a := &fakeProvider{err: boom}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(2))
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || !errors.Is(done.Err, boom) {
+		t.Fatalf("expected provider error to surface, got %+v", done)
+	}
+	if done.Result.Reason != StopError {
+		t.Fatalf("expected error stop reason, got %s", done.Result.Reason)
+	}
+}
+
+func TestConversationChunkTimeout(t *testing.T) {
+	a := &fakeProvider{hang: true}
+	opts := testOptions(1)
+	opts.ChunkTimeout = 20 * time.Millisecond
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err == nil || !strings.Contains(done.Err.Error(), "timeout") {
+		t.Fatalf("expected stream timeout, got %+v", done)
+	}
+}
+
+func TestConversationStopsAtMaxDuration(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a2", "a3", "a4", "a5"}}
+	b := &fakeProvider{replies: []string{"b1", "b2", "b3", "b4", "b5"}}
+	opts := testOptions(10)
+	opts.RoundDelay = 20 * time.Millisecond
+	opts.MaxDuration = 30 * time.Millisecond
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil || done.Result.Reason != StopMaxDuration {
+		t.Fatalf("expected a clean stop at the time limit, got %+v", done)
+	}
+	if r := done.Result; r.Rounds == 0 || r.Rounds >= 10 || r.Elapsed < opts.MaxDuration {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestConversationCutsOffRoundPastMaxDuration(t *testing.T) {
+	a := &fakeProvider{replies: []string{"first from A"}}
+	b := &fakeProvider{hang: true}
+	opts := testOptions(4)
+	opts.MaxDuration = 10 * time.Millisecond
+	opts.DurationGrace = 20 * time.Millisecond
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil || done.Result.Reason != StopMaxDuration || done.Result.Rounds != 1 {
+		t.Fatalf("expected B's hung round to be cut off cleanly, got %+v", done)
+	}
+
+	var warned bool
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete && ev.Speaker == 1 {
+			t.Fatal("the cut-off turn must not be completed")
+		}
+		warned = warned || (ev.Type == EventWarning && errors.Is(ev.Err, context.DeadlineExceeded))
+	}
+	if !warned {
+		t.Fatal("expected a warning that the round was cut off")
+	}
+}
+
+func TestConversationCancelClosesChannel(t *testing.T) {
+	a := &fakeProvider{hang: true}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := conv.Run(ctx)
+	if ev := <-events; ev.Type != EventTurnStart {
+		t.Fatalf("expected turn start, got %v", ev.Type)
+	}
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event channel not closed after cancellation")
+	}
+}
+
+func TestConversationCancelDuringRoundDelay(t *testing.T) {
+	for _, mode := range []Mode{ModeAlternating, ModeSimultaneous} {
+		opts := testOptions(3)
+		opts.Mode = mode
+		opts.RoundDelay = time.Hour
+		conv := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events := conv.Run(ctx)
+		for ev := range events {
+			if ev.Type == EventTurnComplete {
+				cancel()
+				break
+			}
+		}
+
+		closed := make(chan struct{})
+		go func() {
+			for range events {
+			}
+			close(closed)
+		}()
+
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: event channel not closed while waiting between rounds", mode)
+		}
+		cancel()
+	}
+}
+
+// fakeMemory records stored turns and returns canned recall results
+type fakeMemory struct {
+	stored   []mcp.Entry
+	recall   []string
+	failNext bool
+}
+
+func (m *fakeMemory) Health(ctx context.Context) error { return nil }
+func (m *fakeMemory) Close() error                     { return nil }
+
+func (m *fakeMemory) Store(ctx context.Context, entry mcp.Entry) error {
+	m.stored = append(m.stored, entry)
+	return nil
+}
+
+func (m *fakeMemory) Recall(ctx context.Context, query string, limit int) ([]string, error) {
+	if m.failNext {
+		return nil, errors.New("connection refused")
+	}
+	return m.recall, nil
+}
+
+func TestConversationInjectsRecalledMemory(t *testing.T) {
+	memory := &fakeMemory{recall: []string{"Ada likes tides."}}
+	a := &fakeProvider{replies: []string{"one"}}
+	b := &fakeProvider{replies: []string{"two"}}
+
+	opts := testOptions(2)
+	opts.Memory = memory
+	conv := New(&Agent{Name: "Ada", Provider: a}, &Agent{Name: "Bob", Provider: b}, opts)
+	collect(t, conv.Run(context.Background()))
+
+	first := a.requests[0].Messages[0]
+	if first.Role != "system" || !strings.Contains(first.Content, "Ada likes tides.") {
+		t.Fatalf("expected recalled memory as system context, got %+v", first)
+	}
+	for _, msg := range conv.History() {
+		if msg.Role == "system" {
+			t.Fatal("recalled memory must not be stored in history")
+		}
+	}
+	if len(memory.stored) != 2 || memory.stored[1].Agent != "Bob" || memory.stored[1].Content != "two" {
+		t.Fatalf("unexpected stored turns: %+v", memory.stored)
+	}
+}
+
+func TestConversationContinuesWhenMemoryFails(t *testing.T) {
+	memory := &fakeMemory{failNext: true}
+	opts := testOptions(2)
+	opts.Memory = memory
+	conv := New(
+		&Agent{Name: "A", Provider: &fakeProvider{replies: []string{"one"}}},
+		&Agent{Name: "B", Provider: &fakeProvider{replies: []string{"two"}}},
+		opts,
+	)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 2 {
+		t.Fatalf("expected conversation to finish despite memory failure, got %+v", done)
+	}
+
+	warnings := 0
+	for _, ev := range events {
+		if ev.Type == EventWarning {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected exactly one memory warning, got %d", warnings)
+	}
+	if len(memory.stored) != 0 {
+		t.Fatal("memory should be disabled after the first failure")
+	}
+}
+
+func TestNewAgentRejectsOutOfRangeTemperature(t *testing.T) {
+	cfg := &config.Config{OpenAIKey: "test"}
+
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "openai", Temperature: providers.Float(2)}); err != nil {
+		t.Fatalf("temperature at the bound should be accepted: %v", err)
+	}
+
+	_, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "openai", Temperature: providers.Float(2.5)})
+	if err == nil || !strings.Contains(err.Error(), "valid: 0 to 2") {
+		t.Fatalf("expected range error before any request, got %v", err)
+	}
+
+	// No temperature leaves it to the provider, so there is nothing to check
+	agent, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "openai"})
+	if err != nil || agent.Temperature != nil {
+		t.Fatalf("expected an unset temperature to be accepted as-is, got %v, %v", agent, err)
+	}
+}
+
+func TestConversationContinuesFromPriorTurns(t *testing.T) {
+	a := &fakeProvider{replies: []string{"third from A"}}
+	b := &fakeProvider{replies: []string{"fourth from B"}}
+
+	opts := testOptions(4)
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Agent: "A", Content: "first from A"},
+		{Round: 2, Speaker: 1, Agent: "B", Content: "second from B"},
+	}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 4 {
+		t.Fatalf("expected to finish at round 4, got %+v", done)
+	}
+	if first := events[0]; first.Type != EventTurnStart || first.Round != 3 || first.Speaker != 0 {
+		t.Fatalf("expected to resume at round 3 with Agent A, got %+v", first)
+	}
+
+	// Agent A sees the rebuilt history ending with B's last prior reply
+	msgs := a.requests[0].Messages
+	if len(msgs) != 5 || msgs[0].Content != "Hello there" || msgs[4].Content != "second from B" {
+		t.Fatalf("unexpected rebuilt history: %+v", msgs)
+	}
+	if len(conv.History()) != 8 {
+		t.Fatalf("expected 8 history messages, got %d", len(conv.History()))
+	}
+}
+
+// systemMessages returns the content of each system message in msgs
+func systemMessages(msgs []providers.Message) []string {
+	var system []string
+	for _, msg := range msgs {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+		}
+	}
+	return system
+}
+
+func TestConversationReinforcesSystemPrompts(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a2", "a3"}}
+	b := &fakeProvider{replies: []string{"b1", "b2", "b3"}}
+
+	opts := testOptions(6)
+	opts.ReinforceEvery = 4
+	conv := New(
+		&Agent{Name: "A", Provider: a, SystemPrompt: "Be A"},
+		&Agent{Name: "B", Provider: b, SystemPrompt: "Be B"},
+		opts,
+	)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+
+	// A speaks in rounds 1, 3, 5 and B in 2, 4, 6; each is reinforced 4 rounds after its first turn
+	var reinforced []int
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete && ev.Turn.Reinforced {
+			reinforced = append(reinforced, ev.Turn.Round)
+		}
+	}
+	if len(reinforced) != 2 || reinforced[0] != 5 || reinforced[1] != 6 {
+		t.Fatalf("expected rounds 5 and 6 to be reinforced, got %v", reinforced)
+	}
+
+	// The prompt sits right before the incoming message, and only in its own agent's view
+	last := a.requests[2].Messages
+	if got := systemMessages(last); len(got) != 1 || got[0] != "Be A" {
+		t.Fatalf("expected A's prompt once, got %v", got)
+	}
+	if msg := last[len(last)-2]; msg.Role != "system" || msg.Content != "Be A" {
+		t.Fatalf("expected the prompt before the incoming message, got %+v", msg)
+	}
+	if got := systemMessages(b.requests[2].Messages); len(got) != 1 || got[0] != "Be B" {
+		t.Fatalf("expected only B's prompt in B's view, got %v", got)
+	}
+	if got := systemMessages(a.requests[1].Messages); len(got) != 0 {
+		t.Fatalf("expected no reinforcement before it was due, got %v", got)
+	}
+	if got := systemMessages(conv.History()); len(got) != 0 {
+		t.Fatalf("reinforcements leaked into the shared history: %v", got)
+	}
+}
+
+func TestConversationRestoresReinforcementsFromPriorTurns(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a3"}}
+	b := &fakeProvider{}
+
+	opts := testOptions(3)
+	opts.ReinforceEvery = 1
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Content: "a1"},
+		{Round: 2, Speaker: 1, Content: "b2", Reinforced: true},
+	}
+	conv := New(
+		&Agent{Name: "A", Provider: a, SystemPrompt: "Be A"},
+		&Agent{Name: "B", Provider: b, SystemPrompt: "Be B"},
+		opts,
+	)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+
+	// B's recorded reinforcement stays out of A's view, while A's own is due again
+	msgs := a.requests[0].Messages
+	if got := systemMessages(msgs); len(got) != 1 || got[0] != "Be A" {
+		t.Fatalf("unexpected system messages for A: %v", got)
+	}
+	if len(systemMessages(conv.agentView(1))) != 1 {
+		t.Fatalf("expected B's view to keep its recorded reinforcement")
+	}
+}
+
+func TestConversationWrapsTurnsTransiently(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a3"}}
+	b := &fakeProvider{replies: []string{"b2"}}
+
+	opts := testOptions(3)
+	opts.TurnPrefix = "Reply:"
+	opts.TurnSuffix = "Respond in one paragraph."
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+
+	// Only the incoming message of each request is wrapped, once
+	msgs := a.requests[1].Messages
+	if got, want := msgs[len(msgs)-1].Content, "Reply:\n\nb2\n\nRespond in one paragraph."; got != want {
+		t.Fatalf("incoming message = %q, want %q", got, want)
+	}
+	for _, msg := range msgs[:len(msgs)-1] {
+		if strings.Contains(msg.Content, "paragraph") {
+			t.Fatalf("earlier message kept the wrapper: %q", msg.Content)
+		}
+	}
+
+	// History and the emitted turns keep the plain text
+	for _, msg := range conv.History() {
+		if strings.Contains(msg.Content, "Reply:") {
+			t.Fatalf("wrapper leaked into history: %q", msg.Content)
+		}
+	}
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete && ev.Turn.Content != [...]string{"a1", "b2", "a3"}[ev.Turn.Round-1] {
+			t.Fatalf("turn %d content = %q", ev.Turn.Round, ev.Turn.Content)
+		}
+	}
+}
+
+func TestConversationSendsContextWithoutATurn(t *testing.T) {
+	for _, mode := range []Mode{ModeAlternating, ModeSimultaneous} {
+		a := &fakeProvider{replies: []string{"a1", "a2"}}
+		b := &fakeProvider{replies: []string{"b1", "b2"}}
+		opts := testOptions(2)
+		opts.Mode = mode
+		opts.Context = "You are both detectives."
+		conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+		events, done := collect(t, conv.Run(context.Background()))
+		if done.Err != nil {
+			t.Fatalf("%s: %v", mode, done.Err)
+		}
+		for name, p := range map[string]*fakeProvider{"A": a, "B": b} {
+			for _, req := range p.requests {
+				if msgs := req.Messages; msgs[0].Role != "system" || msgs[0].Content != opts.Context || len(systemMessages(msgs)) != 1 {
+					t.Fatalf("%s %s: expected the context first, once: %+v", mode, name, msgs)
+				}
+			}
+		}
+		for _, msg := range conv.History() {
+			if msg.Content == opts.Context {
+				t.Fatalf("%s: context leaked into history", mode)
+			}
+		}
+		if first := events[0]; first.Type != EventTurnStart || first.Round != 1 {
+			t.Fatalf("%s: expected the first event to start round 1, got %+v", mode, first)
+		}
+	}
+}
+
+func TestNewAgentResolvesAlias(t *testing.T) {
+	cfg := &config.Config{Aliases: map[string]config.Alias{
+		"local": {Provider: "openai", BaseURL: "http://localhost:8080/v1", Model: "qwen2.5"},
+	}}
+
+	agent, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "local", Temperature: providers.Float(0.5)})
+	if err != nil {
+		t.Fatalf("NewAgent: %v", err)
+	}
+	if agent.Provider.Name() != "openai" || agent.Alias != "local" || agent.Model != "qwen2.5" {
+		t.Fatalf("alias not resolved: provider=%s alias=%q model=%q", agent.Provider.Name(), agent.Alias, agent.Model)
+	}
+
+	// The underlying provider's rules still apply
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "local", Temperature: providers.Float(3)}); err == nil {
+		t.Fatal("expected the provider's temperature range to apply to its alias")
+	}
+}
+
+func TestConversationAttachesImagesToStarter(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-vision", Name: "Fake Vision", SupportsImages: true})
+
+	a := &fakeProvider{name: "fake-vision", replies: []string{"a1", "a2"}}
+	b := &fakeProvider{replies: []string{"b1"}}
+
+	opts := testOptions(3)
+	opts.Images = []providers.Image{{URL: "https://example.com/cat.jpg"}}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+
+	// Only the starter carries the image, and it reaches A on every request
+	for i, req := range a.requests {
+		msgs := req.Messages
+		if len(msgs[0].Images) != 1 || msgs[0].Images[0].URL != "https://example.com/cat.jpg" {
+			t.Fatalf("request %d: starter image missing: %+v", i, msgs[0])
+		}
+		for _, msg := range msgs[1:] {
+			if len(msg.Images) != 0 {
+				t.Fatalf("request %d: image attached to a later message: %+v", i, msg)
+			}
+		}
+	}
+
+	// B's provider doesn't support images, so it gets text only
+	if msg := b.requests[0].Messages[0]; msg.Content != "Hello there" || len(msg.Images) != 0 {
+		t.Fatalf("expected a text-only starter for B, got %+v", msg)
+	}
+}
+
+// fakeToolProvider is a fakeProvider that calls the scripted tools before its reply
+type fakeToolProvider struct {
+	fakeProvider
+	calls [][]providers.ToolCall // Calls made by successive requests; replies are only used once these run out
+}
+
+func (p *fakeToolProvider) StreamChatTools(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error, <-chan []providers.ToolCall) {
+	p.mu.Lock()
+	var calls []providers.ToolCall
+	if len(p.calls) > 0 {
+		calls, p.calls = p.calls[0], p.calls[1:]
+	}
+	p.mu.Unlock()
+
+	if calls == nil {
+		textChan, errChan := p.StreamChat(ctx, req)
+		return textChan, errChan, nil
+	}
+
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	callsChan := make(chan []providers.ToolCall, 1)
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+		defer close(callsChan)
+		textChan <- "Let me check. "
+		callsChan <- calls
+	}()
+	return textChan, errChan, callsChan
+}
+
+func TestConversationRunsToolCalls(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-tools", Name: "Fake Tools", SupportsTools: true})
+
+	a := &fakeToolProvider{
+		fakeProvider: fakeProvider{name: "fake-tools", replies: []string{"It is 42."}},
+		calls: [][]providers.ToolCall{{
+			{ID: "c1", Name: "calculator", Arguments: `{"expression":"6*7"}`},
+			{ID: "c2", Name: "missing", Arguments: `{}`},
+		}},
+	}
+	b := &fakeProvider{replies: []string{"b1"}}
+
+	calculator, err := tools.Lookup([]string{"calculator"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv := New(&Agent{Name: "A", Provider: a, Tools: calculator}, &Agent{Name: "B", Provider: b}, testOptions(2))
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+
+	var used []*ToolUse
+	var turn *Turn
+	for _, ev := range events {
+		switch {
+		case ev.Type == EventToolCall:
+			used = append(used, ev.Tool)
+		case ev.Type == EventTurnComplete && turn == nil:
+			turn = ev.Turn
+		}
+	}
+	if len(used) != 2 || used[0].Result != "42" || used[1].Error != `unknown tool "missing"` {
+		t.Fatalf("unexpected tool events: %+v", used)
+	}
+
+	// The results go back to the model after its tool-call message
+	if len(a.requests) != 2 {
+		t.Fatalf("expected a follow-up request after the tool calls, got %d requests", len(a.requests))
+	}
+	if len(a.requests[0].Tools) != 1 || a.requests[0].Tools[0].Name != "calculator" {
+		t.Fatalf("tools not offered: %+v", a.requests[0].Tools)
+	}
+	followUp := a.requests[1].Messages
+	n := len(followUp)
+	if n < 3 || len(followUp[n-3].ToolCalls) != 2 || followUp[n-3].Content != "Let me check. " ||
+		followUp[n-2].ToolCallID != "c1" || followUp[n-2].Content != "42" ||
+		followUp[n-1].ToolCallID != "c2" || !strings.HasPrefix(followUp[n-1].Content, "error: ") {
+		t.Fatalf("unexpected follow-up messages: %+v", followUp)
+	}
+
+	// The turn holds the whole reply, and the tool exchange stays out of B's view
+	if turn.Content != "Let me check. It is 42." || len(turn.ToolCalls) != 2 {
+		t.Fatalf("unexpected turn: %+v", turn)
+	}
+	for _, msg := range b.requests[0].Messages {
+		if len(msg.ToolCalls) > 0 || msg.ToolCallID != "" {
+			t.Fatalf("tool exchange leaked into B's history: %+v", msg)
+		}
+	}
+}
+
+func TestConversationStopsModelsThatIgnoreWithheldTools(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-tools", Name: "Fake Tools", SupportsTools: true})
+
+	call := []providers.ToolCall{{ID: "c1", Name: "calculator", Arguments: `{"expression":"1+1"}`}}
+	a := &fakeToolProvider{
+		fakeProvider: fakeProvider{name: "fake-tools", replies: []string{"never reached"}},
+		calls:        [][]providers.ToolCall{call, call, call, call},
+	}
+	calculator, err := tools.Lookup([]string{"calculator"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := testOptions(2)
+	opts.MaxToolCalls = 2
+	conv := New(&Agent{Name: "A", Provider: a, Tools: calculator}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if !errors.Is(done.Err, ErrToolLimit) {
+		t.Fatalf("expected ErrToolLimit, got %v", done.Err)
+	}
+	// Two calls within the budget, then one request with tools withheld
+	if len(a.requests) != 3 || a.requests[2].ToolChoice != providers.ToolChoiceNone {
+		t.Fatalf("expected the third request to withhold tools, got %d requests", len(a.requests))
+	}
+}
+
+func TestAgentCheckModel(t *testing.T) {
+	p := &fakeProvider{models: []string{"gpt-4", "gpt-4o", "gpt-4o-mini"}}
+
+	if err := (&Agent{Name: "A", Provider: p, Model: "gpt-4o"}).CheckModel(context.Background()); err != nil {
+		t.Fatalf("expected a listed model to pass, got %v", err)
+	}
+
+	err := (&Agent{Name: "A", Provider: p, Model: "gpt4"}).CheckModel(context.Background())
+	if !errors.Is(err, providers.ErrModelNotFound) || !strings.Contains(err.Error(), "did you mean gpt-4, gpt-4o") {
+		t.Fatalf("expected a model-not-found error with suggestions, got %v", err)
+	}
+
+	// Providers that can't list models aren't checked
+	if err := (&Agent{Name: "A", Provider: &fakeProvider{}, Model: "anything"}).CheckModel(context.Background()); err != nil {
+		t.Fatalf("expected no check without a model list, got %v", err)
+	}
+
+	// Nor does a failed listing block the run
+	failing := &fakeProvider{modelErr: fmt.Errorf("%w: connection failed", providers.ErrModelListFailed)}
+	if err := (&Agent{Name: "A", Provider: failing, Model: "anything"}).CheckModel(context.Background()); err != nil {
+		t.Fatalf("expected a failed model list to skip the check, got %v", err)
+	}
+}
+
+func TestConversationSimultaneousMode(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a2"}}
+	b := &fakeProvider{replies: []string{"b1", "b2"}}
+
+	opts := testOptions(2)
+	opts.Mode = ModeSimultaneous
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 2 {
+		t.Fatalf("expected 2 rounds, got %+v", done)
+	}
+
+	// Each round completes A then B under the same round number
+	var completed []string
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete {
+			completed = append(completed, fmt.Sprintf("%d:%s:%s", ev.Round, ev.Turn.Agent, ev.Turn.Content))
+		}
+	}
+	if got := strings.Join(completed, " "); got != "1:A:a1 1:B:b1 2:A:a2 2:B:b2" {
+		t.Fatalf("unexpected turns: %s", got)
+	}
+
+	// Both start from the starter, then each sees its own reply and the other's
+	for _, p := range []*fakeProvider{a, b} {
+		if msgs := p.requests[0].Messages; len(msgs) != 1 || msgs[0].Content != "Hello there" {
+			t.Fatalf("expected the starter alone in round 1, got %+v", msgs)
+		}
+	}
+	wantA := []providers.Message{{Role: "user", Content: "Hello there"}, {Role: "assistant", Content: "a1"}, {Role: "user", Content: "b1"}}
+	wantB := []providers.Message{{Role: "user", Content: "Hello there"}, {Role: "assistant", Content: "b1"}, {Role: "user", Content: "a1"}}
+	if got := a.requests[1].Messages; !reflect.DeepEqual(got, wantA) {
+		t.Fatalf("A's round 2 view = %+v, want %+v", got, wantA)
+	}
+	if got := b.requests[1].Messages; !reflect.DeepEqual(got, wantB) {
+		t.Fatalf("B's round 2 view = %+v, want %+v", got, wantB)
+	}
+}
+
+func TestConversationSimultaneousCancelsPeerOnError(t *testing.T) {
+	a := &fakeProvider{hang: true}
+	b := &fakeProvider{err: errors.New("boom")}
+
+	opts := testOptions(2)
+	opts.Mode = ModeSimultaneous
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err == nil || done.Err.Error() != "B: boom" || done.Result.Rounds != 0 {
+		t.Fatalf("expected B's failure to end the run, got %+v", done)
+	}
+}
+
+func TestConversationSimultaneousContinuesFromPriorTurns(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a2"}}
+	b := &fakeProvider{replies: []string{"b2"}}
+
+	opts := testOptions(2)
+	opts.Mode = ModeSimultaneous
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Agent: "A", Content: "a1"},
+		{Round: 1, Speaker: 1, Agent: "B", Content: "b1"},
+	}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 2 || len(a.requests) != 1 {
+		t.Fatalf("expected only round 2 to run, got %+v with %d requests", done, len(a.requests))
+	}
+	if msgs := b.requests[0].Messages; len(msgs) != 3 || msgs[1].Content != "b1" || msgs[2].Content != "a1" {
+		t.Fatalf("unexpected rebuilt view for B: %+v", msgs)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for name, want := range map[string]Mode{"": ModeAlternating, "alternating": ModeAlternating, "simultaneous": ModeSimultaneous} {
+		if got, err := ParseMode(name); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseMode("chaos"); err == nil {
+		t.Error("expected an unknown mode to fail")
+	}
+}
+
+func TestConversationRetriesEmptyStreams(t *testing.T) {
+	a := &fakeProvider{empty: 2, replies: []string{"warmed up"}}
+	b := &fakeProvider{replies: []string{"b1"}}
+
+	opts := testOptions(1)
+	opts.EmptyStreamDelay = time.Millisecond
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+	if len(a.requests) != 3 || events[len(events)-2].Turn.Content != "warmed up" {
+		t.Fatalf("expected the third attempt to succeed, got %d requests", len(a.requests))
+	}
+}
+
+func TestConversationGivesUpOnPersistentEmptyStreams(t *testing.T) {
+	opts := testOptions(1)
+	opts.EmptyStreamRetries = 1
+	opts.EmptyStreamDelay = time.Millisecond
+	a := &fakeProvider{empty: 5}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if !errors.Is(done.Err, providers.ErrEmptyStream) || !strings.Contains(done.Err.Error(), "after 2 attempts") || len(a.requests) != 2 {
+		t.Fatalf("expected to give up after 2 attempts, got %v with %d requests", done.Err, len(a.requests))
+	}
+
+	// Negative retries disable the retry entirely
+	opts.EmptyStreamRetries = -1
+	a = &fakeProvider{empty: 5}
+	conv = New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+	if _, done := collect(t, conv.Run(context.Background())); !errors.Is(done.Err, providers.ErrEmptyStream) || len(a.requests) != 1 {
+		t.Fatalf("expected a single attempt, got %v with %d requests", done.Err, len(a.requests))
+	}
+}
+
+func TestNewAgentRequiresAPIKey(t *testing.T) {
+	cfg := &config.Config{GeminiKey: "g-key", Aliases: map[string]config.Alias{
+		"local":   {Provider: "openai", BaseURL: "http://localhost:8080/v1"},
+		"shared":  {Provider: "openai", APIKeyEnv: "BRIDGE_TEST_UNSET_KEY"},
+		"gateway": {Provider: "openai", BaseURL: "https://llm.example/v1", APIKeyEnv: "BRIDGE_TEST_UNSET_KEY"},
+	}}
+
+	_, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "openai"})
+	if !errors.Is(err, ErrNoAPIKey) || !strings.Contains(err.Error(), "set OPENAI_API_KEY") {
+		t.Fatalf("expected a missing-key error naming OPENAI_API_KEY, got %v", err)
+	}
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "shared"}); err == nil || !strings.Contains(err.Error(), "set BRIDGE_TEST_UNSET_KEY") {
+		t.Fatalf("expected the alias's key variable to be named, got %v", err)
+	}
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "gateway"}); !errors.Is(err, ErrNoAPIKey) {
+		t.Fatalf("an alias naming a key variable needs it even with a base URL, got %v", err)
+	}
+
+	// Local endpoints and keyless providers need no key
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "local"}); err != nil {
+		t.Fatalf("alias with a base URL should not need a key: %v", err)
+	}
+	if _, err := NewAgent(cfg, AgentConfig{Name: "A", Provider: "exec", Command: "cat"}); err != nil {
+		t.Fatalf("exec should not need a key: %v", err)
+	}
+}
+
+func TestConversationOmitsTemperatureForFixedModels(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-reasoning", Name: "Fake Reasoning", Models: []providers.ModelInfo{
+		{ID: "fake-o1", FixedTemperature: true},
+	}})
+	a := &fakeProvider{name: "fake-reasoning", replies: []string{"a1", "a2"}}
+	schedule := ModelSchedule{{First: 3, Last: 3, Model: "fake-o1"}}
+	conv := New(
+		&Agent{Name: "A", Provider: a, Model: "fake-chat", Temperature: providers.Float(0.7), ModelSchedule: schedule},
+		&Agent{Name: "B", Provider: &fakeProvider{}},
+		testOptions(3),
+	)
+
+	if _, done := collect(t, conv.Run(context.Background())); done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if first, second := a.requests[0], a.requests[1]; first.Temperature == nil || second.Model != "fake-o1" || second.Temperature != nil {
+		t.Fatalf("expected the temperature sent only to the model that takes one, got %+v then %+v", first, second)
+	}
+}
+
+func TestConversationSummarizesRun(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-priced", Name: "Fake Priced", Models: []providers.ModelInfo{
+		{ID: "fake-model", InputPrice: 1, OutputPrice: 2},
+	}})
+	a := &fakeProvider{name: "fake-priced", replies: []string{"héllo", "again"}}
+	b := &fakeProvider{replies: []string{"hi"}}
+	counter := providers.TokenCounterFunc(func(text string) int { return len(text) })
+	conv := New(
+		&Agent{Name: "A", Provider: a, Model: "fake-model", TokenCounter: counter},
+		&Agent{Name: "B", Provider: b, Model: "fake-model", TokenCounter: counter},
+		testOptions(3),
+	)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+
+	sumA, sumB := done.Result.Summary.Agents[0], done.Result.Summary.Agents[1]
+	if sumA.Name != "A" || sumA.Provider != "fake-priced" || sumA.Messages != 2 || sumA.Characters != 10 || sumB.Messages != 1 {
+		t.Fatalf("unexpected summary: %+v", done.Result.Summary)
+	}
+	// Output is counted in bytes here, so "héllo" costs 6
+	if sumA.OutputTokens != 11 || sumA.InputTokens == 0 || sumA.InputTokens < sumB.InputTokens {
+		t.Fatalf("unexpected token estimates: %+v", sumA)
+	}
+	if want := (float64(sumA.InputTokens) + 2*float64(sumA.OutputTokens)) / 1e6; !sumA.Priced || math.Abs(sumA.Cost-want) > 1e-12 {
+		t.Fatalf("expected priced cost %v, got %+v", want, sumA)
+	}
+
+	// B's model has no pricing, so the total can't be known
+	if sumB.Priced {
+		t.Fatalf("expected B to be unpriced: %+v", sumB)
+	}
+	if _, ok := done.Result.Summary.Cost(); ok {
+		t.Fatal("expected the total cost to be unknown")
+	}
+}
+
+// metaProvider reports meta with every response
+type metaProvider struct {
+	fakeProvider
+	meta providers.ResponseMeta
+}
+
+func (p *metaProvider) StreamChatMeta(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error, <-chan []providers.ToolCall, <-chan providers.ResponseMeta) {
+	textChan, errChan := p.StreamChat(ctx, req)
+	metaChan := make(chan providers.ResponseMeta, 1)
+	metaChan <- p.meta
+	close(metaChan)
+	return textChan, errChan, nil, metaChan
+}
+
+func TestConversationRecordsResponseMeta(t *testing.T) {
+	a := &metaProvider{fakeProvider: fakeProvider{replies: []string{"Cut off mid"}}, meta: providers.ResponseMeta{Model: "served-1", FinishReason: providers.FinishLength}}
+	b := &metaProvider{fakeProvider: fakeProvider{replies: []string{"Complete."}}, meta: providers.ResponseMeta{Model: "served-2", FinishReason: providers.FinishStop}}
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, testOptions(2)).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	var warnings []string
+	var turns []*Turn
+	for _, ev := range events {
+		switch ev.Type {
+		case EventWarning:
+			warnings = append(warnings, ev.Text)
+		case EventTurnComplete:
+			turns = append(turns, ev.Turn)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "A's reply was cut off") {
+		t.Fatalf("expected one truncation warning for A, got %q", warnings)
+	}
+	if turns[0].ServedModel != "served-1" || turns[0].FinishReason != providers.FinishLength || turns[1].ServedModel != "served-2" || turns[1].FinishReason != providers.FinishStop {
+		t.Fatalf("unexpected metadata: %+v, %+v", turns[0], turns[1])
+	}
+}
+
+func TestConversationHoldsBackSplitRunes(t *testing.T) {
+	// "日本🎉" with each rune cut across chunks, and a dangling byte at the very end
+	reply := "日本🎉"
+	a := &fakeProvider{chunks: []string{reply[:2], reply[2:4], reply[4:8], reply[8:], "\xe6"}}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+
+	var printed []string
+	for _, ev := range events {
+		if ev.Type == EventToken {
+			printed = append(printed, ev.Text)
+		}
+	}
+	// Every printed chunk but the unfinished tail is valid UTF-8, and nothing is lost
+	for _, text := range printed[:len(printed)-1] {
+		if !utf8.ValidString(text) {
+			t.Fatalf("printed a broken rune: %q in %q", text, printed)
+		}
+	}
+	if got := strings.Join(printed, ""); got != reply+"\xe6" || printed[len(printed)-1] != "\xe6" {
+		t.Fatalf("unexpected output %q", printed)
+	}
+}
+
+func TestSplitPartialRune(t *testing.T) {
+	emoji := "🎉" // 4 bytes
+	tests := []struct{ input, complete, partial string }{
+		{"", "", ""},
+		{"abc", "abc", ""},
+		{"ab" + emoji, "ab" + emoji, ""},
+		{"ab" + emoji[:1], "ab", emoji[:1]},
+		{"ab" + emoji[:3], "ab", emoji[:3]},
+		{"日" + "本"[:2], "日", "本"[:2]},
+		{"a\x80", "a\x80", ""}, // A stray continuation byte can't be completed, so it isn't held
+	}
+	for _, tt := range tests {
+		complete, partial := splitPartialRune(tt.input)
+		if complete != tt.complete || partial != tt.partial {
+			t.Errorf("splitPartialRune(%q) = %q, %q; want %q, %q", tt.input, complete, partial, tt.complete, tt.partial)
+		}
+	}
+}
+
+func TestConversationStripsPreambles(t *testing.T) {
+	stripper, err := conversation.NewPreambleStripper(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &fakeProvider{replies: []string{"Sure! Here's my take: time is a river."}}
+	b := &fakeProvider{replies: []string{"Rivers end in the sea."}}
+	opts := testOptions(2)
+	opts.Preamble = stripper
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+
+	var printed strings.Builder
+	var turns []*Turn
+	for _, ev := range events {
+		switch ev.Type {
+		case EventToken:
+			if ev.Speaker == 0 {
+				printed.WriteString(ev.Text)
+			}
+		case EventTurnComplete:
+			turns = append(turns, ev.Turn)
+		}
+	}
+	if printed.String() != "time is a river." {
+		t.Fatalf("expected the preamble to be held back from display, got %q", printed.String())
+	}
+	if turns[0].Content != "time is a river." || turns[0].RawContent != "Sure! Here's my take: time is a river." {
+		t.Fatalf("unexpected turn: %+v", turns[0])
+	}
+	if turns[1].RawContent != "" {
+		t.Fatalf("expected no raw content for a reply without a preamble, got %q", turns[1].RawContent)
+	}
+	msgs := b.requests[0].Messages
+	if got := msgs[len(msgs)-1].Content; got != "time is a river." {
+		t.Fatalf("expected Agent B to receive the stripped reply, got %q", got)
+	}
+}
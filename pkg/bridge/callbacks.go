@@ -0,0 +1,42 @@
+package bridge
+
+import "context"
+
+// Callbacks receives a conversation's events as function calls, for callers that
+// would rather not select on the channel returned by Run. Any of them may be nil.
+type Callbacks struct {
+	OnToken        func(agent, text string) // A streamed chunk from the named agent
+	OnTurnComplete func(turn Turn)          // An agent finished its response
+
+	// OnEvent gets every other event except EventDone, whose Result and Err are
+	// returned instead. Without it, prompts paused for review are accepted as
+	// proposed; with it, the callback must answer EventReview with Review.Decide.
+	OnEvent func(ev Event)
+}
+
+// RunWithCallbacks runs the conversation like Run, calling cb for each event on
+// the calling goroutine, and returns once it ends. The result is nil only if ctx
+// was cancelled before the conversation finished.
+func (c *Conversation) RunWithCallbacks(ctx context.Context, cb Callbacks) (*Result, error) {
+	for ev := range c.Run(ctx) {
+		switch ev.Type {
+		case EventToken:
+			if cb.OnToken != nil {
+				cb.OnToken(ev.Agent.Name, ev.Text)
+			}
+		case EventTurnComplete:
+			if cb.OnTurnComplete != nil {
+				cb.OnTurnComplete(*ev.Turn)
+			}
+		case EventDone:
+			return ev.Result, ev.Err
+		default:
+			if cb.OnEvent != nil {
+				cb.OnEvent(ev)
+			} else if ev.Type == EventReview {
+				ev.Review.Decide(Decision{Action: ReviewAccept})
+			}
+		}
+	}
+	return nil, ctx.Err()
+}
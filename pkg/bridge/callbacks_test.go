@@ -0,0 +1,47 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunWithCallbacks(t *testing.T) {
+	a := &fakeProvider{replies: []string{"hello there", "again"}}
+	b := &fakeProvider{replies: []string{"hi back"}}
+	opts := testOptions(3)
+	opts.ReviewEvery = 2 // Accepted automatically without OnEvent
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	var streamed strings.Builder
+	var turns []Turn
+	result, err := conv.RunWithCallbacks(context.Background(), Callbacks{
+		OnToken:        func(agent, text string) { streamed.WriteString(agent + ":" + text + " ") },
+		OnTurnComplete: func(turn Turn) { turns = append(turns, turn) },
+	})
+	if err != nil || result == nil || result.Rounds != 3 || result.Reason != StopMaxRounds {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+	if len(turns) != 3 || turns[1].Agent != "B" || turns[1].Content != "hi back" {
+		t.Fatalf("unexpected turns: %+v", turns)
+	}
+	if !strings.Contains(streamed.String(), "B:") || !strings.Contains(streamed.String(), "A:") {
+		t.Fatalf("expected tokens from both agents, got %q", streamed.String())
+	}
+}
+
+func TestRunWithCallbacksCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{hang: true}}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(2))
+
+	result, err := conv.RunWithCallbacks(ctx, Callbacks{
+		OnEvent: func(ev Event) {
+			if ev.Type == EventTurnStart {
+				cancel()
+			}
+		},
+	})
+	if result != nil || err != context.Canceled {
+		t.Fatalf("expected a cancelled run, got %+v, %v", result, err)
+	}
+}
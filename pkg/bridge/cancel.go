@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTurnCancelled is the cause of a turn's context once CancelTurn stops it
+var ErrTurnCancelled = errors.New("turn cancelled")
+
+// CancelTurn stops the speaker's in-flight turn, e.g. to cut a long-winded reply
+// short, or in simultaneous mode when only the faster reply matters while the other
+// agent's keeps streaming. The cancelled turn still completes with the text received
+// so far and Turn.Cancelled set, so the conversation carries on as usual: the other
+// agent answers the partial reply. It reports whether a turn was streaming.
+//
+// Cancelling closes the request, but most providers still bill the tokens they
+// generated before they noticed, which can be more than the partial text shows.
+func (c *Conversation) CancelTurn(speaker int) bool {
+	if speaker < 0 || speaker >= len(c.agents) {
+		return false
+	}
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	if c.streams[speaker] == nil {
+		return false
+	}
+	c.streams[speaker](ErrTurnCancelled)
+	return true
+}
+
+// turnContext gives the speaker's turn a context CancelTurn can stop; release
+// unregisters it once the turn is over
+func (c *Conversation) turnContext(ctx context.Context, speaker int) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	c.streamsMu.Lock()
+	c.streams[speaker] = cancel
+	c.streamsMu.Unlock()
+	return ctx, func() {
+		c.streamsMu.Lock()
+		c.streams[speaker] = nil
+		c.streamsMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// turnCancelled reports whether CancelTurn stopped the turn running under ctx
+func turnCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrTurnCancelled)
+}
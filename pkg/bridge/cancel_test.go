@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// slowProvider streams one chunk, then holds the stream open until its context
+// ends; exited closes once the stream goroutine has returned
+type slowProvider struct {
+	fakeProvider
+	exited chan struct{}
+}
+
+func (p *slowProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(p.exited)
+		defer close(textChan)
+		defer close(errChan)
+		select {
+		case textChan <- "partial ":
+		case <-ctx.Done():
+		}
+		<-ctx.Done()
+		errChan <- providers.ErrContextCancelled
+	}()
+	return textChan, errChan
+}
+
+func TestCancelTurnStopsOneSimultaneousStream(t *testing.T) {
+	a := &fakeProvider{replies: []string{"fast answer"}}
+	b := &slowProvider{exited: make(chan struct{})}
+	opts := testOptions(1)
+	opts.Mode = ModeSimultaneous
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	var turns [2]*Turn
+	var done *Event
+	for ev := range conv.Run(context.Background()) {
+		switch ev.Type {
+		case EventToken:
+			if ev.Speaker == 1 && !conv.CancelTurn(1) {
+				t.Error("expected B's turn to be in flight")
+			}
+		case EventTurnComplete:
+			turns[ev.Speaker] = ev.Turn
+		case EventDone:
+			ev := ev
+			done = &ev
+		}
+	}
+
+	if done == nil || done.Err != nil || done.Result.Reason != StopMaxRounds {
+		t.Fatalf("expected the round to finish normally, got %+v", done)
+	}
+	if turns[0] == nil || turns[0].Content != "fast answer" || turns[0].Cancelled {
+		t.Fatalf("A's turn should be unaffected, got %+v", turns[0])
+	}
+	if turns[1] == nil || turns[1].Content != "partial " || !turns[1].Cancelled {
+		t.Fatalf("expected B's partial, cancelled turn, got %+v", turns[1])
+	}
+	select {
+	case <-b.exited:
+	case <-time.After(time.Second):
+		t.Fatal("the cancelled stream's goroutine never exited")
+	}
+
+	if conv.CancelTurn(1) || conv.CancelTurn(2) {
+		t.Fatal("expected no turn to cancel once the run is over")
+	}
+}
+
+func TestCancelTurnMovesAlternatingRunOn(t *testing.T) {
+	a := &slowProvider{exited: make(chan struct{})}
+	b := &fakeProvider{replies: []string{"b2"}}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, testOptions(2))
+
+	var turns []*Turn
+	var done *Event
+	for ev := range conv.Run(context.Background()) {
+		switch ev.Type {
+		case EventToken:
+			if ev.Speaker == 0 && !conv.CancelTurn(0) {
+				t.Error("expected A's turn to be in flight")
+			}
+		case EventTurnComplete:
+			turns = append(turns, ev.Turn)
+		case EventDone:
+			ev := ev
+			done = &ev
+		}
+	}
+
+	if done == nil || done.Err != nil || done.Result.Rounds != 2 {
+		t.Fatalf("expected the run to carry on after the cancelled turn, got %+v", done)
+	}
+	if len(turns) != 2 || turns[0].Content != "partial " || !turns[0].Cancelled || turns[1].Cancelled {
+		t.Fatalf("expected A's partial, cancelled turn and then B's, got %+v", turns)
+	}
+	// B answers what A managed to say
+	if msgs := b.requests[0].Messages; msgs[len(msgs)-1].Content != "partial " {
+		t.Fatalf("expected B to receive A's partial reply, got %+v", msgs)
+	}
+}
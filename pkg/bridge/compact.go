@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// compactHistory returns messages with the earlier turns compacted for sending
+// (Options.CompactHistory): whitespace runs collapsed outside code blocks and, with
+// CompactMaxChars, long turns cut short. System messages and the final incoming
+// message go out as they are, and the caller's slice is left untouched.
+func (c *Conversation) compactHistory(messages []providers.Message) []providers.Message {
+	if !c.opts.CompactHistory || len(messages) < 2 {
+		return messages
+	}
+	compacted := append([]providers.Message(nil), messages...)
+	for i := range compacted[:len(compacted)-1] {
+		if compacted[i].Role != "system" {
+			compacted[i].Content = compactText(compacted[i].Content, c.opts.CompactMaxChars)
+		}
+	}
+	return compacted
+}
+
+// compactText collapses runs of spaces within each line and runs of blank lines,
+// keeping each line's indentation, so unfenced code and nested lists keep their
+// shape; fenced code blocks are left exactly as written. With maxChars > 0, longer
+// text is cut at that many characters with a note of how much was left out.
+func compactText(text string, maxChars int) string {
+	var lines []string
+	fence := "" // Marker of the open code block, if any
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			lines = append(lines, line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if trimmed == "" {
+			// One blank line still separates paragraphs
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			lines = append(lines, indent+trimmed)
+			continue
+		}
+		lines = append(lines, indent+strings.Join(strings.Fields(trimmed), " "))
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	compacted := strings.Join(lines, "\n")
+
+	if maxChars > 0 && utf8.RuneCountInString(compacted) > maxChars {
+		runes := []rune(compacted)
+		compacted = fmt.Sprintf("%s […%d more characters]", string(runes[:maxChars]), len(runes)-maxChars)
+	}
+	return compacted
+}
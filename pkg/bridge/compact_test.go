@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestCompactTextKeepsCodeBlocks(t *testing.T) {
+	text := "  Here   is\tthe   fix:\n\n\n```go\nif x {\n\treturn  y  \n}\n```\n\nDone,   really.  "
+	want := "  Here is the fix:\n\n```go\nif x {\n\treturn  y  \n}\n```\n\nDone, really."
+	if got := compactText(text, 0); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// An indented fence opens a block too, and only its own marker closes it
+	text = "Try:\n  ~~~\n  a    b\n  ```\n  ~~~\nafter    it"
+	want = "Try:\n  ~~~\n  a    b\n  ```\n  ~~~\nafter it"
+	if got := compactText(text, 0); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Unfenced code and nested lists keep their indentation and line breaks
+	text = "Steps:\n\n\n- one   thing\n    - nested    item\n\n    indented  code()\n"
+	want = "Steps:\n\n- one thing\n    - nested item\n\n    indented code()"
+	if got := compactText(text, 0); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestCompactTextTruncatesByCharacter(t *testing.T) {
+	if got := compactText("héllo   wörld and more", 11); got != "héllo wörld […9 more characters]" {
+		t.Fatalf("unexpected truncation: %q", got)
+	}
+	if got := compactText("short", 11); got != "short" {
+		t.Fatalf("expected short text untouched, got %q", got)
+	}
+}
+
+func TestConversationCompactsOnlyWhatIsSent(t *testing.T) {
+	a := &fakeProvider{replies: []string{"spaced     out     reply", "second"}}
+	b := &fakeProvider{replies: []string{"b    reply   here"}}
+	opts := testOptions(3)
+	opts.Starter = "Hello    there,   friend"
+	opts.CompactHistory = true
+	chars := providers.TokenCounterFunc(func(text string) int { return len(text) })
+	conv := New(&Agent{Name: "A", Provider: a, TokenCounter: chars}, &Agent{Name: "B", Provider: b, TokenCounter: chars}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	// Round 3: A's earlier messages are compacted, the incoming one is not
+	msgs := a.requests[1].Messages
+	if msgs[0].Content != "Hello there, friend" || msgs[1].Content != "spaced out reply" || msgs[3].Content != "b reply here" || msgs[4].Content != "b    reply   here" {
+		t.Fatalf("unexpected request: %+v", msgs)
+	}
+	if history := conv.History(); history[1].Content != "spaced     out     reply" {
+		t.Fatalf("history should keep the full text, got %q", history[1].Content)
+	}
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete && ev.Round == 1 && ev.Turn.Content != "spaced     out     reply" {
+			t.Fatalf("turn should keep the full text, got %q", ev.Turn.Content)
+		}
+	}
+
+	// Characters count as tokens here. B's round 2 saved 5 on the starter and 8 on
+	// A's reply; A's round 3 also saved 8 on A's reply as B's incoming message and 5
+	// on B's reply.
+	if a, b := done.Result.Summary.Agents[0].SavedTokens, done.Result.Summary.Agents[1].SavedTokens; a != 26 || b != 13 {
+		t.Fatalf("expected 26 and 13 tokens saved, got %d and %d", a, b)
+	}
+}
@@ -0,0 +1,54 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// DefaultCoolerFactor is the temperature multiplier suggested for RetryCooler
+const DefaultCoolerFactor = 0.5
+
+// errEmptyReply describes a reply with no text
+var errEmptyReply = errors.New("empty reply")
+
+// coolerApplies reports whether a failed reply to req may be retried cooler: only
+// with RetryCooler set and a temperature above 0 to lower
+func (c *Conversation) coolerApplies(req *providers.ChatRequest) bool {
+	return c.opts.RetryCooler > 0 && req.Temperature != nil && *req.Temperature > 0
+}
+
+// retryCooler asks the agent once more at RetryCooler times the temperature when
+// its reply was declined or empty, returning the new reply and the retry's tool
+// calls. The second reply stands either way.
+func (c *Conversation) retryCooler(ctx context.Context, round, speaker int, req *providers.ChatRequest, content string, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error) {
+	if !c.coolerApplies(req) {
+		return content, nil, nil
+	}
+	agent := c.agents[speaker]
+	err, text := errEmptyReply, agent.Name+"'s reply was empty"
+	if reason := refusal(*meta); reason != "" {
+		err, text = fmt.Errorf("%w: %s", ErrRefused, reason), agent.Name+" declined to respond"
+	} else if strings.TrimSpace(content) != "" {
+		return content, nil, nil
+	}
+
+	cooler := *req.Temperature * c.opts.RetryCooler
+	c.log.Info("retrying cooler", "round", round, "agent", agent.Name, "reason", err, "temperature", *req.Temperature, "retry_temperature", cooler)
+	if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: fmt.Sprintf("%s; retrying at temperature %g", text, cooler), Err: err}) {
+		return "", nil, ctx.Err()
+	}
+
+	retry := *req
+	retry.Temperature = &cooler
+	var retryMeta providers.ResponseMeta
+	content, uses, err := c.respond(ctx, round, speaker, &retry, &retryMeta, emit)
+	if err != nil {
+		return "", uses, err
+	}
+	*meta = retryMeta
+	return content, uses, nil
+}
@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestConversationRetriesRefusalsCooler(t *testing.T) {
+	a := &refusingProvider{fakeProvider: fakeProvider{replies: []string{"Cooler now."}}, refuse: map[int]bool{1: true}}
+	opts := testOptions(1)
+	opts.RetryCooler = 0.5
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a, Temperature: providers.Float(0.8)}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	var turn *Turn
+	warnings := 0
+	for _, ev := range events {
+		switch ev.Type {
+		case EventWarning:
+			warnings++
+			if !errors.Is(ev.Err, ErrRefused) || ev.Text != "A declined to respond; retrying at temperature 0.4" {
+				t.Fatalf("unexpected warning: %+v", ev)
+			}
+		case EventTurnComplete:
+			turn = ev.Turn
+		}
+	}
+	if warnings != 1 || turn == nil || turn.Content != "Cooler now." || turn.Refusal != "" {
+		t.Fatalf("expected the cooler reply to stand, got %d warnings, %+v", warnings, turn)
+	}
+	if first, retry := a.requests[0], a.requests[1]; *first.Temperature != 0.8 || *retry.Temperature != 0.4 {
+		t.Fatalf("expected the retry at 0.4, got %v then %v", *first.Temperature, *retry.Temperature)
+	}
+}
+
+func TestConversationRetriesEmptyStreamsCooler(t *testing.T) {
+	opts := testOptions(1)
+	opts.EmptyStreamRetries = -1
+	opts.RetryCooler = 0.5
+	a := &fakeProvider{empty: 1, replies: []string{"a1"}}
+	_, done := collect(t, New(&Agent{Name: "A", Provider: a, Temperature: providers.Float(1)}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if done.Err != nil || len(a.requests) != 2 || *a.requests[1].Temperature != 0.5 {
+		t.Fatalf("expected one cooler retry, got %v with %d requests", done.Err, len(a.requests))
+	}
+
+	// Without a temperature to lower, the empty stream ends the run as before
+	a = &fakeProvider{empty: 1, replies: []string{"a1"}}
+	_, done = collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if !errors.Is(done.Err, providers.ErrEmptyStream) || len(a.requests) != 1 {
+		t.Fatalf("expected no retry, got %v with %d requests", done.Err, len(a.requests))
+	}
+}
@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// DefaultDirectorEvery is how many rounds pass between director instructions
+const DefaultDirectorEvery = 3
+
+// DefaultDirectorPrompt asks the director for a single steering instruction
+const DefaultDirectorPrompt = "You are the hidden director of a conversation between two AI agents. Read the conversation so far and write one short instruction, a sentence or two, that steers where it goes next: introduce a complication, a new angle, or a change of tone. Reply with the instruction only."
+
+const (
+	directorTurns     = 10  // Recent turns the director reads
+	directorMaxTokens = 200 // Instructions are meant to be short
+)
+
+// directionNote introduces the director's instruction to the agents
+const directionNote = "A note from the conversation's director, for your next reply only. Follow it without mentioning it or the director: "
+
+// direct records the round's completed turns for the director and, every
+// DirectorEvery rounds, asks it for an instruction that both agents' next requests
+// carry. A failed request is a warning; the conversation continues undirected.
+func (c *Conversation) direct(ctx context.Context, round int, turns []*Turn, emit func(Event) bool) {
+	director := c.opts.Director
+	if director == nil {
+		return
+	}
+	for _, turn := range turns {
+		c.recent = append(c.recent, *turn)
+	}
+	if over := len(c.recent) - directorTurns; over > 0 {
+		c.recent = c.recent[over:]
+	}
+	if round%c.opts.DirectorEvery != 0 || round >= c.opts.MaxRounds {
+		return
+	}
+
+	instruction, err := c.askDirector(ctx)
+	if err != nil {
+		c.log.Debug("director failed", "round", round, "provider", director.Provider.Name(), "error", err)
+		emit(Event{Type: EventWarning, Round: round, Text: "Director unavailable; the next round runs without an instruction", Err: err})
+		return
+	}
+	c.log.Debug("director instructed", "round", round, "chars", len(instruction))
+	c.directions = [2]string{instruction, instruction}
+	emit(Event{Type: EventDirection, Round: round, Agent: director, Text: instruction})
+}
+
+// askDirector sends the recent turns to the director and returns its instruction
+func (c *Conversation) askDirector(ctx context.Context) (string, error) {
+	director := c.opts.Director
+	prompt := director.SystemPrompt
+	if prompt == "" {
+		prompt = DefaultDirectorPrompt
+	}
+
+	var conversation strings.Builder
+	fmt.Fprintf(&conversation, "Opening message: %s", c.opts.Starter)
+	for _, turn := range c.recent {
+		fmt.Fprintf(&conversation, "\n\n%s (round %d): %s", turn.Agent, turn.Round, turn.Content)
+	}
+	conversation.WriteString("\n\nWrite the instruction for the next round.")
+
+	req := &providers.ChatRequest{
+		Model:        director.Model,
+		Messages:     []providers.Message{{Role: "user", Content: conversation.String()}},
+		Temperature:  director.Temperature,
+		MaxTokens:    directorMaxTokens,
+		SystemPrompt: prompt,
+		Sampling:     director.Sampling,
+	}
+	if !director.AcceptsTemperature(director.Model) {
+		req.Temperature = nil
+	}
+	// Cancelled on every return, so a timed-out request doesn't leave the provider
+	// blocked on a stream nobody reads
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	textChan, errChan := director.Provider.StreamChat(ctx, req)
+
+	var reply strings.Builder
+	for {
+		select {
+		case text, ok := <-textChan:
+			if !ok {
+				if err := <-errChan; err != nil {
+					return "", err
+				}
+				instruction := strings.TrimSpace(reply.String())
+				if instruction == "" {
+					return "", fmt.Errorf("%s sent an empty instruction", director.Name)
+				}
+				return instruction, nil
+			}
+			reply.WriteString(text)
+		case <-time.After(c.opts.ChunkTimeout):
+			return "", fmt.Errorf("stream timeout")
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// withDirection adds the director's pending instruction to the speaker's request,
+// just before the incoming message, and returns it for the turn's record. Like the
+// loop nudge it is sent once and never stored in history.
+func (c *Conversation) withDirection(speaker int, messages []providers.Message) ([]providers.Message, string) {
+	direction := c.directions[speaker]
+	if direction == "" || len(messages) == 0 {
+		return messages, ""
+	}
+	c.directions[speaker] = ""
+
+	last := len(messages) - 1
+	directed := make([]providers.Message, 0, len(messages)+1)
+	directed = append(directed, messages[:last]...)
+	return append(directed, providers.Message{Role: "system", Content: directionNote + direction}, messages[last]), direction
+}
@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestDirectorSteersBothAgentsOnce(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a2", "a3"}}
+	b := &fakeProvider{replies: []string{"b1", "b2", "b3"}}
+	director := &fakeProvider{replies: []string{"Introduce a complication."}}
+	opts := testOptions(5)
+	opts.Director = &Agent{Name: "Director", Provider: director, SystemPrompt: "Steer it."}
+	opts.DirectorEvery = 2
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 5 {
+		t.Fatalf("expected 5 rounds, got %+v", done)
+	}
+
+	// Asked after rounds 2 and 4; the second request gets no scripted reply
+	if len(director.requests) != 2 {
+		t.Fatalf("expected the director to be asked twice, got %d", len(director.requests))
+	}
+	req := director.requests[0]
+	if req.SystemPrompt != "Steer it." || !strings.Contains(req.Messages[0].Content, "B (round 2): b1") {
+		t.Fatalf("unexpected director request: %+v", req)
+	}
+
+	var directions []string
+	for _, ev := range events {
+		switch ev.Type {
+		case EventDirection:
+			directions = append(directions, ev.Text)
+		case EventTurnComplete:
+			if want := map[int]string{3: "Introduce a complication.", 4: "Introduce a complication."}[ev.Round]; ev.Turn.Direction != want {
+				t.Fatalf("round %d: expected direction %q, got %q", ev.Round, want, ev.Turn.Direction)
+			}
+		}
+	}
+	if len(directions) != 1 || directions[0] != "Introduce a complication." {
+		t.Fatalf("expected one direction event, got %v", directions)
+	}
+
+	// Rounds 3 (A) and 4 (B) carry it as a system message before the incoming one
+	for name, p := range map[string]*fakeProvider{"A": a, "B": b} {
+		msgs := p.requests[1].Messages
+		note := msgs[len(msgs)-2]
+		if note.Role != "system" || !strings.HasSuffix(note.Content, "Introduce a complication.") {
+			t.Fatalf("%s: expected the direction before the incoming message, got %+v", name, msgs)
+		}
+	}
+	for _, msg := range conv.History() {
+		if strings.Contains(msg.Content, "complication") {
+			t.Fatalf("direction leaked into history: %+v", msg)
+		}
+	}
+}
+
+func TestDirectorFailureIsAWarning(t *testing.T) {
+	opts := testOptions(3)
+	opts.Director = &Agent{Name: "Director", Provider: &fakeProvider{err: errors.New("offline")}}
+	opts.DirectorEvery = 1
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 3 {
+		t.Fatalf("expected the run to continue without the director, got %+v", done)
+	}
+	warnings := 0
+	for _, ev := range events {
+		if ev.Type == EventWarning && strings.Contains(ev.Err.Error(), "offline") {
+			warnings++
+		}
+		if ev.Type == EventDirection {
+			t.Fatalf("unexpected direction: %q", ev.Text)
+		}
+	}
+	if warnings != 2 {
+		t.Fatalf("expected a warning after rounds 1 and 2, got %d", warnings)
+	}
+}
+
+// ctxProvider is a fakeProvider that remembers the context of its last request
+type ctxProvider struct {
+	fakeProvider
+	ctx context.Context
+}
+
+func (p *ctxProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	p.ctx = ctx
+	return p.fakeProvider.StreamChat(ctx, req)
+}
+
+func TestDirectorTimeoutCancelsTheRequest(t *testing.T) {
+	director := &ctxProvider{fakeProvider: fakeProvider{hang: true}}
+	opts := testOptions(3)
+	opts.Director = &Agent{Name: "Director", Provider: director}
+	opts.ChunkTimeout = 10 * time.Millisecond
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	if _, err := conv.askDirector(context.Background()); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if director.ctx.Err() == nil {
+		t.Fatal("expected the timed-out request to be cancelled")
+	}
+}
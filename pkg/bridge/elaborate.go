@@ -0,0 +1,52 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// DefaultElaboratePrompt asks an agent to expand a reply shorter than MinResponseChars
+const DefaultElaboratePrompt = "That reply was very short. Please elaborate and develop your answer further."
+
+// elaborate asks the agent once more when its reply is shorter than
+// MinResponseChars, returning the new reply and the retry's tool calls. It asks
+// only once: a reply that is still short is accepted, and a blank one keeps the
+// original (and its meta).
+func (c *Conversation) elaborate(ctx context.Context, round, speaker int, req *providers.ChatRequest, content string, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error) {
+	length := utf8.RuneCountInString(strings.TrimSpace(content))
+	if c.opts.MinResponseChars <= 0 || length >= c.opts.MinResponseChars {
+		return content, nil, nil
+	}
+
+	agent := c.agents[speaker]
+	c.log.Info("short reply, asking to elaborate", "round", round, "agent", agent.Name, "chars", length, "min", c.opts.MinResponseChars)
+	err := fmt.Errorf("%d characters, minimum %d", length, c.opts.MinResponseChars)
+	if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: "Reply too short; asking " + agent.Name + " to elaborate", Err: err}) {
+		return "", nil, ctx.Err()
+	}
+
+	prompt := c.opts.ElaboratePrompt
+	if prompt == "" {
+		prompt = DefaultElaboratePrompt
+	}
+	retry := *req
+	n := len(req.Messages)
+	retry.Messages = append(req.Messages[:n:n],
+		providers.Message{Role: "assistant", Content: content},
+		providers.Message{Role: "user", Content: prompt},
+	)
+	var retryMeta providers.ResponseMeta
+	elaborated, uses, err := c.respond(ctx, round, speaker, &retry, &retryMeta, emit)
+	if err != nil {
+		return "", uses, err
+	}
+	if strings.TrimSpace(elaborated) == "" {
+		return content, uses, nil
+	}
+	*meta = retryMeta
+	return elaborated, uses, nil
+}
@@ -0,0 +1,63 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConversationAsksShortRepliesToElaborate(t *testing.T) {
+	a := &fakeProvider{replies: []string{"Yes.", "Yes, because the harbor is foggy tonight."}}
+	b := &fakeProvider{replies: []string{"A long enough answer from B."}}
+	opts := testOptions(2)
+	opts.MinResponseChars = 20
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	warnings := 0
+	for _, ev := range events {
+		if ev.Type == EventWarning {
+			warnings++
+		}
+		if ev.Type == EventTurnComplete && ev.Round == 1 && ev.Turn.Content != "Yes, because the harbor is foggy tonight." {
+			t.Fatalf("expected the elaborated reply, got %q", ev.Turn.Content)
+		}
+	}
+	if warnings != 1 || len(a.requests) != 2 || len(b.requests) != 1 {
+		t.Fatalf("expected one re-prompt of A, got %d warnings and %d/%d requests", warnings, len(a.requests), len(b.requests))
+	}
+	retry := a.requests[1].Messages
+	if n := len(retry); retry[n-2].Content != "Yes." || retry[n-1].Content != DefaultElaboratePrompt {
+		t.Fatalf("unexpected re-prompt: %+v", retry)
+	}
+	if incoming := b.requests[0].Messages; incoming[len(incoming)-1].Content != "Yes, because the harbor is foggy tonight." {
+		t.Fatalf("B should receive the elaborated reply, got %+v", incoming)
+	}
+}
+
+func TestConversationElaboratesOnlyOnce(t *testing.T) {
+	a := &fakeProvider{replies: []string{"No.", "Nope."}}
+	b := &fakeProvider{replies: []string{"Hm.", ""}}
+	opts := testOptions(2)
+	opts.MinResponseChars = 20
+	opts.ElaboratePrompt = "Say more."
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if len(a.requests) != 2 || len(b.requests) != 2 || a.requests[1].Messages[len(a.requests[1].Messages)-1].Content != "Say more." {
+		t.Fatalf("expected one re-prompt each, got %d and %d requests", len(a.requests), len(b.requests))
+	}
+
+	// A's second short reply is accepted; B's blank one keeps the first
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete && ev.Turn.Content != [...]string{"Nope.", "Hm."}[ev.Round-1] {
+			t.Fatalf("round %d: unexpected content %q", ev.Round, ev.Turn.Content)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package bridge
+
+import "github.com/markjamesm/chat-bridge-go/pkg/providers"
+
+// Estimate projects the token use and cost of running the conversation to
+// opts.MaxRounds without calling any provider, assuming every reply is replyTokens
+// long (capped at opts.MaxTokens). Input is counted as Run would send it: the
+// system prompt, the context, the growing history, and the turn prefix and suffix.
+// Prior turns count as history but are not charged again; tools, memory, and
+// reinforced system prompts are left out.
+func Estimate(a, b *Agent, opts Options, replyTokens int) Summary {
+	c := New(a, b, opts)
+	replyTokens = min(replyTokens, c.opts.MaxTokens)
+
+	// Token cost of each history message, and of the fixed parts of every request
+	var history [2][]int
+	var fixed [2]int
+	for speaker, agent := range c.agents {
+		counter := agent.Counter()
+		for _, msg := range c.perspective(speaker) {
+			history[speaker] = append(history[speaker], providers.MessageOverheadTokens+counter.CountTokens(msg.Content))
+		}
+		if agent.SystemPrompt != "" {
+			fixed[speaker] += providers.MessageOverheadTokens + counter.CountTokens(agent.SystemPrompt)
+		}
+		if c.opts.Context != "" {
+			fixed[speaker] += providers.MessageOverheadTokens + counter.CountTokens(c.opts.Context)
+		}
+		fixed[speaker] += counter.CountTokens(c.opts.TurnPrefix) + counter.CountTokens(c.opts.TurnSuffix)
+	}
+	reply := providers.MessageOverheadTokens + replyTokens
+
+	var summary Summary
+	respond := func(round, speaker int) {
+		input := fixed[speaker]
+		for _, tokens := range history[speaker] {
+			input += tokens
+		}
+		agent := c.agents[speaker]
+		s := &summary.Agents[speaker]
+		if s.Messages == 0 {
+			s.Name, s.Provider = agent.Name, agent.ProviderName()
+			s.Priced = true
+		}
+		s.count(agent.Provider.Name(), agent.modelFor(round), input, replyTokens)
+	}
+
+	if c.opts.Mode == ModeSimultaneous {
+		for round := PriorRounds(c.opts.Prior, c.opts.Mode) + 1; round <= c.opts.MaxRounds; round++ {
+			respond(round, 0)
+			respond(round, 1)
+			for speaker := range history {
+				history[speaker] = append(history[speaker], reply, reply)
+			}
+		}
+		return summary
+	}
+
+	// Each round adds the incoming message and the reply to the shared history
+	incoming := [2]int{}
+	for speaker, agent := range c.agents {
+		text := c.opts.Starter
+		if n := len(c.opts.Prior); n > 0 {
+			text = c.opts.Prior[n-1].Content
+		}
+		incoming[speaker] = providers.MessageOverheadTokens + agent.Counter().CountTokens(text)
+	}
+	speaker := nextSpeaker(c.opts.Prior)
+	for round := PriorRounds(c.opts.Prior, c.opts.Mode) + 1; round <= c.opts.MaxRounds; round++ {
+		for s := range history {
+			history[s] = append(history[s], incoming[s])
+		}
+		respond(round, speaker)
+		for s := range history {
+			history[s] = append(history[s], reply)
+		}
+		incoming = [2]int{reply, reply}
+		speaker = 1 - speaker
+	}
+	return summary
+}
@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+var wordCounter = providers.TokenCounterFunc(func(text string) int { return len(strings.Fields(text)) })
+
+// wordyAgent replies with five words every time, counted as five tokens
+func wordyAgent(name, system string) *Agent {
+	replies := make([]string, 10)
+	for i := range replies {
+		replies[i] = "one two three four five"
+	}
+	return &Agent{Name: name, Provider: &fakeProvider{replies: replies}, SystemPrompt: system, TokenCounter: wordCounter}
+}
+
+func TestEstimateMatchesARunWithRepliesOfThatLength(t *testing.T) {
+	for _, mode := range []Mode{ModeAlternating, ModeSimultaneous} {
+		opts := testOptions(4)
+		opts.Mode = mode
+		opts.TurnSuffix = "Be brief."
+		opts.Context = "Set in a quiet harbor town."
+
+		estimate := Estimate(wordyAgent("A", "You are A."), wordyAgent("B", ""), opts, 5)
+
+		conv := New(wordyAgent("A", "You are A."), wordyAgent("B", ""), opts)
+		_, done := collect(t, conv.Run(context.Background()))
+		if done.Err != nil {
+			t.Fatalf("%s: %v", mode, done.Err)
+		}
+		for i, got := range estimate.Agents {
+			want := done.Result.Summary.Agents[i]
+			if got.Messages != want.Messages || got.InputTokens != want.InputTokens || got.OutputTokens != want.OutputTokens {
+				t.Fatalf("%s agent %d: estimated %+v, ran %+v", mode, i, got, want)
+			}
+		}
+	}
+}
+
+func TestEstimateContinuesFromPriorTurnsAndCapsReplies(t *testing.T) {
+	opts := testOptions(4)
+	opts.MaxTokens = 3
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Agent: "A", Content: "first from A"},
+		{Round: 2, Speaker: 1, Agent: "B", Content: "second from B"},
+	}
+
+	estimate := Estimate(wordyAgent("A", ""), wordyAgent("B", ""), opts, 100)
+	a, b := estimate.Agents[0], estimate.Agents[1]
+	if a.Messages != 1 || b.Messages != 1 {
+		t.Fatalf("expected only rounds 3 and 4 to be charged, got %+v", estimate)
+	}
+	if a.OutputTokens != 3 {
+		t.Fatalf("expected replies capped at MaxTokens, got %d output tokens", a.OutputTokens)
+	}
+	// Round 3 sends the starter, then each prior reply twice: as the speaker's answer
+	// and as the next incoming message
+	if want := 5*providers.MessageOverheadTokens + 2 + 3 + 3 + 3 + 3; a.InputTokens != want {
+		t.Fatalf("expected %d input tokens for round 3, got %d", want, a.InputTokens)
+	}
+}
@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCacheTTL is how long a successful health check is reused
+const DefaultHealthCacheTTL = time.Minute
+
+// HealthCache reuses recent successful health checks, so agents sharing a
+// provider, endpoint, and API key (both sides of a conversation, or many server
+// sessions) aren't checked again within the TTL. Failures are never cached. It is
+// safe for concurrent use; the zero TTL disables it.
+type HealthCache struct {
+	ttl time.Duration
+	now func() time.Time // Replaced in tests
+
+	mu      sync.Mutex
+	checked map[string]time.Time // When each endpoint last passed
+}
+
+// NewHealthCache creates a cache that reuses a passed check for ttl
+func NewHealthCache(ttl time.Duration) *HealthCache {
+	return &HealthCache{ttl: ttl, now: time.Now, checked: make(map[string]time.Time)}
+}
+
+// Check runs the agent's health check unless the same endpoint passed one within
+// the TTL. Agents not created by NewAgent, and a nil cache, are always checked.
+func (h *HealthCache) Check(ctx context.Context, a *Agent) error {
+	if h == nil || h.ttl <= 0 || a.endpoint == "" {
+		return a.Health(ctx)
+	}
+
+	h.mu.Lock()
+	passed, ok := h.checked[a.endpoint]
+	h.mu.Unlock()
+	if ok && h.now().Sub(passed) < h.ttl {
+		slog.Debug("health check cached", "agent", a.Name, "provider", a.Provider.Name(), "age", h.now().Sub(passed))
+		return nil
+	}
+
+	if err := a.Health(ctx); err != nil {
+		h.Invalidate(a)
+		return err
+	}
+	h.mu.Lock()
+	h.checked[a.endpoint] = h.now()
+	h.mu.Unlock()
+	return nil
+}
+
+// Invalidate forgets the agent's endpoint, so its next Check calls the provider;
+// the conversation does this when a request is rejected for its credentials
+func (h *HealthCache) Invalidate(a *Agent) {
+	if h == nil || a.endpoint == "" {
+		return
+	}
+	h.mu.Lock()
+	delete(h.checked, a.endpoint)
+	h.mu.Unlock()
+}
+
+// endpointKey identifies what a health check vouches for: the provider, its base
+// URL or command, and a hash of the API key, so the key itself is never held as a
+// map key
+func endpointKey(provider, baseURL, command, apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return provider + "|" + baseURL + "|" + command + "|" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// checkedProvider counts health checks
+type checkedProvider struct {
+	fakeProvider
+	checks int
+	err    error
+}
+
+func (p *checkedProvider) Health(ctx context.Context) error {
+	p.checks++
+	return p.err
+}
+
+func TestHealthCacheReusesPassedChecks(t *testing.T) {
+	now := time.Now()
+	cache := NewHealthCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	p := &checkedProvider{}
+	a := &Agent{Name: "A", Provider: p, endpoint: endpointKey("fake", "http://x", "", "key")}
+	b := &Agent{Name: "B", Provider: p, endpoint: endpointKey("fake", "http://x", "", "key")}
+	other := &Agent{Name: "C", Provider: p, endpoint: endpointKey("fake", "http://x", "", "other key")}
+	for _, agent := range []*Agent{a, b, other, a} {
+		if err := cache.Check(context.Background(), agent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.checks != 2 {
+		t.Fatalf("expected one check per endpoint and key, got %d", p.checks)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Check(context.Background(), a)
+	if p.checks != 3 {
+		t.Fatalf("expected a check once the TTL passed, got %d", p.checks)
+	}
+
+	// Failures aren't cached, and agents without an endpoint are always checked
+	p.err = errors.New("down")
+	cache.Invalidate(a)
+	for range 2 {
+		if err := cache.Check(context.Background(), a); err == nil {
+			t.Fatal("expected the failure")
+		}
+	}
+	p.err = nil
+	cache.Check(context.Background(), &Agent{Name: "D", Provider: p})
+	cache.Check(context.Background(), &Agent{Name: "D", Provider: p})
+	if p.checks != 7 {
+		t.Fatalf("expected 7 checks, got %d", p.checks)
+	}
+}
+
+func TestConversationInvalidatesHealthOnAuthFailure(t *testing.T) {
+	cache := NewHealthCache(time.Minute)
+	p := &checkedProvider{fakeProvider: fakeProvider{err: fmt.Errorf("%w: key revoked", providers.ErrInvalidCredentials)}}
+	a := &Agent{Name: "A", Provider: p, endpoint: endpointKey("fake", "", "", "key")}
+	cache.Check(context.Background(), a)
+
+	opts := testOptions(1)
+	opts.HealthCache = cache
+	_, done := collect(t, New(a, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if !errors.Is(done.Err, providers.ErrInvalidCredentials) {
+		t.Fatalf("expected the auth failure, got %v", done.Err)
+	}
+
+	cache.Check(context.Background(), a)
+	if p.checks != 2 {
+		t.Fatalf("expected the auth failure to force a new check, got %d checks", p.checks)
+	}
+}
+
+func TestAgentWarmupTimesFirstToken(t *testing.T) {
+	p := &fakeProvider{replies: []string{"OK"}}
+	agent := &Agent{Name: "A", Provider: p, Model: "fake-model"}
+
+	ttft, err := agent.Warmup(context.Background())
+	if err != nil || ttft <= 0 {
+		t.Fatalf("expected a first-token time, got %v, %v", ttft, err)
+	}
+	if req := p.requests[0]; req.MaxTokens != 1 || req.Model != "fake-model" || req.Messages[0].Content != WarmupPrompt {
+		t.Fatalf("expected a one-token request to the agent's model, got %+v", req)
+	}
+
+	failing := &Agent{Name: "B", Provider: &fakeProvider{err: providers.ErrInvalidCredentials}}
+	if _, err := failing.Warmup(context.Background()); !errors.Is(err, providers.ErrInvalidCredentials) {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+}
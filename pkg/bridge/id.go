@@ -0,0 +1,26 @@
+package bridge
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// NewID returns a random (version 4) UUID identifying a conversation
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Still unique enough to correlate one machine's runs
+		return time.Now().UTC().Format("20060102-150405.000000000")
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ID returns the conversation's ID, from Options.ID or generated by New. The
+// engine's log lines carry it as "conversation", and Run tags its requests'
+// context with it (providers.WithConversationID) so trace files do too.
+func (c *Conversation) ID() string {
+	return c.opts.ID
+}
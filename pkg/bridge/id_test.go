@@ -0,0 +1,43 @@
+package bridge
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// contextProvider records the conversation ID each request's context carries
+type contextProvider struct {
+	fakeProvider
+	ids []string
+}
+
+func (p *contextProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	p.ids = append(p.ids, providers.ConversationID(ctx))
+	return p.fakeProvider.StreamChat(ctx, req)
+}
+
+func TestConversationIDs(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	one := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+	two := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+	if !uuid.MatchString(one.ID()) || one.ID() == two.ID() {
+		t.Fatalf("expected distinct UUIDs, got %q and %q", one.ID(), two.ID())
+	}
+
+	a := &contextProvider{fakeProvider: fakeProvider{replies: []string{"hi"}}}
+	opts := testOptions(1)
+	opts.ID = "run-42"
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+	if conv.ID() != "run-42" {
+		t.Fatalf("expected Options.ID to be kept, got %q", conv.ID())
+	}
+	if _, done := collect(t, conv.Run(context.Background())); done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+	if len(a.ids) != 1 || a.ids[0] != "run-42" {
+		t.Fatalf("expected requests to carry the conversation ID, got %q", a.ids)
+	}
+}
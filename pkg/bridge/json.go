@@ -0,0 +1,149 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// Errors for JSON-mode replies that are still wrong after a retry
+var (
+	ErrInvalidJSON    = errors.New("reply is not a valid JSON object")
+	ErrSchemaMismatch = errors.New("reply does not match the JSON schema")
+)
+
+const (
+	jsonInstruction   = "Respond with a single valid JSON object and nothing else: no prose and no code fences."
+	jsonRetryPrompt   = "That reply was not a valid JSON object (%v). Send it again as a single valid JSON object only."
+	schemaInstruction = "Respond with a single valid JSON object matching this JSON schema, and nothing else: no prose and no code fences.\n\n%s"
+	schemaRetryPrompt = "That reply did not match the JSON schema (%v). Send it again as a single JSON object matching the schema."
+)
+
+// MentionsJSON reports whether a prompt asks for JSON; OpenAI's JSON mode rejects
+// requests whose messages never mention it
+func MentionsJSON(prompt string) bool {
+	return strings.Contains(strings.ToLower(prompt), "json")
+}
+
+// SupportsJSONMode reports whether the agent's provider can enforce JSON output itself
+func (a *Agent) SupportsJSONMode() bool {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	return ok && spec.SupportsJSONMode
+}
+
+// SupportsJSONSchema reports whether the agent's provider can enforce a JSON schema itself
+func (a *Agent) SupportsJSONSchema() bool {
+	spec, ok := providers.GetProviderSpec(a.Provider.Name())
+	return ok && spec.SupportsJSONSchema
+}
+
+// requestJSON asks for JSON output: natively where the provider supports it, and by
+// an instruction on the final message when it doesn't or the system prompt never
+// mentions JSON. An agent's schema is sent natively where supported and otherwise
+// shown in the instruction, on top of any plain JSON mode.
+func (c *Conversation) requestJSON(agent *Agent, req *providers.ChatRequest) {
+	instruction := jsonInstruction
+	native := agent.SupportsJSONMode()
+	switch {
+	case agent.Schema != nil && agent.SupportsJSONSchema():
+		req.ResponseFormat, req.Schema = providers.ResponseFormatJSONSchema, agent.Schema
+		return
+	case agent.Schema != nil:
+		instruction = fmt.Sprintf(schemaInstruction, agent.Schema.Document)
+		native = false // The schema still needs stating
+		if agent.SupportsJSONMode() {
+			req.ResponseFormat = providers.ResponseFormatJSON
+		}
+	case native:
+		req.ResponseFormat = providers.ResponseFormatJSON
+	}
+	if (native && MentionsJSON(agent.SystemPrompt)) || len(req.Messages) == 0 {
+		return
+	}
+
+	// Edit a copy, so the shared history keeps the original message
+	messages := append([]providers.Message(nil), req.Messages...)
+	last := &messages[len(messages)-1]
+	last.Content = strings.TrimRight(last.Content, "\n") + "\n\n" + instruction
+	req.Messages = messages
+}
+
+// enforceJSON validates a JSON-mode reply, asking once more when it isn't a JSON
+// object or doesn't match the agent's schema. It returns the reply with any code
+// fence removed, plus the retry's tool calls; a retry replaces meta with its own.
+func (c *Conversation) enforceJSON(ctx context.Context, round, speaker int, req *providers.ChatRequest, content string, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error) {
+	agent := c.agents[speaker]
+	reply, mismatch, err := checkJSONReply(agent, content)
+	if err == nil {
+		return reply, nil, nil
+	}
+
+	kind, prompt, warning := ErrInvalidJSON, jsonRetryPrompt, "Reply was not valid JSON; asking again"
+	if mismatch {
+		kind, prompt, warning = ErrSchemaMismatch, schemaRetryPrompt, "Reply did not match the JSON schema; asking again"
+	}
+	c.log.Debug("invalid JSON reply, retrying", "round", round, "agent", agent.Name, "error", err)
+	if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: warning, Err: fmt.Errorf("%w: %v", kind, err)}) {
+		return "", nil, ctx.Err()
+	}
+
+	retry := *req
+	n := len(req.Messages)
+	retry.Messages = append(req.Messages[:n:n],
+		providers.Message{Role: "assistant", Content: content},
+		providers.Message{Role: "user", Content: fmt.Sprintf(prompt, err)},
+	)
+	content, uses, err := c.respond(ctx, round, speaker, &retry, meta, emit)
+	if err != nil {
+		return "", uses, err
+	}
+	if reply, mismatch, err = checkJSONReply(agent, content); err != nil {
+		kind = ErrInvalidJSON
+		if mismatch {
+			kind = ErrSchemaMismatch
+		}
+		return "", uses, fmt.Errorf("%w after a retry: %v", kind, err)
+	}
+	return reply, uses, nil
+}
+
+// checkJSONReply parses a JSON-mode reply and checks it against the agent's schema,
+// reporting whether a bad reply was valid JSON that failed the schema
+func checkJSONReply(agent *Agent, content string) (string, bool, error) {
+	reply, err := parseJSONReply(content)
+	if err != nil {
+		return "", false, err
+	}
+	if agent.Schema != nil {
+		if err := agent.Schema.Validate([]byte(reply)); err != nil {
+			return "", true, err
+		}
+	}
+	return reply, false, nil
+}
+
+// parseJSONReply checks that a reply is one JSON object, tolerating surrounding
+// whitespace and a Markdown code fence, and returns the bare object
+func parseJSONReply(content string) (string, error) {
+	text := strings.TrimSpace(content)
+	if fenced, ok := strings.CutPrefix(text, "```"); ok {
+		// Drop the info string (e.g. "json") and the closing fence
+		if i := strings.IndexByte(fenced, '\n'); i >= 0 {
+			fenced = fenced[i+1:]
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(fenced), "```"))
+	}
+
+	if !strings.HasPrefix(text, "{") {
+		return "", errors.New("expected a JSON object")
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(text), &object); err != nil {
+		return "", err
+	}
+	return text, nil
+}
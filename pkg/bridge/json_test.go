@@ -0,0 +1,154 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestConversationJSONModeRetriesInvalidReplies(t *testing.T) {
+	a := &fakeProvider{replies: []string{"Sure, here you go!", "```json\n{\"answer\": 42}\n```"}}
+	b := &fakeProvider{replies: []string{"thanks"}}
+	conv := New(&Agent{Name: "A", Provider: a, JSONMode: true}, &Agent{Name: "B", Provider: b}, testOptions(2))
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+
+	var warned bool
+	var turns []*Turn
+	for _, ev := range events {
+		warned = warned || (ev.Type == EventWarning && ev.Speaker == 0)
+		if ev.Type == EventTurnComplete {
+			turns = append(turns, ev.Turn)
+		}
+	}
+	if !warned || turns[0].Content != `{"answer": 42}` {
+		t.Fatalf("expected a retried, unfenced JSON turn, got warned=%v %q", warned, turns[0].Content)
+	}
+
+	// Without native JSON mode the request carries an instruction, and the retry sees the bad reply
+	first, retry := a.requests[0], a.requests[1]
+	if first.ResponseFormat != "" || !strings.Contains(first.Messages[len(first.Messages)-1].Content, jsonInstruction) {
+		t.Fatalf("expected a JSON instruction on the starter: %+v", first.Messages)
+	}
+	if n := len(retry.Messages); n != 3 || retry.Messages[1].Content != "Sure, here you go!" || !strings.Contains(retry.Messages[2].Content, "not a valid JSON object") {
+		t.Fatalf("unexpected retry messages: %+v", retry.Messages)
+	}
+
+	// Neither the instruction nor the failed attempt leaks into B's view
+	for _, msg := range b.requests[0].Messages {
+		if strings.Contains(msg.Content, jsonInstruction) || strings.Contains(msg.Content, "Sure, here") {
+			t.Fatalf("JSON enforcement leaked into B's history: %+v", b.requests[0].Messages)
+		}
+	}
+}
+
+func TestConversationJSONModeFailsAfterRetry(t *testing.T) {
+	a := &fakeProvider{replies: []string{"no", "still no"}}
+	conv := New(&Agent{Name: "A", Provider: a, JSONMode: true}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(2))
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || !errors.Is(done.Err, ErrInvalidJSON) || len(a.requests) != 2 {
+		t.Fatalf("expected ErrInvalidJSON after one retry, got %+v (%d requests)", done, len(a.requests))
+	}
+}
+
+func TestConversationJSONModeUsesNativeFormat(t *testing.T) {
+	a := &fakeProvider{name: "openai", replies: []string{`{"ok": true}`}}
+	agent := &Agent{Name: "A", Provider: a, JSONMode: true, SystemPrompt: "Answer in JSON."}
+	conv := New(agent, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	if _, done := collect(t, conv.Run(context.Background())); done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+	req := a.requests[0]
+	if req.ResponseFormat != providers.ResponseFormatJSON || req.Messages[0].Content != "Hello there" {
+		t.Fatalf("expected native JSON mode without an added instruction: %+v", req)
+	}
+}
+
+// testSchema is a schema requiring an integer answer
+func testSchema(t *testing.T) *providers.JSONSchema {
+	t.Helper()
+	schema, err := providers.ParseJSONSchema("answer", []byte(`{"type": "object", "properties": {"answer": {"type": "integer"}}, "required": ["answer"], "additionalProperties": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestConversationJSONSchemaRetriesMismatches(t *testing.T) {
+	a := &fakeProvider{replies: []string{`{"answer": "forty-two"}`, `{"answer": 42}`}}
+	conv := New(&Agent{Name: "A", Provider: a, JSONMode: true, Schema: testSchema(t)}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+	var warning *Event
+	for i, ev := range events {
+		if ev.Type == EventWarning {
+			warning = &events[i]
+		}
+	}
+	if warning == nil || !errors.Is(warning.Err, ErrSchemaMismatch) {
+		t.Fatalf("expected a schema mismatch warning, got %+v", warning)
+	}
+
+	// Without native schema support the schema is stated in the request, and the retry names the mismatch
+	first, retry := a.requests[0], a.requests[1]
+	if first.Schema != nil || !strings.Contains(first.Messages[0].Content, `"required":["answer"]`) {
+		t.Fatalf("expected the schema in the instruction: %+v", first)
+	}
+	if last := retry.Messages[len(retry.Messages)-1].Content; !strings.Contains(last, "$.answer: expected integer, got string") {
+		t.Fatalf("expected the retry to name the mismatch, got %q", last)
+	}
+}
+
+func TestConversationJSONSchemaFailsAfterRetry(t *testing.T) {
+	a := &fakeProvider{replies: []string{`{"answer": 1.5}`, `{"answer": 42, "extra": true}`}}
+	conv := New(&Agent{Name: "A", Provider: a, JSONMode: true, Schema: testSchema(t)}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done == nil || !errors.Is(done.Err, ErrSchemaMismatch) || len(a.requests) != 2 {
+		t.Fatalf("expected ErrSchemaMismatch after one retry, got %+v (%d requests)", done, len(a.requests))
+	}
+}
+
+func TestConversationJSONSchemaUsesNativeFormat(t *testing.T) {
+	a := &fakeProvider{name: "openai", replies: []string{`{"answer": 42}`}}
+	schema := testSchema(t)
+	conv := New(&Agent{Name: "A", Provider: a, JSONMode: true, Schema: schema}, &Agent{Name: "B", Provider: &fakeProvider{}}, testOptions(1))
+
+	if _, done := collect(t, conv.Run(context.Background())); done == nil || done.Err != nil {
+		t.Fatalf("expected clean finish, got %+v", done)
+	}
+	req := a.requests[0]
+	if req.ResponseFormat != providers.ResponseFormatJSONSchema || req.Schema != schema || req.Messages[0].Content != "Hello there" {
+		t.Fatalf("expected the native schema format without an added instruction: %+v", req)
+	}
+}
+
+func TestParseJSONReply(t *testing.T) {
+	valid := map[string]string{
+		`{"a": 1}`:                      `{"a": 1}`,
+		"  {\"a\": 1}\n":                `{"a": 1}`,
+		"```json\n{\"a\": [1, 2]}\n```": `{"a": [1, 2]}`,
+		"```\n{}\n```":                  `{}`,
+	}
+	for input, want := range valid {
+		if got, err := parseJSONReply(input); err != nil || got != want {
+			t.Errorf("parseJSONReply(%q) = %q, %v", input, got, err)
+		}
+	}
+	for _, input := range []string{"", "null", "[1, 2]", `{"a": }`, "Here: {\"a\": 1}", `"text"`} {
+		if _, err := parseJSONReply(input); err == nil {
+			t.Errorf("parseJSONReply(%q): expected error", input)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// ErrLoop describes a conversation whose replies keep repeating recent ones
+var ErrLoop = errors.New("replies are repeating each other")
+
+// LoopAction is what happens when Options.Loop reports a loop
+type LoopAction string
+
+const (
+	LoopStop  LoopAction = "stop"  // End the conversation with StopLoop (default)
+	LoopNudge LoopAction = "nudge" // Ask the agents to change the subject and carry on
+)
+
+const loopNudge = "The conversation is going in circles: recent replies repeat each other. Change the subject or take the discussion somewhere new instead of restating what has been said."
+
+// ParseLoopAction validates a loop action name; empty selects LoopStop
+func ParseLoopAction(name string) (LoopAction, error) {
+	switch LoopAction(name) {
+	case "", LoopStop:
+		return LoopStop, nil
+	case LoopNudge:
+		return LoopNudge, nil
+	}
+	return "", fmt.Errorf("unknown loop action %q (use %s or %s)", name, LoopStop, LoopNudge)
+}
+
+// checkLoop feeds completed turns to the loop detector and reports whether the
+// conversation should stop. With LoopNudge, a loop instead queues the nudge for the
+// next requests of the agents in next.
+func (c *Conversation) checkLoop(round int, turns []*Turn, emit func(Event) bool, next ...int) bool {
+	if c.opts.Loop == nil {
+		return false
+	}
+	looping, similarity := false, 0.0
+	for _, turn := range turns {
+		if sim, loop := c.opts.Loop.Observe(turn.Content); loop {
+			looping, similarity = true, max(similarity, sim)
+		}
+	}
+	if !looping {
+		return false
+	}
+
+	err := fmt.Errorf("%w (%.0f%% similar to a recent reply)", ErrLoop, similarity*100)
+	c.log.Debug("loop detected", "round", round, "similarity", similarity, "action", c.opts.OnLoop)
+	if c.opts.OnLoop == LoopNudge {
+		for _, speaker := range next {
+			c.nudge[speaker] = true
+		}
+		emit(Event{Type: EventWarning, Round: round, Text: "Agents are repeating each other; asking them to change the subject", Err: err})
+		return false
+	}
+	emit(Event{Type: EventWarning, Round: round, Text: "Agents are repeating each other; ending the conversation", Err: err})
+	return true
+}
+
+// withNudge adds the queued loop nudge to the speaker's request, just before the
+// incoming message. Like recalled memory it is never stored in history.
+func (c *Conversation) withNudge(speaker int, messages []providers.Message) []providers.Message {
+	if !c.nudge[speaker] || len(messages) == 0 {
+		return messages
+	}
+	c.nudge[speaker] = false
+
+	last := len(messages) - 1
+	nudged := make([]providers.Message, 0, len(messages)+1)
+	nudged = append(nudged, messages[:last]...)
+	return append(nudged, providers.Message{Role: "system", Content: loopNudge}, messages[last])
+}
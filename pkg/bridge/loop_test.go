@@ -0,0 +1,80 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/conversation"
+)
+
+const echoReply = "I completely agree, curiosity is what drives all real progress."
+
+func repeated(n int) []string {
+	replies := make([]string, n)
+	for i := range replies {
+		replies[i] = echoReply
+	}
+	return replies
+}
+
+func TestConversationStopsOnLoop(t *testing.T) {
+	loop, err := conversation.NewLoopDetector(0.8, 4, 2)
+	if err != nil {
+		t.Fatalf("NewLoopDetector: %v", err)
+	}
+	opts := testOptions(10)
+	opts.Loop = loop
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{replies: repeated(5)}}, &Agent{Name: "B", Provider: &fakeProvider{replies: repeated(5)}}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+	// The first reply has nothing to repeat, then two repeats make a loop
+	if done.Result.Reason != StopLoop || done.Result.Rounds != 3 {
+		t.Fatalf("expected a loop stop after 3 rounds, got %s after %d", done.Result.Reason, done.Result.Rounds)
+	}
+
+	reported := false
+	for _, ev := range events {
+		reported = reported || ev.Type == EventWarning && errors.Is(ev.Err, ErrLoop)
+	}
+	if !reported {
+		t.Fatal("expected a warning explaining the stop")
+	}
+}
+
+func TestConversationNudgesOutOfLoop(t *testing.T) {
+	loop, _ := conversation.NewLoopDetector(0.8, 4, 2)
+	a := &fakeProvider{replies: append(repeated(2), "Fine, let's talk about volcanoes and plate tectonics instead.")}
+	b := &fakeProvider{replies: repeated(3)}
+
+	opts := testOptions(6)
+	opts.Loop = loop
+	opts.OnLoop = LoopNudge
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil || done.Result.Reason != StopMaxRounds {
+		t.Fatalf("expected the nudged conversation to carry on, got %s (%v)", done.Result.Reason, done.Err)
+	}
+
+	// The loop completes in round 3 (A), so only B's next request is nudged
+	nudged := func(p *fakeProvider, i int) bool {
+		msgs := p.requests[i].Messages
+		return len(msgs) > 1 && msgs[len(msgs)-2].Content == loopNudge
+	}
+	if !nudged(b, 1) {
+		t.Fatalf("expected B's round 4 request to carry the nudge: %+v", b.requests[1].Messages)
+	}
+	if nudged(a, 2) || nudged(b, 2) {
+		t.Fatal("the nudge should only be sent once")
+	}
+	for _, msg := range conv.History() {
+		if strings.Contains(msg.Content, "going in circles") {
+			t.Fatal("the nudge leaked into history")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// ErrRefused describes a turn the provider or model declined to answer
+var ErrRefused = errors.New("refused")
+
+// RefusalAction is what happens when an agent declines to respond
+type RefusalAction string
+
+const (
+	RefusalSkip  RefusalAction = "skip"  // Record the refusal and pass the prompt to the other agent (default)
+	RefusalNudge RefusalAction = "nudge" // Ask once more, then skip if the agent still declines
+	RefusalStop  RefusalAction = "stop"  // End the conversation with StopRefusal
+)
+
+const refusalNudge = "Your last reply declined to respond. If you can, answer the message in a way you are comfortable with rather than declining outright."
+
+// ParseRefusalAction validates a refusal action name; empty selects RefusalSkip
+func ParseRefusalAction(name string) (RefusalAction, error) {
+	switch RefusalAction(name) {
+	case "", RefusalSkip:
+		return RefusalSkip, nil
+	case RefusalNudge, RefusalStop:
+		return RefusalAction(name), nil
+	}
+	return "", fmt.Errorf("unknown refusal action %q (use %s, %s or %s)", name, RefusalSkip, RefusalNudge, RefusalStop)
+}
+
+// refusal returns why a reply counts as declined, or "" for an answer: the model's
+// refusal message, or the provider's content filter withholding it
+func refusal(meta providers.ResponseMeta) string {
+	switch {
+	case meta.Refusal != "":
+		return meta.Refusal
+	case meta.FinishReason == providers.FinishContentFilter:
+		return "withheld by the provider's content filter"
+	case meta.FinishReason == providers.FinishRefusal:
+		return "the model refused"
+	}
+	return ""
+}
+
+// nudgeRefusal asks the agent once more when its reply was declined and OnRefusal
+// is RefusalNudge, returning the new reply and the retry's tool calls. The second
+// reply stands either way, so a repeated refusal is then skipped.
+func (c *Conversation) nudgeRefusal(ctx context.Context, round, speaker int, req *providers.ChatRequest, content string, meta *providers.ResponseMeta, emit func(Event) bool) (string, []ToolUse, error) {
+	reason := refusal(*meta)
+	if c.opts.OnRefusal != RefusalNudge || reason == "" {
+		return content, nil, nil
+	}
+
+	agent := c.agents[speaker]
+	c.log.Info("reply declined, asking again", "round", round, "agent", agent.Name, "reason", reason)
+	err := fmt.Errorf("%w: %s", ErrRefused, reason)
+	if !emit(Event{Type: EventWarning, Round: round, Speaker: speaker, Agent: agent, Text: agent.Name + " declined to respond; asking again", Err: err}) {
+		return "", nil, ctx.Err()
+	}
+
+	retry := *req
+	n := len(req.Messages)
+	retry.Messages = append(req.Messages[:n:n], providers.Message{Role: "system", Content: refusalNudge})
+	var retryMeta providers.ResponseMeta
+	content, uses, err := c.respond(ctx, round, speaker, &retry, &retryMeta, emit)
+	if err != nil {
+		return "", uses, err
+	}
+	*meta = retryMeta
+	return content, uses, nil
+}
+
+// reportRefusals completes a round containing declined turns: each turn is tallied
+// and emitted, so transcripts record the refusal, and each refusal is reported as
+// an EventWarning. It reports whether the conversation should stop (RefusalStop),
+// and ok is false once emit fails.
+func (c *Conversation) reportRefusals(round int, turns []*Turn, emit func(Event) bool) (stop, ok bool) {
+	stop = c.opts.OnRefusal == RefusalStop
+	for _, turn := range turns {
+		agent := c.agents[turn.Speaker]
+		c.tally(turn)
+		if !emit(Event{Type: EventTurnComplete, Round: round, Speaker: turn.Speaker, Agent: agent, Turn: turn}) {
+			return stop, false
+		}
+		if turn.Refusal == "" {
+			continue
+		}
+
+		c.summary.Agents[turn.Speaker].Refusals++
+		c.log.Info("reply declined", "round", round, "agent", agent.Name, "reason", turn.Refusal, "action", c.opts.OnRefusal)
+		text := agent.Name + " declined to respond; skipping its turn"
+		if stop {
+			text = agent.Name + " declined to respond; ending the conversation"
+		}
+		if !emit(Event{Type: EventWarning, Round: round, Speaker: turn.Speaker, Agent: agent, Text: text, Err: fmt.Errorf("%w: %s", ErrRefused, turn.Refusal)}) {
+			return stop, false
+		}
+	}
+	return stop, true
+}
+
+// resumeText returns the message the next alternating turn after prior answers:
+// the last reply, or the prompt a trailing run of refused turns left unanswered
+func resumeText(starter string, prior []Turn) string {
+	text := starter
+	for _, turn := range prior {
+		if turn.Prompt != "" {
+			text = turn.Prompt
+		}
+		if turn.Refusal == "" {
+			text = turn.Content
+		}
+	}
+	return text
+}
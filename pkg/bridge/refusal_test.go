@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// refusingProvider declines the requests numbered in refuse (1-based) with a
+// refusal message and otherwise behaves like fakeProvider
+type refusingProvider struct {
+	fakeProvider
+	refuse map[int]bool
+	calls  int
+}
+
+func (p *refusingProvider) StreamChatMeta(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error, <-chan []providers.ToolCall, <-chan providers.ResponseMeta) {
+	p.calls++
+	metaChan := make(chan providers.ResponseMeta, 1)
+	defer close(metaChan)
+	if !p.refuse[p.calls] {
+		textChan, errChan := p.fakeProvider.StreamChat(ctx, req)
+		metaChan <- providers.ResponseMeta{FinishReason: providers.FinishStop}
+		return textChan, errChan, nil, metaChan
+	}
+
+	p.requests = append(p.requests, req)
+	textChan := make(chan string)
+	errChan := make(chan error)
+	close(textChan)
+	close(errChan)
+	metaChan <- providers.ResponseMeta{FinishReason: providers.FinishStop, Refusal: "I can't help with that."}
+	return textChan, errChan, nil, metaChan
+}
+
+func TestConversationSkipsRefusals(t *testing.T) {
+	a := &refusingProvider{fakeProvider: fakeProvider{replies: []string{"a1", "a2"}}, refuse: map[int]bool{2: true}}
+	b := &fakeProvider{replies: []string{"b1", "b2"}}
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, testOptions(4)).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	var turns []*Turn
+	warnings := 0
+	for _, ev := range events {
+		switch ev.Type {
+		case EventWarning:
+			warnings++
+			if ev.Round != 3 || ev.Agent == nil || ev.Agent.Name != "A" || !errors.Is(ev.Err, ErrRefused) {
+				t.Fatalf("unexpected warning: %+v", ev)
+			}
+		case EventTurnComplete:
+			turns = append(turns, ev.Turn)
+		}
+	}
+	if warnings != 1 || len(turns) != 4 || turns[2].Refusal != "I can't help with that." || done.Result.Summary.Agents[0].Refusals != 1 {
+		t.Fatalf("expected round 3 refused and recorded, got %d warnings, %+v", warnings, turns)
+	}
+
+	// B receives the prompt A declined, with no gap in its history
+	retry := b.requests[1].Messages
+	if n := len(retry); retry[n-1].Content != "b1" || retry[n-1].Role != "user" || retry[n-2].Role != "assistant" {
+		t.Fatalf("unexpected request after the refusal: %+v", retry)
+	}
+}
+
+func TestConversationStopsOnRefusal(t *testing.T) {
+	a := &refusingProvider{refuse: map[int]bool{1: true}}
+	opts := testOptions(4)
+	opts.OnRefusal = RefusalStop
+	_, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if done.Err != nil || done.Result.Reason != StopRefusal || done.Result.Rounds != 1 {
+		t.Fatalf("expected to stop after the refusal, got %+v", done)
+	}
+}
+
+func TestConversationNudgesRefusals(t *testing.T) {
+	a := &refusingProvider{fakeProvider: fakeProvider{replies: []string{"Fine, here goes."}}, refuse: map[int]bool{1: true}}
+	opts := testOptions(1)
+	opts.OnRefusal = RefusalNudge
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	var turn *Turn
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete {
+			turn = ev.Turn
+		}
+	}
+	if turn == nil || turn.Content != "Fine, here goes." || turn.Refusal != "" {
+		t.Fatalf("expected the nudged reply to stand, got %+v", turn)
+	}
+	retry := a.requests[1].Messages
+	if last := retry[len(retry)-1]; last.Role != "system" || last.Content != refusalNudge {
+		t.Fatalf("expected the retry to carry the nudge, got %+v", retry)
+	}
+}
+
+func TestResumeSkipsRefusedTurns(t *testing.T) {
+	opts := testOptions(4)
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Content: "a1"},
+		{Round: 2, Speaker: 1, Refusal: "withheld by the provider's content filter"},
+	}
+	b := &fakeProvider{replies: []string{"b2"}}
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{replies: []string{"a2"}}}, &Agent{Name: "B", Provider: b}, opts)
+	if history := conv.History(); len(history) != 2 {
+		t.Fatalf("expected the refused turn left out of history, got %+v", history)
+	}
+
+	// A answers the message B declined
+	_, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if history := conv.History(); history[2].Content != "a1" || history[3].Content != "a2" {
+		t.Fatalf("unexpected history after resuming: %+v", history)
+	}
+}
@@ -0,0 +1,60 @@
+package bridge
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// lastTurn is the latest turn of this run with the messages it was requested with,
+// kept so a reviewer can regenerate it
+type lastTurn struct {
+	turn     *Turn
+	messages []providers.Message
+}
+
+// regenerate discards the previous turn and requests it again from the same agent
+// with the same messages, at temperature when set. The new turn replaces the old
+// one in history, the summary, and the director's view, and is emitted with
+// Turn.Regenerated set. A failed attempt keeps the old turn; ok is false only if
+// ctx was cancelled.
+func (c *Conversation) regenerate(ctx context.Context, temperature *float64, emit func(Event) bool) (turn *Turn, ok bool) {
+	old := c.last.turn
+	agent := c.agents[old.Speaker]
+	c.log.Debug("regenerating turn", "round", old.Round, "agent", agent.Name, "temperature", temperature)
+	if !emit(Event{Type: EventTurnStart, Round: old.Round, Speaker: old.Speaker, Agent: agent}) {
+		return nil, false
+	}
+
+	turn, err := c.streamTurn(ctx, old.Round, old.Speaker, c.last.messages, temperature, emit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		c.log.Warn("regeneration failed", "round", old.Round, "agent", agent.Name, "error", err)
+		return old, emit(Event{Type: EventWarning, Round: old.Round, Speaker: old.Speaker, Agent: agent, Text: "Couldn't regenerate " + agent.Name + "'s reply; keeping it", Err: err})
+	}
+	turn.Reinforced, turn.Direction, turn.Prompt = old.Reinforced, old.Direction, old.Prompt
+	turn.Regenerated = true
+
+	// The old reply is the latest history entry until the next prompt is added
+	c.history[len(c.history)-1] = providers.Message{Role: "assistant", Content: turn.Content}
+	c.retally(old, turn)
+	if n := len(c.recent); n > 0 && c.recent[n-1].Round == old.Round && c.recent[n-1].Speaker == old.Speaker {
+		c.recent[n-1] = *turn
+	}
+	c.remember(ctx, turn, emit)
+	c.last.turn = turn
+
+	return turn, emit(Event{Type: EventTurnComplete, Round: turn.Round, Speaker: turn.Speaker, Agent: agent, Turn: turn})
+}
+
+// retally replaces old with turn in the running totals. The discarded reply's
+// tokens stay counted, since the provider billed them.
+func (c *Conversation) retally(old, turn *Turn) {
+	c.tally(turn)
+	s := &c.summary.Agents[turn.Speaker]
+	s.Messages--
+	s.Characters -= utf8.RuneCountInString(old.Content)
+}
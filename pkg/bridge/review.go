@@ -0,0 +1,85 @@
+package bridge
+
+import (
+	"context"
+)
+
+// ReviewAction is a reviewer's answer to a paused prompt
+type ReviewAction int
+
+const (
+	ReviewAccept     ReviewAction = iota // Send the prompt as proposed
+	ReviewEdit                           // Send Decision.Prompt instead
+	ReviewSkip                           // Send the prompt as proposed and stop pausing for the rest of the run
+	ReviewRegenerate                     // Discard the reply the prompt came from, request it again, and review the new one
+)
+
+// Decision answers an EventReview
+type Decision struct {
+	Action      ReviewAction
+	Prompt      string   // Replacement prompt for ReviewEdit
+	Temperature *float64 // Temperature for ReviewRegenerate; nil keeps the agent's
+}
+
+// Review is a prompt waiting for approval before it is sent to the event's agent
+type Review struct {
+	Prompt      string // The message the agent is about to receive
+	Regenerable bool   // The prompt is a reply from this run, so ReviewRegenerate can replace it
+
+	decided chan Decision
+}
+
+// Decide answers the review and lets the conversation continue. Only the first
+// call counts.
+func (r *Review) Decide(d Decision) {
+	select {
+	case r.decided <- d:
+	default:
+	}
+}
+
+// review pauses for a Decision on every ReviewEvery-th round, replacing *prompt
+// when it is edited or regenerated; a regenerated prompt is reviewed again. It
+// reports whether the prompt was edited, and false for ok if ctx was cancelled
+// while waiting. Regeneration requests run under reqCtx.
+func (c *Conversation) review(ctx, reqCtx context.Context, round, speaker int, prompt *string, emit func(Event) bool) (edited, ok bool) {
+	if c.opts.ReviewEvery <= 0 || c.unreviewed || round%c.opts.ReviewEvery != 0 {
+		return false, true
+	}
+
+	for {
+		r := &Review{Prompt: *prompt, Regenerable: c.last != nil, decided: make(chan Decision, 1)}
+		if !emit(Event{Type: EventReview, Round: round, Speaker: speaker, Agent: c.agents[speaker], Review: r}) {
+			return false, false
+		}
+
+		var d Decision
+		select {
+		case d = <-r.decided:
+		case <-ctx.Done():
+			return false, false
+		}
+		c.log.Debug("prompt reviewed", "round", round, "agent", c.agents[speaker].Name, "action", d.Action)
+
+		switch d.Action {
+		case ReviewEdit:
+			if d.Prompt != *prompt {
+				*prompt = d.Prompt
+				return true, true
+			}
+		case ReviewSkip:
+			c.unreviewed = true
+		case ReviewRegenerate:
+			if !r.Regenerable {
+				continue
+			}
+			turn, ok := c.regenerate(reqCtx, d.Temperature, emit)
+			if !ok {
+				return false, false
+			}
+			*prompt = turn.Content
+			continue
+		}
+		return false, true
+	}
+}
@@ -0,0 +1,153 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// reviewing answers each EventReview with decide as the events arrive, like a
+// person at the terminal would
+func reviewing(events <-chan Event, decide func(ev Event) Decision) <-chan Event {
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for ev := range events {
+			if ev.Type == EventReview {
+				ev.Review.Decide(decide(ev))
+			}
+			out <- ev
+		}
+	}()
+	return out
+}
+
+func TestConversationPausesForReview(t *testing.T) {
+	a := &fakeProvider{replies: []string{"first from A", "second from A"}}
+	b := &fakeProvider{replies: []string{"first from B", "second from B"}}
+	opts := testOptions(4)
+	opts.ReviewEvery = 2
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	var reviewed []int
+	events, done := collect(t, reviewing(conv.Run(context.Background()), func(ev Event) Decision {
+		reviewed = append(reviewed, ev.Round)
+		if ev.Round == 2 {
+			if ev.Review.Prompt != "first from A" || ev.Speaker != 1 {
+				t.Errorf("expected B's incoming message to be proposed, got %q for speaker %d", ev.Review.Prompt, ev.Speaker)
+			}
+			return Decision{Action: ReviewEdit, Prompt: "edited by a person"}
+		}
+		return Decision{Action: ReviewAccept}
+	}))
+	if done.Err != nil || done.Result.Rounds != 4 {
+		t.Fatalf("expected 4 rounds, got %+v", done)
+	}
+	if len(reviewed) != 2 || reviewed[0] != 2 || reviewed[1] != 4 {
+		t.Fatalf("expected reviews at rounds 2 and 4, got %v", reviewed)
+	}
+
+	msgs := b.requests[0].Messages
+	if last := msgs[len(msgs)-1].Content; last != "edited by a person" {
+		t.Fatalf("expected B to receive the edited prompt, got %q", last)
+	}
+	for _, ev := range events {
+		if ev.Type != EventTurnComplete {
+			continue
+		}
+		if want := map[int]string{2: "edited by a person"}[ev.Round]; ev.Turn.Prompt != want {
+			t.Fatalf("round %d: expected recorded prompt %q, got %q", ev.Round, want, ev.Turn.Prompt)
+		}
+	}
+}
+
+func TestConversationReviewSkipStopsPausing(t *testing.T) {
+	opts := testOptions(4)
+	opts.ReviewEvery = 1
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	reviews := 0
+	_, done := collect(t, reviewing(conv.Run(context.Background()), func(ev Event) Decision {
+		reviews++
+		return Decision{Action: ReviewSkip}
+	}))
+	if done.Err != nil || done.Result.Rounds != 4 {
+		t.Fatalf("expected the run to finish unattended, got %+v", done)
+	}
+	if reviews != 1 {
+		t.Fatalf("expected a single review before skipping the rest, got %d", reviews)
+	}
+}
+
+func TestConversationRestoresEditedPrompts(t *testing.T) {
+	a := &fakeProvider{replies: []string{"third from A"}}
+	opts := testOptions(3)
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Agent: "A", Content: "first from A"},
+		{Round: 2, Speaker: 1, Agent: "B", Content: "second from B", Prompt: "edited by a person"},
+	}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts)
+
+	if history := conv.History(); history[2].Content != "edited by a person" {
+		t.Fatalf("expected the edited prompt in the rebuilt history, got %+v", history)
+	}
+	if _, done := collect(t, conv.Run(context.Background())); done.Err != nil {
+		t.Fatalf("unexpected error: %v", done.Err)
+	}
+}
+
+func TestConversationReviewRegeneratesLastTurn(t *testing.T) {
+	a := &fakeProvider{replies: []string{"first from A", "retry from A"}}
+	b := &fakeProvider{replies: []string{"first from B"}}
+	opts := testOptions(2)
+	opts.ReviewEvery = 1
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	var prompts []string
+	var regenerable []bool
+	events, done := collect(t, reviewing(conv.Run(context.Background()), func(ev Event) Decision {
+		prompts = append(prompts, ev.Review.Prompt)
+		regenerable = append(regenerable, ev.Review.Regenerable)
+		if ev.Review.Prompt == "first from A" {
+			return Decision{Action: ReviewRegenerate, Temperature: providers.Float(1.3)}
+		}
+		return Decision{Action: ReviewAccept}
+	}))
+	if done.Err != nil || done.Result.Rounds != 2 {
+		t.Fatalf("expected 2 rounds, got %+v", done)
+	}
+
+	// The starter can't be regenerated; the new reply is reviewed in place of the old
+	if len(prompts) != 3 || prompts[1] != "first from A" || prompts[2] != "retry from A" || regenerable[0] || !regenerable[2] {
+		t.Fatalf("unexpected reviews: %q %v", prompts, regenerable)
+	}
+	if len(a.requests) != 2 || len(a.requests[1].Messages) != len(a.requests[0].Messages) || *a.requests[1].Temperature != 1.3 {
+		t.Fatalf("expected A re-asked with the same messages at the chosen temperature, got %+v", a.requests)
+	}
+	msgs := b.requests[0].Messages
+	if last := msgs[len(msgs)-1].Content; last != "retry from A" {
+		t.Fatalf("expected B to answer the regenerated reply, got %q", last)
+	}
+	for _, msg := range conv.History() {
+		if msg.Content == "first from A" {
+			t.Fatalf("the discarded reply stayed in history: %+v", conv.History())
+		}
+	}
+
+	var completed []string
+	for _, ev := range events {
+		if ev.Type == EventTurnComplete {
+			completed = append(completed, ev.Turn.Content)
+			if ev.Turn.Regenerated != (ev.Turn.Content == "retry from A") {
+				t.Fatalf("unexpected Regenerated flag on %+v", ev.Turn)
+			}
+		}
+	}
+	if len(completed) != 3 {
+		t.Fatalf("expected the original, regenerated, and B's turns, got %q", completed)
+	}
+	if s := done.Result.Summary.Agents[0]; s.Messages != 1 || s.Characters != len("retry from A") {
+		t.Fatalf("expected the summary to count only the kept reply, got %+v", s)
+	}
+}
@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"slices"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// alternateRoles fits messages to a provider with ProviderSpec.StrictAlternation.
+// System messages before the first user or assistant message are left for the
+// provider to lift into its system prompt; later ones (directions, nudges,
+// reinforced prompts) are re-labelled as user messages. Consecutive user or
+// assistant messages are then merged into one, their contents joined by a blank
+// line. Tool calls and results are never merged. It reports whether anything
+// changed; the caller's slice is left untouched either way.
+func alternateRoles(messages []providers.Message) ([]providers.Message, bool) {
+	fitted := make([]providers.Message, 0, len(messages))
+	changed, started := false, false
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			if !started {
+				fitted = append(fitted, msg)
+				continue
+			}
+			msg.Role = "user"
+			changed = true
+		}
+		started = true
+
+		last := len(fitted) - 1
+		if last >= 0 && mergeable(fitted[last], msg) {
+			prev := &fitted[last]
+			prev.Content = joinContent(prev.Content, msg.Content)
+			if len(msg.Images) > 0 {
+				prev.Images = append(slices.Clip(prev.Images), msg.Images...)
+			}
+			changed = true
+			continue
+		}
+		fitted = append(fitted, msg)
+	}
+	if !changed {
+		return messages, false
+	}
+	return fitted, true
+}
+
+// mergeable reports whether next can be folded into prev: both user or both
+// assistant messages, neither part of a tool exchange
+func mergeable(prev, next providers.Message) bool {
+	if prev.Role != next.Role || (prev.Role != "user" && prev.Role != "assistant") {
+		return false
+	}
+	return len(prev.ToolCalls) == 0 && len(next.ToolCalls) == 0
+}
+
+// joinContent joins two message contents with a blank line, skipping empty ones
+func joinContent(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n\n" + b
+}
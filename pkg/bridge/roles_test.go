@@ -0,0 +1,111 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// alternationError reports the first break in Anthropic's rule: after any leading
+// system messages, user and assistant messages alternate, starting with user
+func alternationError(messages []providers.Message) error {
+	want := "user"
+	for i, msg := range messages {
+		if msg.Role == "system" && i == 0 || msg.Role == "system" && messages[i-1].Role == "system" {
+			continue
+		}
+		if msg.Role != want {
+			return fmt.Errorf("message %d is %s, want %s", i, msg.Role, want)
+		}
+		want = map[string]string{"user": "assistant", "assistant": "user"}[want]
+	}
+	return nil
+}
+
+func TestAlternateRoles(t *testing.T) {
+	history := []providers.Message{
+		{Role: "system", Content: "Shared context"},
+		{Role: "user", Content: "Hello there", Images: []providers.Image{{MediaType: "image/png"}}},
+		{Role: "user", Content: "Anyone?"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "system", Content: "Be A"},
+		{Role: "system", Content: directionNote + "Wrap up"},
+		{Role: "user", Content: "b2"},
+	}
+	original := slices.Clone(history)
+	if err := alternationError(history); err == nil {
+		t.Fatal("expected the synthetic history to break the alternation")
+	}
+
+	fitted, changed := alternateRoles(history)
+	if !changed {
+		t.Fatal("expected the history to be changed")
+	}
+	if err := alternationError(fitted); err != nil {
+		t.Fatalf("history still breaks the alternation: %v\n%+v", err, fitted)
+	}
+	want := []string{"Shared context", "Hello there\n\nAnyone?", "a1\n\na2", "Be A\n\n" + directionNote + "Wrap up\n\nb2"}
+	var got []string
+	for _, msg := range fitted {
+		got = append(got, msg.Content)
+	}
+	if !slices.Equal(got, want) || len(fitted[1].Images) != 1 {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if history[1].Content != original[1].Content || history[5].Role != "system" {
+		t.Fatal("the caller's history was modified")
+	}
+
+	if _, changed := alternateRoles(fitted); changed {
+		t.Fatal("expected an alternating history to be left alone")
+	}
+}
+
+func TestAlternateRolesKeepsToolExchanges(t *testing.T) {
+	history := []providers.Message{
+		{Role: "user", Content: "What time is it?"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Name: "clock"}}},
+		{Role: "tool", Content: "noon", ToolCallID: "1"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "2", Name: "clock"}}},
+		{Role: "tool", Content: "still noon", ToolCallID: "2"},
+	}
+	if _, changed := alternateRoles(history); changed {
+		t.Fatal("expected tool calls and results to be left alone")
+	}
+}
+
+func TestConversationAlternatesRolesForStrictProviders(t *testing.T) {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "fake-strict", Name: "Fake Strict", StrictAlternation: true})
+	strict := &fakeProvider{name: "fake-strict", replies: []string{"a1", "a3"}}
+	lenient := &fakeProvider{replies: []string{"b2"}}
+
+	opts := testOptions(3)
+	opts.Context = "Shared context"
+	opts.ReinforceEvery = 1
+	conv := New(
+		&Agent{Name: "A", Provider: strict, SystemPrompt: "Be A"},
+		&Agent{Name: "B", Provider: lenient, SystemPrompt: "Be B"},
+		opts,
+	)
+	if _, done := collect(t, conv.Run(context.Background())); done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	// The shared context leads as a system message; A's reinforced prompt is folded into B's reply
+	for _, req := range strict.requests {
+		if err := alternationError(req.Messages); err != nil {
+			t.Fatalf("strict provider sent a broken alternation: %v\n%+v", err, req.Messages)
+		}
+	}
+	sent := strict.requests[1].Messages
+	if got := systemMessages(sent); len(got) != 1 || got[0] != "Shared context" || sent[len(sent)-1].Content != "Be A\n\nb2" {
+		t.Fatalf("expected the reinforced prompt merged into the incoming message, got %+v", sent)
+	}
+	if history := conv.History(); len(systemMessages(history)) != 0 || history[len(history)-1].Content != "a3" {
+		t.Fatalf("the merge leaked into the shared history: %+v", history)
+	}
+}
@@ -0,0 +1,90 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// DefaultRoundNote is the round note template used when none is given
+const DefaultRoundNote = "Round {{.Round}} of {{.MaxRounds}}."
+
+// RoundNoteData is what a round note template is executed with. Its rounds are
+// counted in units of Options.RoundNoteTurns turns, as the terminal shows them.
+type RoundNoteData struct {
+	Round     int    // Round the agent is about to answer, counting from 1
+	MaxRounds int    // Last round of the run (Options.MaxRounds)
+	Remaining int    // Rounds left after this one; 0 in the last
+	Agent     string // Name of the agent receiving the note
+}
+
+// RoundNote tells agents where the conversation stands, e.g. "Round 3 of 10".
+// It is rendered from a text/template over RoundNoteData for each request.
+type RoundNote struct {
+	text string
+	tmpl *template.Template
+}
+
+// ParseRoundNote parses a round note template; empty selects DefaultRoundNote. The
+// template is tried on a sample round, so a misspelled field fails here rather than
+// mid-conversation.
+func ParseRoundNote(text string) (*RoundNote, error) {
+	if text == "" {
+		text = DefaultRoundNote
+	}
+	tmpl, err := template.New("round note").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid round note template: %w", err)
+	}
+	note := &RoundNote{text: text, tmpl: tmpl}
+	sample, err := note.Render(RoundNoteData{Round: 3, MaxRounds: 10, Remaining: 7, Agent: "Agent A"})
+	if err != nil {
+		return nil, err
+	}
+	if sample == "" {
+		return nil, fmt.Errorf("round note template renders nothing")
+	}
+	return note, nil
+}
+
+// String returns the template text
+func (n *RoundNote) String() string {
+	return n.text
+}
+
+// Render executes the template for one request
+func (n *RoundNote) Render(data RoundNoteData) (string, error) {
+	var b strings.Builder
+	if err := n.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid round note template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// withRoundNote adds Options.RoundNote for this request as a system message just
+// before the incoming one. Like the loop nudge it is sent only: history, events and
+// transcripts never see it.
+func (c *Conversation) withRoundNote(round, speaker int, messages []providers.Message) []providers.Message {
+	if c.opts.RoundNote == nil || len(messages) == 0 {
+		return messages
+	}
+	per := max(c.opts.RoundNoteTurns, 1)
+	counted, total := (round+per-1)/per, (c.opts.MaxRounds+per-1)/per
+	note, err := c.opts.RoundNote.Render(RoundNoteData{
+		Round:     counted,
+		MaxRounds: total,
+		Remaining: max(total-counted, 0),
+		Agent:     c.agents[speaker].Name,
+	})
+	if err != nil || note == "" {
+		c.log.Warn("round note skipped", "round", round, "error", err)
+		return messages
+	}
+
+	last := len(messages) - 1
+	noted := make([]providers.Message, 0, len(messages)+1)
+	noted = append(noted, messages[:last]...)
+	return append(noted, providers.Message{Role: "system", Content: note}, messages[last])
+}
@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestParseRoundNote(t *testing.T) {
+	note, err := ParseRoundNote("")
+	if err != nil || note.String() != DefaultRoundNote {
+		t.Fatalf("expected the default note, got %v, %v", note, err)
+	}
+	if got, _ := note.Render(RoundNoteData{Round: 3, MaxRounds: 10}); got != "Round 3 of 10." {
+		t.Fatalf("default note rendered %q", got)
+	}
+
+	for _, text := range []string{"Round {{.Round", "Round {{.Turn}}", "{{if false}}x{{end}}"} {
+		if _, err := ParseRoundNote(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
+
+func TestConversationSendsRoundNotesTransiently(t *testing.T) {
+	for _, mode := range []Mode{ModeAlternating, ModeSimultaneous} {
+		a := &fakeProvider{replies: []string{"a1", "a2"}}
+		b := &fakeProvider{replies: []string{"b1", "b2"}}
+		opts := testOptions(2)
+		opts.Mode = mode
+		opts.RoundNote, _ = ParseRoundNote("{{.Agent}}: round {{.Round}}/{{.MaxRounds}}, {{.Remaining}} left.")
+		conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+		events, done := collect(t, conv.Run(context.Background()))
+		if done.Err != nil {
+			t.Fatalf("%s: %v", mode, done.Err)
+		}
+
+		// The note sits just before the incoming message, for that request only
+		last := b.requests[len(b.requests)-1].Messages
+		if note := last[len(last)-2]; note.Role != "system" || note.Content != "B: round 2/2, 0 left." {
+			t.Fatalf("%s: unexpected note %+v", mode, note)
+		}
+		if notes := systemNotes(last, "round"); len(notes) != 1 {
+			t.Fatalf("%s: earlier notes were resent: %q", mode, notes)
+		}
+
+		if notes := systemNotes(conv.History(), "round"); len(notes) != 0 {
+			t.Fatalf("%s: note leaked into history: %q", mode, notes)
+		}
+		for _, ev := range events {
+			if ev.Type == EventTurnComplete && strings.Contains(ev.Turn.Content, "round") {
+				t.Fatalf("%s: note leaked into turn %d: %q", mode, ev.Turn.Round, ev.Turn.Content)
+			}
+		}
+	}
+}
+
+// systemNotes returns the system messages containing substr
+func systemNotes(msgs []providers.Message, substr string) []string {
+	var notes []string
+	for _, content := range systemMessages(msgs) {
+		if strings.Contains(content, substr) {
+			notes = append(notes, content)
+		}
+	}
+	return notes
+}
+
+func TestRoundNotesCountExchanges(t *testing.T) {
+	a := &fakeProvider{replies: []string{"a1", "a2"}}
+	b := &fakeProvider{replies: []string{"b1", "b2"}}
+	opts := testOptions(4)
+	opts.RoundNote, _ = ParseRoundNote("{{.Agent}}: {{.Round}}/{{.MaxRounds}}, {{.Remaining}} left.")
+	opts.RoundNoteTurns = 2
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts)
+
+	if _, done := collect(t, conv.Run(context.Background())); done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	var got []string
+	for _, p := range []*fakeProvider{a, b} {
+		for _, req := range p.requests {
+			got = append(got, systemNotes(req.Messages, "left")...)
+		}
+	}
+	want := []string{"A: 1/2, 1 left.", "A: 2/2, 0 left.", "B: 1/2, 1 left.", "B: 2/2, 0 left."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got notes %q, want %q", got, want)
+	}
+}
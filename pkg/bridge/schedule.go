@@ -0,0 +1,141 @@
+package bridge
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ModelRange selects a model for rounds First through Last (1-based, inclusive)
+type ModelRange struct {
+	First, Last int
+	Model       string
+}
+
+// ModelSchedule switches an agent's model by round, e.g. a cheap model for the
+// opening rounds and a stronger one for the finale. Validate checks that it covers
+// the whole run; an uncovered round would fall back to the agent's Model.
+type ModelSchedule []ModelRange
+
+// ParseModelSchedule parses a schedule such as "1-8:gpt-4o-mini,9-10:gpt-4o"; a
+// single round may be written alone ("10:gpt-4o"). Ranges must not overlap.
+// Model IDs may contain colons (llama3.1:8b), since only the first one separates
+// the rounds.
+func ParseModelSchedule(spec string) (ModelSchedule, error) {
+	var schedule ModelSchedule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rounds, model, ok := strings.Cut(entry, ":")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			return nil, fmt.Errorf("%q: expected ROUNDS:MODEL, e.g. 1-8:gpt-4o-mini", entry)
+		}
+		r := ModelRange{Model: model}
+		first, last, isRange := strings.Cut(rounds, "-")
+		var err error
+		if r.First, err = strconv.Atoi(strings.TrimSpace(first)); err != nil || r.First < 1 {
+			return nil, fmt.Errorf("%q: rounds must be a number or range of numbers from 1", entry)
+		}
+		r.Last = r.First
+		if isRange {
+			if r.Last, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || r.Last < r.First {
+				return nil, fmt.Errorf("%q: a range must end at or after its first round", entry)
+			}
+		}
+		schedule = append(schedule, r)
+	}
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("empty model schedule")
+	}
+
+	slices.SortFunc(schedule, func(a, b ModelRange) int { return a.First - b.First })
+	for i := 1; i < len(schedule); i++ {
+		if prev, r := schedule[i-1], schedule[i]; r.First <= prev.Last {
+			return nil, fmt.Errorf("rounds %s (%s) and %s (%s) overlap", prev.rounds(), prev.Model, r.rounds(), r.Model)
+		}
+	}
+	return schedule, nil
+}
+
+// Validate checks that the schedule covers every round of a run of maxRounds
+func (s ModelSchedule) Validate(maxRounds int) error {
+	for round := 1; round <= maxRounds; round++ {
+		if _, ok := s.Model(round); !ok {
+			return fmt.Errorf("round %d has no model; the schedule must cover rounds 1-%d", round, maxRounds)
+		}
+	}
+	return nil
+}
+
+// Extend stretches the schedule's last range through maxRounds, so a resumed run
+// given more rounds than it was written for carries on with the finale's model
+func (s ModelSchedule) Extend(maxRounds int) ModelSchedule {
+	if len(s) == 0 || s[len(s)-1].Last >= maxRounds {
+		return s
+	}
+	extended := slices.Clone(s)
+	extended[len(extended)-1].Last = maxRounds
+	return extended
+}
+
+// InTurns converts a schedule written in rounds of turnsPerRound turns each, such as
+// exchanges of two, to the engine's rounds of one turn: exchanges 2-3 become rounds 3-6
+func (s ModelSchedule) InTurns(turnsPerRound int) ModelSchedule {
+	if turnsPerRound <= 1 {
+		return s
+	}
+	converted := make(ModelSchedule, len(s))
+	for i, r := range s {
+		converted[i] = ModelRange{First: (r.First-1)*turnsPerRound + 1, Last: r.Last * turnsPerRound, Model: r.Model}
+	}
+	return converted
+}
+
+// Model returns the model scheduled for round, if any
+func (s ModelSchedule) Model(round int) (string, bool) {
+	for _, r := range s {
+		if round >= r.First && round <= r.Last {
+			return r.Model, true
+		}
+	}
+	return "", false
+}
+
+// Models returns the distinct scheduled models in round order
+func (s ModelSchedule) Models() []string {
+	var models []string
+	for _, r := range s {
+		if !slices.Contains(models, r.Model) {
+			models = append(models, r.Model)
+		}
+	}
+	return models
+}
+
+// String formats the schedule as ParseModelSchedule accepts it
+func (s ModelSchedule) String() string {
+	entries := make([]string, len(s))
+	for i, r := range s {
+		entries[i] = r.rounds() + ":" + r.Model
+	}
+	return strings.Join(entries, ",")
+}
+
+func (r ModelRange) rounds() string {
+	if r.First == r.Last {
+		return strconv.Itoa(r.First)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// modelFor returns the model the agent uses in round: its scheduled one, else Model
+func (a *Agent) modelFor(round int) string {
+	if model, ok := a.ModelSchedule.Model(round); ok {
+		return model
+	}
+	return a.Model
+}
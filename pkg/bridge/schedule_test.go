@@ -0,0 +1,103 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseModelSchedule(t *testing.T) {
+	schedule, err := ParseModelSchedule("9-10:gpt-4o, 1-8:gpt-4o-mini,11:llama3.1:8b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.String(); got != "1-8:gpt-4o-mini,9-10:gpt-4o,11:llama3.1:8b" {
+		t.Fatalf("unexpected schedule %s", got)
+	}
+	if err := schedule.Validate(11); err != nil {
+		t.Fatal(err)
+	}
+	if err := schedule.Validate(12); err == nil || !strings.Contains(err.Error(), "round 12") {
+		t.Fatalf("expected round 12 to be uncovered, got %v", err)
+	}
+
+	for _, spec := range []string{"", "1-8", "1-8:", "0-3:gpt-4o", "5-2:gpt-4o", "a-b:gpt-4o", "1-5:gpt-4o-mini,5-6:gpt-4o"} {
+		if _, err := ParseModelSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+	if err := (ModelSchedule{{First: 1, Last: 2, Model: "a"}, {First: 4, Last: 5, Model: "b"}}).Validate(5); err == nil || !strings.Contains(err.Error(), "round 3") {
+		t.Fatalf("expected the gap at round 3, got %v", err)
+	}
+}
+
+func TestConversationSwitchesModelsBySchedule(t *testing.T) {
+	a := &fakeProvider{replies: []string{"one", "two", "three"}}
+	b := &fakeProvider{replies: []string{"uno", "dos", "tres"}}
+	schedule, _ := ParseModelSchedule("1-3:cheap,4-6:strong")
+	opts := testOptions(6)
+	conv := New(&Agent{Name: "A", Provider: a, Model: "default", ModelSchedule: schedule}, &Agent{Name: "B", Provider: b, Model: "b-model"}, opts)
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	for i, want := range []string{"cheap", "cheap", "strong"} {
+		if got := a.requests[i].Model; got != want {
+			t.Errorf("A's request %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+	for _, ev := range events {
+		if ev.Type != EventTurnComplete || ev.Speaker != 0 {
+			continue
+		}
+		want := "strong"
+		if ev.Round <= 3 {
+			want = "cheap"
+		}
+		if ev.Turn.Model != want {
+			t.Errorf("round %d: expected %s recorded, got %s", ev.Round, want, ev.Turn.Model)
+		}
+	}
+	if got := done.Result.Summary.Agents[0].Model; got != "cheap, strong" {
+		t.Fatalf("expected the summary to list both models, got %q", got)
+	}
+	if got := done.Result.Summary.Agents[1].Model; got != "b-model" {
+		t.Fatalf("unexpected model for B: %q", got)
+	}
+}
+
+func TestModelScheduleExtend(t *testing.T) {
+	schedule := ModelSchedule{{First: 1, Last: 8, Model: "gpt-4o-mini"}, {First: 9, Last: 10, Model: "gpt-4o"}}
+	extended := schedule.Extend(14)
+	if got := extended.String(); got != "1-8:gpt-4o-mini,9-14:gpt-4o" {
+		t.Fatalf("unexpected extended schedule %s", got)
+	}
+	if err := extended.Validate(14); err != nil {
+		t.Fatal(err)
+	}
+	if schedule[1].Last != 10 {
+		t.Fatal("Extend modified the original schedule")
+	}
+	if got := schedule.Extend(6).String(); got != schedule.String() {
+		t.Fatalf("expected a covering schedule unchanged, got %s", got)
+	}
+}
+
+func TestModelScheduleInTurns(t *testing.T) {
+	schedule, err := ParseModelSchedule("1-4:gpt-4o-mini,5:gpt-4o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	turns := schedule.InTurns(2)
+	if got := turns.String(); got != "1-8:gpt-4o-mini,9-10:gpt-4o" {
+		t.Fatalf("unexpected schedule in turns %s", got)
+	}
+	// Five exchanges are ten turns, each of them covered
+	if err := turns.Validate(10); err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.InTurns(1).String(); got != schedule.String() {
+		t.Fatalf("expected a schedule in turns unchanged, got %s", got)
+	}
+}
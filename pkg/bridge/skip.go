@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"context"
+)
+
+// maxConsecutiveErrors is how many failed turns in a row ContinueOnError skips
+// before the run ends with StopError anyway
+const maxConsecutiveErrors = 3
+
+// skipFailure decides whether a failed round continues under ContinueOnError,
+// counting the failure against the speakers whose turns failed. Cancellation and
+// the session deadline always end the run, as does the maxConsecutiveErrors-th
+// failure in a row.
+func (c *Conversation) skipFailure(ctx context.Context, round int, speakers []int, err error, emit func(Event) bool) bool {
+	if !c.opts.ContinueOnError || ctx.Err() != nil {
+		return false
+	}
+	c.failures++
+	if c.failures >= maxConsecutiveErrors {
+		c.log.Warn("too many consecutive failures", "round", round, "failures", c.failures, "error", err)
+		return false
+	}
+
+	for _, speaker := range speakers {
+		agent := c.agents[speaker]
+		c.summary.Agents[speaker].Errors++
+		c.log.Warn("turn failed, skipping", "round", round, "agent", agent.Name, "error", err)
+	}
+	ev := Event{Type: EventWarning, Round: round, Text: "Round failed; skipping it", Err: err}
+	if len(speakers) == 1 {
+		ev.Speaker, ev.Agent = speakers[0], c.agents[speakers[0]]
+		ev.Text = ev.Agent.Name + "'s turn failed; skipping it"
+	}
+	return emit(ev)
+}
+
+// dropIncoming removes the speaker's unanswered incoming message from history,
+// along with the system prompt re-injected before it, so the next agent receives
+// the message instead
+func (c *Conversation) dropIncoming(speaker int, reinforced bool) {
+	c.history = c.history[:len(c.history)-1]
+	c.last = nil
+	if reinforced {
+		c.reinforced[speaker] = c.reinforced[speaker][:len(c.reinforced[speaker])-1]
+	}
+}
+
+// PriorRounds returns how many rounds the prior turns of a resumed run completed.
+// A turn skipped under ContinueOnError leaves no record, so this is the last
+// turn's round rather than a count of turns; turns without rounds are counted.
+func PriorRounds(prior []Turn, mode Mode) int {
+	if len(prior) == 0 {
+		return 0
+	}
+	if last := prior[len(prior)-1]; last.Round > 0 {
+		return last.Round
+	}
+	if mode == ModeSimultaneous {
+		return len(prior) / 2
+	}
+	return len(prior)
+}
+
+// nextSpeaker returns who answers the round after the prior turns: the agent that
+// didn't speak last, which after a skipped turn isn't the one the count suggests
+func nextSpeaker(prior []Turn) int {
+	if len(prior) == 0 {
+		return 0
+	}
+	if last := prior[len(prior)-1]; last.Round > 0 {
+		return 1 - last.Speaker
+	}
+	return len(prior) % 2
+}
@@ -0,0 +1,117 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// flakyProvider fails the requests numbered in fail (1-based) and otherwise
+// behaves like fakeProvider
+type flakyProvider struct {
+	fakeProvider
+	fail  map[int]bool
+	calls int
+}
+
+func (p *flakyProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	p.calls++
+	if !p.fail[p.calls] {
+		return p.fakeProvider.StreamChat(ctx, req)
+	}
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	errChan <- errors.New("502 bad gateway")
+	close(errChan)
+	close(textChan)
+	return textChan, errChan
+}
+
+func TestConversationSkipsFailedTurns(t *testing.T) {
+	a := &flakyProvider{fakeProvider: fakeProvider{replies: []string{"a1", "a2"}}, fail: map[int]bool{2: true}}
+	b := &fakeProvider{replies: []string{"b1", "b2"}}
+	opts := testOptions(4)
+	opts.ContinueOnError = true
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+
+	warnings, turns := 0, 0
+	for _, ev := range events {
+		switch ev.Type {
+		case EventWarning:
+			warnings++
+			if ev.Round != 3 || ev.Agent == nil || ev.Agent.Name != "A" {
+				t.Fatalf("unexpected warning: %+v", ev)
+			}
+		case EventTurnComplete:
+			turns++
+		}
+	}
+	if warnings != 1 || turns != 3 || done.Result.Rounds != 4 || done.Result.Summary.Agents[0].Errors != 1 {
+		t.Fatalf("expected round 3 skipped, got %d warnings, %d turns, %+v", warnings, turns, done.Result)
+	}
+
+	// B receives the prompt A failed to answer, with no gap in its history
+	retry := b.requests[1].Messages
+	if n := len(retry); retry[n-1].Content != "b1" || retry[n-1].Role != "user" || retry[n-2].Role != "assistant" {
+		t.Fatalf("unexpected request after the skip: %+v", retry)
+	}
+}
+
+func TestConversationStopsAfterConsecutiveFailures(t *testing.T) {
+	boom := errors.New("boom")
+	opts := testOptions(10)
+	opts.ContinueOnError = true
+	conv := New(&Agent{Name: "A", Provider: &fakeProvider{err: boom}}, &Agent{Name: "B", Provider: &fakeProvider{err: boom}}, opts)
+
+	_, done := collect(t, conv.Run(context.Background()))
+	if !errors.Is(done.Err, boom) || done.Result.Reason != StopError || done.Round != maxConsecutiveErrors {
+		t.Fatalf("expected to stop at round %d, got %+v", maxConsecutiveErrors, done)
+	}
+}
+
+func TestSimultaneousConversationSkipsFailedRounds(t *testing.T) {
+	a := &flakyProvider{fakeProvider: fakeProvider{replies: []string{"a1", "a2"}}, fail: map[int]bool{1: true}}
+	b := &fakeProvider{replies: []string{"b-discarded", "b1"}}
+	opts := testOptions(2)
+	opts.Mode = ModeSimultaneous
+	opts.ContinueOnError = true
+
+	_, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	if s := done.Result.Summary; s.Agents[0].Errors != 1 || s.Agents[1].Errors != 0 || s.Agents[0].Messages != 1 {
+		t.Fatalf("expected only A's failure counted, got %+v", s)
+	}
+}
+
+func TestResumeAfterASkippedTurn(t *testing.T) {
+	// A's round 3 was skipped, so B answered the prompt A failed to in round 4
+	opts := testOptions(6)
+	opts.Prior = []Turn{
+		{Round: 1, Speaker: 0, Agent: "A", Content: "a1"},
+		{Round: 2, Speaker: 1, Agent: "B", Content: "b1"},
+		{Round: 4, Speaker: 1, Agent: "B", Content: "b2"},
+	}
+	a := &fakeProvider{replies: []string{"a2"}}
+	b := &fakeProvider{replies: []string{"b3"}}
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, opts).Run(context.Background()))
+	if done.Err != nil || done.Result.Rounds != 6 {
+		t.Fatalf("expected to finish at round 6, got %+v", done)
+	}
+	if first := events[0]; first.Type != EventTurnStart || first.Round != 5 || first.Speaker != 0 {
+		t.Fatalf("expected to resume at round 5 with Agent A, got %+v", first)
+	}
+	msgs := a.requests[0].Messages
+	if last := msgs[len(msgs)-1]; last.Role != "user" || last.Content != "b2" {
+		t.Fatalf("expected A to answer B's last reply, got %+v", msgs)
+	}
+	if PriorRounds(opts.Prior, ModeAlternating) != 4 {
+		t.Fatalf("expected 4 prior rounds, got %d", PriorRounds(opts.Prior, ModeAlternating))
+	}
+}
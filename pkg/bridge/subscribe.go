@@ -0,0 +1,72 @@
+package bridge
+
+import "sync/atomic"
+
+// DefaultSubscriberBuffer is how many events a subscriber can fall behind by
+// before it starts missing them
+const DefaultSubscriberBuffer = 256
+
+// Subscription is an extra consumer of a conversation's events, from Subscribe
+type Subscription struct {
+	events  chan Event
+	dropped atomic.Int64
+}
+
+// Events returns the subscribed events; the channel closes once the run ends
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns how many events were dropped because the subscriber fell behind
+func (s *Subscription) Dropped() int {
+	return int(s.dropped.Load())
+}
+
+// Subscribe adds a consumer that receives a copy of every event Run emits, e.g. a
+// log or a second display beside whoever reads Run's channel, without either
+// reading the provider streams themselves. A subscriber never slows the
+// conversation: it has room for buffer events (0 means DefaultSubscriberBuffer),
+// and events that arrive while it is full are dropped and counted in Dropped.
+// Leave EventReview to Run's reader. Call Subscribe before Run; a subscription
+// made after the run has ended is already closed.
+func (c *Conversation) Subscribe(buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+	s := &Subscription{events: make(chan Event, buffer)}
+
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	if c.subsClosed {
+		close(s.events)
+		return s
+	}
+	c.subs = append(c.subs, s)
+	return s
+}
+
+// publish hands ev to every subscriber that has room for it. Both agents' turns
+// emit concurrently in simultaneous mode, so it is safe for concurrent use.
+func (c *Conversation) publish(ev Event) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	for _, s := range c.subs {
+		select {
+		case s.events <- ev:
+		default:
+			if s.dropped.Add(1) == 1 {
+				c.log.Debug("subscriber fell behind; dropping events", "event", ev.Type)
+			}
+		}
+	}
+}
+
+// closeSubscribers closes every subscription once the run is over
+func (c *Conversation) closeSubscribers() {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	for _, s := range c.subs {
+		close(s.events)
+	}
+	c.subs, c.subsClosed = nil, true
+}
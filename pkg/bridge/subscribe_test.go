@@ -0,0 +1,44 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubscribersReceiveEveryEvent(t *testing.T) {
+	a := &fakeProvider{replies: []string{"one two three four"}}
+	b := &fakeProvider{replies: []string{"five six seven eight"}}
+	conv := New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: b}, testOptions(2))
+	fast := conv.Subscribe(0)
+	slow := conv.Subscribe(1) // Never read until the run is over
+
+	events, done := collect(t, conv.Run(context.Background()))
+	if done == nil || done.Err != nil {
+		t.Fatalf("a subscriber that isn't reading must not stall the run, got %+v", done)
+	}
+
+	var copied []Event
+	for ev := range fast.Events() {
+		copied = append(copied, ev)
+	}
+	if len(copied) != len(events) || fast.Dropped() != 0 {
+		t.Fatalf("expected all %d events, got %d (%d dropped)", len(events), len(copied), fast.Dropped())
+	}
+	for i := range events {
+		if copied[i].Type != events[i].Type || copied[i].Text != events[i].Text {
+			t.Fatalf("event %d differs: %+v vs %+v", i, copied[i], events[i])
+		}
+	}
+
+	kept := 0
+	for range slow.Events() {
+		kept++
+	}
+	if kept != 1 || slow.Dropped() != len(events)-1 {
+		t.Fatalf("expected the slow subscriber to keep 1 event and drop the rest, got %d kept, %d dropped", kept, slow.Dropped())
+	}
+
+	if _, ok := <-conv.Subscribe(0).Events(); ok {
+		t.Fatal("expected a subscription after the run to be closed")
+	}
+}
@@ -0,0 +1,89 @@
+package bridge
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// Summary totals a run's completed turns for end-of-run reports
+type Summary struct {
+	Agents [2]AgentSummary `json:"agents"`
+}
+
+// AgentSummary totals one agent's turns. Token counts are estimates from the
+// agent's token counter, and input counts the history sent with each turn.
+type AgentSummary struct {
+	Name         string  `json:"name"`
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"` // Models used, comma-separated when a ModelSchedule switched them
+	Messages     int     `json:"messages"`
+	Characters   int     `json:"characters"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost_usd,omitempty"` // Estimated at list prices
+	Priced       bool    `json:"priced"`             // The model's pricing is known, so Cost is meaningful
+
+	SavedTokens int `json:"saved_tokens,omitempty"` // Input tokens Options.CompactHistory saved
+	Errors      int `json:"errors,omitempty"`       // Failed turns Options.ContinueOnError skipped
+	Refusals    int `json:"refusals,omitempty"`     // Turns the agent declined to answer
+}
+
+// Tokens returns the agent's estimated input plus output tokens
+func (s AgentSummary) Tokens() int {
+	return s.InputTokens + s.OutputTokens
+}
+
+// Cost returns the estimated cost of both agents; ok is false when an agent that
+// spoke has no pricing, since the total would leave it out
+func (s Summary) Cost() (cost float64, ok bool) {
+	ok = true
+	for _, agent := range s.Agents {
+		cost += agent.Cost
+		ok = ok && (agent.Priced || agent.Messages == 0)
+	}
+	return cost, ok
+}
+
+// tally adds a completed turn to the running summary
+func (c *Conversation) tally(turn *Turn) {
+	agent := c.agents[turn.Speaker]
+	s := &c.summary.Agents[turn.Speaker]
+	if s.Messages == 0 {
+		s.Name, s.Provider = agent.Name, agent.ProviderName()
+		s.Priced = true
+	}
+
+	s.Characters += utf8.RuneCountInString(turn.Content)
+	s.SavedTokens += turn.SavedTokens
+	s.count(turn.Provider, turn.Model, turn.InputTokens, turn.OutputTokens)
+}
+
+// count adds one response's tokens and their cost at the model's list prices
+func (s *AgentSummary) count(provider, model string, inputTokens, outputTokens int) {
+	s.Messages++
+	if s.Model == "" {
+		s.Model = model
+	} else if !slices.Contains(strings.Split(s.Model, ", "), model) {
+		s.Model += ", " + model
+	}
+	s.InputTokens += inputTokens
+	s.OutputTokens += outputTokens
+
+	var info providers.ModelInfo
+	if spec, ok := providers.GetProviderSpec(provider); ok {
+		info, _ = spec.Model(model)
+	}
+	cost, priced := info.Cost(inputTokens, outputTokens)
+	s.Cost += cost
+	s.Priced = s.Priced && priced
+}
+
+// finish records the run's elapsed time and summary on its result
+func (c *Conversation) finish(result *Result, started time.Time) {
+	result.Elapsed = time.Since(started)
+	result.Summary = c.summary
+}
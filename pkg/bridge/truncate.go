@@ -0,0 +1,49 @@
+package bridge
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// FinishMaxChars is the Turn.FinishReason of a reply cut off at
+// Options.MaxResponseChars; the provider's stream was cancelled there
+const FinishMaxChars = "max_chars"
+
+// errMaxChars stops readStream once a reply reaches MaxResponseChars
+var errMaxChars = errors.New("reply reached the character limit")
+
+// capResponse appends text to response, cutting it short on a rune boundary where
+// the response would pass MaxResponseChars. It returns the part of text that was
+// kept and whether the limit was reached.
+func (c *Conversation) capResponse(response *strings.Builder, text string) (string, bool) {
+	response.WriteString(text)
+	limit := c.opts.MaxResponseChars
+	// Bytes bound runes, so most chunks need no counting
+	if limit <= 0 || response.Len() <= limit || countRunes(response.String()) <= limit {
+		return text, false
+	}
+
+	full := response.String()
+	cut, runes := 0, 0
+	for cut = range full {
+		if runes == limit {
+			break
+		}
+		runes++
+	}
+	// Earlier chunks stayed within the limit, so the cut falls in this one
+	kept := text[:len(text)-(len(full)-cut)]
+	response.Reset()
+	response.WriteString(full[:cut])
+	return kept, true
+}
+
+// countRunes counts the runes in s, an incomplete one at the end as one
+func countRunes(s string) int {
+	complete, partial := splitPartialRune(s)
+	if partial != "" {
+		return utf8.RuneCountInString(complete) + 1
+	}
+	return utf8.RuneCountInString(complete)
+}
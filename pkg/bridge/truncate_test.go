@@ -0,0 +1,79 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// endlessProvider streams chunks until its request is cancelled, then closes stopped
+type endlessProvider struct {
+	fakeProvider
+	chunk   string
+	stopped chan struct{}
+}
+
+func (p *endlessProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(p.stopped)
+		defer close(textChan)
+		defer close(errChan)
+		for {
+			select {
+			case textChan <- p.chunk:
+			case <-ctx.Done():
+				errChan <- providers.ErrContextCancelled
+				return
+			}
+		}
+	}()
+	return textChan, errChan
+}
+
+func TestConversationCapsReplies(t *testing.T) {
+	a := &endlessProvider{chunk: "日本", stopped: make(chan struct{})}
+	opts := testOptions(1)
+	opts.MaxResponseChars = 5
+	events, done := collect(t, New(&Agent{Name: "A", Provider: a}, &Agent{Name: "B", Provider: &fakeProvider{}}, opts).Run(context.Background()))
+	if done.Err != nil {
+		t.Fatal(done.Err)
+	}
+	<-a.stopped // The stream was cancelled at the limit
+
+	var streamed strings.Builder
+	var turn *Turn
+	warned := false
+	for _, ev := range events {
+		switch ev.Type {
+		case EventToken:
+			streamed.WriteString(ev.Text)
+		case EventTurnComplete:
+			turn = ev.Turn
+		case EventWarning:
+			warned = strings.Contains(ev.Text, "character limit")
+		}
+	}
+	if turn.Content != "日本日本日" || streamed.String() != turn.Content || turn.FinishReason != FinishMaxChars || !warned {
+		t.Fatalf("expected the reply cut at 5 runes with a warning, got %q (streamed %q), %+v", turn.Content, streamed.String(), turn)
+	}
+}
+
+func TestCapResponseCutsOnRuneBoundaries(t *testing.T) {
+	c := New(&Agent{Name: "A", Provider: &fakeProvider{}}, &Agent{Name: "B", Provider: &fakeProvider{}}, Options{MaxResponseChars: 3})
+	var response strings.Builder
+	var kept []string
+	for _, chunk := range []string{"ab", "日本"[:2], "日本"[2:] + "cd"} {
+		text, capped := c.capResponse(&response, chunk)
+		kept = append(kept, text)
+		if capped {
+			break
+		}
+	}
+	if response.String() != "ab日" || strings.Join(kept, "") != "ab日" || len(kept) != 3 {
+		t.Fatalf("expected the cut after the split rune, got %q from %q", response.String(), kept)
+	}
+}
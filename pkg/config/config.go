@@ -1,10 +1,18 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
 )
 
 // Config holds the application configuration
@@ -35,25 +43,59 @@ type Config struct {
 	// MCP Configuration
 	MCPMode    string
 	MCPBaseURL string
+	MCPCommand string
 
 	// Default providers
 	DefaultProviderA string
 	DefaultProviderB string
+
+	// OpenAIAPI selects the openai provider's wire API, "chat" (completions, the
+	// default) or "responses"
+	OpenAIAPI string
+
+	// Proxy overrides HTTP_PROXY/HTTPS_PROXY for provider requests
+	Proxy string
+
+	// TraceDir, when set, receives raw provider requests and responses
+	TraceDir string
+
+	// Connection reuse for provider requests; zero values use the providers package
+	// defaults (see providers.ProviderConfig)
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+
+	// Banner is BannerOff, a banner file to show instead of the default one, or
+	// empty for the default (BRIDGE_BANNER, else the config file's banner)
+	Banner string
+
+	// Aliases and profiles from the config file, keyed by name (see File)
+	Aliases  map[string]Alias
+	Profiles map[string]Profile
+
+	// ConfigFile is the config file that was loaded, if any
+	ConfigFile string
 }
 
-// Load loads configuration from environment variables and .env file
+// Load loads configuration from environment variables, the .env file, and the
+// config file at its default location
 func Load() (*Config, error) {
-	// Try to load .env file (it's okay if it doesn't exist)
-	_ = godotenv.Load()
+	return LoadFrom("")
+}
 
-	config := &Config{
-		// API Keys
-		OpenAIKey:     os.Getenv("OPENAI_API_KEY"),
-		AnthropicKey:  os.Getenv("ANTHROPIC_API_KEY"),
-		GeminiKey:     os.Getenv("GEMINI_API_KEY"),
-		DeepSeekKey:   os.Getenv("DEEPSEEK_API_KEY"),
-		OpenRouterKey: os.Getenv("OPENROUTER_API_KEY"),
+// LoadFrom is like Load but reads the config file at path (when non-empty)
+func LoadFrom(path string) (*Config, error) {
+	return LoadFiles(path, "")
+}
 
+// LoadFiles is like LoadFrom but loads variables from the .env file at envPath
+// (when non-empty) instead of the default one; see LoadEnvFile
+func LoadFiles(path, envPath string) (*Config, error) {
+	if err := LoadEnvFile(envPath); err != nil {
+		return nil, err
+	}
+
+	config := &Config{
 		// Base URLs
 		OpenAIBaseURL:     getEnvOrDefault("OPENAI_BASE_URL", "https://api.openai.com/v1"),
 		OllamaHost:        getEnvOrDefault("OLLAMA_HOST", "http://localhost:11434"),
@@ -73,15 +115,95 @@ func Load() (*Config, error) {
 		// MCP Configuration
 		MCPMode:    getEnvOrDefault("MCP_MODE", "http"),
 		MCPBaseURL: getEnvOrDefault("MCP_BASE_URL", "http://localhost:8000"),
+		MCPCommand: os.Getenv("MCP_COMMAND"),
 
 		// Default providers
 		DefaultProviderA: getEnvOrDefault("BRIDGE_PROVIDER_A", "openai"),
 		DefaultProviderB: getEnvOrDefault("BRIDGE_PROVIDER_B", "anthropic"),
+
+		OpenAIAPI: os.Getenv("OPENAI_API"),
+
+		// Proxy
+		Proxy: os.Getenv("BRIDGE_PROXY"),
+
+		Banner: os.Getenv("BRIDGE_BANNER"),
+	}
+	if err := config.loadConnectionSettings(); err != nil {
+		return nil, err
+	}
+
+	// API Keys, from the variables themselves or their _FILE and _CMD forms
+	for _, key := range []struct {
+		env   string
+		value *string
+	}{
+		{"OPENAI_API_KEY", &config.OpenAIKey},
+		{"ANTHROPIC_API_KEY", &config.AnthropicKey},
+		{"GEMINI_API_KEY", &config.GeminiKey},
+		{"DEEPSEEK_API_KEY", &config.DeepSeekKey},
+		{"OPENROUTER_API_KEY", &config.OpenRouterKey},
+	} {
+		value, err := LookupKey(key.env)
+		if err != nil {
+			return nil, err
+		}
+		*key.value = value
+	}
+
+	f, file, err := loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	aliases := f.Aliases
+	for _, name := range sortedNames(aliases) {
+		alias := aliases[name]
+		if alias.APIKeyEnv == "" {
+			continue
+		}
+		if alias.envKey, err = LookupKey(alias.APIKeyEnv); err != nil {
+			return nil, fmt.Errorf("alias %q: %w", name, err)
+		}
+		aliases[name] = alias
+	}
+	config.Aliases = aliases
+	config.Profiles = f.Profiles
+	config.ConfigFile = file
+	if config.Banner == "" && f.Banner != "" {
+		config.Banner = f.Banner
+		if f.Banner != BannerOff && !filepath.IsAbs(f.Banner) {
+			config.Banner = filepath.Join(filepath.Dir(file), f.Banner)
+		}
 	}
 
 	return config, nil
 }
 
+// EnvFileVar names the variable that points at a .env file to load instead of ./.env
+const EnvFileVar = "CHAT_BRIDGE_ENV"
+
+// LoadEnvFile loads variables from the .env file at path, or the one named by
+// CHAT_BRIDGE_ENV when path is empty; a file named either way must exist. With
+// neither, ./.env is loaded if present. Variables already set in the environment
+// take precedence over the file.
+func LoadEnvFile(path string) error {
+	if path == "" {
+		path = os.Getenv(EnvFileVar)
+	}
+	if path == "" {
+		// It's okay if the default .env doesn't exist
+		_ = godotenv.Load()
+		return nil
+	}
+
+	if err := godotenv.Load(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("env file %s does not exist", path)
+		}
+		return fmt.Errorf("failed to load env file %s: %w", path, err)
+	}
+	return nil
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// Check if at least one provider has credentials
@@ -93,8 +215,96 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// GetAPIKey returns the API key for a given provider
+// ErrMissingAPIKey is returned by ValidateForProviders when a selected provider
+// has no API key configured
+var ErrMissingAPIKey = errors.New("missing API key")
+
+// ValidateForProviders is the stricter form of Validate for a run between two
+// providers or aliases, a for Agent A and b for Agent B: each one that needs an API
+// key (see NeedsAPIKey) must have its own. The error names every provider missing
+// one and what to set.
+func (c *Config) ValidateForProviders(a, b string) error {
+	agents := map[string][]string{}
+	var order []string
+	for _, p := range []struct{ provider, agent string }{{a, "A"}, {b, "B"}} {
+		if _, seen := agents[p.provider]; !seen {
+			order = append(order, p.provider)
+		}
+		agents[p.provider] = append(agents[p.provider], p.agent)
+	}
+
+	var missing []string
+	for _, provider := range order {
+		if !c.NeedsAPIKey(provider) || c.GetAPIKey(provider) != "" {
+			continue
+		}
+		who := "Agent " + agents[provider][0]
+		if len(agents[provider]) == 2 {
+			who = "Agents A and B"
+		}
+		hint := "set " + c.APIKeyEnv(provider)
+		if c.APIKeyEnv(provider) == "" {
+			hint = "set api_key or api_key_env for it in the config file"
+		}
+		missing = append(missing, fmt.Sprintf("%s (%s): %s", provider, who, hint))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w for %s", ErrMissingAPIKey, strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// NeedsAPIKey reports whether a provider or alias must have an API key. An alias
+// that names api_key_env needs that variable set, and other aliases with their own
+// base URL (usually local servers) need none. Otherwise the provider's registered
+// spec decides; providers without one need a key if they have a built-in variable.
+func (c *Config) NeedsAPIKey(provider string) bool {
+	if alias, ok := c.Aliases[provider]; ok {
+		if alias.APIKeyEnv != "" {
+			return true
+		}
+		if alias.BaseURL != "" {
+			return false
+		}
+		provider = alias.Provider
+	}
+	if spec, ok := providers.GetProviderSpec(provider); ok {
+		return spec.NeedsAPIKey
+	}
+	return c.APIKeyEnv(provider) != ""
+}
+
+// Secrets returns every configured credential (API keys and proxy passwords) so they can be redacted from logs
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, key := range []string{c.OpenAIKey, c.AnthropicKey, c.GeminiKey, c.DeepSeekKey, c.OpenRouterKey} {
+		if key != "" {
+			secrets = append(secrets, key)
+		}
+	}
+	for _, alias := range c.Aliases {
+		if key := alias.Key(); key != "" {
+			secrets = append(secrets, key)
+		}
+	}
+	if u, err := url.Parse(c.Proxy); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok && password != "" {
+			secrets = append(secrets, password)
+		}
+	}
+	return secrets
+}
+
+// GetAPIKey returns the API key for a given provider or alias
 func (c *Config) GetAPIKey(provider string) string {
+	if alias, ok := c.Aliases[provider]; ok {
+		// Never send the provider's own key to a different endpoint
+		if key := alias.Key(); key != "" || alias.APIKeyEnv != "" || alias.BaseURL != "" {
+			return key
+		}
+		provider = alias.Provider
+	}
+
 	switch provider {
 	case "openai":
 		return c.OpenAIKey
@@ -111,8 +321,33 @@ func (c *Config) GetAPIKey(provider string) string {
 	}
 }
 
-// GetDefaultModel returns the default model for a provider
+// APIKeyEnv names the environment variable GetAPIKey reads for a provider or
+// alias, or "" when there is none (e.g. an alias with a literal api_key)
+func (c *Config) APIKeyEnv(provider string) string {
+	if alias, ok := c.Aliases[provider]; ok {
+		if alias.APIKeyEnv != "" || alias.APIKey != "" || alias.BaseURL != "" {
+			return alias.APIKeyEnv
+		}
+		provider = alias.Provider
+	}
+
+	switch provider {
+	case "openai", "anthropic", "gemini", "deepseek", "openrouter":
+		return strings.ToUpper(provider) + "_API_KEY"
+	default:
+		return ""
+	}
+}
+
+// GetDefaultModel returns the default model for a provider or alias
 func (c *Config) GetDefaultModel(provider string) string {
+	if alias, ok := c.Aliases[provider]; ok {
+		if alias.Model != "" {
+			return alias.Model
+		}
+		provider = alias.Provider
+	}
+
 	switch provider {
 	case "openai":
 		return c.OpenAIModel
@@ -133,8 +368,35 @@ func (c *Config) GetDefaultModel(provider string) string {
 	}
 }
 
-// GetProviderBaseURL returns custom base URL for a provider
+// GetProviderAPI returns the wire API configured for a provider or alias; empty
+// leaves the provider's default
+func (c *Config) GetProviderAPI(provider string) string {
+	if alias, ok := c.Aliases[provider]; ok {
+		if alias.API != "" {
+			return alias.API
+		}
+		provider = alias.Provider
+	}
+	if provider == "openai" {
+		return c.OpenAIAPI
+	}
+	return ""
+}
+
+// GetProviderHeaders returns the extra HTTP headers configured for an alias
+func (c *Config) GetProviderHeaders(provider string) map[string]string {
+	return c.Aliases[provider].Headers
+}
+
+// GetProviderBaseURL returns custom base URL for a provider or alias
 func (c *Config) GetProviderBaseURL(provider string) string {
+	if alias, ok := c.Aliases[provider]; ok {
+		if alias.BaseURL != "" {
+			return alias.BaseURL
+		}
+		provider = alias.Provider
+	}
+
 	switch provider {
 	case "openai":
 		return c.OpenAIBaseURL
@@ -151,6 +413,34 @@ func (c *Config) GetProviderBaseURL(provider string) string {
 	}
 }
 
+// loadConnectionSettings reads the connection reuse variables:
+// BRIDGE_MAX_IDLE_CONNS_PER_HOST, BRIDGE_IDLE_CONN_TIMEOUT (a duration such as
+// "2m"), and BRIDGE_DISABLE_KEEP_ALIVES
+func (c *Config) loadConnectionSettings() error {
+	if value := os.Getenv("BRIDGE_MAX_IDLE_CONNS_PER_HOST"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid BRIDGE_MAX_IDLE_CONNS_PER_HOST %q: must be a positive number", value)
+		}
+		c.MaxIdleConnsPerHost = n
+	}
+	if value := os.Getenv("BRIDGE_IDLE_CONN_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid BRIDGE_IDLE_CONN_TIMEOUT %q: must be a positive duration such as 2m", value)
+		}
+		c.IdleConnTimeout = d
+	}
+	if value := os.Getenv("BRIDGE_DISABLE_KEEP_ALIVES"); value != "" {
+		disable, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid BRIDGE_DISABLE_KEEP_ALIVES %q: must be true or false", value)
+		}
+		c.DisableKeepAlives = disable
+	}
+	return nil
+}
+
 // getEnvOrDefault returns environment variable value or default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultFileName is the config file looked up in the working directory
+const DefaultFileName = "chat-bridge.json"
+
+// File is the optional JSON config file. Settings in it sit alongside the
+// environment; they never replace environment variables.
+type File struct {
+	Aliases  map[string]Alias   `json:"aliases"`
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+
+	// Banner is BannerOff to hide the welcome banner, or a file holding one to show
+	// instead, relative to the config file
+	Banner string `json:"banner,omitempty"`
+}
+
+// BannerOff is the banner setting that hides the welcome banner
+const BannerOff = "off"
+
+// Alias names a provider plus endpoint overrides, e.g. a local OpenAI-compatible server
+type Alias struct {
+	Provider    string `json:"provider"`              // Underlying provider key (e.g., "openai")
+	BaseURL     string `json:"base_url,omitempty"`    // Overrides the provider's base URL
+	Model       string `json:"model,omitempty"`       // Overrides the provider's default model
+	APIKey      string `json:"api_key,omitempty"`     // Literal API key
+	APIKeyEnv   string `json:"api_key_env,omitempty"` // Environment variable holding the API key
+	Description string `json:"description,omitempty"` // Shown by the providers command
+
+	Headers map[string]string `json:"headers,omitempty"` // Extra HTTP headers sent with every request
+	API     string            `json:"api,omitempty"`     // Wire API: "chat" (default) or "responses"
+
+	envKey string // api_key_env as resolved by LoadFrom, including its _FILE and _CMD forms
+}
+
+// Profile is a named bundle of agent settings, applied with --profile-a or
+// --profile-b. Flags given alongside it take precedence.
+type Profile struct {
+	Provider     string   `json:"provider,omitempty"` // Provider key or alias
+	Model        string   `json:"model,omitempty"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Description  string   `json:"description,omitempty"` // Shown by the providers command
+
+	Headers map[string]string `json:"headers,omitempty"` // Extra HTTP headers, under any --header-a/--header-b
+}
+
+// Key returns the alias's API key, preferring api_key_env when set. Aliases loaded
+// with the config resolve api_key_env like the built-in keys (see LookupKey); others
+// read the variable itself.
+func (a Alias) Key() string {
+	if a.APIKeyEnv != "" {
+		if a.envKey != "" {
+			return a.envKey
+		}
+		return os.Getenv(a.APIKeyEnv)
+	}
+	return a.APIKey
+}
+
+// FilePath returns the config file to load: BRIDGE_CONFIG if set, otherwise
+// chat-bridge.json in the working directory, otherwise chat-bridge/config.json
+// in the user config directory. It returns "" when none exists.
+func FilePath() string {
+	if path := os.Getenv("BRIDGE_CONFIG"); path != "" {
+		return path
+	}
+
+	candidates := []string{DefaultFileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "chat-bridge", "config.json"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadFile reads and validates a config file
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for _, name := range sortedNames(f.Aliases) {
+		if f.Aliases[name].Provider == "" {
+			return nil, fmt.Errorf("invalid config file %s: alias %q has no provider", path, name)
+		}
+		if _, ok := f.Aliases[f.Aliases[name].Provider]; ok {
+			return nil, fmt.Errorf("invalid config file %s: alias %q points at another alias", path, name)
+		}
+	}
+	for _, name := range sortedNames(f.Profiles) {
+		if t := f.Profiles[name].Temperature; t != nil && *t < 0 {
+			return nil, fmt.Errorf("invalid config file %s: profile %q has a negative temperature", path, name)
+		}
+	}
+	return &f, nil
+}
+
+// AliasNames returns the configured alias names in sorted order
+func (c *Config) AliasNames() []string {
+	return sortedNames(c.Aliases)
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	return sortedNames(c.Profiles)
+}
+
+// ResolveProfile returns the profile registered under name
+func (c *Config) ResolveProfile(name string) (Profile, bool) {
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// ResolveAlias returns the alias registered under name
+func (c *Config) ResolveAlias(name string) (Alias, bool) {
+	alias, ok := c.Aliases[name]
+	return alias, ok
+}
+
+// ProviderKey returns the provider a name refers to: the alias's provider for
+// aliases, or the name itself
+func (c *Config) ProviderKey(name string) string {
+	if alias, ok := c.Aliases[name]; ok {
+		return alias.Provider
+	}
+	return name
+}
+
+func sortedNames[T any](entries map[string]T) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadFile reads the config file at path, or at FilePath when path is empty. It
+// returns the file that was read, if any; without one the File is empty.
+func loadFile(path string) (*File, string, error) {
+	if path == "" {
+		path = FilePath()
+	}
+	if path == "" {
+		return &File{}, "", nil
+	}
+
+	f, err := LoadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, path, nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFromResolvesAliases(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-openai")
+	t.Setenv("WORK_KEY", "sk-work")
+	t.Setenv("OPENAI_API", "chat")
+	path := writeConfigFile(t, `{"aliases": {
+		"local": {"provider": "openai", "base_url": "http://localhost:8080/v1", "model": "qwen2.5"},
+		"work":  {"provider": "openai", "api_key_env": "WORK_KEY", "headers": {"X-Tenant-ID": "acme"}},
+		"fast":  {"provider": "openai", "model": "gpt-4o-mini", "api": "responses"}
+	}}`)
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.ConfigFile != path || strings.Join(cfg.AliasNames(), ",") != "fast,local,work" {
+		t.Fatalf("unexpected aliases from %q: %v", cfg.ConfigFile, cfg.AliasNames())
+	}
+	if cfg.ProviderKey("local") != "openai" || cfg.ProviderKey("openai") != "openai" {
+		t.Fatalf("unexpected provider keys")
+	}
+
+	if got := cfg.GetProviderBaseURL("local"); got != "http://localhost:8080/v1" {
+		t.Errorf("local base URL = %q", got)
+	}
+	if got := cfg.GetDefaultModel("local"); got != "qwen2.5" {
+		t.Errorf("local model = %q", got)
+	}
+	if got := cfg.GetProviderBaseURL("work"); got != cfg.OpenAIBaseURL {
+		t.Errorf("work should fall back to the provider's base URL, got %q", got)
+	}
+	if got := cfg.GetProviderHeaders("work")["X-Tenant-ID"]; got != "acme" || cfg.GetProviderHeaders("openai") != nil {
+		t.Errorf("unexpected alias headers: %v", cfg.GetProviderHeaders("work"))
+	}
+
+	if cfg.GetProviderAPI("fast") != "responses" || cfg.GetProviderAPI("work") != "chat" {
+		t.Errorf("unexpected APIs: fast %q, work %q", cfg.GetProviderAPI("fast"), cfg.GetProviderAPI("work"))
+	}
+
+	// The provider's own key is only reused for its own endpoint
+	for name, want := range map[string]string{"local": "", "work": "sk-work", "fast": "sk-openai"} {
+		if got := cfg.GetAPIKey(name); got != want {
+			t.Errorf("GetAPIKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if secrets := strings.Join(cfg.Secrets(), ","); !strings.Contains(secrets, "sk-work") {
+		t.Errorf("alias key missing from secrets: %s", secrets)
+	}
+}
+
+func TestLoadFileRejectsInvalidAliases(t *testing.T) {
+	for name, content := range map[string]string{
+		"malformed":   `{"aliases": [`,
+		"no provider": `{"aliases": {"local": {"base_url": "http://localhost"}}}`,
+		"chained":     `{"aliases": {"a": {"provider": "openai"}, "b": {"provider": "a"}}}`,
+	} {
+		if _, err := LoadFile(writeConfigFile(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadFromReadsProfiles(t *testing.T) {
+	path := writeConfigFile(t, `{"profiles": {
+		"critic": {"provider": "local", "model": "qwen2.5", "temperature": 0, "system_prompt": "Find the flaws.", "headers": {"X-Run": "eval"}},
+		"brief":  {"system_prompt": "Answer in one line."}
+	}}`)
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if strings.Join(cfg.ProfileNames(), ",") != "brief,critic" {
+		t.Fatalf("unexpected profiles: %v", cfg.ProfileNames())
+	}
+	critic, ok := cfg.ResolveProfile("critic")
+	if !ok || critic.Provider != "local" || critic.Temperature == nil || *critic.Temperature != 0 || critic.Headers["X-Run"] != "eval" {
+		t.Fatalf("unexpected profile: %+v", critic)
+	}
+	if _, ok := cfg.ResolveProfile("missing"); ok {
+		t.Fatal("expected no profile under an unknown name")
+	}
+
+	if _, err := LoadFile(writeConfigFile(t, `{"profiles": {"hot": {"temperature": -1}}}`)); err == nil {
+		t.Fatal("expected a negative temperature to be rejected")
+	}
+}
+
+func TestLoadFromResolvesBanner(t *testing.T) {
+	t.Setenv("BRIDGE_BANNER", "")
+	path := writeConfigFile(t, `{"banner": "banner.txt"}`)
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if want := filepath.Join(filepath.Dir(path), "banner.txt"); cfg.Banner != want {
+		t.Fatalf("Banner = %q, want %q", cfg.Banner, want)
+	}
+
+	// The environment takes precedence over the file
+	t.Setenv("BRIDGE_BANNER", BannerOff)
+	if cfg, err = LoadFrom(path); err != nil || cfg.Banner != BannerOff {
+		t.Fatalf("expected BRIDGE_BANNER to win, got %q, %v", cfg.Banner, err)
+	}
+}
+
+func TestFilePathPrefersEnvironment(t *testing.T) {
+	t.Setenv("BRIDGE_CONFIG", "/etc/chat-bridge.json")
+	if got := FilePath(); got != "/etc/chat-bridge.json" {
+		t.Fatalf("FilePath() = %q", got)
+	}
+	if _, err := Load(); err == nil {
+		t.Fatal("expected an error for a missing BRIDGE_CONFIG file")
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/markjamesm/chat-bridge-go/internal/cmdline"
+)
+
+// keyCommandTimeout bounds a NAME_CMD lookup, leaving time for a password manager
+// to ask for an unlock
+const keyCommandTimeout = time.Minute
+
+// LookupKey returns the secret for an environment variable such as OPENAI_API_KEY.
+// Instead of the variable itself, NAME_FILE can name a file holding the key
+// (Docker-style secrets), or NAME_CMD a command that prints it, e.g.
+// "op read op://Private/OpenAI/credential" or "vault kv get -field=key secret/openai".
+// Commands run without a shell and their stderr is passed through. Setting more
+// than one of the three is an error. Trailing whitespace is trimmed, so the newline
+// ending a file or command output isn't sent as part of the key. An unset key is ""
+// without an error.
+func LookupKey(name string) (string, error) {
+	file, command := os.Getenv(name+"_FILE"), os.Getenv(name+"_CMD")
+	set := 0
+	for _, value := range []string{os.Getenv(name), file, command} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", fmt.Errorf("set only one of %s, %s_FILE, and %s_CMD", name, name, name)
+	}
+
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return trimKey(string(data)), nil
+	case command != "":
+		key, err := runKeyCommand(command)
+		if err != nil {
+			return "", fmt.Errorf("%s_CMD: %w", name, err)
+		}
+		return key, nil
+	}
+	return trimKey(os.Getenv(name)), nil
+}
+
+// runKeyCommand runs a NAME_CMD command line and returns the key it printed
+func runKeyCommand(command string) (string, error) {
+	args, err := cmdline.Split(command)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s timed out after %s", args[0], keyCommandTimeout)
+		}
+		return "", fmt.Errorf("%s failed: %w", args[0], err)
+	}
+
+	key := trimKey(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("%s printed no key", args[0])
+	}
+	return key, nil
+}
+
+// trimKey drops trailing whitespace, such as the newline ending a file
+func trimKey(key string) string {
+	return strings.TrimRightFunc(key, unicode.IsSpace)
+}
@@ -0,0 +1,68 @@
+package conversation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultFarewellPatterns are the closing signals matched when no custom list is given
+var DefaultFarewellPatterns = []string{
+	`\bgood-?bye\b`,
+	`\bfarewell\b`,
+	`\btake care\b`,
+	`\bbye for now\b`,
+	`\bsee you\b`,
+	`\buntil next time\b`,
+	`\b(nice|great|lovely|a pleasure) (talking|chatting|speaking) (to|with) you\b`,
+	`\bit was (a pleasure|great|lovely|nice) (talking|chatting|speaking)\b`,
+}
+
+// FarewellDetector spots mutual closing signals across consecutive turns.
+// A single farewell is not enough to stop: both the previous turn and the
+// current one must match, so one agent wrapping up a thought doesn't end the run.
+type FarewellDetector struct {
+	patterns     []*regexp.Regexp
+	previousSeen bool
+}
+
+// NewFarewellDetector compiles the given patterns (case-insensitive).
+// An empty list falls back to DefaultFarewellPatterns.
+func NewFarewellDetector(patterns []string) (*FarewellDetector, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultFarewellPatterns
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid farewell pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return &FarewellDetector{patterns: compiled}, nil
+}
+
+// IsFarewell reports whether text contains any closing signal
+func (d *FarewellDetector) IsFarewell(text string) bool {
+	for _, re := range d.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Observe records a turn and reports whether it completes a mutual farewell
+func (d *FarewellDetector) Observe(text string) bool {
+	current := d.IsFarewell(text)
+	mutual := current && d.previousSeen
+	d.previousSeen = current
+	return mutual
+}
+
+// Reset clears the remembered state from the previous turn
+func (d *FarewellDetector) Reset() {
+	d.previousSeen = false
+}
@@ -0,0 +1,43 @@
+package conversation
+
+import "testing"
+
+func TestFarewellDetectorRequiresMutualSignal(t *testing.T) {
+	d, err := NewFarewellDetector(nil)
+	if err != nil {
+		t.Fatalf("new detector: %v", err)
+	}
+
+	if d.Observe("Goodbye for now!") {
+		t.Fatal("a single farewell should not stop the conversation")
+	}
+	if d.Observe("Actually, one more thought about entropy...") {
+		t.Fatal("a non-farewell turn should not stop the conversation")
+	}
+	if d.Observe("Take care!") {
+		t.Fatal("farewell after a non-farewell turn should not stop the conversation")
+	}
+	if !d.Observe("It was a pleasure talking with you. Bye!") {
+		t.Fatal("expected consecutive farewells to stop the conversation")
+	}
+}
+
+func TestFarewellDetectorCustomPatterns(t *testing.T) {
+	d, err := NewFarewellDetector([]string{`\bover and out\b`})
+	if err != nil {
+		t.Fatalf("new detector: %v", err)
+	}
+
+	if d.IsFarewell("Goodbye!") {
+		t.Fatal("custom patterns should replace the defaults")
+	}
+	if !d.IsFarewell("Roger. OVER AND OUT.") {
+		t.Fatal("expected case-insensitive match on custom pattern")
+	}
+}
+
+func TestFarewellDetectorInvalidPattern(t *testing.T) {
+	if _, err := NewFarewellDetector([]string{"("}); err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+}
@@ -0,0 +1,118 @@
+package conversation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Loop detection defaults
+const (
+	DefaultLoopThreshold = 0.8 // Similarity at which a reply counts as a repeat
+	DefaultLoopWindow    = 4   // Recent replies each new one is compared against
+	DefaultLoopRepeats   = 3   // Consecutive repeats that make a loop
+)
+
+// LoopDetector spots agents echoing each other. Each reply is compared with the
+// last few (from either agent); once enough consecutive replies are near-copies of
+// a recent one, the conversation is looping.
+type LoopDetector struct {
+	threshold float64
+	window    int
+	repeats   int
+
+	recent []map[string]struct{} // Trigram sets of the last window replies, oldest first
+	streak int
+}
+
+// NewLoopDetector returns a detector that reports a loop after repeats consecutive
+// replies each reach threshold similarity (0 to 1) with one of the previous window
+// replies
+func NewLoopDetector(threshold float64, window, repeats int) (*LoopDetector, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("loop threshold %g must be above 0 and at most 1", threshold)
+	}
+	if window < 1 {
+		return nil, fmt.Errorf("loop window must be 1 or more")
+	}
+	if repeats < 1 {
+		return nil, fmt.Errorf("loop repeats must be 1 or more")
+	}
+	return &LoopDetector{threshold: threshold, window: window, repeats: repeats}, nil
+}
+
+// Observe records a reply and returns its highest similarity to the recent ones,
+// reporting whether it completes a loop. The streak starts over after a loop is
+// reported, so a nudged conversation has to repeat itself again to trigger another.
+func (d *LoopDetector) Observe(text string) (similarity float64, looping bool) {
+	grams := trigrams(text)
+	for _, previous := range d.recent {
+		similarity = max(similarity, jaccard(grams, previous))
+	}
+
+	d.recent = append(d.recent, grams)
+	if len(d.recent) > d.window {
+		d.recent = d.recent[1:]
+	}
+
+	if similarity < d.threshold {
+		d.streak = 0
+		return similarity, false
+	}
+	if d.streak++; d.streak < d.repeats {
+		return similarity, false
+	}
+	d.streak = 0
+	return similarity, true
+}
+
+// Reset forgets the recent replies
+func (d *LoopDetector) Reset() {
+	d.recent = nil
+	d.streak = 0
+}
+
+// Similarity compares two texts as the Jaccard index of their character trigrams
+// after normalizing case, punctuation and spacing: 1 for the same words, 0 for
+// nothing in common
+func Similarity(a, b string) float64 {
+	return jaccard(trigrams(a), trigrams(b))
+}
+
+// trigrams returns the set of three-character shingles of the normalized text
+func trigrams(text string) map[string]struct{} {
+	var normalized strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalized.WriteRune(r)
+			space = false
+		} else if !space {
+			normalized.WriteRune(' ')
+			space = true
+		}
+	}
+	runes := []rune(strings.TrimSpace(normalized.String()))
+
+	grams := make(map[string]struct{})
+	if len(runes) > 0 && len(runes) < 3 {
+		grams[string(runes)] = struct{}{}
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = struct{}{}
+	}
+	return grams
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for gram := range a {
+		if _, ok := b[gram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
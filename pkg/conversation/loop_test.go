@@ -0,0 +1,64 @@
+package conversation
+
+import "testing"
+
+func TestSimilarityNormalizesText(t *testing.T) {
+	if got := Similarity("I agree, entirely!", "i AGREE entirely"); got != 1 {
+		t.Fatalf("expected case and punctuation to be ignored, got %g", got)
+	}
+	if got := Similarity("The ocean is deep.", "Quantum fields fluctuate."); got > 0.2 {
+		t.Fatalf("unrelated replies scored %g", got)
+	}
+	if got := Similarity("", "anything"); got != 0 {
+		t.Fatalf("empty text scored %g", got)
+	}
+}
+
+func TestLoopDetectorNeedsConsecutiveRepeats(t *testing.T) {
+	d, err := NewLoopDetector(0.8, 4, 2)
+	if err != nil {
+		t.Fatalf("new detector: %v", err)
+	}
+
+	echo := "I completely agree with your point about the importance of curiosity."
+	if _, looping := d.Observe(echo); looping {
+		t.Fatal("the first reply can't be a loop")
+	}
+	if sim, looping := d.Observe(echo + "!"); looping || sim < 0.8 {
+		t.Fatalf("one repeat should not be a loop yet (similarity %g)", sim)
+	}
+	if _, looping := d.Observe(echo); !looping {
+		t.Fatal("expected a loop after two consecutive repeats")
+	}
+
+	// The streak starts over once a loop is reported, and fresh replies break it
+	if _, looping := d.Observe(echo); looping {
+		t.Fatal("a reported loop should reset the streak")
+	}
+	if _, looping := d.Observe("Let's talk about volcanoes and plate tectonics instead."); looping {
+		t.Fatal("a new topic should not be a loop")
+	}
+	if _, looping := d.Observe(echo); looping {
+		t.Fatal("a new topic should break the streak")
+	}
+}
+
+func TestLoopDetectorForgetsOutsideWindow(t *testing.T) {
+	d, _ := NewLoopDetector(0.8, 1, 1)
+	d.Observe("We should discuss the merits of open source software.")
+	d.Observe("Bananas are an excellent source of potassium.")
+	if _, looping := d.Observe("We should discuss the merits of open source software."); looping {
+		t.Fatal("replies outside the window should not count")
+	}
+}
+
+func TestNewLoopDetectorValidates(t *testing.T) {
+	for _, tc := range []struct {
+		threshold       float64
+		window, repeats int
+	}{{0, 4, 3}, {1.5, 4, 3}, {0.8, 0, 3}, {0.8, 4, 0}} {
+		if _, err := NewLoopDetector(tc.threshold, tc.window, tc.repeats); err == nil {
+			t.Errorf("expected an error for %+v", tc)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package conversation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultPreamblePatterns are the opening fillers stripped when no custom list is given
+var DefaultPreamblePatterns = []string{
+	`(sure|certainly|of course|absolutely)(,? (thing|here you go))?[!.,:]+`,
+	`(great|good|excellent|interesting) (question|point)[!.,]+`,
+	`here('s| is) (my|a|the) [^\n.!:]{0,60}:`,
+	`i'?d be (happy|glad|delighted) to [^\n.!]{0,80}[.!:]`,
+}
+
+// PreambleWindow is how much of a reply's opening is examined for filler; a
+// streaming caller holds back that much, or up to the first line break, before
+// deciding
+const PreambleWindow = 200
+
+// PreambleStripper removes filler such as "Sure! Here's my answer:" from the start
+// of replies. Patterns only match at the very start, and several may match one
+// after another, so "Sure! Great question." loses both.
+type PreambleStripper struct {
+	patterns []*regexp.Regexp
+}
+
+// NewPreambleStripper compiles the given patterns (case-insensitive, anchored to the
+// start). An empty list falls back to DefaultPreamblePatterns.
+func NewPreambleStripper(patterns []string) (*PreambleStripper, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultPreamblePatterns
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(`(?i)^(?:` + pattern + `)\s*`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preamble pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return &PreambleStripper{patterns: compiled}, nil
+}
+
+// Decided reports whether enough of a streamed reply's opening has arrived to strip
+// it: a full line, or PreambleWindow bytes
+func (p *PreambleStripper) Decided(opening string) bool {
+	return strings.Contains(strings.TrimLeft(opening, " \t\r\n"), "\n") || len(opening) >= PreambleWindow
+}
+
+// Strip removes leading filler from text, returning the rest and what was removed.
+// A reply that is nothing but filler is left alone.
+func (p *PreambleStripper) Strip(text string) (rest, preamble string) {
+	rest = strings.TrimLeft(text, " \t\r\n")
+	for matched := true; matched; {
+		matched = false
+		for _, re := range p.patterns {
+			if loc := re.FindStringIndex(rest); loc != nil && loc[1] > 0 {
+				rest, matched = rest[loc[1]:], true
+			}
+		}
+	}
+	if rest == "" || len(rest) == len(strings.TrimLeft(text, " \t\r\n")) {
+		return text, ""
+	}
+	return rest, text[:len(text)-len(rest)]
+}
@@ -0,0 +1,53 @@
+package conversation
+
+import "testing"
+
+func TestPreambleStripperRemovesLeadingFiller(t *testing.T) {
+	p, err := NewPreambleStripper(nil)
+	if err != nil {
+		t.Fatalf("new stripper: %v", err)
+	}
+
+	cases := []struct{ in, rest, preamble string }{
+		{"Sure! Here's my take: entropy always wins.", "entropy always wins.", "Sure! Here's my take: "},
+		{"Certainly. Great question! Time is a river.", "Time is a river.", "Certainly. Great question! "},
+		{"I'd be happy to help with that.\n\nFirst, consider...", "First, consider...", "I'd be happy to help with that.\n\n"},
+		{"Surely you see the flaw.", "Surely you see the flaw.", ""},
+		{"I agree. Sure! That works.", "I agree. Sure! That works.", ""},
+		{"Sure!", "Sure!", ""}, // Nothing but filler stays as it is
+	}
+	for _, tc := range cases {
+		rest, preamble := p.Strip(tc.in)
+		if rest != tc.rest || preamble != tc.preamble {
+			t.Errorf("Strip(%q) = %q, %q; want %q, %q", tc.in, rest, preamble, tc.rest, tc.preamble)
+		}
+	}
+}
+
+func TestPreambleStripperCustomPatterns(t *testing.T) {
+	p, err := NewPreambleStripper([]string{`as an ai[^.]*\.`})
+	if err != nil {
+		t.Fatalf("new stripper: %v", err)
+	}
+
+	if rest, _ := p.Strip("Sure! Let's go."); rest != "Sure! Let's go." {
+		t.Fatal("custom patterns should replace the defaults")
+	}
+	if rest, _ := p.Strip("As an AI, I have no views. But here's one."); rest != "But here's one." {
+		t.Fatalf("expected case-insensitive match on custom pattern, got %q", rest)
+	}
+
+	if _, err := NewPreambleStripper([]string{"("}); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestPreambleStripperDecided(t *testing.T) {
+	p, _ := NewPreambleStripper(nil)
+	if p.Decided("\nSure! Here") {
+		t.Fatal("a leading line break shouldn't decide the opening")
+	}
+	if !p.Decided("Sure! Here it is:\n") {
+		t.Fatal("expected a complete line to decide the opening")
+	}
+}
@@ -0,0 +1,94 @@
+// Package export renders transcripts into shareable documents and training data.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+)
+
+// Supported export formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatOpenAIFT = "openai-ft" // OpenAI fine-tuning JSONL
+)
+
+// Formats lists the accepted --export values
+var Formats = []string{FormatMarkdown, FormatHTML, FormatOpenAIFT}
+
+// Options tune formats that need more than the transcript; the zero value uses
+// each format's defaults
+type Options struct {
+	Assistant string // Whose turns FormatOpenAIFT trains on: AssistantA (default), AssistantB or AssistantBoth
+}
+
+// defaultColors match the start command's default agent colors
+var defaultColors = [2]lipgloss.Color{ui.Retro.Green, ui.Retro.Magenta}
+
+// Extension returns the file extension used for a format
+func Extension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatOpenAIFT:
+		// Set apart from the transcript's own .jsonl
+		return ".ft.jsonl"
+	}
+	return ".md"
+}
+
+// ValidateFormat rejects unknown export formats
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown export format %q (use one of: %s)", format, strings.Join(Formats, ", "))
+}
+
+// Write renders t in the given format
+func Write(w io.Writer, format string, t *transcript.Transcript, opts Options) error {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(w, t)
+	case FormatHTML:
+		return HTML(w, t)
+	case FormatOpenAIFT:
+		return FineTune(w, t, opts.Assistant)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// WriteFile renders t in the given format to path. Nothing is written if rendering
+// fails, e.g. when a transcript doesn't make a valid fine-tuning example.
+func WriteFile(path, format string, t *transcript.Transcript, opts Options) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, format, t, opts); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+// agentColor returns the CSS color recorded for an agent, or the default for its side
+func agentColor(t *transcript.Transcript, speaker int) string {
+	if speaker < 0 || speaker > 1 {
+		speaker = 0
+	}
+	color := defaultColors[speaker]
+	if c := t.Header.Agents[speaker].Color; c != "" {
+		color = lipgloss.Color(c)
+	}
+	return ui.HexColor(color)
+}
@@ -0,0 +1,227 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+)
+
+func sampleTranscript() *transcript.Transcript {
+	return &transcript.Transcript{
+		Header: transcript.Header{
+			Version: transcript.Version,
+			Started: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Starter: "Show me a loop",
+			Agents: [2]transcript.AgentInfo{
+				{Name: "Agent A", Provider: "openai", Model: "gpt-4o-mini", Temperature: providers.Float(0.7)},
+				{Name: "Agent B", Provider: "openai", Model: "gpt-4o", Temperature: providers.Float(0.7), Color: "#123456"},
+			},
+		},
+		Turns: []transcript.Turn{
+			{Round: 1, Speaker: 0, Agent: "Agent A", Model: "gpt-4o-mini", Content: "Try <script>alert(1)</script> & see"},
+			{Round: 2, Speaker: 1, Agent: "Agent B", Model: "gpt-4o", Content: "Here:\n\n```go\nfor i := 0; i < 3; i++ {\n\tfmt.Println(\"hi\") // greet\n}\n```\nDone."},
+		},
+		End: &transcript.End{Rounds: 2, Reason: "max_rounds"},
+	}
+}
+
+func TestHTMLEscapesModelOutput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HTML(&buf, sampleTranscript()); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	page := buf.String()
+
+	if strings.Contains(page, "<script>") {
+		t.Fatal("model output was not escaped")
+	}
+	if !strings.Contains(page, "&lt;script&gt;alert(1)&lt;/script&gt; &amp; see") {
+		t.Errorf("escaped message missing from page:\n%s", page)
+	}
+}
+
+func TestHTMLHighlightsCodeAndColorsAgents(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HTML(&buf, sampleTranscript()); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		`<pre class="code" data-lang="go">`,
+		`<span class="tok-kw">for</span>`,
+		`<span class="tok-str">&#34;hi&#34;</span>`,
+		`<span class="tok-com">// greet</span>`,
+		`<span class="tok-num">0</span>`,
+		"--agent: #00ff00", // Default color for Agent A
+		"--agent: #123456", // Recorded color for Agent B
+		"Completed 2 rounds",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, sampleTranscript()); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	doc := buf.String()
+
+	for _, want := range []string{
+		"- **Starter:** Show me a loop",
+		"- **Agent B:** openai / gpt-4o (temperature 0.7)",
+		"## Round 2 — Agent B",
+		"```go\nfor i := 0",
+		"*Completed 2 rounds*",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document missing %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestExportsShowEachDirectionOnce(t *testing.T) {
+	directed := sampleTranscript()
+	for i := range directed.Turns {
+		directed.Turns[i].Direction = "Add a <twist>"
+	}
+
+	var md, page bytes.Buffer
+	if err := Markdown(&md, directed); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	if err := HTML(&page, directed); err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	if n := strings.Count(md.String(), "> 🎬 **Director:** *Add a <twist>*"); n != 1 {
+		t.Errorf("expected the direction once in Markdown, got %d:\n%s", n, md.String())
+	}
+	if n := strings.Count(page.String(), "🎬 Director: Add a &lt;twist&gt;"); n != 1 {
+		t.Errorf("expected the escaped direction once in HTML, got %d", n)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	blocks := splitBlocks("intro\n```py\nx = 1\n```\noutro\n```\nunterminated")
+	want := []block{
+		{text: "intro"},
+		{code: true, lang: "py", text: "x = 1"},
+		{text: "outro"},
+		{code: true, text: "unterminated"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Errorf("block %d = %+v, want %+v", i, blocks[i], want[i])
+		}
+	}
+}
+
+func TestWriteRejectsUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "pdf", sampleTranscript(), Options{}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestTemplateRendersCustomFormats(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "turns.csv.tmpl")
+	os.WriteFile(path, []byte("round,agent,content\n{{range .Turns}}{{.Round}},{{csv .Agent}},{{csv .Content}}\n{{end}}"), 0o644)
+
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	if ext := tmpl.Extension(); ext != ".csv" {
+		t.Errorf("expected .csv, got %q", ext)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Write(&buf, sampleTranscript()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "round,agent,content\n1,Agent A,Try <script>alert(1)</script> & see\n2,Agent B,\"Here:\n") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestLoadTemplateFailsFast(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"syntax.tmpl": "{{range .Turns}}",
+		"field.tmpl":  "{{range .Turns}}{{.Speaker}} {{.Text}}{{end}}", // Turns have Content, not Text
+		"end.tmpl":    "{{.End.Reasons}}",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(text), 0o644)
+		if _, err := LoadTemplate(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFineTuneMapsRoles(t *testing.T) {
+	tr := sampleTranscript()
+	tr.Header.Agents[1].SystemPrompt = "You are terse."
+	tr.Turns = append(tr.Turns, transcript.Turn{Round: 3, Speaker: 0, Agent: "Agent A", Content: "Agreed."})
+
+	var buf bytes.Buffer
+	if err := FineTune(&buf, tr, AssistantBoth); err != nil {
+		t.Fatal(err)
+	}
+	var examples []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var example struct {
+			Messages []struct{ Role, Content string }
+		}
+		if err := json.Unmarshal([]byte(line), &example); err != nil {
+			t.Fatal(err)
+		}
+		var roles []string
+		for _, m := range example.Messages {
+			roles = append(roles, m.Role+":"+strings.Fields(m.Content)[0])
+		}
+		examples = append(examples, strings.Join(roles, " "))
+	}
+
+	// B never saw the starter, and A's closing reply has no answer to train on
+	want := []string{
+		"user:Show assistant:Try user:Here: assistant:Agreed.",
+		"system:You user:Try assistant:Here:",
+	}
+	if !slices.Equal(examples, want) {
+		t.Fatalf("got %q, want %q", examples, want)
+	}
+}
+
+func TestFineTuneRejectsInvalidExamples(t *testing.T) {
+	// B's refusal leaves A answering itself
+	tr := sampleTranscript()
+	tr.Turns[1].Refusal = "the model refused"
+	tr.Turns = append(tr.Turns, transcript.Turn{Round: 3, Speaker: 0, Agent: "Agent A", Content: "Anyway."})
+	if err := FineTune(&bytes.Buffer{}, tr, AssistantA); err == nil || !strings.Contains(err.Error(), "round 3 breaks the user/assistant alternation") {
+		t.Fatalf("expected an alternation error, got %v", err)
+	}
+
+	tr = sampleTranscript()
+	tr.Turns[0].Content = " "
+	path := filepath.Join(t.TempDir(), "run.ft.jsonl")
+	if err := WriteFile(path, FormatOpenAIFT, tr, Options{}); err == nil || !strings.Contains(err.Error(), "round 1 is empty") {
+		t.Fatalf("expected an empty content error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no file for a rejected export, got %v", err)
+	}
+}
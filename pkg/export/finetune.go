@@ -0,0 +1,164 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+)
+
+// Fine-tuning role mappings: whose turns become the assistant's messages
+const (
+	AssistantA    = "a"
+	AssistantB    = "b"
+	AssistantBoth = "both" // One example from each agent's side
+)
+
+// ftMessage is a message of an OpenAI fine-tuning example
+type ftMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	round int // Turn the message came from; 0 for the system prompt and starter
+}
+
+// ValidateAssistant rejects unknown fine-tuning role mappings; empty selects AssistantA
+func ValidateAssistant(name string) error {
+	switch name {
+	case "", AssistantA, AssistantB, AssistantBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown assistant %q (use %s, %s or %s)", name, AssistantA, AssistantB, AssistantBoth)
+}
+
+// FineTune writes t as OpenAI fine-tuning JSONL: a {"messages": [...]} line in
+// which the assistant agent's turns are "assistant" messages and the other agent's
+// (and the starter) are "user" ones, after a system message with the assistant's
+// system prompt and any session context. AssistantBoth writes a line from each
+// side. Every example is checked against the format before anything is written.
+func FineTune(w io.Writer, t *transcript.Transcript, assistant string) error {
+	if err := ValidateAssistant(assistant); err != nil {
+		return err
+	}
+	sides := []int{0}
+	switch assistant {
+	case AssistantB:
+		sides = []int{1}
+	case AssistantBoth:
+		sides = []int{0, 1}
+	}
+
+	var examples [][]ftMessage
+	for _, side := range sides {
+		messages := fineTuneMessages(t, side)
+		if err := validateFineTune(messages); err != nil {
+			return fmt.Errorf("%s as assistant: %w", t.Header.Agents[side].Name, err)
+		}
+		examples = append(examples, messages)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, messages := range examples {
+		if err := enc.Encode(struct {
+			Messages []ftMessage `json:"messages"`
+		}{messages}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fineTuneMessages lays the conversation out as the agent on side saw it
+func fineTuneMessages(t *transcript.Transcript, side int) []ftMessage {
+	var turns []transcript.Turn
+	for _, turn := range t.Turns {
+		// Refused turns never joined the history
+		if turn.Refusal == "" {
+			turns = append(turns, turn)
+		}
+	}
+	if t.Header.Mode == string(bridge.ModeSimultaneous) {
+		// Both agents answer the previous round, so each saw its own reply first
+		slices.SortStableFunc(turns, func(x, y transcript.Turn) int {
+			if x.Round != y.Round {
+				return x.Round - y.Round
+			}
+			return boolOrder(x.Speaker != side) - boolOrder(y.Speaker != side)
+		})
+	}
+
+	var messages []ftMessage
+	system := strings.TrimSpace(t.Header.Agents[side].SystemPrompt + "\n\n" + t.Header.Context)
+	if system != "" {
+		messages = append(messages, ftMessage{Role: "system", Content: system})
+	}
+	start := len(messages)
+	messages = append(messages, ftMessage{Role: "user", Content: t.Header.Starter})
+	for _, turn := range turns {
+		if turn.Speaker != side {
+			messages = append(messages, ftMessage{Role: "user", Content: turn.Content, round: turn.Round})
+			continue
+		}
+		// A reviewer's edit is what the agent was actually sent
+		if last := &messages[len(messages)-1]; turn.Prompt != "" && last.Role == "user" {
+			last.Content = turn.Prompt
+		}
+		messages = append(messages, ftMessage{Role: "assistant", Content: turn.Content, round: turn.Round})
+	}
+
+	// The agent answering second never saw the starter, and a closing message
+	// nobody answered has nothing to train on
+	if len(messages) > start+1 && messages[start+1].Role == "user" {
+		messages = slices.Delete(messages, start, start+1)
+	}
+	for len(messages) > start && messages[len(messages)-1].Role == "user" {
+		messages = messages[:len(messages)-1]
+	}
+	return messages
+}
+
+// validateFineTune checks messages against the fine-tuning format: an optional
+// system message, then user and assistant messages alternating from user, with
+// no empty content and at least one assistant message
+func validateFineTune(messages []ftMessage) error {
+	want, replies := "user", 0
+	for i, m := range messages {
+		where := "the starter"
+		if m.round > 0 {
+			where = fmt.Sprintf("round %d", m.round)
+		} else if m.Role == "system" {
+			where = "the system message"
+		}
+		if strings.TrimSpace(m.Content) == "" {
+			return fmt.Errorf("%s is empty", where)
+		}
+		if i == 0 && m.Role == "system" {
+			continue
+		}
+		if m.Role != want {
+			return fmt.Errorf("%s breaks the user/assistant alternation (two %s messages in a row)", where, m.Role)
+		}
+		if m.Role == "assistant" {
+			want, replies = "user", replies+1
+		} else {
+			want = "assistant"
+		}
+	}
+	if replies == 0 {
+		return errors.New("no assistant messages to train on")
+	}
+	return nil
+}
+
+// boolOrder sorts false before true
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// block is a run of prose or a fenced code block within a message
+type block struct {
+	code bool
+	lang string
+	text string
+}
+
+// splitBlocks separates ``` fenced code blocks from prose. An unterminated fence
+// runs to the end of the message, as it would in a Markdown renderer.
+func splitBlocks(content string) []block {
+	var blocks []block
+	var current strings.Builder
+	inCode := false
+	lang := ""
+
+	flush := func() {
+		if current.Len() > 0 || inCode {
+			blocks = append(blocks, block{code: inCode, lang: lang, text: strings.TrimSuffix(current.String(), "\n")})
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			if inCode {
+				inCode, lang = false, ""
+			} else {
+				inCode, lang = true, strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+			}
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	flush()
+	return blocks
+}
+
+var (
+	inlineCode     = regexp.MustCompile("`([^`\n]+)`")
+	paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+)
+
+// renderProse escapes text and turns paragraphs, line breaks, and `inline code` into HTML
+func renderProse(text string) string {
+	var out strings.Builder
+	for _, para := range paragraphBreak.Split(strings.TrimSpace(text), -1) {
+		if para == "" {
+			continue
+		}
+		escaped := html.EscapeString(para)
+		escaped = inlineCode.ReplaceAllString(escaped, "<code>$1</code>")
+		out.WriteString("<p>")
+		out.WriteString(strings.ReplaceAll(escaped, "\n", "<br>\n"))
+		out.WriteString("</p>\n")
+	}
+	return out.String()
+}
+
+// keywords common to popular languages; highlighting is heuristic, not a full lexer
+var keywords = map[string]bool{}
+
+func init() {
+	for _, kw := range strings.Fields(`
+		break case catch class const continue def default defer del do elif else enum except
+		export extends false final finally fn for from func function go if impl import in
+		interface is lambda let match mut nil none null package pass pub raise return self
+		static struct super switch this throw true try type typeof use var void while with yield
+		async await and or not new chan map range select int string bool float`) {
+		keywords[kw] = true
+	}
+}
+
+// hashComments lists languages where # starts a comment
+var hashComments = map[string]bool{
+	"python": true, "py": true, "sh": true, "bash": true, "shell": true, "zsh": true,
+	"ruby": true, "rb": true, "yaml": true, "yml": true, "toml": true, "perl": true, "r": true,
+	"dockerfile": true, "makefile": true, "make": true,
+}
+
+// highlightCode escapes code and wraps keywords, strings, comments, and numbers in spans
+func highlightCode(code, lang string) string {
+	var out strings.Builder
+	runes := []rune(code)
+	n := len(runes)
+
+	span := func(class string, start, end int) {
+		out.WriteString(`<span class="tok-` + class + `">`)
+		out.WriteString(html.EscapeString(string(runes[start:end])))
+		out.WriteString("</span>")
+	}
+	toEOL := func(i int) int {
+		for i < n && runes[i] != '\n' {
+			i++
+		}
+		return i
+	}
+
+	for i := 0; i < n; {
+		r := runes[i]
+		switch {
+		case r == '/' && i+1 < n && runes[i+1] == '/',
+			r == '#' && hashComments[lang],
+			r == '-' && i+1 < n && runes[i+1] == '-' && (lang == "sql" || lang == "lua"):
+			end := toEOL(i)
+			span("com", i, end)
+			i = end
+
+		case r == '/' && i+1 < n && runes[i+1] == '*':
+			end := i + 2
+			for end+1 < n && !(runes[end] == '*' && runes[end+1] == '/') {
+				end++
+			}
+			end = min(end+2, n)
+			span("com", i, end)
+			i = end
+
+		case r == '"' || r == '\'' || r == '`':
+			end := i + 1
+			for end < n && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				} else if runes[end] == '\n' && r != '`' {
+					break
+				}
+				end++
+			}
+			if end < n && runes[end] == r {
+				end++
+			}
+			end = min(end, n)
+			span("str", i, end)
+			i = end
+
+		case unicode.IsDigit(r) && (i == 0 || !isIdent(runes[i-1])):
+			end := i
+			for end < n && (isIdent(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			span("num", i, end)
+			i = end
+
+		case isIdent(r):
+			end := i
+			for end < n && isIdent(runes[end]) {
+				end++
+			}
+			if keywords[strings.ToLower(string(runes[i:end]))] {
+				span("kw", i, end)
+			} else {
+				out.WriteString(html.EscapeString(string(runes[i:end])))
+			}
+			i = end
+
+		default:
+			out.WriteString(html.EscapeString(string(r)))
+			i++
+		}
+	}
+	return out.String()
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
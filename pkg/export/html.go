@@ -0,0 +1,155 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+)
+
+// htmlTurn is a turn prepared for the page template
+type htmlTurn struct {
+	Round   int
+	Agent   string
+	Side    string // "a" or "b", for bubble alignment
+	Color   string
+	Model   string
+	Content template.HTML // Pre-escaped message body
+
+	Direction string // Director instruction shown before the turn, if it is the first to carry it
+}
+
+type htmlPage struct {
+	Header transcript.Header
+	Agents [2]htmlAgent
+	Turns  []htmlTurn
+	End    string
+}
+
+type htmlAgent struct {
+	Name    string
+	Summary string
+	Color   string
+}
+
+// HTML renders t as a self-contained HTML page. Every piece of model output is
+// HTML-escaped before rendering, so transcripts can't inject markup or scripts.
+func HTML(w io.Writer, t *transcript.Transcript) error {
+	page := htmlPage{Header: t.Header}
+	for i, a := range t.Header.Agents {
+		page.Agents[i] = htmlAgent{Name: a.Name, Summary: agentSummary(a), Color: agentColor(t, i)}
+	}
+	for i, turn := range t.Turns {
+		side := "a"
+		if turn.Speaker == 1 {
+			side = "b"
+		}
+		page.Turns = append(page.Turns, htmlTurn{
+			Round:   turn.Round,
+			Agent:   turn.Agent,
+			Side:    side,
+			Color:   agentColor(t, turn.Speaker),
+			Model:   turn.Model,
+			Content: renderMessage(turn.Content),
+		})
+		if directed(t.Turns, i) {
+			page.Turns[len(page.Turns)-1].Direction = turn.Direction
+		}
+	}
+	if t.End != nil {
+		page.End = endSummary(t.End)
+	}
+
+	return pageTemplate.Execute(w, page)
+}
+
+// renderMessage converts message text into escaped HTML with highlighted code blocks
+func renderMessage(content string) template.HTML {
+	var out strings.Builder
+	for _, b := range splitBlocks(content) {
+		if !b.code {
+			out.WriteString(renderProse(b.text))
+			continue
+		}
+		out.WriteString(`<pre class="code"`)
+		if b.lang != "" {
+			out.WriteString(` data-lang="` + template.HTMLEscapeString(b.lang) + `"`)
+		}
+		out.WriteString("><code>")
+		out.WriteString(highlightCode(b.text, b.lang))
+		out.WriteString("</code></pre>\n")
+	}
+	return template.HTML(out.String())
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Chat Bridge — {{(index .Agents 0).Name}} &amp; {{(index .Agents 1).Name}}</title>
+<style>
+  :root { --bg: #0c0c0c; --panel: #161616; --text: #e5e5e5; --dim: #585858; --cyan: #00ffff; --yellow: #ffff00; }
+  * { box-sizing: border-box; }
+  body { margin: 0; background: var(--bg); color: var(--text); font: 15px/1.55 "SFMono-Regular", Menlo, Consolas, "Liberation Mono", monospace; }
+  main { max-width: 60rem; margin: 0 auto; padding: 2rem 1rem 4rem; }
+  header { border: 2px solid var(--cyan); padding: 1rem 1.25rem; margin-bottom: 2rem; box-shadow: 0 0 12px rgba(0, 255, 255, .25); }
+  h1 { color: var(--cyan); font-size: 1.4rem; margin: 0 0 .75rem; text-align: center; letter-spacing: .05em; }
+  dl { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; margin: 0; }
+  dt { color: var(--yellow); }
+  dd { margin: 0; overflow-wrap: anywhere; }
+  .round { color: var(--dim); text-align: center; margin: 1.5rem 0 .5rem; font-size: .85rem; }
+  .direction { color: var(--dim); font-style: italic; text-align: center; margin: 1rem 0 0; }
+  .turn { display: flex; }
+  .turn.b { justify-content: flex-end; }
+  .bubble { max-width: 85%; background: var(--panel); border: 1px solid var(--agent); border-left-width: 4px; padding: .6rem .9rem; border-radius: 6px; }
+  .turn.b .bubble { border-left-width: 1px; border-right-width: 4px; }
+  .speaker { color: var(--agent); font-weight: bold; margin-bottom: .25rem; }
+  .speaker small { color: var(--dim); font-weight: normal; margin-left: .5rem; }
+  .bubble p { margin: .4rem 0; overflow-wrap: anywhere; }
+  code { background: #222; padding: 0 .25rem; border-radius: 3px; }
+  pre.code { background: #000; border: 1px solid #333; padding: .75rem; overflow-x: auto; border-radius: 4px; }
+  pre.code code { background: none; padding: 0; }
+  pre.code[data-lang]::before { content: attr(data-lang); display: block; color: var(--dim); font-size: .75rem; margin-bottom: .35rem; }
+  .tok-kw { color: #ff87ff; font-weight: bold; }
+  .tok-str { color: #87ff87; }
+  .tok-com { color: #808080; font-style: italic; }
+  .tok-num { color: #ffaf5f; }
+  footer { color: var(--dim); text-align: center; margin-top: 2.5rem; border-top: 1px dashed var(--dim); padding-top: 1rem; }
+</style>
+</head>
+<body>
+<main>
+<header>
+  <h1>🌉 CHAT BRIDGE 🌉</h1>
+  <dl>
+    <dt>Started</dt><dd>{{.Header.Started.Format "2006-01-02 15:04:05 MST"}}</dd>
+    {{- range .Agents}}
+    <dt style="color: {{.Color}}">{{.Name}}</dt><dd>{{.Summary}}</dd>
+    {{- end}}
+    <dt>Starter</dt><dd>{{.Header.Starter}}</dd>
+    {{- if .Header.BranchedFrom}}
+    <dt>Branched from</dt><dd>{{.Header.BranchedFrom}}</dd>
+    {{- end}}
+  </dl>
+</header>
+{{- range .Turns}}
+{{- if .Direction}}
+<div class="direction">🎬 Director: {{.Direction}}</div>
+{{- end}}
+<div class="round">═══ Round {{.Round}} ═══</div>
+<div class="turn {{.Side}}" style="--agent: {{.Color}}">
+  <div class="bubble">
+    <div class="speaker">{{.Agent}}<small>{{.Model}}</small></div>
+    {{.Content}}
+  </div>
+</div>
+{{- end}}
+{{- if .End}}
+<footer>{{.End}}</footer>
+{{- end}}
+</main>
+</body>
+</html>
+`))
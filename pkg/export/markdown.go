@@ -0,0 +1,61 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+)
+
+// Markdown renders t as a Markdown document; model output is included verbatim
+func Markdown(w io.Writer, t *transcript.Transcript) error {
+	return markdownTemplate.Execute(w, t)
+}
+
+// markdownTemplate is the built-in Markdown format, written as a Template would be
+var markdownTemplate = template.Must(template.New("markdown").Funcs(templateFuncs).Parse(`# 🌉 Chat Bridge Conversation
+
+- **Started:** {{.Header.Started.Format "2006-01-02 15:04:05 MST"}}
+{{range .Header.Agents}}- **{{.Name}}:** {{summary .}}
+{{end}}- **Starter:** {{.Header.Starter}}
+{{with .Header.BranchedFrom}}- **Branched from:** ` + "`{{.}}`" + `
+{{end}}{{range $i, $turn := .Turns}}{{if directed $.Turns $i}}
+> 🎬 **Director:** *{{.Direction}}*
+{{end}}
+## Round {{.Round}} — {{.Agent}}
+
+{{trim .Content}}
+{{end}}{{with .End}}
+---
+
+*{{ending .}}*
+{{end}}`))
+
+// directed reports whether turn i is the first to carry its director instruction;
+// both agents receive each one, but it is shown once
+func directed(turns []transcript.Turn, i int) bool {
+	return turns[i].Direction != "" && (i == 0 || turns[i-1].Direction != turns[i].Direction)
+}
+
+// agentSummary describes an agent's provider settings in one line
+func agentSummary(a transcript.AgentInfo) string {
+	if a.Temperature == nil {
+		return fmt.Sprintf("%s / %s (default temperature)", a.Provider, a.Model)
+	}
+	return fmt.Sprintf("%s / %s (temperature %.1f)", a.Provider, a.Model, *a.Temperature)
+}
+
+// endSummary describes how the conversation finished
+func endSummary(end *transcript.End) string {
+	switch {
+	case end.Error != "":
+		return fmt.Sprintf("Stopped after %d rounds: %s", end.Rounds, end.Error)
+	case end.Reason == "farewell":
+		return fmt.Sprintf("Ended naturally after %d rounds", end.Rounds)
+	case end.Reason == "max_duration":
+		return fmt.Sprintf("Stopped at the time limit after %d rounds", end.Rounds)
+	default:
+		return fmt.Sprintf("Completed %d rounds", end.Rounds)
+	}
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/transcript"
+)
+
+// Template is a user-supplied text/template that renders a whole transcript. It
+// executes with the *transcript.Transcript (.Header, .Turns, .End) and these
+// functions besides the built-in ones:
+//
+//	trim     strings.TrimSpace
+//	csv      quotes a value as one CSV field when needed
+//	summary  an AgentInfo as "provider / model (temperature 0.7)"
+//	ending   an *End as "Completed 10 rounds" and the like
+//	directed $.Turns $i is true for the first turn carrying its director instruction
+type Template struct {
+	name string
+	tmpl *template.Template
+}
+
+// templateFuncs are available to the built-in and user templates
+var templateFuncs = template.FuncMap{
+	"trim":     strings.TrimSpace,
+	"csv":      csvField,
+	"summary":  agentSummary,
+	"ending":   endSummary,
+	"directed": directed,
+}
+
+// LoadTemplate parses the template at path and renders a sample transcript with
+// it, so a misspelled field or function fails before a conversation starts rather
+// than when it is exported
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	t := &Template{name: filepath.Base(path), tmpl: tmpl}
+	if err := t.Write(io.Discard, templateSample()); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return t, nil
+}
+
+// Extension returns the extension for documents the template renders: the one
+// before ".tmpl" or ".tpl" if the name has one ("chat.csv.tmpl" gives ".csv"),
+// the template's own otherwise, and ".txt" when neither says
+func (t *Template) Extension() string {
+	name := t.name
+	if ext := filepath.Ext(name); ext == ".tmpl" || ext == ".tpl" {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if ext := filepath.Ext(name); ext != "" {
+		return ext
+	}
+	return ".txt"
+}
+
+// Write renders tr with the template
+func (t *Template) Write(w io.Writer, tr *transcript.Transcript) error {
+	return t.tmpl.Execute(w, tr)
+}
+
+// WriteFile renders tr with the template to path
+func (t *Template) WriteFile(path string, tr *transcript.Transcript) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	if err := t.Write(f, tr); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// csvField quotes s as a single CSV field if it needs quoting
+func csvField(s string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{s})
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// templateSample exercises every field a template can reach, for LoadTemplate
+func templateSample() *transcript.Transcript {
+	temperature := 0.7
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &transcript.Transcript{
+		Header: transcript.Header{
+			Version:      transcript.Version,
+			Started:      started,
+			Starter:      "Hello",
+			MaxRounds:    2,
+			BranchedFrom: "earlier.jsonl",
+			Images:       []string{"chart.png"},
+			Agents: [2]transcript.AgentInfo{
+				{Name: "Agent A", Provider: "openai", Model: "gpt-4o-mini", Temperature: &temperature, SystemPrompt: "Be brief.", Tools: []string{"echo"}},
+				{Name: "Agent B", Provider: "openai", Model: "gpt-4o"},
+			},
+		},
+		Turns: []transcript.Turn{
+			{Round: 1, Speaker: 0, Agent: "Agent A", Provider: "openai", Model: "gpt-4o-mini", Content: "Hi", Started: started, DurationMS: 1200,
+				ToolCalls: []transcript.ToolUse{{Name: "echo", Arguments: `{"text":"hi"}`, Result: "hi"}}, Direction: "Change the subject"},
+			{Round: 2, Speaker: 1, Agent: "Agent B", Provider: "openai", Model: "gpt-4o", Content: "Hello", Started: started, DurationMS: 900, Prompt: "Hi there"},
+		},
+		End: &transcript.End{Rounds: 2, Reason: "max_rounds", ElapsedMS: 2100},
+	}
+}
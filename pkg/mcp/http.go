@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpTimeout keeps a slow memory server from stalling a round
+const httpTimeout = 5 * time.Second
+
+// HTTPClient talks to an MCP memory server over HTTP.
+//
+// Endpoints (relative to the base URL):
+//
+//	GET  /health                       -> 200 when ready
+//	POST /memory                       <- Entry JSON
+//	GET  /memory/search?q=...&limit=N  -> {"results": [{"content": "..."}]}
+type HTTPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPClient creates a client for the memory server at baseURL
+func NewHTTPClient(baseURL string) *HTTPClient {
+	return &HTTPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// Health checks that the memory server is reachable
+func (c *HTTPClient) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+// Store saves a completed turn
+func (c *HTTPClient) Store(ctx context.Context, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/memory", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, nil)
+}
+
+// Recall returns up to limit snippets relevant to query
+func (c *HTTPClient) Recall(ctx context.Context, query string, limit int) ([]string, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/memory/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return nil, err
+	}
+
+	snippets := make([]string, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Content != "" {
+			snippets = append(snippets, r.Content)
+		}
+	}
+	return snippets, nil
+}
+
+// Close is a no-op for the HTTP client
+func (c *HTTPClient) Close() error {
+	return nil
+}
+
+// do sends req and decodes a JSON response into out when non-nil
+func (c *HTTPClient) do(req *http.Request, out interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("memory server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("memory server error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid memory server response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+)
+
+func TestHTTPClientStoreAndRecall(t *testing.T) {
+	var stored []Entry
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /memory", func(w http.ResponseWriter, r *http.Request) {
+		var entry Entry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stored = append(stored, entry)
+	})
+	mux.HandleFunc("GET /memory/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "tides" || r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"results": [{"content": "The moon drives tides."}, {"content": ""}]}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client := NewHTTPClient(ts.URL + "/")
+	ctx := context.Background()
+
+	if err := client.Health(ctx); err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if err := client.Store(ctx, Entry{Agent: "Ada", Round: 1, Content: "hello"}); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Agent != "Ada" || stored[0].Content != "hello" {
+		t.Fatalf("unexpected stored entries: %+v", stored)
+	}
+
+	snippets, err := client.Recall(ctx, "tides", 2)
+	if err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if len(snippets) != 1 || snippets[0] != "The moon drives tides." {
+		t.Fatalf("unexpected snippets: %q", snippets)
+	}
+}
+
+func TestHTTPClientReportsServerErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database locked", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(ts.URL)
+	if _, err := client.Recall(context.Background(), "x", 1); err == nil {
+		t.Fatal("expected error from failing server")
+	}
+}
+
+func TestNewSelectsMode(t *testing.T) {
+	ctx := context.Background()
+	if _, err := New(ctx, &config.Config{MCPMode: "http", MCPBaseURL: "http://localhost:1"}); err != nil {
+		t.Fatalf("http mode: %v", err)
+	}
+	if _, err := New(ctx, &config.Config{MCPMode: "stdio", MCPBaseURL: "http://localhost:8000"}); err == nil {
+		t.Fatal("expected stdio mode to require MCP_COMMAND even when MCP_BASE_URL is set")
+	}
+	if _, err := New(ctx, &config.Config{MCPMode: "off"}); err != ErrDisabled {
+		t.Fatalf("expected ErrDisabled, got %v", err)
+	}
+	if _, err := New(ctx, &config.Config{MCPMode: "carrier-pigeon"}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
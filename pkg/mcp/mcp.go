@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+)
+
+// Supported values for MCP_MODE
+const (
+	ModeHTTP  = "http"
+	ModeStdio = "stdio"
+	ModeOff   = "off"
+)
+
+// ErrDisabled is returned by New when MCP_MODE is "off"
+var ErrDisabled = errors.New("MCP memory is disabled (MCP_MODE=off)")
+
+// Entry is a single conversation turn stored in memory
+type Entry struct {
+	Agent     string    `json:"agent"`
+	Round     int       `json:"round"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Memory stores conversation turns and recalls related context from previous sessions
+type Memory interface {
+	// Health checks that the memory server is reachable
+	Health(ctx context.Context) error
+
+	// Store saves a completed turn
+	Store(ctx context.Context, entry Entry) error
+
+	// Recall returns up to limit snippets relevant to query
+	Recall(ctx context.Context, query string, limit int) ([]string, error)
+
+	// Close releases any resources held by the client
+	Close() error
+}
+
+// New creates the memory client selected by cfg.MCPMode.
+// In stdio mode MCP_BASE_URL is ignored and MCP_COMMAND is required; the server
+// subprocess lives until Close is called or ctx is cancelled.
+func New(ctx context.Context, cfg *config.Config) (Memory, error) {
+	switch cfg.MCPMode {
+	case ModeHTTP, "":
+		return NewHTTPClient(cfg.MCPBaseURL), nil
+	case ModeStdio:
+		if cfg.MCPCommand == "" {
+			return nil, errors.New("MCP_COMMAND is required when MCP_MODE=stdio")
+		}
+		return NewStdioClient(ctx, cfg.MCPCommand)
+	case ModeOff:
+		return nil, ErrDisabled
+	default:
+		return nil, fmt.Errorf("unsupported MCP_MODE %q (use %q, %q, or %q)", cfg.MCPMode, ModeHTTP, ModeStdio, ModeOff)
+	}
+}
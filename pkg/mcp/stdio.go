@@ -0,0 +1,289 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/internal/cmdline"
+	"github.com/markjamesm/chat-bridge-go/internal/version"
+)
+
+// MCP protocol details for the stdio transport
+const (
+	protocolVersion = "2024-11-05"
+	callTimeout     = 10 * time.Second
+	stdioWaitDelay  = 2 * time.Second
+
+	// StoreTool and RecallTool are the MCP tools called on the memory server
+	StoreTool  = "store_memory"
+	RecallTool = "search_memory"
+)
+
+// StdioClient launches an MCP server subprocess and speaks JSON-RPC 2.0 over its
+// stdin/stdout (one JSON message per line), as described by the MCP stdio transport.
+// Memory operations map to tools/call requests for StoreTool and RecallTool.
+type StdioClient struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan rpcResponse
+	done    chan struct{}
+	readErr error
+}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// toolResult is the result payload of a tools/call request
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// NewStdioClient starts the MCP server command and performs the initialize handshake.
+// The subprocess is killed when ctx is cancelled or Close is called.
+func NewStdioClient(ctx context.Context, command string) (*StdioClient, error) {
+	args, err := cmdline.Split(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MCP_COMMAND: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.WaitDelay = stdioWaitDelay
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	c := &StdioClient{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan rpcResponse),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(stdout)
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Health pings the MCP server
+func (c *StdioClient) Health(ctx context.Context) error {
+	_, err := c.call(ctx, "ping", struct{}{})
+	return err
+}
+
+// Store saves a completed turn via the StoreTool
+func (c *StdioClient) Store(ctx context.Context, entry Entry) error {
+	_, err := c.callTool(ctx, StoreTool, map[string]interface{}{
+		"agent":     entry.Agent,
+		"round":     entry.Round,
+		"content":   entry.Content,
+		"timestamp": entry.Timestamp,
+	})
+	return err
+}
+
+// Recall returns text snippets from the RecallTool
+func (c *StdioClient) Recall(ctx context.Context, query string, limit int) ([]string, error) {
+	result, err := c.callTool(ctx, RecallTool, map[string]interface{}{
+		"query": query,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var snippets []string
+	for _, item := range result.Content {
+		if item.Type == "text" && strings.TrimSpace(item.Text) != "" {
+			snippets = append(snippets, item.Text)
+		}
+	}
+	if len(snippets) > limit {
+		snippets = snippets[:limit]
+	}
+	return snippets, nil
+}
+
+// Close closes stdin (asking the server to exit), then kills it if it lingers
+func (c *StdioClient) Close() error {
+	c.stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() { exited <- c.cmd.Wait() }()
+
+	select {
+	case <-exited:
+	case <-time.After(stdioWaitDelay):
+		c.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+// initialize performs the MCP handshake
+func (c *StdioClient) initialize(ctx context.Context) error {
+	_, err := c.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]string{
+			"name":    "chat-bridge",
+			"version": version.GetVersion(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("MCP initialize failed: %w", err)
+	}
+
+	return c.write(rpcRequest{JSONRPC: "2.0", Method: "notifications/initialized"})
+}
+
+// callTool invokes an MCP tool and fails if the tool reports an error
+func (c *StdioClient) callTool(ctx context.Context, name string, arguments map[string]interface{}) (*toolResult, error) {
+	raw, err := c.call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result toolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid %s result: %w", name, err)
+	}
+	if result.IsError {
+		msg := name + " failed"
+		if len(result.Content) > 0 {
+			msg += ": " + result.Content[0].Text
+		}
+		return nil, errors.New(msg)
+	}
+	return &result, nil
+}
+
+// call sends a request and waits for the matching response
+func (c *StdioClient) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	respChan := make(chan rpcResponse, 1)
+	c.pending[id] = respChan
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		// A write to a server that already exited fails with a broken pipe; report the exit instead
+		select {
+		case <-c.done:
+			return nil, fmt.Errorf("MCP server exited: %w", c.readErr)
+		default:
+			return nil, err
+		}
+	}
+
+	select {
+	case resp := <-respChan:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("MCP error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		return resp.Result, nil
+	case <-c.done:
+		return nil, fmt.Errorf("MCP server exited: %w", c.readErr)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("MCP %s: %w", method, ctx.Err())
+	}
+}
+
+// write sends one newline-delimited JSON-RPC message
+func (c *StdioClient) write(msg rpcRequest) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if _, err := c.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to MCP server: %w", err)
+	}
+	return nil
+}
+
+// readLoop dispatches responses to waiting callers; notifications and server requests are ignored
+func (c *StdioClient) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		var resp rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil || resp.ID == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		respChan, ok := c.pending[*resp.ID]
+		c.mu.Unlock()
+		if ok {
+			respChan <- resp
+		}
+	}
+
+	c.readErr = scanner.Err()
+	if c.readErr == nil {
+		c.readErr = io.EOF
+	}
+	close(c.done)
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMCPHelperProcess is not a real test; it is the fake MCP server spawned by the stdio tests
+func TestMCPHelperProcess(t *testing.T) {
+	if os.Getenv("CHAT_BRIDGE_MCP_HELPER") == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	var stored []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Name      string                 `json:"name"`
+				Arguments map[string]interface{} `json:"arguments"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue // notifications
+		}
+
+		// Interleave a server notification to prove the client ignores it
+		fmt.Println(`{"jsonrpc":"2.0","method":"notifications/message","params":{}}`)
+
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			result = map[string]interface{}{"protocolVersion": protocolVersion, "capabilities": map[string]interface{}{}}
+		case "ping":
+			result = map[string]interface{}{}
+		case "tools/call":
+			switch req.Params.Name {
+			case StoreTool:
+				stored = append(stored, fmt.Sprint(req.Params.Arguments["content"]))
+				result = map[string]interface{}{"content": []interface{}{}}
+			case RecallTool:
+				items := []interface{}{}
+				for _, s := range stored {
+					items = append(items, map[string]string{"type": "text", "text": "remembered: " + s})
+				}
+				result = map[string]interface{}{"content": items}
+			default:
+				fmt.Printf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"unknown tool"}}`+"\n", *req.ID)
+				continue
+			}
+		}
+
+		data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+		fmt.Println(string(data))
+	}
+}
+
+func helperServerCommand(t *testing.T) string {
+	t.Helper()
+	t.Setenv("CHAT_BRIDGE_MCP_HELPER", "1")
+	return fmt.Sprintf("%q -test.run=^TestMCPHelperProcess$", os.Args[0])
+}
+
+func TestStdioClientStoreAndRecall(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewStdioClient(ctx, helperServerCommand(t))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Health(ctx); err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if err := client.Store(ctx, Entry{Agent: "Ada", Round: 1, Content: "tides"}); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	snippets, err := client.Recall(ctx, "tides", 3)
+	if err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if len(snippets) != 1 || snippets[0] != "remembered: tides" {
+		t.Fatalf("unexpected snippets: %q", snippets)
+	}
+}
+
+func TestStdioClientTerminatesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := NewStdioClient(ctx, helperServerCommand(t))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case <-client.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("MCP server subprocess not terminated on context cancellation")
+	}
+
+	if err := client.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Fatalf("expected exited-server error, got %v", err)
+	}
+	client.Close()
+}
+
+func TestNewStdioClientBadCommand(t *testing.T) {
+	if _, err := NewStdioClient(context.Background(), "/definitely/not/a/server"); err == nil {
+		t.Fatal("expected error for missing MCP server binary")
+	}
+}
@@ -0,0 +1,175 @@
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one mistake in a file; Line is 0 when it concerns the whole file
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// Error lists every problem found while loading a file and the files it refers to,
+// in file order
+type Error struct {
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// yamlLine matches the position yaml.v3 puts at the start of its error messages
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// decoder reads fields out of YAML nodes, collecting problems instead of stopping
+// at the first one
+type decoder struct {
+	file     string
+	problems []Problem
+}
+
+func (d *decoder) add(line int, format string, args ...any) {
+	d.problems = append(d.problems, Problem{File: d.file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// result returns err, or the collected problems as an *Error, or nil. Problems are
+// sorted by line, keeping each file's together.
+func (d *decoder) result(err error) error {
+	if err != nil {
+		return err
+	}
+	if len(d.problems) == 0 {
+		return nil
+	}
+
+	files := map[string]int{}
+	for _, p := range d.problems {
+		if _, ok := files[p.File]; !ok {
+			files[p.File] = len(files)
+		}
+	}
+	sort.SliceStable(d.problems, func(i, j int) bool {
+		a, b := d.problems[i], d.problems[j]
+		if a.File != b.File {
+			return files[a.File] < files[b.File]
+		}
+		return a.Line < b.Line
+	})
+	return &Error{Problems: d.problems}
+}
+
+// read parses path into its top-level node. Syntax errors and empty files are
+// problems; a nil node without an error means there is nothing more to check.
+func (d *decoder) read(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line, message := splitLine(err.Error())
+		d.add(line, "%s", message)
+		return nil, nil
+	}
+	if len(doc.Content) == 0 {
+		d.add(0, "file is empty")
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// fields indexes a mapping node by key, reporting unknown and duplicate keys
+func (d *decoder) fields(node *yaml.Node, known []string) map[string]*yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		d.add(node.Line, "expected a mapping of fields like %q", known[0]+": ...")
+		return nil
+	}
+
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case !slices.Contains(known, key.Value):
+			message := fmt.Sprintf("unknown field %q", key.Value)
+			if suggestions := providers.SuggestModels(key.Value, known); len(suggestions) > 0 {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestions[0])
+			}
+			d.add(key.Line, "%s", message)
+		case fields[key.Value] != nil:
+			d.add(key.Line, "duplicate field %q", key.Value)
+		default:
+			fields[key.Value] = value
+		}
+	}
+	return fields
+}
+
+// decode stores the field's value in out, reporting whether it was present and valid
+func (d *decoder) decode(fields map[string]*yaml.Node, key string, out any) bool {
+	node := fields[key]
+	if node == nil {
+		return false
+	}
+	if err := node.Decode(out); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+			_, message := splitLine(typeErr.Errors[0])
+			d.add(node.Line, "%s: %s", key, message)
+		} else {
+			d.add(node.Line, "%s: %v", key, err)
+		}
+		return false
+	}
+	return true
+}
+
+// required decodes a string field that must be present and non-empty
+func (d *decoder) required(node *yaml.Node, fields map[string]*yaml.Node, key string, out *string) {
+	if fields[key] == nil {
+		d.add(node.Line, "missing required field %q", key)
+		return
+	}
+	d.nonEmpty(fields, key, out)
+}
+
+// nonEmpty decodes an optional string field that must not be empty when present
+func (d *decoder) nonEmpty(fields map[string]*yaml.Node, key string, out *string) {
+	if d.decode(fields, key, out) && strings.TrimSpace(*out) == "" {
+		d.add(fields[key].Line, "%s must not be empty", key)
+	}
+}
+
+// splitLine separates the line number from a yaml.v3 error message
+func splitLine(message string) (int, string) {
+	m := yamlLine.FindStringSubmatch(message)
+	if m == nil {
+		return 0, strings.TrimPrefix(message, "yaml: ")
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, message[len(m[0]):]
+}
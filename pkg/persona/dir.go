@@ -0,0 +1,76 @@
+package persona
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirEnv names the environment variable that replaces the default persona directories
+const DirEnv = "BRIDGE_PERSONA_DIR"
+
+// Dirs returns the directories personas are listed from, highest precedence first:
+// BRIDGE_PERSONA_DIR if set, otherwise ./personas and then chat-bridge/personas in
+// the user config directory
+func Dirs() []string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return []string{dir}
+	}
+	dirs := []string{"personas"}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "chat-bridge", "personas"))
+	}
+	return dirs
+}
+
+// Entry is a persona file found by List; Err holds its problems if it failed to load
+type Entry struct {
+	Path    string
+	Persona *Persona
+	Err     error
+}
+
+// Name returns the persona's name, or the file name for a file that failed to load
+func (e Entry) Name() string {
+	if e.Persona != nil && e.Persona.Name != "" {
+		return e.Persona.Name
+	}
+	return strings.TrimSuffix(filepath.Base(e.Path), filepath.Ext(e.Path))
+}
+
+// List loads every .yaml and .yml file in dirs, sorted by name. A file name seen
+// in an earlier directory hides the same name in later ones, so a project's
+// personas override the user's. Missing directories are skipped.
+func List(dirs []string, checks Checks) ([]Entry, error) {
+	var entries []Entry
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			ext := filepath.Ext(file.Name())
+			if file.IsDir() || (ext != ".yaml" && ext != ".yml") || seen[file.Name()] {
+				continue
+			}
+			seen[file.Name()] = true
+
+			path := filepath.Join(dir, file.Name())
+			p, err := LoadPersona(path, checks)
+			entries = append(entries, Entry{Path: path, Persona: p, Err: err})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
+	})
+	return entries, nil
+}
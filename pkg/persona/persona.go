@@ -0,0 +1,277 @@
+// Package persona loads agent personas and session templates from YAML files.
+// Loading checks every field and reports all problems at once, with line numbers,
+// so a broken file fails before any provider is called.
+package persona
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// Persona is a reusable agent: its character and, optionally, how it is run.
+//
+//	name: Socrates
+//	description: Answers questions with questions
+//	system_prompt: You are Socrates. Never state a conclusion; ask probing questions.
+//	provider: openai
+//	model: gpt-4o
+//	temperature: 0.9
+//	color: cyan
+//
+// A persona may extend another with extends, a path relative to its own file. It
+// inherits every field it leaves out, and its system_prompt replaces the base's
+// unless it contains BaseMarker, which is replaced by the base's prompt:
+//
+//	extends: concise.yaml
+//	name: Terse Socrates
+//	system_prompt: |
+//	  {{base}}
+//	  You are Socrates.
+type Persona struct {
+	Name         string   // Display name (required)
+	Description  string   // One line shown in listings
+	SystemPrompt string   // The agent's instructions (required)
+	Provider     string   // Provider key or alias
+	Model        string   // Model ID
+	Temperature  *float64 // nil leaves the temperature to the other settings
+	Color        string   // Palette color name or ANSI index
+	Extends      string   // Persona file this one inherits from, as written
+}
+
+// BaseMarker stands for the base persona's system prompt in an extending persona's
+// system_prompt, so it can be added to before or after
+const BaseMarker = "{{base}}"
+
+// Template is a ready-made session: a starter plus a persona for each agent. Each
+// agent is either a persona file path (relative to the template) or an inline persona.
+//
+//	name: Socratic debate
+//	starter: Is it ever right to break a promise?
+//	max_rounds: 6
+//	agent_a: socrates.yaml
+//	agent_b:
+//	  name: Skeptic
+//	  system_prompt: Doubt every claim and ask for evidence.
+type Template struct {
+	Name        string
+	Description string
+	Starter     string      // First message (required)
+	MaxRounds   int         // 0 leaves the round limit to the other settings
+	Mode        string      // Turn-taking mode, as for bridge.ParseMode
+	Agents      [2]*Persona // From agent_a and agent_b (both required)
+}
+
+// Checks resolve references this package can't check on its own. Nil checks are skipped.
+type Checks struct {
+	Provider    func(name string) error                    // Rejects unknown providers and aliases
+	Temperature func(provider string, value float64) error // Rejects temperatures the provider can't take
+}
+
+var (
+	personaFields  = []string{"name", "description", "system_prompt", "provider", "model", "temperature", "color", "extends"}
+	templateFields = []string{"name", "description", "starter", "max_rounds", "mode", "agent_a", "agent_b"}
+)
+
+// LoadPersona reads and checks a persona file. Problems with its contents are
+// returned together as an *Error.
+func LoadPersona(path string, checks Checks) (*Persona, error) {
+	return loadPersona(path, checks, nil)
+}
+
+// loadPersona loads a persona file; chain holds the files whose bases are being
+// loaded, for cycle detection
+func loadPersona(path string, checks Checks, chain []string) (*Persona, error) {
+	d := &decoder{file: path}
+	root, err := d.read(path)
+	if err != nil || root == nil {
+		return nil, d.result(err)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	p := d.persona(root, filepath.Dir(path), append(chain[:len(chain):len(chain)], path), checks)
+	return p, d.result(nil)
+}
+
+// LoadTemplate reads and checks a template file and the persona files it refers to.
+// Problems with their contents are returned together as an *Error.
+func LoadTemplate(path string, checks Checks) (*Template, error) {
+	d := &decoder{file: path}
+	root, err := d.read(path)
+	if err != nil || root == nil {
+		return nil, d.result(err)
+	}
+	t := d.template(root, filepath.Dir(path), checks)
+	return t, d.result(nil)
+}
+
+// persona decodes a persona in a file in dir; chain is as for loadPersona
+func (d *decoder) persona(node *yaml.Node, dir string, chain []string, checks Checks) *Persona {
+	fields := d.fields(node, personaFields)
+	if fields == nil {
+		return nil
+	}
+
+	p := &Persona{}
+	var base *Persona
+	if d.decode(fields, "extends", &p.Extends) {
+		base = d.extend(fields["extends"], p.Extends, dir, chain, checks)
+	}
+	// A base supplies the required fields its extensions leave out
+	if fields["extends"] == nil {
+		d.required(node, fields, "name", &p.Name)
+		d.required(node, fields, "system_prompt", &p.SystemPrompt)
+	} else {
+		d.nonEmpty(fields, "name", &p.Name)
+		d.nonEmpty(fields, "system_prompt", &p.SystemPrompt)
+	}
+	d.decode(fields, "description", &p.Description)
+	d.decode(fields, "provider", &p.Provider)
+	d.decode(fields, "model", &p.Model)
+	d.decode(fields, "color", &p.Color)
+
+	if p.Provider != "" && checks.Provider != nil {
+		if err := checks.Provider(p.Provider); err != nil {
+			d.add(fields["provider"].Line, "provider: %v", err)
+		}
+	}
+	if p.Color != "" {
+		if _, err := ui.ParseColor(p.Color); err != nil {
+			d.add(fields["color"].Line, "color: %v", err)
+		}
+	}
+
+	var temperature float64
+	if d.decode(fields, "temperature", &temperature) {
+		p.Temperature = &temperature
+		if temperature < 0 {
+			d.add(fields["temperature"].Line, "temperature must be 0 or more")
+		}
+	}
+
+	if base != nil {
+		p.inherit(base)
+	}
+	// The temperature may come from one file and the provider from another; a pair
+	// both inherited was checked in the base
+	line := 0
+	if node := fields["provider"]; node != nil {
+		line = node.Line
+	}
+	if node := fields["temperature"]; node != nil {
+		line = node.Line
+	}
+	if line != 0 && p.Temperature != nil && *p.Temperature >= 0 && p.Provider != "" && checks.Temperature != nil {
+		if err := checks.Temperature(p.Provider, *p.Temperature); err != nil {
+			d.add(line, "temperature: %v", err)
+		}
+	}
+	return p
+}
+
+// extend loads the base persona at path (relative to dir), collecting its problems.
+// A path already in chain is reported as a cycle rather than loaded again.
+func (d *decoder) extend(node *yaml.Node, path, dir string, chain []string, checks Checks) *Persona {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if i := slices.Index(chain, path); i >= 0 {
+		cycle := make([]string, 0, len(chain)-i+1)
+		for _, file := range append(chain[i:], path) {
+			cycle = append(cycle, filepath.Base(file))
+		}
+		d.add(node.Line, "extends: inheritance cycle %s", strings.Join(cycle, " → "))
+		return nil
+	}
+
+	p, err := loadPersona(path, checks, chain)
+	if perr, ok := err.(*Error); ok {
+		d.problems = append(d.problems, perr.Problems...)
+	} else if err != nil {
+		d.add(node.Line, "extends: %v", err)
+	}
+	return p
+}
+
+// inherit fills in the fields p leaves out from base, and puts the base's system
+// prompt in place of BaseMarker
+func (p *Persona) inherit(base *Persona) {
+	switch {
+	case p.SystemPrompt == "":
+		p.SystemPrompt = base.SystemPrompt
+	case strings.Contains(p.SystemPrompt, BaseMarker):
+		p.SystemPrompt = strings.ReplaceAll(p.SystemPrompt, BaseMarker, strings.TrimSpace(base.SystemPrompt))
+	}
+	for field, inherited := range map[*string]string{
+		&p.Name:        base.Name,
+		&p.Description: base.Description,
+		&p.Provider:    base.Provider,
+		&p.Model:       base.Model,
+		&p.Color:       base.Color,
+	} {
+		if *field == "" {
+			*field = inherited
+		}
+	}
+	if p.Temperature == nil {
+		p.Temperature = base.Temperature
+	}
+}
+
+func (d *decoder) template(node *yaml.Node, dir string, checks Checks) *Template {
+	fields := d.fields(node, templateFields)
+	if fields == nil {
+		return nil
+	}
+
+	t := &Template{}
+	d.decode(fields, "name", &t.Name)
+	d.decode(fields, "description", &t.Description)
+	d.required(node, fields, "starter", &t.Starter)
+
+	if d.decode(fields, "max_rounds", &t.MaxRounds) && t.MaxRounds < 1 {
+		d.add(fields["max_rounds"].Line, "max_rounds must be 1 or more")
+	}
+	if d.decode(fields, "mode", &t.Mode) {
+		if _, err := bridge.ParseMode(t.Mode); err != nil {
+			d.add(fields["mode"].Line, "mode: %v", err)
+		}
+	}
+
+	for i, key := range []string{"agent_a", "agent_b"} {
+		agent := fields[key]
+		switch {
+		case agent == nil:
+			d.add(node.Line, "missing required field %q", key)
+		case agent.Kind == yaml.ScalarNode:
+			t.Agents[i] = d.include(agent, key, dir, checks)
+		default:
+			t.Agents[i] = d.persona(agent, dir, nil, checks)
+		}
+	}
+	return t
+}
+
+// include loads a persona file a template refers to, collecting its problems
+func (d *decoder) include(node *yaml.Node, key, dir string, checks Checks) *Persona {
+	path := node.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	p, err := LoadPersona(path, checks)
+	if perr, ok := err.(*Error); ok {
+		d.problems = append(d.problems, perr.Problems...)
+	} else if err != nil {
+		d.add(node.Line, "%s: %v", key, err)
+	}
+	return p
+}
@@ -0,0 +1,191 @@
+package persona
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// knownProviders accepts only openai, and temperatures up to 2
+var knownProviders = Checks{
+	Provider: func(name string) error {
+		if name != "openai" {
+			return fmt.Errorf("unknown provider %q", name)
+		}
+		return nil
+	},
+	Temperature: func(provider string, value float64) error {
+		if value > 2 {
+			return fmt.Errorf("%g is above 2", value)
+		}
+		return nil
+	},
+}
+
+func TestLoadPersona(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "socrates.yaml", `
+name: Socrates
+description: Answers questions with questions
+system_prompt: |
+  You are Socrates.
+provider: openai
+temperature: 0.9
+color: cyan
+`)
+	p, err := LoadPersona(path, knownProviders)
+	if err != nil {
+		t.Fatalf("LoadPersona: %v", err)
+	}
+	if p.Name != "Socrates" || p.SystemPrompt != "You are Socrates.\n" || p.Temperature == nil || *p.Temperature != 0.9 {
+		t.Fatalf("unexpected persona: %+v", p)
+	}
+}
+
+func TestLoadPersonaReportsEveryProblemByLine(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "broken.yaml", `name: Socrates
+sytem_prompt: You are Socrates.
+provider: opnai
+temperature: warm
+color: chartreuse
+`)
+	_, err := LoadPersona(path, knownProviders)
+	var perr *Error
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected an *Error, got %v", err)
+	}
+
+	want := []string{
+		`broken.yaml:2: unknown field "sytem_prompt" (did you mean "system_prompt"?)`,
+		`broken.yaml:1: missing required field "system_prompt"`,
+		`broken.yaml:3: provider: unknown provider "opnai"`,
+		`broken.yaml:5: color: unknown color "chartreuse"`,
+		`broken.yaml:4: temperature: cannot unmarshal`,
+	}
+	if len(perr.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got:\n%v", len(want), err)
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("missing problem %q in:\n%v", w, err)
+		}
+	}
+	for i := 1; i < len(perr.Problems); i++ {
+		if perr.Problems[i].Line < perr.Problems[i-1].Line {
+			t.Fatalf("problems are not in line order:\n%v", err)
+		}
+	}
+}
+
+func TestLoadPersonaSyntaxError(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "bad.yaml", "name: Socrates\nsystem_prompt: [unterminated\n")
+	_, err := LoadPersona(path, Checks{})
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Problems[0].Line == 0 {
+		t.Fatalf("expected a syntax problem with a line number, got %v", err)
+	}
+}
+
+func TestLoadPersonaExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", "name: Base\nsystem_prompt: |\n  Answer in plain English.\nprovider: openai\ntemperature: 0.4\ncolor: cyan\n")
+	writeFile(t, dir, "concise.yaml", "extends: base.yaml\nname: Concise\nsystem_prompt: \"{{base}} Keep it short.\"\ntemperature: 0.2\n")
+	path := writeFile(t, dir, "socrates.yaml", "extends: concise.yaml\nname: Socrates\nsystem_prompt: \"You are Socrates. {{base}}\"\ncolor: magenta\n")
+
+	p, err := LoadPersona(path, knownProviders)
+	if err != nil {
+		t.Fatalf("LoadPersona: %v", err)
+	}
+	if p.Name != "Socrates" || p.SystemPrompt != "You are Socrates. Answer in plain English. Keep it short." {
+		t.Fatalf("unexpected persona: %+v", p)
+	}
+	if p.Provider != "openai" || p.Temperature == nil || *p.Temperature != 0.2 || p.Color != "magenta" {
+		t.Fatalf("expected the nearest setting of each field, got %+v", p)
+	}
+
+	// An inherited provider still limits the extension's temperature
+	writeFile(t, dir, "hot.yaml", "extends: base.yaml\ntemperature: 3\n")
+	if _, err := LoadPersona(filepath.Join(dir, "hot.yaml"), knownProviders); err == nil || !strings.Contains(err.Error(), "hot.yaml:2: temperature: 3 is above 2") {
+		t.Fatalf("expected the temperature to be checked, got %v", err)
+	}
+}
+
+func TestLoadPersonaExtendsReportsCycles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "extends: b.yaml\nname: A\n")
+	writeFile(t, dir, "b.yaml", "extends: a.yaml\nname: B\n")
+	_, err := LoadPersona(filepath.Join(dir, "a.yaml"), Checks{})
+	if err == nil || !strings.Contains(err.Error(), "b.yaml:1: extends: inheritance cycle a.yaml → b.yaml → a.yaml") {
+		t.Fatalf("expected the cycle to be reported, got %v", err)
+	}
+}
+
+func TestLoadTemplateChecksReferencedPersonas(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "socrates.yaml", "name: Socrates\nsystem_prompt: Ask questions.\n")
+	path := writeFile(t, dir, "debate.yaml", `
+starter: Is it ever right to break a promise?
+max_rounds: 6
+mode: simultaneous
+agent_a: socrates.yaml
+agent_b:
+  name: Skeptic
+  system_prompt: Doubt every claim.
+`)
+	tmpl, err := LoadTemplate(path, knownProviders)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	if tmpl.MaxRounds != 6 || tmpl.Agents[0].Name != "Socrates" || tmpl.Agents[1].Name != "Skeptic" {
+		t.Fatalf("unexpected template: %+v", tmpl)
+	}
+
+	// Problems in a referenced persona are reported against that file
+	writeFile(t, dir, "socrates.yaml", "name: Socrates\n")
+	writeFile(t, dir, "debate.yaml", "starter: Hi\nmode: chaotic\nagent_a: socrates.yaml\nagent_b: missing.yaml\n")
+	_, err = LoadTemplate(path, knownProviders)
+	for _, want := range []string{
+		`debate.yaml:2: mode: unknown mode "chaotic"`,
+		`socrates.yaml:1: missing required field "system_prompt"`,
+		`debate.yaml:4: agent_b: open`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing problem %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestListPersonas(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	writeFile(t, project, "socrates.yaml", "name: Socrates\nsystem_prompt: Ask questions.\n")
+	writeFile(t, project, "broken.yml", "name: Broken\n")
+	writeFile(t, project, "notes.txt", "not a persona")
+	writeFile(t, user, "socrates.yaml", "name: Other Socrates\nsystem_prompt: Hidden by the project's.\n")
+	writeFile(t, user, "aristotle.yaml", "name: Aristotle\nsystem_prompt: Classify everything.\n")
+
+	entries, err := List([]string{project, filepath.Join(project, "missing"), user}, Checks{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ", "); got != "Aristotle, Broken, Socrates" {
+		t.Fatalf("expected project personas to hide the user's and entries sorted by name, got %s", got)
+	}
+	if broken := entries[1]; broken.Err == nil || broken.Persona == nil {
+		t.Fatalf("expected the broken persona to be listed with its problems, got %+v", broken)
+	}
+}
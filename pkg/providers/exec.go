@@ -0,0 +1,235 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/internal/cmdline"
+)
+
+func init() {
+	// Register the external command provider in the global registry
+	RegisterProvider(ProviderSpec{
+		Key:          "exec",
+		Name:         "External Command",
+		Description:  "Any local program speaking JSON on stdin and text on stdout",
+		DefaultModel: "external",
+		NeedsAPIKey:  false,
+
+		// The system prompt is passed through in the stdin JSON; sampling knobs are not
+		SupportsSystemPrompt: true,
+	})
+
+	RegisterProviderFactory("exec", func(cfg ProviderConfig) Provider {
+		return NewExecProvider(cfg)
+	})
+}
+
+// execWaitDelay bounds how long we wait for the subprocess pipes to close after it is killed
+const execWaitDelay = 2 * time.Second
+
+// ExecProvider implements the Provider interface by shelling out to a user command.
+//
+// Protocol: the ChatRequest is written to the command's stdin as a single JSON
+// document, then stdin is closed. Each stdout line is a chunk of the response:
+// either plain text, or a JSON object of the form {"text": "..."} or
+// {"error": "..."}. A non-zero exit status fails the stream with stderr attached.
+type ExecProvider struct {
+	command string
+	model   string
+	trace   *tracer
+}
+
+// execRequest is the JSON document written to the subprocess stdin
+type execRequest struct {
+	Model        string        `json:"model"`
+	Messages     []execMessage `json:"messages"`
+	Temperature  *float64      `json:"temperature,omitempty"`
+	MaxTokens    int           `json:"max_tokens,omitempty"`
+	SystemPrompt string        `json:"system_prompt,omitempty"`
+}
+
+type execMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// execChunk is the optional JSON form of a stdout line
+type execChunk struct {
+	Text  *string `json:"text"`
+	Error string  `json:"error"`
+}
+
+// NewExecProvider creates a new external command provider instance
+func NewExecProvider(config ProviderConfig) *ExecProvider {
+	model := config.Model
+	if model == "" {
+		model = "external"
+	}
+
+	return &ExecProvider{
+		command: config.Command,
+		model:   model,
+		trace:   newTracer(config.TraceDir),
+	}
+}
+
+// Name returns the provider identifier
+func (p *ExecProvider) Name() string {
+	return "exec"
+}
+
+// DefaultModel returns the default model
+func (p *ExecProvider) DefaultModel() string {
+	return p.model
+}
+
+// Models returns the configured model, since the command defines its own catalog
+func (p *ExecProvider) Models(ctx context.Context) ([]string, error) {
+	return []string{p.model}, nil
+}
+
+// Health checks that the command is configured and its executable can be found
+func (p *ExecProvider) Health(ctx context.Context) error {
+	args, err := cmdline.Split(p.command)
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("exec command not found: %w", err)
+	}
+	return nil
+}
+
+// StreamChat runs the command and streams its stdout lines as response chunks
+func (p *ExecProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+
+		args, err := cmdline.Split(p.command)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		payload := execRequest{
+			Model:        req.Model,
+			Messages:     make([]execMessage, len(req.Messages)),
+			Temperature:  req.Temperature,
+			MaxTokens:    req.MaxTokens,
+			SystemPrompt: req.SystemPrompt,
+		}
+		for i, msg := range req.Messages {
+			payload.Messages[i] = execMessage{Role: msg.Role, Content: msg.Content}
+		}
+
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		// CommandContext kills the process when ctx is cancelled
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.WaitDelay = execWaitDelay
+		cmd.Stdin = bytes.NewReader(jsonData)
+
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		traceOut := p.trace.begin(traceRecord{Provider: p.Name(), Command: args[0], Body: jsonData, Conversation: ConversationID(ctx)})
+		defer traceOut.Close()
+
+		started := time.Now()
+		slog.Debug("exec provider started", "command", args[0], "model", req.Model, "messages", len(req.Messages), "conversation", ConversationID(ctx))
+		if err := cmd.Start(); err != nil {
+			errChan <- fmt.Errorf("failed to start exec command: %w", err)
+			return
+		}
+
+		streamErr := p.readChunks(ctx, io.TeeReader(stdout, traceOut), textChan)
+		waitErr := cmd.Wait()
+		slog.Debug("exec provider exited", "command", args[0], "exit_code", cmd.ProcessState.ExitCode(), "elapsed", time.Since(started))
+
+		if ctx.Err() != nil {
+			errChan <- ErrContextCancelled
+			return
+		}
+		if streamErr != nil {
+			errChan <- streamErr
+			return
+		}
+		if waitErr != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				errChan <- fmt.Errorf("exec command failed: %w", waitErr)
+			} else {
+				errChan <- fmt.Errorf("exec command failed: %w: %s", waitErr, msg)
+			}
+		}
+	}()
+
+	return textChan, errChan
+}
+
+// readChunks forwards stdout lines to textChan until EOF, an error chunk, or cancellation
+func (p *ExecProvider) readChunks(ctx context.Context, stdout io.Reader, textChan chan<- string) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		text := line
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			var chunk execChunk
+			if err := json.Unmarshal([]byte(line), &chunk); err == nil {
+				if chunk.Error != "" {
+					return errors.New(chunk.Error)
+				}
+				if chunk.Text == nil {
+					continue
+				}
+				text = *chunk.Text
+			} else if !first {
+				text = "\n" + line
+			}
+		} else if !first {
+			// Plain text lines are joined back together with the newline the scanner stripped
+			text = "\n" + line
+		}
+		first = false
+
+		if text == "" {
+			continue
+		}
+
+		select {
+		case textChan <- text:
+		case <-ctx.Done():
+			return ErrContextCancelled
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExecHelperProcess is not a real test; it is the subprocess spawned by the exec tests
+func TestExecHelperProcess(t *testing.T) {
+	mode := os.Getenv("CHAT_BRIDGE_EXEC_HELPER")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	var req execRequest
+	data, _ := io.ReadAll(os.Stdin)
+	if err := json.Unmarshal(data, &req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "echo":
+		last := req.Messages[len(req.Messages)-1].Content
+		fmt.Printf("model=%s\n", req.Model)
+		fmt.Printf("echo: %s\n", last)
+	case "json":
+		fmt.Println(`{"text": "Hello"}`)
+		fmt.Println(`{"text": ", world"}`)
+	case "error":
+		fmt.Println(`{"error": "model exploded"}`)
+	case "exit":
+		fmt.Fprintln(os.Stderr, "something went wrong")
+		os.Exit(3)
+	case "hang":
+		fmt.Println("partial")
+		time.Sleep(time.Minute)
+	}
+}
+
+func helperCommand(t *testing.T, mode string) string {
+	t.Helper()
+	t.Setenv("CHAT_BRIDGE_EXEC_HELPER", mode)
+	return fmt.Sprintf("%q -test.run=^TestExecHelperProcess$", os.Args[0])
+}
+
+func collectStream(textChan <-chan string, errChan <-chan error) (string, error) {
+	var out strings.Builder
+	for text := range textChan {
+		out.WriteString(text)
+	}
+	return out.String(), <-errChan
+}
+
+func TestExecProviderStreamsPlainText(t *testing.T) {
+	p := NewExecProvider(ProviderConfig{Command: helperCommand(t, "echo"), Model: "tiny"})
+
+	textChan, errChan := p.StreamChat(context.Background(), &ChatRequest{
+		Model:    p.DefaultModel(),
+		Messages: []Message{{Role: "user", Content: "ping"}},
+	})
+
+	got, err := collectStream(textChan, errChan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "model=tiny\necho: ping"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestExecProviderStreamsJSONChunks(t *testing.T) {
+	p := NewExecProvider(ProviderConfig{Command: helperCommand(t, "json")})
+
+	got, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Hello, world" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestExecProviderSurfacesErrors(t *testing.T) {
+	for _, mode := range []string{"error", "exit"} {
+		p := NewExecProvider(ProviderConfig{Command: helperCommand(t, mode)})
+		_, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+			Messages: []Message{{Role: "user", Content: "hi"}},
+		}))
+		if err == nil {
+			t.Fatalf("%s: expected error", mode)
+		}
+		if mode == "exit" && !strings.Contains(err.Error(), "something went wrong") {
+			t.Fatalf("expected stderr in error, got %v", err)
+		}
+	}
+}
+
+func TestExecProviderKillsProcessOnCancel(t *testing.T) {
+	p := NewExecProvider(ProviderConfig{Command: helperCommand(t, "hang")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	textChan, errChan := p.StreamChat(ctx, &ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	})
+
+	if first := <-textChan; first != "partial" {
+		t.Fatalf("expected first chunk before cancel, got %q", first)
+	}
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := collectStream(textChan, errChan)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrContextCancelled) {
+			t.Fatalf("expected ErrContextCancelled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("subprocess was not killed on context cancellation")
+	}
+}
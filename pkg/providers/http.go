@@ -0,0 +1,232 @@
+package providers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/internal/version"
+)
+
+// secretParams are query parameters that carry credentials (e.g., Gemini's ?key=)
+var secretParams = []string{"key", "api_key", "apikey", "token", "access_token"}
+
+// ParseProxy validates a proxy URL such as "http://proxy.corp:3128"
+func ParseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q in %q (use http, https, or socks5)", u.Scheme, raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return u, nil
+}
+
+// Connection reuse defaults for provider requests. Both agents, the director, and
+// health checks can talk to one host, so a few idle connections are kept for it.
+const (
+	DefaultMaxIdleConnsPerHost = 4
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// transports are shared by every provider with the same proxy and connection
+// settings, so agents on the same host reuse each other's connections
+var (
+	transportsMu sync.Mutex
+	transports   = make(map[transportKey]*http.Transport)
+)
+
+type transportKey struct {
+	proxy          string
+	maxIdlePerHost int
+	idleTimeout    time.Duration
+	noKeepAlives   bool
+}
+
+// newHTTPClient returns a client for provider APIs. By default it honours
+// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY; a non-empty config.Proxy overrides them and
+// routes every request through that proxy. Invalid proxies are rejected by
+// bridge.NewAgent before a provider is built, so here they fall back to the environment.
+// config.Headers are added to every request, and so is a User-Agent (config.UserAgent,
+// else DefaultUserAgent) unless the headers already name one.
+func newHTTPClient(config ProviderConfig) *http.Client {
+	headers := make(http.Header, len(config.Headers))
+	for name, value := range config.Headers {
+		headers.Set(name, value)
+	}
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+	return &http.Client{Transport: &headerTransport{base: sharedTransport(config), headers: headers, override: config.OverrideHeaders, userAgent: userAgent}}
+}
+
+// sharedTransport returns the transport for config's proxy and connection
+// settings, creating it on first use
+func sharedTransport(config ProviderConfig) *http.Transport {
+	key := transportKey{
+		proxy:          config.Proxy,
+		maxIdlePerHost: config.MaxIdleConnsPerHost,
+		idleTimeout:    config.IdleConnTimeout,
+		noKeepAlives:   config.DisableKeepAlives,
+	}
+	if key.maxIdlePerHost <= 0 {
+		key.maxIdlePerHost = DefaultMaxIdleConnsPerHost
+	}
+	if key.idleTimeout <= 0 {
+		key.idleTimeout = DefaultIdleConnTimeout
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport
+	}
+	transport := newTransport(key)
+	transports[key] = transport
+	return transport
+}
+
+// newTransport builds a transport that negotiates HTTP/2 where the server offers
+// it, so streams to one host can share a single connection
+func newTransport(key transportKey) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if key.proxy != "" {
+		if u, err := ParseProxy(key.proxy); err == nil {
+			transport.Proxy = http.ProxyURL(u)
+		}
+	}
+
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConnsPerHost = key.maxIdlePerHost
+	transport.IdleConnTimeout = key.idleTimeout
+	transport.DisableKeepAlives = key.noKeepAlives
+	return transport
+}
+
+// DefaultUserAgent identifies chat-bridge and its version to providers
+func DefaultUserAgent() string {
+	return "chat-bridge/" + version.GetVersion()
+}
+
+// headerTransport adds custom headers and the User-Agent to each request. Headers
+// already set by the provider are left alone unless override is true. Responses
+// are gzip-decoded where the base transport left that to the caller.
+type headerTransport struct {
+	base      http.RoundTripper
+	headers   http.Header
+	override  bool
+	userAgent string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		if !t.override && req.Header.Get(name) != "" {
+			continue
+		}
+		req.Header[name] = values
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		decodeGzip(resp)
+	}
+	return resp, err
+}
+
+// decodeGzip unwraps a gzip-encoded response body. http.Transport only decodes
+// responses to its own Accept-Encoding, so a gzip body still arrives compressed
+// when custom headers ask for it or a gateway compresses unasked.
+func decodeGzip(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if resp.Uncompressed || (encoding != "gzip" && encoding != "x-gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decompresses a response body. The gzip header is read on the first
+// Read rather than up front, so a stream's response isn't held up until the
+// provider sends its first event.
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+	err    error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = gzip.NewReader(b.body)
+		if b.err != nil && b.err != io.EOF {
+			b.err = fmt.Errorf("invalid gzip response: %w", b.err)
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// ParseHeader splits a "Name=value" header flag, validating the name
+func ParseHeader(raw string) (name, value string, err error) {
+	name, value, ok := strings.Cut(raw, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("header %q must be in the form Name=value", raw)
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return "", "", fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return http.CanonicalHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// redactURL strips credentials from a URL before it is logged
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "[unparseable URL]"
+	}
+	if u.User != nil {
+		u.User = url.User("redacted")
+	}
+
+	query := u.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, "redacted")
+				changed = true
+			}
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// MaxImageSize is the largest local image file LoadImage accepts
+const MaxImageSize = 20 << 20
+
+// imageTypes are the image formats multimodal APIs commonly accept
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Image is an image attached to a message, referenced by URL or carried inline
+type Image struct {
+	URL       string // Remote http(s) URL; empty for inline images
+	MediaType string // MIME type of inline data (e.g., "image/png")
+	Data      string // Base64-encoded inline image data
+}
+
+// DataURL returns the image as a URL providers can send: the remote URL, or a data: URL
+func (i Image) DataURL() string {
+	if i.URL != "" {
+		return i.URL
+	}
+	return "data:" + i.MediaType + ";base64," + i.Data
+}
+
+// LoadImage resolves ref into an Image. ref may be an http(s) URL, a base64
+// data: URL, or a path to a local PNG, JPEG, GIF, or WebP file.
+func LoadImage(ref string) (Image, error) {
+	switch {
+	case strings.HasPrefix(ref, "http://"), strings.HasPrefix(ref, "https://"):
+		u, err := url.Parse(ref)
+		if err != nil || u.Host == "" {
+			return Image{}, fmt.Errorf("invalid image URL %q", ref)
+		}
+		return Image{URL: ref}, nil
+
+	case strings.HasPrefix(ref, "data:"):
+		return parseDataURL(ref)
+	}
+
+	info, err := os.Stat(ref)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.IsDir() {
+		return Image{}, fmt.Errorf("image %s is a directory", ref)
+	}
+	if info.Size() > MaxImageSize {
+		return Image{}, fmt.Errorf("image %s is too large (%d MB, max %d MB)", ref, info.Size()>>20, MaxImageSize>>20)
+	}
+
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	mediaType := http.DetectContentType(data)
+	if !imageTypes[mediaType] {
+		return Image{}, fmt.Errorf("%s is not a supported image (detected %s; use PNG, JPEG, GIF, or WebP)", ref, mediaType)
+	}
+	return Image{MediaType: mediaType, Data: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// parseDataURL validates a data:<type>;base64,<data> image URL
+func parseDataURL(ref string) (Image, error) {
+	meta, data, ok := strings.Cut(strings.TrimPrefix(ref, "data:"), ",")
+	mediaType, encoding, _ := strings.Cut(meta, ";")
+	if !ok || encoding != "base64" {
+		return Image{}, fmt.Errorf("image data URLs must be base64 encoded (data:image/png;base64,...)")
+	}
+	if !imageTypes[mediaType] {
+		return Image{}, fmt.Errorf("unsupported image type %q in data URL", mediaType)
+	}
+	if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+		return Image{}, fmt.Errorf("invalid base64 in image data URL: %w", err)
+	}
+	return Image{MediaType: mediaType, Data: data}, nil
+}
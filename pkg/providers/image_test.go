@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	png := filepath.Join(dir, "chart.png")
+	text := filepath.Join(dir, "notes.txt")
+	os.WriteFile(png, pngHeader, 0o600)
+	os.WriteFile(text, []byte("not an image"), 0o600)
+
+	img, err := LoadImage(png)
+	if err != nil {
+		t.Fatalf("load png: %v", err)
+	}
+	if img.MediaType != "image/png" || img.Data != base64.StdEncoding.EncodeToString(pngHeader) {
+		t.Fatalf("unexpected image: %+v", img)
+	}
+	if !strings.HasPrefix(img.DataURL(), "data:image/png;base64,") {
+		t.Fatalf("unexpected data URL: %s", img.DataURL())
+	}
+
+	if img, err := LoadImage("https://example.com/cat.jpg"); err != nil || img.DataURL() != "https://example.com/cat.jpg" {
+		t.Fatalf("remote URL: %+v, %v", img, err)
+	}
+	if img, err := LoadImage("data:image/gif;base64,R0lGODlh"); err != nil || img.MediaType != "image/gif" {
+		t.Fatalf("data URL: %+v, %v", img, err)
+	}
+
+	for _, bad := range []string{
+		text,
+		dir,
+		filepath.Join(dir, "missing.png"),
+		"https://",
+		"data:image/png,plain",
+		"data:text/plain;base64,aGk=",
+		"data:image/png;base64,%%%",
+	} {
+		if _, err := LoadImage(bad); err == nil {
+			t.Errorf("LoadImage(%q): expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// localModelError recognizes the error bodies local OpenAI-compatible servers send
+// when the requested model isn't available and returns an error wrapping
+// ErrModelNotFound that says how to fix it, or nil for any other failure. Ollama
+// answers "model ... not found, try pulling it first"; LM Studio answers "No
+// models loaded" until one is loaded.
+func localModelError(body []byte, model string) error {
+	message := strings.ToLower(apiErrorMessage(body))
+	switch {
+	case strings.Contains(message, "try pulling it"):
+		return fmt.Errorf("%w: Ollama hasn't pulled %q; run 'ollama pull %s' and try again", ErrModelNotFound, model, model)
+	case strings.Contains(message, "no models loaded"):
+		return fmt.Errorf("%w: LM Studio has no model loaded; load %q in the app or run 'lms load %s'", ErrModelNotFound, model, model)
+	}
+	return nil
+}
+
+// apiErrorMessage extracts the message from an error body shaped either like
+// OpenAI's {"error": {"message": "..."}} or Ollama's native {"error": "..."},
+// falling back to the raw body
+func apiErrorMessage(body []byte) string {
+	var parsed struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && len(parsed.Error) > 0 {
+		var message string
+		if json.Unmarshal(parsed.Error, &message) == nil {
+			return message
+		}
+		var detail struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &detail) == nil && detail.Message != "" {
+			return detail.Message
+		}
+	}
+	return string(body)
+}
@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Logit bias limits accepted by OpenAI; -100 effectively bans a token and 100
+// effectively forces it
+const (
+	MinLogitBias = -100
+	MaxLogitBias = 100
+)
+
+// ParseLogitBias turns repeated "tokenID=bias" flags into a ChatRequest.LogitBias
+// map, e.g. "50256=-100" to forbid GPT's end-of-text token
+func ParseLogitBias(pairs []string) (map[string]float64, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	bias := make(map[string]float64, len(pairs))
+	for _, raw := range pairs {
+		token, value, ok := strings.Cut(raw, "=")
+		if !ok {
+			return nil, fmt.Errorf("logit bias %q must be in the form tokenID=bias", raw)
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("logit bias %q: %q is not a number", raw, value)
+		}
+		bias[strings.TrimSpace(token)] = n
+	}
+	if err := ValidateLogitBias(bias); err != nil {
+		return nil, err
+	}
+	return bias, nil
+}
+
+// ValidateLogitBias checks that every key is a token ID and every bias is within
+// MinLogitBias..MaxLogitBias
+func ValidateLogitBias(bias map[string]float64) error {
+	tokens := make([]string, 0, len(bias))
+	for token := range bias {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	for _, token := range tokens {
+		if id, err := strconv.Atoi(token); err != nil || id < 0 {
+			return fmt.Errorf("logit bias token %q must be a token ID (a non-negative integer)", token)
+		}
+		if v := bias[token]; v < MinLogitBias || v > MaxLogitBias {
+			return fmt.Errorf("logit bias %g for token %s is outside %d..%d", v, token, MinLogitBias, MaxLogitBias)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLogitBias(t *testing.T) {
+	bias, err := ParseLogitBias([]string{"50256=-100", " 1734 = 2.5", "50256=-50"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(bias) != 2 || bias["50256"] != -50 || bias["1734"] != 2.5 {
+		t.Fatalf("unexpected bias: %v", bias)
+	}
+	if bias, err := ParseLogitBias(nil); bias != nil || err != nil {
+		t.Fatalf("expected no bias without flags, got %v, %v", bias, err)
+	}
+
+	for raw, want := range map[string]string{
+		"50256":      "tokenID=bias",
+		"50256=lots": "not a number",
+		"hello=5":    "token ID",
+		"-3=5":       "token ID",
+		"50256=101":  "outside -100..100",
+		"50256=-150": "outside -100..100",
+	} {
+		if _, err := ParseLogitBias([]string{raw}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", raw, want, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package providers
+
+import "context"
+
+// Finish reasons reported in ResponseMeta; providers may report others of their own
+const (
+	FinishStop          = "stop"           // The model ended its reply
+	FinishLength        = "length"         // Cut off by ChatRequest.MaxTokens
+	FinishContentFilter = "content_filter" // Withheld or cut off by the provider's content filter
+	FinishToolCalls     = "tool_calls"     // The model stopped to call tools
+	FinishRefusal       = "refusal"        // The model declined to answer, as Anthropic reports it
+)
+
+// ResponseMeta describes how a provider served a response
+type ResponseMeta struct {
+	Model        string // Model that served the request; may differ from the requested one, e.g. when OpenRouter routes it
+	FinishReason string // Why the reply ended, e.g. FinishStop or FinishLength
+	Refusal      string // The model's refusal message, when it declined and said why apart from the reply
+}
+
+// MetaStreamer is implemented by providers that report ResponseMeta. StreamChatMeta
+// behaves like StreamChatTools, and sends whatever metadata the stream carried on
+// the fourth channel before the text channel closes.
+type MetaStreamer interface {
+	StreamChatMeta(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error, <-chan []ToolCall, <-chan ResponseMeta)
+}
@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// nonStreaming records the chat completions endpoints that answered a streaming
+// request with one JSON response. Later requests to them ask for stream: false
+// rather than relying on the probe again.
+var (
+	nonStreamingMu sync.Mutex
+	nonStreaming   = make(map[string]bool)
+)
+
+// streamsUnsupported reports whether the endpoint was found not to stream
+func streamsUnsupported(url string) bool {
+	nonStreamingMu.Lock()
+	defer nonStreamingMu.Unlock()
+	return nonStreaming[url]
+}
+
+// markNonStreaming caches that the endpoint doesn't stream, logging it the first time
+func markNonStreaming(provider, url string) {
+	nonStreamingMu.Lock()
+	defer nonStreamingMu.Unlock()
+	if nonStreaming[url] {
+		return
+	}
+	nonStreaming[url] = true
+	slog.Warn("provider ignored stream: true; falling back to non-streaming requests", "provider", provider, "url", redactURL(url))
+}
+
+// isCompleteResponse peeks at a 200 response to tell a whole JSON body from an
+// event stream by its first byte that isn't whitespace: an event stream never
+// starts with "{". The content type isn't trusted, as some servers label their
+// streams application/json.
+func isCompleteResponse(body *bufio.Reader) bool {
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return false // Let the SSE reader report the empty or broken stream
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		body.UnreadByte()
+		return b == '{'
+	}
+}
+
+// readCompletion decodes a non-streaming chat completion and sends its content as
+// a single chunk, followed by its tool calls and response metadata
+func (p *OpenAIProvider) readCompletion(ctx context.Context, body io.Reader, textChan chan<- string, errChan chan<- error, callsChan chan<- []ToolCall, metaChan chan<- ResponseMeta) {
+	var completion struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content   string `json:"content"`
+				Refusal   string `json:"refusal"`
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(body).Decode(&completion); err != nil {
+		if ctx.Err() != nil {
+			errChan <- ErrContextCancelled
+			return
+		}
+		errChan <- fmt.Errorf("%w: decoding the non-streaming response: %w", ErrStreamingFailed, err)
+		return
+	}
+	// Gateways that don't stream sometimes report errors with a 200 status too
+	if completion.Error != nil {
+		errChan <- fmt.Errorf("API error: %s", completion.Error.Message)
+		return
+	}
+	if len(completion.Choices) == 0 {
+		errChan <- ErrEmptyStream
+		return
+	}
+
+	choice := completion.Choices[0]
+	meta := ResponseMeta{Model: completion.Model, FinishReason: choice.FinishReason, Refusal: choice.Message.Refusal}
+	var calls []ToolCall
+	for _, call := range choice.Message.ToolCalls {
+		calls = append(calls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments})
+	}
+	slog.Debug("provider response read whole", "provider", p.Name(), "chars", len(choice.Message.Content), "tool_calls", len(calls), "served_model", meta.Model, "finish_reason", meta.FinishReason)
+
+	if choice.Message.Content != "" {
+		select {
+		case textChan <- choice.Message.Content:
+		case <-ctx.Done():
+			errChan <- ErrContextCancelled
+			return
+		}
+	}
+	if len(calls) > 0 {
+		callsChan <- calls
+	}
+	metaChan <- meta
+}
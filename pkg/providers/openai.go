@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"strings"
+	"sort"
+	"time"
 )
 
 func init() {
@@ -19,13 +21,30 @@ func init() {
 		Description:  "GPT models from OpenAI",
 		DefaultModel: "gpt-4o-mini",
 		NeedsAPIKey:  true,
-		Models: []string{
-			"gpt-4o",
-			"gpt-4o-mini",
-			"gpt-4-turbo",
-			"gpt-4",
-			"gpt-3.5-turbo",
+		Models: []ModelInfo{
+			{ID: "gpt-4o", ContextWindow: 128000, MaxOutputTokens: 16384, InputPrice: 2.5, OutputPrice: 10},
+			{ID: "gpt-4o-mini", ContextWindow: 128000, MaxOutputTokens: 16384, InputPrice: 0.15, OutputPrice: 0.6},
+			{ID: "gpt-4-turbo", ContextWindow: 128000, MaxOutputTokens: 4096, InputPrice: 10, OutputPrice: 30},
+			{ID: "gpt-4", ContextWindow: 8192, MaxOutputTokens: 8192, InputPrice: 30, OutputPrice: 60},
+			{ID: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutputTokens: 4096, InputPrice: 0.5, OutputPrice: 1.5},
+			{ID: "o1", ContextWindow: 200000, MaxOutputTokens: 100000, InputPrice: 15, OutputPrice: 60, FixedTemperature: true},
+			{ID: "o1-mini", ContextWindow: 128000, MaxOutputTokens: 65536, InputPrice: 1.1, OutputPrice: 4.4, FixedTemperature: true},
+			{ID: "o3-mini", ContextWindow: 200000, MaxOutputTokens: 100000, InputPrice: 1.1, OutputPrice: 4.4, FixedTemperature: true},
 		},
+		MinTemperature: 0,
+		MaxTemperature: 2,
+
+		SupportsSystemPrompt: true,
+		SupportsSeed:         true,
+		SupportsTopP:         true,
+		SupportsPenalties:    true,
+		SupportsImages:       true,
+		SupportsTools:        true,
+		SupportsJSONMode:     true,
+		SupportsJSONSchema:   true,
+		SupportsLogitBias:    true,
+
+		TokenCounter: openAITokenCounter,
 	})
 
 	// Register the factory so the CLI can instantiate providers dynamically
@@ -34,12 +53,15 @@ func init() {
 	})
 }
 
-// OpenAIProvider implements the Provider interface for OpenAI
+// OpenAIProvider implements the Provider interface for OpenAI. It speaks the chat
+// completions API unless ProviderConfig.API selects the Responses API.
 type OpenAIProvider struct {
 	apiKey  string
 	baseURL string
 	model   string
+	api     string
 	client  *http.Client
+	trace   *tracer
 }
 
 // NewOpenAIProvider creates a new OpenAI provider instance
@@ -58,7 +80,9 @@ func NewOpenAIProvider(config ProviderConfig) *OpenAIProvider {
 		apiKey:  config.APIKey,
 		baseURL: baseURL,
 		model:   model,
-		client:  &http.Client{},
+		api:     config.API,
+		client:  newHTTPClient(config),
+		trace:   newTracer(config.TraceDir),
 	}
 }
 
@@ -72,10 +96,56 @@ func (p *OpenAIProvider) DefaultModel() string {
 	return p.model
 }
 
-// Models returns available models
+// modelsTimeout bounds a model list request, so an unresponsive API falls back to
+// the known models quickly
+const modelsTimeout = 10 * time.Second
+
+// Models returns the models the API currently offers, sorted by ID. Every failure,
+// including an empty list, wraps ErrModelListFailed.
 func (p *OpenAIProvider) Models(ctx context.Context) ([]string, error) {
-	spec, _ := GetProviderSpec("openai")
-	return spec.Models, nil
+	ctx, cancel := context.WithTimeout(ctx, modelsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrModelListFailed, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	slog.Debug("listing models", "provider", p.Name(), "url", redactURL(req.URL.String()))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: connection failed: %w", ErrModelListFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return nil, fmt.Errorf("%w: %w", ErrModelListFailed, ErrInvalidCredentials)
+	}
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: API error (status %d): %s", ErrModelListFailed, resp.StatusCode, string(body))
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("%w: invalid model list: %w", ErrModelListFailed, err)
+	}
+	models := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("%w: the API listed no models", ErrModelListFailed)
+	}
+	sort.Strings(models)
+	return models, nil
 }
 
 // Health checks if the provider is accessible
@@ -87,11 +157,13 @@ func (p *OpenAIProvider) Health(ctx context.Context) error {
 
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 
+	slog.Debug("provider health check", "provider", p.Name(), "url", redactURL(req.URL.String()))
 	resp, err := p.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("connection failed: %w", err)
 	}
 	defer resp.Body.Close()
+	slog.Debug("provider health response", "provider", p.Name(), "status", resp.StatusCode)
 
 	if resp.StatusCode == 401 {
 		return ErrInvalidCredentials
@@ -107,23 +179,36 @@ func (p *OpenAIProvider) Health(ctx context.Context) error {
 
 // StreamChat initiates a streaming chat completion
 func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error) {
+	textChan, errChan, _ := p.StreamChatTools(ctx, req)
+	return textChan, errChan
+}
+
+// StreamChatTools streams a chat completion and reports any tool calls the model makes
+func (p *OpenAIProvider) StreamChatTools(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error, <-chan []ToolCall) {
+	textChan, errChan, callsChan, _ := p.StreamChatMeta(ctx, req)
+	return textChan, errChan, callsChan
+}
+
+// StreamChatMeta streams a chat completion, reporting tool calls and the served
+// model and finish reason from the stream's chunks
+func (p *OpenAIProvider) StreamChatMeta(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error, <-chan []ToolCall, <-chan ResponseMeta) {
 	textChan := make(chan string)
 	errChan := make(chan error, 1)
+	callsChan := make(chan []ToolCall, 1)
+	metaChan := make(chan ResponseMeta, 1)
 
 	go func() {
 		defer close(textChan)
 		defer close(errChan)
+		defer close(callsChan)
+		defer close(metaChan)
 
 		// Build request body
-		requestBody := map[string]interface{}{
-			"model":       req.Model,
-			"messages":    p.convertMessages(req.Messages),
-			"temperature": req.Temperature,
-			"stream":      true,
-		}
-
-		if req.MaxTokens > 0 {
-			requestBody["max_tokens"] = req.MaxTokens
+		path, requestBody := "/chat/completions", p.chatBody(req)
+		if p.api == APIResponses {
+			path, requestBody = "/responses", responsesBody(req)
+		} else if streamsUnsupported(p.baseURL + path) {
+			requestBody["stream"] = false
 		}
 
 		jsonData, err := json.Marshal(requestBody)
@@ -136,7 +221,7 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-ch
 		httpReq, err := http.NewRequestWithContext(
 			ctx,
 			"POST",
-			p.baseURL+"/chat/completions",
+			p.baseURL+path,
 			bytes.NewBuffer(jsonData),
 		)
 		if err != nil {
@@ -147,23 +232,71 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-ch
 		httpReq.Header.Set("Content-Type", "application/json")
 		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 
+		traceOut := p.trace.begin(traceRecord{
+			Provider: p.Name(),
+			Method:   httpReq.Method,
+			URL:      redactURL(httpReq.URL.String()),
+			Body:     jsonData,
+
+			Conversation: ConversationID(ctx),
+		})
+		defer traceOut.Close()
+
 		// Make request
+		started := time.Now()
+		slog.Debug("provider request", "provider", p.Name(), "url", redactURL(httpReq.URL.String()), "model", req.Model, "messages", len(req.Messages), "conversation", ConversationID(ctx))
 		resp, err := p.client.Do(httpReq)
 		if err != nil {
+			slog.Debug("provider request failed", "provider", p.Name(), "error", err)
 			errChan <- err
 			return
 		}
 		defer resp.Body.Close()
+		slog.Debug("provider response", "provider", p.Name(), "status", resp.StatusCode, "elapsed", time.Since(started))
+
+		// Tee the raw response to the trace file as it is read (a no-op unless tracing)
+		body := io.TeeReader(resp.Body, traceOut)
 
 		// Check status
 		if resp.StatusCode != 200 {
-			body, _ := io.ReadAll(resp.Body)
+			body, _ := io.ReadAll(body)
+			if err := localModelError(body, req.Model); err != nil {
+				errChan <- err
+				return
+			}
+			if resp.StatusCode == 401 {
+				errChan <- fmt.Errorf("%w: %s", ErrInvalidCredentials, string(body))
+				return
+			}
 			errChan <- fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 			return
 		}
+		if p.api == APIResponses {
+			p.streamResponses(ctx, body, started, textChan, errChan, callsChan, metaChan)
+			return
+		}
 
-		// Stream response
-		reader := bufio.NewReader(resp.Body)
+		// Some OpenAI-compatible gateways ignore stream: true and answer with the
+		// whole completion; read that as one chunk rather than failing as SSE
+		buffered := bufio.NewReader(body)
+		if isCompleteResponse(buffered) {
+			markNonStreaming(p.Name(), p.baseURL+path)
+			p.readCompletion(ctx, buffered, textChan, errChan, callsChan, metaChan)
+			return
+		}
+
+		// Stream response one SSE event at a time
+		events := newSSEReader(buffered)
+		chunks, received := 0, 0
+		var calls toolCallAccumulator
+		var meta ResponseMeta
+		defer func() {
+			slog.Debug("provider stream finished", "provider", p.Name(), "chunks", chunks, "tool_calls", len(calls.calls), "served_model", meta.Model, "finish_reason", meta.FinishReason, "elapsed", time.Since(started))
+		}()
+		finish := func() {
+			calls.send(callsChan)
+			metaChan <- meta
+		}
 		for {
 			select {
 			case <-ctx.Done():
@@ -172,39 +305,57 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-ch
 			default:
 			}
 
-			line, err := reader.ReadString('\n')
+			data, err := events.Next()
 			if err != nil {
 				if err == io.EOF {
+					if received == 0 {
+						errChan <- ErrEmptyStream
+						return
+					}
+					finish()
 					return
 				}
-				errChan <- err
+				slog.Debug("provider stream broke", "provider", p.Name(), "events", received, "error", err)
+				errChan <- streamReadError(ctx, err, received)
 				return
 			}
 
-			line = strings.TrimSpace(line)
-			if line == "" || line == "data: [DONE]" {
-				continue
-			}
-
-			if !strings.HasPrefix(line, "data: ") {
-				continue
+			received++
+			if data == "[DONE]" {
+				finish()
+				return
 			}
 
 			// Parse SSE data
-			jsonData := strings.TrimPrefix(line, "data: ")
 			var chunk struct {
+				Model   string `json:"model"`
 				Choices []struct {
 					Delta struct {
-						Content string `json:"content"`
+						Content   string          `json:"content"`
+						Refusal   string          `json:"refusal"`
+						ToolCalls []toolCallDelta `json:"tool_calls"`
 					} `json:"delta"`
+					FinishReason string `json:"finish_reason"`
 				} `json:"choices"`
 			}
 
-			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
 				continue // Skip malformed chunks
 			}
 
+			if chunk.Model != "" {
+				meta.Model = chunk.Model
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != "" {
+				meta.FinishReason = chunk.Choices[0].FinishReason
+			}
+
+			if len(chunk.Choices) > 0 {
+				calls.add(chunk.Choices[0].Delta.ToolCalls)
+				meta.Refusal += chunk.Choices[0].Delta.Refusal
+			}
 			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				chunks++
 				select {
 				case textChan <- chunk.Choices[0].Delta.Content:
 				case <-ctx.Done():
@@ -215,17 +366,182 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-ch
 		}
 	}()
 
-	return textChan, errChan
+	return textChan, errChan, callsChan, metaChan
 }
 
-// convertMessages converts internal message format to OpenAI format
-func (p *OpenAIProvider) convertMessages(messages []Message) []map[string]string {
-	result := make([]map[string]string, len(messages))
-	for i, msg := range messages {
-		result[i] = map[string]string{
-			"role":    msg.Role,
-			"content": msg.Content,
+// chatBody builds a chat completions request
+func (p *OpenAIProvider) chatBody(req *ChatRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    req.Model,
+		"messages": p.convertMessages(req.SystemPrompt, req.Messages),
+		"stream":   true,
+	}
+
+	// Some models (e.g. o1) reject any temperature, so it is only sent when set
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+	if s := req.Sampling; s.Seed != nil {
+		body["seed"] = *s.Seed
+	}
+	if s := req.Sampling; s.TopP != nil {
+		body["top_p"] = *s.TopP
+	}
+	if s := req.Sampling; s.FrequencyPenalty != nil {
+		body["frequency_penalty"] = *s.FrequencyPenalty
+	}
+	if s := req.Sampling; s.PresencePenalty != nil {
+		body["presence_penalty"] = *s.PresencePenalty
+	}
+	if len(req.LogitBias) > 0 {
+		body["logit_bias"] = req.LogitBias
+	}
+	if req.ResponseFormat == ResponseFormatJSONSchema && req.Schema != nil {
+		body["response_format"] = map[string]interface{}{
+			"type":        ResponseFormatJSONSchema,
+			"json_schema": map[string]interface{}{"name": req.Schema.Name, "schema": req.Schema.Document, "strict": true},
+		}
+	} else if req.ResponseFormat != "" {
+		body["response_format"] = map[string]string{"type": req.ResponseFormat}
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = convertTools(req.Tools)
+		if choice := convertToolChoice(req.ToolChoice); choice != nil {
+			body["tool_choice"] = choice
+		}
+	}
+	return body
+}
+
+// toolCallDelta is one streamed fragment of a tool call; arguments arrive in pieces
+type toolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// toolCallAccumulator reassembles streamed tool call fragments by index
+type toolCallAccumulator struct {
+	calls []ToolCall
+}
+
+func (a *toolCallAccumulator) add(deltas []toolCallDelta) {
+	for _, d := range deltas {
+		if d.Index < 0 || d.Index > 64 {
+			continue // Ignore nonsense indexes rather than allocating for them
+		}
+		for len(a.calls) <= d.Index {
+			a.calls = append(a.calls, ToolCall{})
+		}
+		call := &a.calls[d.Index]
+		if d.ID != "" {
+			call.ID = d.ID
+		}
+		call.Name += d.Function.Name
+		call.Arguments += d.Function.Arguments
+	}
+}
+
+// send reports the completed calls, if any; callsChan is buffered so this never blocks
+func (a *toolCallAccumulator) send(callsChan chan<- []ToolCall) {
+	if len(a.calls) > 0 {
+		callsChan <- a.calls
+	}
+}
+
+// convertTools converts tool specs to OpenAI function tools
+func convertTools(tools []ToolSpec) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(tools))
+	for i, tool := range tools {
+		function := map[string]interface{}{
+			"name":        tool.Name,
+			"description": tool.Description,
+		}
+		if len(tool.Parameters) > 0 {
+			function["parameters"] = tool.Parameters
+		}
+		result[i] = map[string]interface{}{"type": "function", "function": function}
+	}
+	return result
+}
+
+// convertToolChoice maps ChatRequest.ToolChoice to OpenAI's tool_choice; nil leaves the default
+func convertToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+		return choice
+	default:
+		return map[string]interface{}{"type": "function", "function": map[string]string{"name": choice}}
+	}
+}
+
+// convertToolMessage converts an assistant tool-call request or a tool result
+func convertToolMessage(msg Message) map[string]interface{} {
+	if msg.ToolCallID != "" {
+		return map[string]interface{}{
+			"role":         "tool",
+			"tool_call_id": msg.ToolCallID,
+			"content":      msg.Content,
+		}
+	}
+
+	calls := make([]map[string]interface{}, len(msg.ToolCalls))
+	for i, call := range msg.ToolCalls {
+		calls[i] = map[string]interface{}{
+			"id":       call.ID,
+			"type":     "function",
+			"function": map[string]string{"name": call.Name, "arguments": call.Arguments},
 		}
 	}
+	result := map[string]interface{}{"role": msg.Role, "tool_calls": calls}
+	if msg.Content != "" {
+		result["content"] = msg.Content
+	}
+	return result
+}
+
+// convertMessages converts internal message format to OpenAI format, leading with the system prompt if set.
+// Messages with images use the content-parts form; others keep plain string content.
+func (p *OpenAIProvider) convertMessages(systemPrompt string, messages []Message) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(messages)+1)
+	if systemPrompt != "" {
+		result = append(result, map[string]interface{}{
+			"role":    "system",
+			"content": systemPrompt,
+		})
+	}
+	for _, msg := range messages {
+		if len(msg.ToolCalls) > 0 || msg.ToolCallID != "" {
+			result = append(result, convertToolMessage(msg))
+			continue
+		}
+		if len(msg.Images) == 0 {
+			result = append(result, map[string]interface{}{
+				"role":    msg.Role,
+				"content": msg.Content,
+			})
+			continue
+		}
+
+		parts := []map[string]interface{}{{"type": "text", "text": msg.Content}}
+		for _, img := range msg.Images {
+			parts = append(parts, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": img.DataURL()},
+			})
+		}
+		result = append(result, map[string]interface{}{
+			"role":    msg.Role,
+			"content": parts,
+		})
+	}
 	return result
 }
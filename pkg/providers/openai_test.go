@@ -0,0 +1,533 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpenAIRequestIncludesSystemPromptAndSampling(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	seed, topP := 42, 0.8
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	_, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Model:        "gpt-test",
+		Messages:     []Message{{Role: "user", Content: "hi"}},
+		SystemPrompt: "You are a pirate.",
+		Sampling:     Sampling{Seed: &seed, TopP: &topP},
+
+		ResponseFormat: ResponseFormatJSON,
+	}))
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	messages := body["messages"].([]interface{})
+	first := messages[0].(map[string]interface{})
+	if len(messages) != 2 || first["role"] != "system" || first["content"] != "You are a pirate." {
+		t.Fatalf("expected system prompt first, got %v", messages)
+	}
+	if body["seed"] != float64(42) || body["top_p"] != 0.8 {
+		t.Fatalf("sampling params missing: %v", body)
+	}
+	if format, _ := body["response_format"].(map[string]interface{}); format["type"] != "json_object" {
+		t.Fatalf("expected JSON response format, got %v", body["response_format"])
+	}
+	if _, ok := body["frequency_penalty"]; ok {
+		t.Fatal("unset parameters must not be sent")
+	}
+}
+
+func TestOpenAIRequestSendsTemperatureOnlyWhenSet(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	send := func(temperature *float64) {
+		t.Helper()
+		_, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+			Model:       "o1-mini",
+			Messages:    []Message{{Role: "user", Content: "hi"}},
+			Temperature: temperature,
+		}))
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+	}
+
+	// Zero is a real setting (greedy sampling), not "unset"
+	send(Float(0))
+	if temp, ok := body["temperature"]; !ok || temp != float64(0) {
+		t.Fatalf("expected temperature 0 to be sent, got %v", body)
+	}
+
+	send(nil)
+	if _, ok := body["temperature"]; ok {
+		t.Fatalf("unset temperature must be omitted, got %v", body)
+	}
+	if _, ok := body["logit_bias"]; ok {
+		t.Fatalf("unset logit bias must be omitted, got %v", body)
+	}
+
+	_, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Model:     "gpt-4o",
+		Messages:  []Message{{Role: "user", Content: "hi"}},
+		LogitBias: map[string]float64{"50256": -100, "1734": 2.5},
+	}))
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if bias, _ := body["logit_bias"].(map[string]interface{}); len(bias) != 2 || bias["50256"] != float64(-100) || bias["1734"] != 2.5 {
+		t.Fatalf("expected the logit bias to be sent, got %v", body)
+	}
+}
+
+func TestOpenAIRequestSendsImagesAsContentParts(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	_, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Model: "gpt-test",
+		Messages: []Message{
+			{Role: "user", Content: "What is this?", Images: []Image{{URL: "https://example.com/cat.jpg"}, {MediaType: "image/png", Data: "iVBO"}}},
+			{Role: "assistant", Content: "A cat."},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	var parts []struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		ImageURL struct {
+			URL string `json:"url"`
+		} `json:"image_url"`
+	}
+	if err := json.Unmarshal(body.Messages[0].Content, &parts); err != nil {
+		t.Fatalf("expected content parts, got %s", body.Messages[0].Content)
+	}
+	if len(parts) != 3 || parts[0].Type != "text" || parts[0].Text != "What is this?" ||
+		parts[1].Type != "image_url" || parts[1].ImageURL.URL != "https://example.com/cat.jpg" ||
+		parts[2].ImageURL.URL != "data:image/png;base64,iVBO" {
+		t.Fatalf("unexpected content parts: %+v", parts)
+	}
+
+	// Text-only messages keep plain string content
+	if string(body.Messages[1].Content) != `"A cat."` {
+		t.Fatalf("expected string content, got %s", body.Messages[1].Content)
+	}
+}
+
+func TestOpenAIStreamsToolCalls(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		io.WriteString(w, `data: {"choices":[{"delta":{"content":"Checking. "}}]}`+"\n\n")
+		io.WriteString(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"calculator","arguments":"{\"expr"}}]}}]}`+"\n\n")
+		io.WriteString(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ession\":\"6*7\"}"}}]}}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	textChan, errChan, callsChan := p.StreamChatTools(context.Background(), &ChatRequest{
+		Model: "gpt-test",
+		Messages: []Message{
+			{Role: "user", Content: "What is 6*7?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_0", Name: "calculator", Arguments: `{"expression":"1+1"}`}}},
+			{Role: "tool", Content: "2", ToolCallID: "call_0"},
+		},
+		Tools:      []ToolSpec{{Name: "calculator", Description: "Do math", Parameters: json.RawMessage(`{"type":"object"}`)}},
+		ToolChoice: "calculator",
+	})
+	text, err := collectStream(textChan, errChan)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if text != "Checking. " {
+		t.Fatalf("unexpected text %q", text)
+	}
+
+	calls := <-callsChan
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "calculator" || calls[0].Arguments != `{"expression":"6*7"}` {
+		t.Fatalf("tool call not reassembled: %+v", calls)
+	}
+
+	tools := body["tools"].([]interface{})
+	function := tools[0].(map[string]interface{})["function"].(map[string]interface{})
+	if len(tools) != 1 || function["name"] != "calculator" || function["parameters"] == nil {
+		t.Fatalf("unexpected tools: %v", body["tools"])
+	}
+	choice, _ := json.Marshal(body["tool_choice"])
+	if string(choice) != `{"function":{"name":"calculator"},"type":"function"}` {
+		t.Fatalf("unexpected tool_choice: %s", choice)
+	}
+
+	// The earlier exchange is replayed as an assistant tool_calls message and a tool result
+	messages := body["messages"].([]interface{})
+	assistant := messages[1].(map[string]interface{})
+	result := messages[2].(map[string]interface{})
+	if assistant["tool_calls"] == nil || result["role"] != "tool" || result["tool_call_id"] != "call_0" || result["content"] != "2" {
+		t.Fatalf("unexpected tool messages: %v", messages)
+	}
+}
+
+func TestOpenAIModelsListsLiveCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer test" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"object":"list","data":[{"id":"gpt-4o"},{"id":"dall-e-3"},{"id":"gpt-4.1"}]}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	models, err := p.Models(context.Background())
+	if err != nil {
+		t.Fatalf("models: %v", err)
+	}
+	if want := []string{"dall-e-3", "gpt-4.1", "gpt-4o"}; strings.Join(models, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", models, want)
+	}
+}
+
+func TestOpenAIModelsWrapsFailures(t *testing.T) {
+	status, reply := 0, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, reply)
+	}))
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		reply  string
+		also   error
+	}{
+		{"server error", 500, "overloaded", nil},
+		{"bad key", 401, "", ErrInvalidCredentials},
+		{"invalid JSON", 200, "<html>", nil},
+		{"empty list", 200, `{"object":"list","data":[]}`, nil},
+	} {
+		status, reply = tc.status, tc.reply
+		models, err := p.Models(context.Background())
+		if !errors.Is(err, ErrModelListFailed) || (tc.also != nil && !errors.Is(err, tc.also)) || models != nil {
+			t.Fatalf("%s: expected ErrModelListFailed, got %v, %v", tc.name, models, err)
+		}
+	}
+
+	// An unreachable API is a wrapped connection failure too
+	server.Close()
+	if _, err := p.Models(context.Background()); !errors.Is(err, ErrModelListFailed) || !strings.Contains(err.Error(), "connection failed") {
+		t.Fatalf("expected a wrapped connection failure, got %v", err)
+	}
+}
+
+func TestOpenAIReportsResponseMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `data: {"model":"gpt-4o-2024-08-06","choices":[{"delta":{"content":"Cut"},"finish_reason":null}]}`+"\n\n")
+		io.WriteString(w, `data: {"model":"gpt-4o-2024-08-06","choices":[{"delta":{},"finish_reason":"length"}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+
+	textChan, errChan, _, metaChan := p.StreamChatMeta(context.Background(), &ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if text, err := collectStream(textChan, errChan); err != nil || text != "Cut" {
+		t.Fatalf("unexpected stream: %q, %v", text, err)
+	}
+	if meta := <-metaChan; meta != (ResponseMeta{Model: "gpt-4o-2024-08-06", FinishReason: FinishLength}) {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestOpenAIReportsRefusals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `data: {"model":"gpt-4o","choices":[{"delta":{"refusal":"I can't "},"finish_reason":null}]}`+"\n\n")
+		io.WriteString(w, `data: {"model":"gpt-4o","choices":[{"delta":{"refusal":"help with that."},"finish_reason":"stop"}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+
+	textChan, errChan, _, metaChan := p.StreamChatMeta(context.Background(), &ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if text, err := collectStream(textChan, errChan); err != nil || text != "" {
+		t.Fatalf("expected no reply text, got %q, %v", text, err)
+	}
+	if meta := <-metaChan; meta.Refusal != "I can't help with that." {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestOpenAIReportsEmptyStreams(t *testing.T) {
+	reply := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, reply)
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	req := &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	// A stream closed before any event is the loading-model case...
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); !errors.Is(err, ErrEmptyStream) {
+		t.Fatalf("expected ErrEmptyStream, got %v", err)
+	}
+
+	// ...while a completed response with no text is a legitimate empty reply
+	reply = `data: {"choices":[{"delta":{},"finish_reason":"stop"}]}` + "\n\n"
+	if text, err := collectStream(p.StreamChat(context.Background(), req)); err != nil || text != "" {
+		t.Fatalf("expected an empty reply without error, got %q, %v", text, err)
+	}
+}
+
+func TestOpenAIFallsBackToNonStreamingResponses(t *testing.T) {
+	var streams []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		streams = append(streams, body["stream"])
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model":"gateway-1","choices":[{"message":{"content":"Whole reply","tool_calls":[{"id":"call_1","function":{"name":"calculator","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`)
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	req := &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	for range 2 {
+		textChan, errChan, callsChan, metaChan := p.StreamChatMeta(context.Background(), req)
+		if text, err := collectStream(textChan, errChan); err != nil || text != "Whole reply" {
+			t.Fatalf("unexpected reply: %q, %v", text, err)
+		}
+		if calls := <-callsChan; len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "calculator" {
+			t.Fatalf("unexpected tool calls: %+v", calls)
+		}
+		if meta := <-metaChan; meta != (ResponseMeta{Model: "gateway-1", FinishReason: "tool_calls"}) {
+			t.Fatalf("unexpected meta: %+v", meta)
+		}
+	}
+
+	// The fallback is cached, so the second request no longer asks for a stream
+	if len(streams) != 2 || streams[0] != true || streams[1] != false {
+		t.Fatalf("expected stream true then false, got %v", streams)
+	}
+}
+
+func TestOpenAIExplainsMissingLocalModels(t *testing.T) {
+	status, reply := 0, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, reply)
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	req := &ChatRequest{Model: "llama3.1:8b", Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"ollama", 404, `{"error":{"message":"model \"llama3.1:8b\" not found, try pulling it first","type":"api_error","param":null,"code":null}}`, "run 'ollama pull llama3.1:8b'"},
+		{"ollama native", 404, `{"error":"model 'llama3.1:8b' not found, try pulling it first"}`, "run 'ollama pull llama3.1:8b'"},
+		{"lm studio", 400, `{"error":"No models loaded. Please load a model in the developer page or use the ` + "`lms load`" + ` command."}`, "run 'lms load llama3.1:8b'"},
+	} {
+		status, reply = tc.status, tc.body
+		_, err := collectStream(p.StreamChat(context.Background(), req))
+		if !errors.Is(err, ErrModelNotFound) || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected an actionable ErrModelNotFound, got %v", tc.name, err)
+		}
+	}
+
+	// Other failures keep the raw API error
+	status, reply = 404, `{"error":{"message":"The model 'gpt-9' does not exist"}}`
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); errors.Is(err, ErrModelNotFound) || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected the raw API error, got %v", err)
+	}
+
+	status, reply = 401, `{"error":{"message":"Incorrect API key provided"}}`
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
+func TestOpenAIClassifiesBrokenStreams(t *testing.T) {
+	event := `data: {"choices":[{"delta":{"content":"Hel"}}]}` + "\n\n"
+	req := &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	// The server promises more body than it sends, then drops the connection
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(event)+100))
+		io.WriteString(w, event)
+	}))
+	defer truncated.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: truncated.URL})
+	text, err := collectStream(p.StreamChat(context.Background(), req))
+	if !errors.Is(err, ErrStreamingFailed) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected a retryable ErrStreamingFailed wrapping the unexpected EOF, got %v", err)
+	}
+	if text != "Hel" || !strings.Contains(err.Error(), "after 1 events") {
+		t.Fatalf("expected the text so far and how far the stream got, got %q, %v", text, err)
+	}
+
+	// A chunked response cut off before its terminating chunk is just as broken
+	hijacked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n", len(event), event)
+		buf.Flush()
+	}))
+	defer hijacked.Close()
+	p = NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: hijacked.URL})
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); !errors.Is(err, ErrStreamingFailed) {
+		t.Fatalf("expected ErrStreamingFailed for a cut-off chunked body, got %v", err)
+	}
+
+	// A server that closes cleanly after its data simply ends the stream
+	clean := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, event)
+	}))
+	defer clean.Close()
+	p = NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: clean.URL})
+	if text, err := collectStream(p.StreamChat(context.Background(), req)); err != nil || text != "Hel" {
+		t.Fatalf("expected a clean end of stream, got %q, %v", text, err)
+	}
+}
+
+func TestOpenAICancelledStreamCloses(t *testing.T) {
+	// The server sends one chunk and then holds the response open
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `data: {"choices":[{"delta":{"content":"Hel"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	textChan, errChan := p.StreamChat(ctx, &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if text := <-textChan; text != "Hel" {
+		t.Fatalf("unexpected first chunk %q", text)
+	}
+	cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := collectStream(textChan, errChan)
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrContextCancelled) {
+			t.Fatalf("expected ErrContextCancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the cancelled stream never closed its channels")
+	}
+}
+
+// Run with -race: one provider instance serves many conversations at once in server mode
+func TestOpenAIConcurrentStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("X-Test") != "shared" {
+			http.Error(w, "missing header", http.StatusBadRequest)
+			return
+		}
+
+		// Echo the last message back a word at a time
+		for _, word := range strings.Fields(body.Messages[len(body.Messages)-1].Content) {
+			chunk, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": word + " "}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{
+		APIKey:   "test",
+		BaseURL:  server.URL,
+		Headers:  map[string]string{"X-Test": "shared"},
+		TraceDir: t.TempDir(),
+	})
+
+	const streams = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, streams)
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			want := fmt.Sprintf("conversation %d says hello ", i)
+			got, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+				Model:    fmt.Sprintf("gpt-test-%d", i),
+				Messages: []Message{{Role: "user", Content: want}},
+			}))
+			if err == nil && got != want {
+				err = fmt.Errorf("stream %d got %q, want %q", i, got, want)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -4,18 +4,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Common errors
 var (
-	ErrProviderNotFound    = errors.New("provider not found")
-	ErrInvalidCredentials  = errors.New("invalid credentials")
-	ErrRateLimitExceeded   = errors.New("rate limit exceeded")
-	ErrContextCancelled    = errors.New("context cancelled")
-	ErrStreamingFailed     = errors.New("streaming failed")
+	ErrProviderNotFound   = errors.New("provider not found")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
+	ErrContextCancelled   = errors.New("context cancelled")
+	ErrModelNotFound      = errors.New("model not found")
+
+	// ErrEmptyStream means the stream ended before any data arrived, as local servers
+	// do while a model is still loading. An explicit empty response is not an error.
+	ErrEmptyStream = errors.New("stream closed without any data")
+
+	// ErrStreamingFailed means the response stream broke off after the request was
+	// accepted, e.g. a connection reset or a truncated body, as opposed to a failed
+	// request. Text received before the break has already been sent on.
+	ErrStreamingFailed = errors.New("streaming failed")
+
+	// ErrModelListFailed means a provider couldn't fetch its live model list. Callers
+	// fall back to the known models in its ProviderSpec.
+	ErrModelListFailed = errors.New("model list unavailable")
 )
 
-// Provider defines the interface that all AI providers must implement
+// Provider defines the interface that all AI providers must implement.
+// Implementations must be safe for concurrent use: server mode shares one instance
+// across conversations, so per-request state belongs in the request, never the struct.
 type Provider interface {
 	// Name returns the provider identifier (e.g., "openai", "anthropic")
 	Name() string
@@ -36,63 +55,267 @@ type Provider interface {
 
 // ChatRequest encapsulates a chat completion request
 type ChatRequest struct {
-	Model       string    // Model ID to use
-	Messages    []Message // Conversation history
-	Temperature float64   // Sampling temperature (0.0 - 2.0)
-	MaxTokens   int       // Maximum tokens to generate
-	SystemPrompt string   // Optional system prompt override
+	Model        string     // Model ID to use
+	Messages     []Message  // Conversation history
+	Temperature  *float64   // Sampling temperature (0.0 - 2.0); nil leaves it to the provider
+	MaxTokens    int        // Maximum tokens to generate
+	SystemPrompt string     // Optional system prompt override
+	Sampling     Sampling   // Optional sampling parameters
+	Tools        []ToolSpec // Functions the model may call (see ToolStreamer)
+	ToolChoice   string     // "auto" (default), "none", "required", or a tool name
+
+	// ResponseFormat constrains the output: "" for free text, ResponseFormatJSON,
+	// or ResponseFormatJSONSchema with Schema. Only sent to providers with
+	// SupportsJSONMode (SupportsJSONSchema for a schema).
+	ResponseFormat string
+	Schema         *JSONSchema
+
+	// LogitBias adjusts the likelihood of tokens, keyed by token ID (as a string)
+	// with biases from MinLogitBias to MaxLogitBias. Token IDs depend on the model's
+	// tokenizer. Only sent to providers with SupportsLogitBias.
+	LogitBias map[string]float64
+}
+
+// ResponseFormatJSON asks for a single JSON object (OpenAI's json_object format, which
+// requires the word "JSON" somewhere in the messages)
+const ResponseFormatJSON = "json_object"
+
+// Float returns a pointer to v, for optional fields such as ChatRequest.Temperature
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Sampling holds optional sampling parameters; nil fields are left to the provider's defaults
+type Sampling struct {
+	Seed             *int     // Deterministic sampling seed
+	TopP             *float64 // Nucleus sampling probability mass
+	FrequencyPenalty *float64 // Penalty for frequently repeated tokens
+	PresencePenalty  *float64 // Penalty for tokens already present
 }
 
 // Message represents a single message in the conversation
 type Message struct {
-	Role    string // "system", "user", or "assistant"
-	Content string // The message content
+	Role    string  // "system", "user", or "assistant"
+	Content string  // The message content
+	Images  []Image // Optional image attachments (sent only to providers that support images)
+
+	ToolCalls  []ToolCall // Calls requested by an assistant message
+	ToolCallID string     // Call answered by a "tool" message
 }
 
 // StreamResponse encapsulates a chunk of streamed response
 type StreamResponse struct {
-	Text   string // The text content
-	Done   bool   // Whether this is the final chunk
+	Text string // The text content
+	Done bool   // Whether this is the final chunk
 }
 
 // ProviderConfig holds provider-specific configuration
 type ProviderConfig struct {
-	APIKey      string // API key for the provider
-	BaseURL     string // Optional custom base URL
-	Model       string // Default model to use
-	Temperature float64 // Default temperature
+	APIKey      string   // API key for the provider
+	BaseURL     string   // Optional custom base URL
+	Model       string   // Default model to use
+	Temperature *float64 // Default temperature; nil leaves it to the provider
+	Command     string   // Command line for the exec provider
+	Proxy       string   // Proxy URL overriding HTTP(S)_PROXY
+	TraceDir    string   // Directory for raw request/response traces (empty disables)
+
+	// Headers are added to every HTTP request, e.g. for gateway routing or tenant IDs.
+	// Headers the provider sets itself (Authorization, Content-Type) take precedence
+	// unless OverrideHeaders is set.
+	Headers         map[string]string
+	OverrideHeaders bool
+
+	// API selects the wire API of OpenAI-compatible providers: APIChatCompletions
+	// (the default when empty) or APIResponses. See ValidateAPI.
+	API string
+
+	// UserAgent is sent with every HTTP request; empty uses DefaultUserAgent. A
+	// User-Agent in Headers takes precedence.
+	UserAgent string
+
+	// Connection reuse across requests: how many idle connections are kept per host
+	// (0 uses DefaultMaxIdleConnsPerHost) and for how long (0 uses
+	// DefaultIdleConnTimeout). DisableKeepAlives opens a new connection, with a new
+	// TLS handshake, for every request.
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
 }
 
 // ProviderSpec describes a provider's metadata
 type ProviderSpec struct {
-	Key          string   // Provider key (e.g., "openai")
-	Name         string   // Display name (e.g., "OpenAI")
-	Description  string   // Human-readable description
-	DefaultModel string   // Default model
-	NeedsAPIKey  bool     // Whether an API key is required
-	Models       []string // List of supported models
+	Key          string      // Provider key (e.g., "openai")
+	Name         string      // Display name (e.g., "OpenAI")
+	Description  string      // Human-readable description
+	DefaultModel string      // Default model
+	NeedsAPIKey  bool        // Whether an API key is required
+	Models       []ModelInfo // Known models and their limits
+
+	// Accepted temperature range; both zero means the provider enforces no bound
+	MinTemperature float64
+	MaxTemperature float64
+
+	// Optional request parameters the provider honours; unsupported ones are ignored
+	SupportsSystemPrompt bool
+	SupportsSeed         bool
+	SupportsTopP         bool
+	SupportsPenalties    bool
+	SupportsImages       bool // Image attachments on messages; other providers see text only
+	SupportsTools        bool // Tool calling via ToolStreamer
+	SupportsJSONMode     bool // ChatRequest.ResponseFormat; other providers are asked by instruction
+	SupportsJSONSchema   bool // ResponseFormatJSONSchema; other providers are shown the schema and validated
+	SupportsLogitBias    bool // ChatRequest.LogitBias
+	QualifiedModels      bool // Model IDs name their vendor, e.g. "anthropic/claude-3.5-sonnet" (OpenRouter)
+
+	// StrictAlternation marks providers that reject two user or two assistant
+	// messages in a row, or system messages once the conversation has started
+	// (e.g. Anthropic's Messages API). The engine merges such runs before sending.
+	StrictAlternation bool
+
+	// TokenCounter returns the provider's preferred counter for a model; nil (or a
+	// nil result) falls back to HeuristicCounter. See CounterFor.
+	TokenCounter func(model string) TokenCounter
 }
 
+// ModelInfo describes a model a provider offers; zero limits and prices are unknown
+type ModelInfo struct {
+	ID              string // Model ID sent in requests
+	ContextWindow   int    // Tokens of input and output the model can attend to
+	MaxOutputTokens int    // Most tokens the model generates in one response
+
+	// List prices in USD per million tokens
+	InputPrice  float64
+	OutputPrice float64
+
+	// FixedTemperature marks models that only run at their default temperature
+	// (e.g. OpenAI's o1 family) and reject requests setting one
+	FixedTemperature bool
+}
+
+// Cost estimates the USD cost of a request at the model's list prices; ok is
+// false when the model has no pricing
+func (m ModelInfo) Cost(inputTokens, outputTokens int) (cost float64, ok bool) {
+	if m.InputPrice == 0 && m.OutputPrice == 0 {
+		return 0, false
+	}
+	return (float64(inputTokens)*m.InputPrice + float64(outputTokens)*m.OutputPrice) / 1e6, true
+}
+
+// ModelIDs returns the IDs of the provider's known models
+func (s ProviderSpec) ModelIDs() []string {
+	ids := make([]string, len(s.Models))
+	for i, m := range s.Models {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// Model looks up a known model's metadata by ID
+func (s ProviderSpec) Model(id string) (ModelInfo, bool) {
+	for _, m := range s.Models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// AcceptsTemperature reports whether requests to model may set a temperature.
+// Dated snapshots (e.g. "o1-2024-12-17") follow the model they snapshot.
+func (s ProviderSpec) AcceptsTemperature(model string) bool {
+	for _, m := range s.Models {
+		if m.FixedTemperature && (model == m.ID || strings.HasPrefix(model, m.ID+"-")) {
+			return false
+		}
+	}
+	return true
+}
+
+// UnsupportedParams names the parameters set on req that this provider would ignore
+func (s ProviderSpec) UnsupportedParams(req *ChatRequest) []string {
+	var unsupported []string
+	if req.SystemPrompt != "" && !s.SupportsSystemPrompt {
+		unsupported = append(unsupported, "system prompt")
+	}
+	if !s.SupportsImages && hasImages(req.Messages) {
+		unsupported = append(unsupported, "images")
+	}
+	if len(req.Tools) > 0 && !s.SupportsTools {
+		unsupported = append(unsupported, "tools")
+	}
+	if req.Sampling.Seed != nil && !s.SupportsSeed {
+		unsupported = append(unsupported, "seed")
+	}
+	if req.Sampling.TopP != nil && !s.SupportsTopP {
+		unsupported = append(unsupported, "top_p")
+	}
+	if req.Sampling.FrequencyPenalty != nil && !s.SupportsPenalties {
+		unsupported = append(unsupported, "frequency_penalty")
+	}
+	if req.Sampling.PresencePenalty != nil && !s.SupportsPenalties {
+		unsupported = append(unsupported, "presence_penalty")
+	}
+	if len(req.LogitBias) > 0 && !s.SupportsLogitBias {
+		unsupported = append(unsupported, "logit_bias")
+	}
+	return unsupported
+}
+
+// hasImages reports whether any message carries image attachments
+func hasImages(messages []Message) bool {
+	for _, msg := range messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTemperature rejects temperatures outside the provider's supported range
+func (s ProviderSpec) ValidateTemperature(temperature float64) error {
+	if s.MinTemperature == 0 && s.MaxTemperature == 0 {
+		return nil
+	}
+	if temperature < s.MinTemperature || temperature > s.MaxTemperature {
+		return fmt.Errorf("temperature %g is out of range for %s (valid: %g to %g)",
+			temperature, s.Name, s.MinTemperature, s.MaxTemperature)
+	}
+	return nil
+}
+
+// registryMu guards the registry and factories, which may be read from running
+// conversations while a library user registers a provider
+var registryMu sync.RWMutex
+
 // Registry holds all registered providers
 var providerRegistry = make(map[string]ProviderSpec)
 
 // RegisterProvider registers a provider spec in the global registry
 func RegisterProvider(spec ProviderSpec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	providerRegistry[spec.Key] = spec
 }
 
 // GetProviderSpec returns the spec for a given provider key
 func GetProviderSpec(key string) (ProviderSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	spec, ok := providerRegistry[key]
 	return spec, ok
 }
 
-// ListProviders returns all registered provider specs
+// ListProviders returns all registered provider specs sorted by key
 func ListProviders() []ProviderSpec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	specs := make([]ProviderSpec, 0, len(providerRegistry))
 	for _, spec := range providerRegistry {
 		specs = append(specs, spec)
 	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Key < specs[j].Key
+	})
 	return specs
 }
 
@@ -103,11 +326,15 @@ var providerFactories = make(map[string]ProviderFactory)
 
 // RegisterProviderFactory registers a factory for dynamic provider creation
 func RegisterProviderFactory(key string, factory ProviderFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	providerFactories[key] = factory
 }
 
 // GetProviderFactory returns a factory by key
 func GetProviderFactory(key string) (ProviderFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	factory, ok := providerFactories[key]
 	return factory, ok
 }
@@ -36,3 +36,125 @@ func TestNewProviderUnknown(t *testing.T) {
 		t.Fatal("expected error when provider is not registered")
 	}
 }
+
+func TestListProvidersSortedByKey(t *testing.T) {
+	RegisterProvider(ProviderSpec{Key: "zz-test", Name: "Last"})
+	RegisterProvider(ProviderSpec{Key: "aa-test", Name: "First"})
+	t.Cleanup(func() {
+		delete(providerRegistry, "zz-test")
+		delete(providerRegistry, "aa-test")
+	})
+
+	first := ListProviders()
+	for i := 1; i < len(first); i++ {
+		if first[i-1].Key >= first[i].Key {
+			t.Fatalf("providers not sorted: %q before %q", first[i-1].Key, first[i].Key)
+		}
+	}
+
+	if first[0].Key != "aa-test" || first[len(first)-1].Key != "zz-test" {
+		t.Fatalf("unexpected ordering: first %q, last %q", first[0].Key, first[len(first)-1].Key)
+	}
+
+	for run := 0; run < 20; run++ {
+		again := ListProviders()
+		for i := range first {
+			if again[i].Key != first[i].Key {
+				t.Fatalf("run %d: ordering changed at %d: %q vs %q", run, i, again[i].Key, first[i].Key)
+			}
+		}
+	}
+}
+
+func TestValidateTemperatureBoundaries(t *testing.T) {
+	openai, _ := GetProviderSpec("openai")
+	narrow := ProviderSpec{Name: "Narrow", MinTemperature: 0, MaxTemperature: 1}
+
+	cases := []struct {
+		spec  ProviderSpec
+		temp  float64
+		valid bool
+	}{
+		{openai, 0, true},
+		{openai, 2, true},
+		{openai, 2.0001, false},
+		{openai, -0.1, false},
+		{narrow, 1, true},
+		{narrow, 1.5, false},
+		{ProviderSpec{Name: "Unbounded"}, 7, true},
+	}
+	for _, tc := range cases {
+		err := tc.spec.ValidateTemperature(tc.temp)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s at %g: valid=%v, got err %v", tc.spec.Name, tc.temp, tc.valid, err)
+		}
+	}
+
+	err := narrow.ValidateTemperature(1.5)
+	if err == nil || err.Error() != "temperature 1.5 is out of range for Narrow (valid: 0 to 1)" {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestUnsupportedParams(t *testing.T) {
+	seed, topP, penalty := 7, 0.9, 0.5
+	req := &ChatRequest{
+		SystemPrompt: "be terse",
+		Sampling:     Sampling{Seed: &seed, TopP: &topP, PresencePenalty: &penalty},
+		LogitBias:    map[string]float64{"50256": -100},
+	}
+
+	openai, _ := GetProviderSpec("openai")
+	if got := openai.UnsupportedParams(req); len(got) != 0 {
+		t.Fatalf("OpenAI should support everything, got %v", got)
+	}
+
+	exec, _ := GetProviderSpec("exec")
+	got := exec.UnsupportedParams(req)
+	if len(got) != 4 || got[0] != "seed" || got[1] != "top_p" || got[2] != "presence_penalty" || got[3] != "logit_bias" {
+		t.Fatalf("unexpected unsupported params for exec: %v", got)
+	}
+
+	if got := (ProviderSpec{}).UnsupportedParams(&ChatRequest{}); len(got) != 0 {
+		t.Fatalf("unset params should never be reported, got %v", got)
+	}
+}
+
+func TestAcceptsTemperature(t *testing.T) {
+	openai, _ := GetProviderSpec("openai")
+	for model, want := range map[string]bool{"gpt-4o": true, "o1": false, "o1-2024-12-17": false, "o3-mini": false, "o1x": true, "custom-model": true} {
+		if got := openai.AcceptsTemperature(model); got != want {
+			t.Errorf("AcceptsTemperature(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestProviderSpecModelMetadata(t *testing.T) {
+	spec, _ := GetProviderSpec("openai")
+
+	ids := spec.ModelIDs()
+	if len(ids) != len(spec.Models) || ids[0] != spec.Models[0].ID {
+		t.Fatalf("model IDs out of sync with metadata: %v", ids)
+	}
+	for _, m := range spec.Models {
+		if m.ContextWindow <= 0 || m.MaxOutputTokens <= 0 || m.MaxOutputTokens > m.ContextWindow {
+			t.Fatalf("implausible limits for %s: %+v", m.ID, m)
+		}
+	}
+
+	m, ok := spec.Model("gpt-4o-mini")
+	if !ok || m.ContextWindow != 128000 {
+		t.Fatalf("expected gpt-4o-mini metadata, got %+v, %v", m, ok)
+	}
+	if _, ok := spec.Model("gpt-unknown"); ok {
+		t.Fatal("unknown models should not be found")
+	}
+
+	// $0.15 in and $0.60 out per million tokens
+	if cost, ok := m.Cost(1_000_000, 500_000); !ok || cost != 0.45 {
+		t.Fatalf("expected $0.45, got %v, %v", cost, ok)
+	}
+	if _, ok := (ModelInfo{ID: "local"}).Cost(100, 100); ok {
+		t.Fatal("models without pricing should have no cost")
+	}
+}
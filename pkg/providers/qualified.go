@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// modelVendors are vendor prefixes used by routers such as OpenRouter, e.g.
+// "anthropic/claude-3.5-sonnet"; registered provider keys count as vendors too
+var modelVendors = []string{"anthropic", "cohere", "deepseek", "google", "meta-llama", "mistralai", "openai", "qwen", "x-ai"}
+
+// ParseAgentSpec splits a combined "provider:model" value. The model is optional,
+// and may itself contain colons (e.g. "ollama:llama3.1:8b").
+func ParseAgentSpec(value string) (provider, model string, err error) {
+	provider, model, qualified := strings.Cut(strings.TrimSpace(value), ":")
+	switch {
+	case provider == "":
+		return "", "", fmt.Errorf("invalid agent %q: expected provider or provider:model", value)
+	case qualified && model == "":
+		return "", "", fmt.Errorf("invalid agent %q: model is empty after %q", value, provider+":")
+	case strings.ContainsAny(provider, "/ "):
+		return "", "", fmt.Errorf("invalid agent %q: %q is not a provider name", value, provider)
+	}
+	return provider, model, nil
+}
+
+// ResolveModel checks a vendor-qualified model ID against the provider. Providers
+// with QualifiedModels take it as-is; otherwise a prefix naming this provider is
+// dropped ("openai/gpt-4o" → "gpt-4o") and one naming another vendor is an error.
+// IDs whose prefix isn't a known vendor (e.g. a local model path) pass unchanged.
+func (s ProviderSpec) ResolveModel(model string) (string, error) {
+	vendor, id, found := strings.Cut(model, "/")
+	if s.QualifiedModels || !found {
+		return model, nil
+	}
+	if vendor == s.Key {
+		return id, nil
+	}
+	if _, registered := GetProviderSpec(vendor); registered || slices.Contains(modelVendors, vendor) {
+		return "", fmt.Errorf("model %q belongs to %s, but the provider is %s; use %s:%s or a provider that routes vendor-qualified models",
+			model, vendor, s.Key, vendor, id)
+	}
+	return model, nil
+}
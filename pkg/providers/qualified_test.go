@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAgentSpec(t *testing.T) {
+	tests := []struct {
+		input, provider, model string
+		wantErr                bool
+	}{
+		{input: "openai:gpt-4o", provider: "openai", model: "gpt-4o"},
+		{input: "openai", provider: "openai"},
+		{input: " openai:gpt-4o ", provider: "openai", model: "gpt-4o"},
+		{input: "ollama:llama3.1:8b", provider: "ollama", model: "llama3.1:8b"},
+		{input: "openrouter:anthropic/claude-3.5-sonnet", provider: "openrouter", model: "anthropic/claude-3.5-sonnet"},
+		{input: "", wantErr: true},
+		{input: ":gpt-4o", wantErr: true},
+		{input: "openai:", wantErr: true},
+		{input: "anthropic/claude-3.5-sonnet", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		provider, model, err := ParseAgentSpec(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAgentSpec(%q): expected error, got %q, %q", tt.input, provider, model)
+			}
+			continue
+		}
+		if err != nil || provider != tt.provider || model != tt.model {
+			t.Errorf("ParseAgentSpec(%q) = %q, %q, %v; want %q, %q", tt.input, provider, model, err, tt.provider, tt.model)
+		}
+	}
+}
+
+func TestResolveModel(t *testing.T) {
+	openai, _ := GetProviderSpec("openai")
+
+	for input, want := range map[string]string{
+		"gpt-4o":               "gpt-4o",
+		"openai/gpt-4o":        "gpt-4o",
+		"my-org/finetune-7b":   "my-org/finetune-7b", // Not a known vendor
+		"ft:gpt-4o:acme:tuned": "ft:gpt-4o:acme:tuned",
+	} {
+		if got, err := openai.ResolveModel(input); err != nil || got != want {
+			t.Errorf("ResolveModel(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+
+	_, err := openai.ResolveModel("anthropic/claude-3.5-sonnet")
+	if err == nil || !strings.Contains(err.Error(), "anthropic:claude-3.5-sonnet") {
+		t.Fatalf("expected a vendor mismatch error suggesting the combined form, got %v", err)
+	}
+
+	// Routers take vendor-qualified IDs as they are
+	router := ProviderSpec{Key: "router", QualifiedModels: true}
+	if got, err := router.ResolveModel("anthropic/claude-3.5-sonnet"); err != nil || got != "anthropic/claude-3.5-sonnet" {
+		t.Fatalf("expected the ID unchanged, got %q, %v", got, err)
+	}
+}
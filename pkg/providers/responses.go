@@ -0,0 +1,262 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// APIs an OpenAI-compatible provider can speak (ProviderConfig.API)
+const (
+	APIChatCompletions = "chat"      // POST /chat/completions, the default
+	APIResponses       = "responses" // POST /responses, which some newer models require
+)
+
+// ValidateAPI rejects unknown ProviderConfig.API values; empty means APIChatCompletions
+func ValidateAPI(api string) error {
+	switch api {
+	case "", APIChatCompletions, APIResponses:
+		return nil
+	}
+	return fmt.Errorf("unknown API %q (use %s or %s)", api, APIChatCompletions, APIResponses)
+}
+
+// responsesBody builds a Responses API request. The system prompt becomes
+// instructions and the messages become input items; seed and the penalties have no
+// equivalent there and are dropped.
+func responsesBody(req *ChatRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":  req.Model,
+		"input":  convertResponsesInput(req.Messages),
+		"stream": true,
+		"store":  false,
+	}
+	if req.SystemPrompt != "" {
+		body["instructions"] = req.SystemPrompt
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		body["max_output_tokens"] = req.MaxTokens
+	}
+	if s := req.Sampling; s.TopP != nil {
+		body["top_p"] = *s.TopP
+	}
+	if s := req.Sampling; s.Seed != nil || s.FrequencyPenalty != nil || s.PresencePenalty != nil {
+		slog.Debug("responses API ignores seed and penalties", "model", req.Model)
+	}
+	if len(req.LogitBias) > 0 {
+		slog.Debug("responses API ignores logit bias", "model", req.Model)
+	}
+	if req.ResponseFormat == ResponseFormatJSONSchema && req.Schema != nil {
+		body["text"] = map[string]interface{}{"format": map[string]interface{}{
+			"type": ResponseFormatJSONSchema, "name": req.Schema.Name, "schema": req.Schema.Document, "strict": true,
+		}}
+	} else if req.ResponseFormat != "" {
+		body["text"] = map[string]interface{}{"format": map[string]string{"type": req.ResponseFormat}}
+	}
+	if len(req.Tools) > 0 {
+		body["tools"] = convertResponsesTools(req.Tools)
+		if choice := convertResponsesToolChoice(req.ToolChoice); choice != nil {
+			body["tool_choice"] = choice
+		}
+	}
+	return body
+}
+
+// convertResponsesInput converts messages to Responses API input items. Tool calls
+// and their results are items of their own rather than parts of a message.
+func convertResponsesInput(messages []Message) []map[string]interface{} {
+	input := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.ToolCallID != "":
+			input = append(input, map[string]interface{}{
+				"type":    "function_call_output",
+				"call_id": msg.ToolCallID,
+				"output":  msg.Content,
+			})
+			continue
+		case len(msg.ToolCalls) > 0:
+			if msg.Content != "" {
+				input = append(input, map[string]interface{}{"role": msg.Role, "content": msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input = append(input, map[string]interface{}{
+					"type":      "function_call",
+					"call_id":   call.ID,
+					"name":      call.Name,
+					"arguments": call.Arguments,
+				})
+			}
+			continue
+		case len(msg.Images) == 0:
+			input = append(input, map[string]interface{}{"role": msg.Role, "content": msg.Content})
+			continue
+		}
+
+		parts := []map[string]interface{}{{"type": "input_text", "text": msg.Content}}
+		for _, img := range msg.Images {
+			parts = append(parts, map[string]interface{}{"type": "input_image", "image_url": img.DataURL()})
+		}
+		input = append(input, map[string]interface{}{"role": msg.Role, "content": parts})
+	}
+	return input
+}
+
+// convertResponsesTools converts tool specs to Responses API function tools, which
+// are flat rather than nested under "function"
+func convertResponsesTools(tools []ToolSpec) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(tools))
+	for i, tool := range tools {
+		result[i] = map[string]interface{}{
+			"type":        "function",
+			"name":        tool.Name,
+			"description": tool.Description,
+		}
+		if len(tool.Parameters) > 0 {
+			result[i]["parameters"] = tool.Parameters
+		}
+	}
+	return result
+}
+
+// convertResponsesToolChoice maps ChatRequest.ToolChoice to the Responses API's
+// tool_choice; nil leaves the default
+func convertResponsesToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+		return choice
+	default:
+		return map[string]string{"type": "function", "name": choice}
+	}
+}
+
+// responsesEvent is the part of a streamed Responses API event the provider reads
+type responsesEvent struct {
+	Type  string `json:"type"`
+	Delta string `json:"delta"`
+	Item  struct {
+		Type      string `json:"type"`
+		CallID    string `json:"call_id"`
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"item"`
+	Response struct {
+		Model string `json:"model"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		IncompleteDetails *struct {
+			Reason string `json:"reason"`
+		} `json:"incomplete_details"`
+	} `json:"response"`
+	Message string `json:"message"` // For "error" events
+}
+
+// streamResponses reads a Responses API event stream, sending text deltas as they
+// arrive and the completed function calls and metadata once the response is done
+func (p *OpenAIProvider) streamResponses(ctx context.Context, body io.Reader, started time.Time, textChan chan<- string, errChan chan<- error, callsChan chan<- []ToolCall, metaChan chan<- ResponseMeta) {
+	events := newSSEReader(body)
+	chunks, received := 0, 0
+	var calls []ToolCall
+	var meta ResponseMeta
+	defer func() {
+		slog.Debug("provider stream finished", "provider", p.Name(), "api", APIResponses, "chunks", chunks, "tool_calls", len(calls), "served_model", meta.Model, "finish_reason", meta.FinishReason, "elapsed", time.Since(started))
+	}()
+	finish := func() {
+		if len(calls) > 0 {
+			callsChan <- calls
+		}
+		metaChan <- meta
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			errChan <- ErrContextCancelled
+			return
+		default:
+		}
+
+		data, err := events.Next()
+		if err != nil {
+			if err == io.EOF {
+				if received == 0 {
+					errChan <- ErrEmptyStream
+					return
+				}
+				finish()
+				return
+			}
+			slog.Debug("provider stream broke", "provider", p.Name(), "events", received, "error", err)
+			errChan <- streamReadError(ctx, err, received)
+			return
+		}
+		received++
+
+		var event responsesEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue // Skip malformed events
+		}
+		switch event.Type {
+		case "response.output_text.delta":
+			if event.Delta == "" {
+				continue
+			}
+			chunks++
+			select {
+			case textChan <- event.Delta:
+			case <-ctx.Done():
+				errChan <- ErrContextCancelled
+				return
+			}
+		case "response.refusal.delta":
+			meta.Refusal += event.Delta
+		case "response.output_item.done":
+			// Function calls arrive whole here, after their streamed argument deltas
+			if event.Item.Type == "function_call" {
+				calls = append(calls, ToolCall{ID: event.Item.CallID, Name: event.Item.Name, Arguments: event.Item.Arguments})
+			}
+		case "response.completed":
+			meta.Model, meta.FinishReason = event.Response.Model, FinishStop
+			if len(calls) > 0 {
+				meta.FinishReason = FinishToolCalls
+			}
+			finish()
+			return
+		case "response.incomplete":
+			// Cut short, e.g. by max_output_tokens: keep what was streamed, as chat completions do
+			meta.Model = event.Response.Model
+			if d := event.Response.IncompleteDetails; d != nil {
+				slog.Debug("response incomplete", "provider", p.Name(), "reason", d.Reason)
+				meta.FinishReason = d.Reason
+				if d.Reason == "max_output_tokens" {
+					meta.FinishReason = FinishLength
+				}
+			}
+			finish()
+			return
+		case "response.failed":
+			message := "response failed"
+			if e := event.Response.Error; e != nil && e.Message != "" {
+				message = e.Message
+			}
+			errChan <- fmt.Errorf("API error: %s", message)
+			return
+		case "error":
+			if event.Message == "" {
+				event.Message = apiErrorMessage([]byte(data))
+			}
+			errChan <- errors.New("API error: " + event.Message)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIResponsesAPI(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		for _, event := range []string{
+			`{"type":"response.created","response":{"id":"resp_1"}}`,
+			`{"type":"response.output_text.delta","delta":"Checking"}`,
+			`{"type":"response.output_text.delta","delta":". "}`,
+			`{"type":"response.function_call_arguments.delta","delta":"{\"expr"}`,
+			`{"type":"response.output_item.done","item":{"type":"function_call","call_id":"call_1","name":"calculator","arguments":"{\"expression\":\"6*7\"}"}}`,
+			`{"type":"response.completed","response":{"id":"resp_1"}}`,
+		} {
+			io.WriteString(w, "event: x\ndata: "+event+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL, API: APIResponses})
+	textChan, errChan, callsChan := p.StreamChatTools(context.Background(), &ChatRequest{
+		Model:        "gpt-test",
+		SystemPrompt: "Be exact.",
+		MaxTokens:    50,
+		Messages: []Message{
+			{Role: "user", Content: "What is 6*7?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_0", Name: "calculator", Arguments: `{"expression":"1+1"}`}}},
+			{Role: "tool", Content: "2", ToolCallID: "call_0"},
+		},
+		Tools: []ToolSpec{{Name: "calculator", Description: "Do math", Parameters: json.RawMessage(`{"type":"object"}`)}},
+	})
+	text, err := collectStream(textChan, errChan)
+	if err != nil || text != "Checking. " {
+		t.Fatalf("unexpected stream: %q, %v", text, err)
+	}
+	calls := <-callsChan
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Arguments != `{"expression":"6*7"}` {
+		t.Fatalf("unexpected tool calls: %+v", calls)
+	}
+
+	if path != "/responses" || body["instructions"] != "Be exact." || body["max_output_tokens"] != float64(50) || body["messages"] != nil {
+		t.Fatalf("unexpected request to %s: %v", path, body)
+	}
+	input := body["input"].([]interface{})
+	call, output := input[1].(map[string]interface{}), input[2].(map[string]interface{})
+	if len(input) != 3 || call["type"] != "function_call" || call["call_id"] != "call_0" || output["type"] != "function_call_output" || output["output"] != "2" {
+		t.Fatalf("unexpected input items: %v", input)
+	}
+	if tool := body["tools"].([]interface{})[0].(map[string]interface{}); tool["name"] != "calculator" || tool["function"] != nil {
+		t.Fatalf("expected a flat function tool, got %v", tool)
+	}
+}
+
+func TestOpenAIResponsesAPIReportsFailures(t *testing.T) {
+	reply := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, reply)
+	}))
+	defer server.Close()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL, API: APIResponses})
+	req := &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	reply = `data: {"type":"response.output_text.delta","delta":"Hal"}` + "\n\n" +
+		`data: {"type":"response.failed","response":{"error":{"code":"server_error","message":"The model crashed"}}}` + "\n\n"
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); err == nil || !strings.Contains(err.Error(), "The model crashed") {
+		t.Fatalf("expected the failure message, got %v", err)
+	}
+
+	// An output cut short by max_output_tokens keeps its text
+	reply = `data: {"type":"response.output_text.delta","delta":"Partial"}` + "\n\n" +
+		`data: {"type":"response.incomplete","response":{"model":"gpt-test-1","incomplete_details":{"reason":"max_output_tokens"}}}` + "\n\n"
+	textChan, errChan, _, metaChan := p.StreamChatMeta(context.Background(), req)
+	if text, err := collectStream(textChan, errChan); err != nil || text != "Partial" {
+		t.Fatalf("expected the partial text, got %q, %v", text, err)
+	}
+	if meta := <-metaChan; meta != (ResponseMeta{Model: "gpt-test-1", FinishReason: FinishLength}) {
+		t.Fatalf("expected a length finish, got %+v", meta)
+	}
+}
+
+func TestValidateAPI(t *testing.T) {
+	for _, api := range []string{"", APIChatCompletions, APIResponses} {
+		if err := ValidateAPI(api); err != nil {
+			t.Errorf("%q: %v", api, err)
+		}
+	}
+	if err := ValidateAPI("completions"); err == nil {
+		t.Error("expected an error for an unknown API")
+	}
+}
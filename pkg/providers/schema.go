@@ -0,0 +1,522 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResponseFormatJSONSchema asks for a JSON object matching ChatRequest.Schema
+// (OpenAI's strict json_schema format)
+const ResponseFormatJSONSchema = "json_schema"
+
+// JSONSchema is a schema replies must match. Providers with SupportsJSONSchema are
+// sent the document as-is; Validate checks replies on the client for every provider.
+//
+// Validate understands the keywords structured output relies on: type, enum,
+// const, properties, required, additionalProperties, items, anyOf, oneOf, allOf,
+// local $ref, the length, size and range bounds, and pattern. Others (e.g. format)
+// are ignored.
+type JSONSchema struct {
+	Name     string          // Identifies the schema to the provider: letters, digits, _ and -
+	Document json.RawMessage // The schema itself
+
+	root     any
+	patterns map[string]*regexp.Regexp // Compiled pattern keywords, by source
+}
+
+// schemaNameChars matches the characters OpenAI rejects in schema names
+var schemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// LoadJSONSchema reads a schema file, naming the schema after the file
+func LoadJSONSchema(path string) (*JSONSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	schema, err := ParseJSONSchema(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseJSONSchema parses a schema document, which must describe a JSON object.
+// Characters not allowed in name are replaced with underscores.
+func ParseJSONSchema(name string, data []byte) (*JSONSchema, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	object, ok := root.(map[string]any)
+	if !ok || object["type"] != "object" {
+		return nil, fmt.Errorf(`JSON schema must describe an object ("type": "object" at the top level)`)
+	}
+	schema := &JSONSchema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := schema.checkSchema(root, "#"); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if err := checkRefCycles(root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	name = strings.Trim(schemaNameChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = "reply"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+	schema.Name, schema.Document = name, compact.Bytes()
+	return schema, nil
+}
+
+// Validate checks a JSON document against the schema, reporting the first
+// mismatch with its location (e.g. "$.items[1].name: missing required property")
+func (s *JSONSchema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return s.validateSchema(s.root, value, "$")
+}
+
+// checkSchema verifies that every $ref in schema resolves and compiles every pattern
+func (s *JSONSchema) checkSchema(schema any, at string) error {
+	switch node := schema.(type) {
+	case map[string]any:
+		if ref, ok := node["$ref"].(string); ok {
+			if _, err := resolveRef(s.root, ref); err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+		}
+		if pattern, ok := node["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: pattern: %w", at, err)
+			}
+			s.patterns[pattern] = re
+		}
+		for _, key := range sortedKeys(node) {
+			if key == "enum" || key == "const" {
+				continue // Values, not schemas
+			}
+			if err := s.checkSchema(node[key], at+"/"+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, item := range node {
+			if err := s.checkSchema(item, at+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveRef follows a local JSON pointer such as "#/$defs/item"
+func resolveRef(root any, ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("only local $ref values are supported, got %q", ref)
+	}
+	node := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch parent := node.(type) {
+		case map[string]any:
+			node, ok = parent[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			ok = err == nil && i >= 0 && i < len(parent)
+			if ok {
+				node = parent[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+	}
+	return node, nil
+}
+
+// checkRefCycles rejects $ref loops that never reach into the value, such as a
+// "$ref": "#" beside the root's type, or $defs a and b referring to each other
+// through $ref, allOf, anyOf or oneOf: Validate would follow them forever. Loops
+// through properties or items are fine, as each step goes one level deeper into
+// the value.
+func checkRefCycles(root any) error {
+	const visiting, done = 1, 2
+	state := make(map[string]int)
+	// follow takes the in-place steps from the node at a pointer, with trail the
+	// pointers that led to it
+	var follow func(node any, at string, trail []string) error
+	follow = func(node any, at string, trail []string) error {
+		trail = append(trail, at)
+		switch state[at] {
+		case visiting:
+			return fmt.Errorf("%s: $ref cycle never reaches into the value", strings.Join(trail[slices.Index(trail, at):], " → "))
+		case done:
+			return nil
+		}
+		object, ok := node.(map[string]any)
+		if !ok {
+			state[at] = done
+			return nil
+		}
+		state[at] = visiting
+		if ref, ok := object["$ref"].(string); ok {
+			if target, err := resolveRef(root, ref); err == nil {
+				if err := follow(target, refPointer(ref), trail); err != nil {
+					return err
+				}
+			}
+		}
+		for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+			for i, sub := range schemaList(object[key]) {
+				if err := follow(sub, fmt.Sprintf("%s/%s/%d", at, key, i), trail); err != nil {
+					return err
+				}
+			}
+		}
+		state[at] = done
+		return nil
+	}
+
+	var walk func(node any, at string) error
+	walk = func(node any, at string) error {
+		switch n := node.(type) {
+		case map[string]any:
+			if err := follow(n, at, nil); err != nil {
+				return err
+			}
+			for _, key := range sortedKeys(n) {
+				if key == "enum" || key == "const" {
+					continue
+				}
+				if err := walk(n[key], at+"/"+pointerEscaper.Replace(key)); err != nil {
+					return err
+				}
+			}
+		case []any:
+			for i, item := range n {
+				if err := walk(item, at+"/"+strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(root, "#")
+}
+
+// pointerEscaper escapes an object key as a JSON pointer token
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// refPointer normalizes a local $ref to the pointer checkRefCycles names nodes by,
+// e.g. "#/" and "#" both to "#"
+func refPointer(ref string) string {
+	pointer := "#"
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if token != "" {
+			pointer += "/" + token
+		}
+	}
+	return pointer
+}
+
+// validateSchema checks value, found at path, against schema
+func (s *JSONSchema) validateSchema(schema, value any, path string) error {
+	switch node := schema.(type) {
+	case bool:
+		if !node {
+			return fmt.Errorf("%s: not allowed", path)
+		}
+		return nil
+	case map[string]any:
+		return s.validateNode(node, value, path)
+	}
+	return nil
+}
+
+// validateNode applies an object schema's keywords to value
+func (s *JSONSchema) validateNode(schema map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := resolveRef(s.root, ref)
+		if err != nil {
+			return err
+		}
+		if err := s.validateSchema(target, value, path); err != nil {
+			return err
+		}
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), typeName(value))
+	}
+	if want, ok := schema["const"]; ok && !jsonEqual(value, want) {
+		return fmt.Errorf("%s: expected %s", path, jsonText(want))
+	}
+	if options, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(options, func(option any) bool { return jsonEqual(value, option) }) {
+		texts := make([]string, len(options))
+		for i, option := range options {
+			texts[i] = jsonText(option)
+		}
+		return fmt.Errorf("%s: expected one of %s", path, strings.Join(texts, ", "))
+	}
+
+	for _, sub := range schemaList(schema["allOf"]) {
+		if err := s.validateSchema(sub, value, path); err != nil {
+			return err
+		}
+	}
+	if subs := schemaList(schema["anyOf"]); len(subs) > 0 {
+		var first error
+		for _, sub := range subs {
+			if first = s.validateSchema(sub, value, path); first == nil {
+				break
+			}
+		}
+		if first != nil {
+			return fmt.Errorf("%s: matches none of the allowed schemas (%v)", path, first)
+		}
+	}
+	if subs := schemaList(schema["oneOf"]); len(subs) > 0 {
+		matches := 0
+		for _, sub := range subs {
+			if s.validateSchema(sub, value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: must match exactly one allowed schema, matches %d", path, matches)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return s.validateObject(schema, v, path)
+	case []any:
+		return s.validateArray(schema, v, path)
+	case string:
+		return s.validateString(schema, v, path)
+	case json.Number:
+		return validateNumber(schema, v, path)
+	}
+	return nil
+}
+
+// validateObject applies properties, required and additionalProperties
+func (s *JSONSchema) validateObject(schema, object map[string]any, path string) error {
+	for _, name := range schemaStrings(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	for _, name := range sortedKeys(object) {
+		at := path + "." + name
+		if sub, ok := properties[name]; ok {
+			if err := s.validateSchema(sub, object[name], at); err != nil {
+				return err
+			}
+			continue
+		}
+		if extra, ok := schema["additionalProperties"]; ok {
+			if allowed, isBool := extra.(bool); isBool && !allowed {
+				return fmt.Errorf("%s: unexpected property", at)
+			}
+			if err := s.validateSchema(extra, object[name], at); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateArray applies items, minItems and maxItems
+func (s *JSONSchema) validateArray(schema map[string]any, array []any, path string) error {
+	if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(array)) < min {
+		return fmt.Errorf("%s: expected at least %g items, got %d", path, min, len(array))
+	}
+	if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(array)) > max {
+		return fmt.Errorf("%s: expected at most %g items, got %d", path, max, len(array))
+	}
+	if items, ok := schema["items"]; ok {
+		for i, item := range array {
+			if err := s.validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateString applies minLength, maxLength and pattern
+func (s *JSONSchema) validateString(schema map[string]any, str, path string) error {
+	length := float64(len([]rune(str)))
+	if min, ok := schemaNumber(schema["minLength"]); ok && length < min {
+		return fmt.Errorf("%s: expected at least %g characters, got %g", path, min, length)
+	}
+	if max, ok := schemaNumber(schema["maxLength"]); ok && length > max {
+		return fmt.Errorf("%s: expected at most %g characters, got %g", path, max, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if !s.patterns[pattern].MatchString(str) {
+			return fmt.Errorf("%s: does not match pattern %q", path, pattern)
+		}
+	}
+	return nil
+}
+
+// validateNumber applies minimum, maximum and their exclusive forms
+func validateNumber(schema map[string]any, n json.Number, path string) error {
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if min, ok := schemaNumber(schema["minimum"]); ok && f < min {
+		return fmt.Errorf("%s: expected at least %g, got %s", path, min, n)
+	}
+	if max, ok := schemaNumber(schema["maximum"]); ok && f > max {
+		return fmt.Errorf("%s: expected at most %g, got %s", path, max, n)
+	}
+	if min, ok := schemaNumber(schema["exclusiveMinimum"]); ok && f <= min {
+		return fmt.Errorf("%s: expected more than %g, got %s", path, min, n)
+	}
+	if max, ok := schemaNumber(schema["exclusiveMaximum"]); ok && f >= max {
+		return fmt.Errorf("%s: expected less than %g, got %s", path, max, n)
+	}
+	return nil
+}
+
+// hasType reports whether value is of the JSON schema type t
+func hasType(value any, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return typeName(value) == t
+}
+
+// typeName names the JSON type of a decoded value
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares decoded JSON values, numbers by value
+func jsonEqual(a, b any) bool {
+	return jsonText(a) == jsonText(b)
+}
+
+// jsonText renders a decoded value as canonical JSON: sorted keys, and numbers in
+// their shortest form so 1, 1.0 and 1e0 render alike
+func jsonText(value any) string {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = jsonText(item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case map[string]any:
+		parts := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			parts = append(parts, strconv.Quote(key)+":"+jsonText(v[key]))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	}
+	text, _ := json.Marshal(value)
+	return string(text)
+}
+
+// schemaTypes returns a type keyword's types, given as a string or an array
+func schemaTypes(value any) []string {
+	if t, ok := value.(string); ok {
+		return []string{t}
+	}
+	return schemaStrings(value)
+}
+
+// schemaStrings returns the strings in an array keyword
+func schemaStrings(value any) []string {
+	items, _ := value.([]any)
+	var strs []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// schemaList returns the subschemas of an allOf, anyOf or oneOf keyword
+func schemaList(value any) []any {
+	items, _ := value.([]any)
+	return items
+}
+
+// schemaNumber returns a numeric keyword's value
+func schemaNumber(value any) (float64, bool) {
+	f, ok := value.(float64)
+	return f, ok
+}
+
+// sortedKeys returns an object's keys in sorted order, so errors are deterministic
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
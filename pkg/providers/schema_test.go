@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchemaDoc = `{
+	"type": "object",
+	"properties": {
+		"title": {"type": "string", "minLength": 1},
+		"rating": {"type": "integer", "minimum": 1, "maximum": 5},
+		"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "maxItems": 2},
+		"status": {"enum": ["draft", "final"]},
+		"note": {"type": ["string", "null"]}
+	},
+	"required": ["title", "rating"],
+	"additionalProperties": false,
+	"$defs": {"tag": {"type": "string", "pattern": "^[a-z]+$"}}
+}`
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := ParseJSONSchema("review v2.json", []byte(testSchemaDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Name != "review_v2_json" || !json.Valid(schema.Document) || strings.Contains(string(schema.Document), "\n") {
+		t.Fatalf("unexpected schema: %q %s", schema.Name, schema.Document)
+	}
+
+	for _, valid := range []string{
+		`{"title": "Dune", "rating": 5}`,
+		`{"title": "Dune", "rating": 4.0, "tags": ["scifi"], "status": "final", "note": null}`,
+	} {
+		if err := schema.Validate([]byte(valid)); err != nil {
+			t.Errorf("Validate(%s): %v", valid, err)
+		}
+	}
+
+	invalid := map[string]string{
+		`{"rating": 5}`:                                    `$: missing required property "title"`,
+		`{"title": "", "rating": 5}`:                       "$.title: expected at least 1 characters",
+		`{"title": "Dune", "rating": 4.5}`:                 "$.rating: expected integer, got number",
+		`{"title": "Dune", "rating": 9}`:                   "$.rating: expected at most 5",
+		`{"title": "Dune", "rating": 5, "tags": ["Sci"]}`:  `$.tags[0]: does not match pattern`,
+		`{"title": "Dune", "rating": 5, "status": "done"}`: `$.status: expected one of "draft", "final"`,
+		`{"title": "Dune", "rating": 5, "note": 1}`:        "$.note: expected string or null, got number",
+		`{"title": "Dune", "rating": 5, "year": 1965}`:     "$.year: unexpected property",
+	}
+	for doc, want := range invalid {
+		if err := schema.Validate([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%s) = %v, want %q", doc, err, want)
+		}
+	}
+}
+
+func TestJSONSchemaValidatesRecursiveSchemas(t *testing.T) {
+	// A tree refers to itself through items, one level deeper into the value each time
+	schema, err := ParseJSONSchema("tree", []byte(`{"type": "object", "properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate([]byte(`{"name": "root", "children": [{"name": "leaf", "children": []}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate([]byte(`{"children": [{"name": 1}]}`)); err == nil || !strings.Contains(err.Error(), "$.children[0].name") {
+		t.Fatalf("expected the nested mismatch, got %v", err)
+	}
+}
+
+func TestJSONSchemaRequestFormat(t *testing.T) {
+	schema, err := ParseJSONSchema("review", []byte(testSchemaDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &ChatRequest{Model: "gpt-test", ResponseFormat: ResponseFormatJSONSchema, Schema: schema}
+
+	encoded, err := json.Marshal(map[string]interface{}{
+		"chat":      NewOpenAIProvider(ProviderConfig{APIKey: "test"}).chatBody(req)["response_format"],
+		"responses": responsesBody(req)["text"],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies struct {
+		Chat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name   string          `json:"name"`
+				Schema json.RawMessage `json:"schema"`
+				Strict bool            `json:"strict"`
+			} `json:"json_schema"`
+		} `json:"chat"`
+		Responses struct {
+			Format struct {
+				Type   string          `json:"type"`
+				Name   string          `json:"name"`
+				Schema json.RawMessage `json:"schema"`
+				Strict bool            `json:"strict"`
+			} `json:"format"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(encoded, &bodies); err != nil {
+		t.Fatal(err)
+	}
+	chat, responses := bodies.Chat, bodies.Responses.Format
+	if chat.Type != "json_schema" || chat.JSONSchema.Name != "review" || !chat.JSONSchema.Strict || string(chat.JSONSchema.Schema) != string(schema.Document) {
+		t.Fatalf("unexpected chat completions format: %s", encoded)
+	}
+	if responses.Type != "json_schema" || responses.Name != "review" || !responses.Strict || string(responses.Schema) != string(schema.Document) {
+		t.Fatalf("unexpected responses format: %s", encoded)
+	}
+}
+
+func TestParseJSONSchemaRejectsBadSchemas(t *testing.T) {
+	for doc, want := range map[string]string{
+		`{"type": "object"`: "invalid JSON schema",
+		`{"type": "array"}`: "must describe an object",
+		`{"type": "object", "properties": {"a": {"$ref": "#/$defs/missing"}}}`: "does not resolve",
+		`{"type": "object", "properties": {"a": {"pattern": "("}}}`:            "pattern",
+		`{"type": "object", "$ref": "#"}`:                                      "# → #: $ref cycle",
+		`{"type": "object", "properties": {"x": {"$ref": "#/$defs/a"}}, "$defs": {"a": {"allOf": [{"$ref": "#/$defs/b"}]}, "b": {"$ref": "#/$defs/a"}}}`: "$ref cycle",
+	} {
+		if _, err := ParseJSONSchema("test", []byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseJSONSchema(%s) = %v, want %q", doc, err, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScriptProviderName is the provider name of agents replaying a script
+const ScriptProviderName = "script"
+
+// Script defaults: turns are separated by a line reading "---", and streamed a word
+// at a time with a short pause, like a fast model
+const (
+	DefaultScriptDelimiter  = "---"
+	DefaultScriptChunkDelay = 30 * time.Millisecond
+)
+
+// ErrScriptEnded means a script provider was asked for more turns than it holds
+var ErrScriptEnded = errors.New("script has no more turns")
+
+// ParseScript splits a script into turns at every line consisting of the delimiter
+// alone (surrounding whitespace ignored). Each turn is trimmed; blank turns are dropped.
+func ParseScript(r io.Reader, delimiter string) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var turns []string
+	var turn strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(turn.String()); text != "" {
+			turns = append(turns, text)
+		}
+		turn.Reset()
+	}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.TrimSpace(line) == delimiter {
+			flush()
+			continue
+		}
+		turn.WriteString(line)
+	}
+	flush()
+	return turns, nil
+}
+
+// ScriptProvider replays canned replies in order, one per request, ignoring what
+// it is sent. It streams each reply word by word, so a scripted conversation goes
+// through the same rendering as a live one, e.g. for demos and rendering tests.
+type ScriptProvider struct {
+	mu    sync.Mutex
+	turns []string
+	delay time.Duration // Pause before each chunk
+}
+
+// NewScriptProvider creates a provider replaying turns, pausing chunkDelay between
+// streamed words (0 sends each turn at once)
+func NewScriptProvider(turns []string, chunkDelay time.Duration) *ScriptProvider {
+	return &ScriptProvider{turns: turns, delay: chunkDelay}
+}
+
+// Name returns the provider identifier
+func (p *ScriptProvider) Name() string {
+	return ScriptProviderName
+}
+
+// DefaultModel returns the placeholder model scripted agents report
+func (p *ScriptProvider) DefaultModel() string {
+	return "scripted"
+}
+
+// Models returns the placeholder model
+func (p *ScriptProvider) Models(ctx context.Context) ([]string, error) {
+	return []string{p.DefaultModel()}, nil
+}
+
+// Health always succeeds; there is nothing to reach
+func (p *ScriptProvider) Health(ctx context.Context) error {
+	return nil
+}
+
+// StreamChat streams the next scripted turn, or fails with ErrScriptEnded
+func (p *ScriptProvider) StreamChat(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error) {
+	p.mu.Lock()
+	turn, ok := "", len(p.turns) > 0
+	if ok {
+		turn, p.turns = p.turns[0], p.turns[1:]
+	}
+	p.mu.Unlock()
+
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+		if !ok {
+			errChan <- ErrScriptEnded
+			return
+		}
+
+		for _, word := range strings.SplitAfter(turn, " ") {
+			if p.delay > 0 {
+				select {
+				case <-time.After(p.delay):
+				case <-ctx.Done():
+					errChan <- ErrContextCancelled
+					return
+				}
+			}
+			select {
+			case textChan <- word:
+			case <-ctx.Done():
+				errChan <- ErrContextCancelled
+				return
+			}
+		}
+	}()
+
+	return textChan, errChan
+}
@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseScript(t *testing.T) {
+	script := "Hello there.\n\nHow are you?\n---\n\n  ---  \nFine, thanks.\n```\n---x\n```\n---\n"
+	turns, err := ParseScript(strings.NewReader(script), DefaultScriptDelimiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Hello there.\n\nHow are you?", "Fine, thanks.\n```\n---x\n```"}
+	if !slices.Equal(turns, want) {
+		t.Fatalf("got %q, want %q", turns, want)
+	}
+}
+
+func TestScriptProviderReplaysTurns(t *testing.T) {
+	p := NewScriptProvider([]string{"First reply", "Second"}, 0)
+	req := &ChatRequest{Model: p.DefaultModel(), Messages: []Message{{Role: "user", Content: "ignored"}}}
+
+	for _, want := range []string{"First reply", "Second"} {
+		if text, err := collectStream(p.StreamChat(context.Background(), req)); err != nil || text != want {
+			t.Fatalf("got %q, %v; want %q", text, err, want)
+		}
+	}
+	if _, err := collectStream(p.StreamChat(context.Background(), req)); !errors.Is(err, ErrScriptEnded) {
+		t.Fatalf("expected ErrScriptEnded, got %v", err)
+	}
+}
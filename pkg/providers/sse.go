@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+)
+
+// sseReader parses a Server-Sent Events stream into event payloads.
+// Lines are accumulated until the blank line that ends an event, so events are
+// reassembled correctly however the underlying reads split the bytes, and
+// multiple data: lines in one event are joined with "\n" as the SSE spec requires.
+type sseReader struct {
+	reader *bufio.Reader
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{reader: bufio.NewReader(r)}
+}
+
+// Next returns the data of the next event that carries any.
+// It returns io.EOF once the stream ends; a final event without a trailing
+// blank line is still returned first.
+func (s *sseReader) Next() (string, error) {
+	var data []string
+	hasData := false
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		eof := err == io.EOF
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			// Blank line dispatches the event; a bare EOF ends the stream
+			if hasData {
+				return strings.Join(data, "\n"), nil
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment (often used as a keep-alive)
+		case line == "data" || strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "data"), ":")
+			data = append(data, strings.TrimPrefix(value, " "))
+			hasData = true
+		}
+
+		if eof {
+			if hasData {
+				return strings.Join(data, "\n"), nil
+			}
+			return "", io.EOF
+		}
+	}
+}
+
+// streamReadError classifies an error from reading a response stream after the
+// request succeeded; events is how many had arrived. A connection cut off
+// mid-response is ErrStreamingFailed, unlike a clean EOF (the end of the stream)
+// or ctx being cancelled.
+func streamReadError(ctx context.Context, err error, events int) error {
+	if ctx.Err() != nil {
+		return ErrContextCancelled
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) {
+		return fmt.Errorf("%w: connection closed mid-response after %d events: %w", ErrStreamingFailed, events, err)
+	}
+	return fmt.Errorf("%w: reading the response after %d events: %w", ErrStreamingFailed, events, err)
+}
@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fragmentReader returns its data in reads of the given sizes, cycling through them
+type fragmentReader struct {
+	data  []byte
+	sizes []int
+	n     int
+}
+
+func (r *fragmentReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	size := r.sizes[r.n%len(r.sizes)]
+	r.n++
+	if size > len(r.data) {
+		size = len(r.data)
+	}
+	if size > len(p) {
+		size = len(p)
+	}
+	copy(p, r.data[:size])
+	r.data = r.data[size:]
+	return size, nil
+}
+
+const sseFixture = ": keep-alive\n\n" +
+	"data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+	"event: message\r\ndata: first line\r\ndata: second line\r\n\r\n" +
+	"data:no-space\n\n" +
+	"data: {\"choices\":[{\"delta\":{\"content\":\", wörld 🌉\"}}]}\n\n" +
+	"data: [DONE]\n\n"
+
+var sseWant = []string{
+	`{"choices":[{"delta":{"content":"Hello"}}]}`,
+	"first line\nsecond line",
+	"no-space",
+	`{"choices":[{"delta":{"content":", wörld 🌉"}}]}`,
+	"[DONE]",
+}
+
+func readAllEvents(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	events := newSSEReader(r)
+	var got []string
+	for {
+		data, err := events.Next()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		got = append(got, data)
+	}
+}
+
+func TestSSEReaderSurvivesFragmentation(t *testing.T) {
+	readers := map[string]func() io.Reader{
+		"whole":    func() io.Reader { return strings.NewReader(sseFixture) },
+		"one-byte": func() io.Reader { return iotest.OneByteReader(strings.NewReader(sseFixture)) },
+		"uneven":   func() io.Reader { return &fragmentReader{data: []byte(sseFixture), sizes: []int{3, 17, 1, 40, 7}} },
+	}
+
+	for name, newReader := range readers {
+		got := readAllEvents(t, newReader())
+		if strings.Join(got, "|") != strings.Join(sseWant, "|") {
+			t.Fatalf("%s: got %q, want %q", name, got, sseWant)
+		}
+	}
+}
+
+func TestSSEReaderFlushesFinalEventAtEOF(t *testing.T) {
+	got := readAllEvents(t, strings.NewReader("data: a\n\ndata: tail"))
+	if len(got) != 2 || got[1] != "tail" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestOpenAIStreamFragmentedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		// Flush every few bytes so events and multi-byte runes straddle TCP writes
+		for rest := sseFixture; rest != ""; {
+			n := min(5, len(rest))
+			io.WriteString(w, rest[:n])
+			flusher.Flush()
+			rest = rest[n:]
+			time.Sleep(time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "test", BaseURL: server.URL})
+	got, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Hello, wörld 🌉" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestStreamReadErrorClassifiesFailures(t *testing.T) {
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("read: %w", syscall.ECONNRESET)}
+	if err := streamReadError(context.Background(), reset, 3); !errors.Is(err, ErrStreamingFailed) || !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("expected a connection reset to be ErrStreamingFailed, got %v", err)
+	}
+
+	// Reads fail once the request is cancelled, but that's the caller's doing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := streamReadError(ctx, context.Canceled, 3); err != ErrContextCancelled {
+		t.Fatalf("expected ErrContextCancelled, got %v", err)
+	}
+}
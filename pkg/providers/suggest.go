@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is how many close matches SuggestModels returns at most
+const maxSuggestions = 3
+
+// SuggestModels returns the known models closest to a mistyped name, nearest first.
+// Matching ignores case; names too different to be a plausible typo are left out.
+func SuggestModels(model string, known []string) []string {
+	target := strings.ToLower(model)
+	limit := len(target)/2 + 1
+
+	type candidate struct {
+		name     string
+		prefix   bool // The name extends the input (e.g., "gpt-3.5-turbo" for "gpt-3.5")
+		distance int
+	}
+	var candidates []candidate
+	for _, name := range known {
+		lower := strings.ToLower(name)
+		c := candidate{
+			name:     name,
+			prefix:   target != "" && strings.HasPrefix(lower, target),
+			distance: levenshtein(target, lower),
+		}
+		if c.prefix || c.distance <= limit {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.prefix != b.prefix {
+			return a.prefix
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.name < b.name
+	})
+	if len(candidates) > maxSuggestions {
+		candidates = candidates[:maxSuggestions]
+	}
+
+	suggestions := make([]string, len(candidates))
+	for i, c := range candidates {
+		suggestions[i] = c.name
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b (insertions, deletions, substitutions)
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestModels(t *testing.T) {
+	known := []string{"gpt-4o", "gpt-4o-mini", "gpt-4-turbo", "gpt-4", "gpt-3.5-turbo"}
+
+	if got, want := SuggestModels("gpt4", known), []string{"gpt-4", "gpt-4o"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestModels(gpt4) = %v, want %v", got, want)
+	}
+
+	// The closest match comes first, ignoring case
+	for model, want := range map[string]string{"GPT-4O-MINI": "gpt-4o-mini", "gpt-4-trubo": "gpt-4-turbo", "gpt-3.5": "gpt-3.5-turbo"} {
+		if got := SuggestModels(model, known); len(got) == 0 || got[0] != want {
+			t.Errorf("SuggestModels(%q) = %v, want %s first", model, got, want)
+		}
+	}
+
+	if got := SuggestModels("claude-3-opus", known); len(got) != 0 {
+		t.Errorf("expected no suggestions for an unrelated model, got %v", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"gpt4", "gpt-4", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenCounter counts how many tokens a text costs a model. Counts feed budgets
+// and history limits, so estimates should err high rather than low.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// TokenCounterFunc adapts a plain function to TokenCounter
+type TokenCounterFunc func(text string) int
+
+// CountTokens calls f(text)
+func (f TokenCounterFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// HeuristicCounter estimates tokens without a tokenizer; it's the default for
+// providers that don't declare their own
+var HeuristicCounter TokenCounter = TokenCounterFunc(EstimateTokens)
+
+// MessageOverheadTokens approximates the role and framing tokens chat formats add per message
+const MessageOverheadTokens = 4
+
+// EstimateTokens approximates a BPE token count. Plain chars/4 undercounts code
+// and non-Latin scripts, so instead: each word costs one token per four characters,
+// each punctuation mark or symbol one token, each CJK character one token, and each
+// run of extra whitespace (e.g. indentation) one token.
+func EstimateTokens(text string) int {
+	tokens, word, space := 0, 0, 0
+	flushWord := func() {
+		tokens += (word + 3) / 4
+		word = 0
+	}
+
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		switch {
+		case unicode.IsSpace(r):
+			flushWord()
+			if space++; space == 2 {
+				tokens++
+			}
+			continue
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word++
+		default:
+			flushWord()
+			tokens++
+		}
+		space = 0
+	}
+	flushWord()
+	return tokens
+}
+
+// CountMessages totals the tokens a request's system prompt and messages cost,
+// including per-message framing. Images are not counted.
+func CountMessages(counter TokenCounter, systemPrompt string, messages []Message) int {
+	total := 0
+	if systemPrompt != "" {
+		total += MessageOverheadTokens + counter.CountTokens(systemPrompt)
+	}
+	for _, msg := range messages {
+		total += MessageOverheadTokens + counter.CountTokens(msg.Content)
+		for _, call := range msg.ToolCalls {
+			total += counter.CountTokens(call.Name) + counter.CountTokens(call.Arguments)
+		}
+	}
+	return total
+}
+
+// CounterFor returns the token counter a provider prefers for model, falling back
+// to HeuristicCounter
+func CounterFor(provider, model string) TokenCounter {
+	if spec, ok := GetProviderSpec(provider); ok && spec.TokenCounter != nil {
+		if counter := spec.TokenCounter(model); counter != nil {
+			return counter
+		}
+	}
+	return HeuristicCounter
+}
+
+// encodingCounter returns a counter using a model's real BPE encoding, or nil if
+// it doesn't know the model. It is only set in builds with the tiktoken tag.
+var encodingCounter func(model string) TokenCounter
+
+// openAITokenCounter counts with tiktoken when it's built in
+func openAITokenCounter(model string) TokenCounter {
+	if encodingCounter == nil {
+		return nil
+	}
+	return encodingCounter(model)
+}
@@ -0,0 +1,56 @@
+package providers
+
+import "testing"
+
+func TestEstimateTokens(t *testing.T) {
+	cases := map[string]int{
+		"":                     0,
+		"hello world":          4, // 5 letters → 2 tokens, twice
+		"Hi, there.":           5, // Punctuation counts separately
+		"日本語":                  3,
+		"if (x) {\n    y++\n}": 10,
+		"  ":                   1,
+	}
+	for text, want := range cases {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+
+	// Code costs more per character than prose, which chars/4 misses
+	prose, code := "the quick brown fox jumps over", "a[i]=b(c,d);e.f()"
+	if EstimateTokens(code) <= EstimateTokens(prose) {
+		t.Errorf("expected %q to cost more than %q", code, prose)
+	}
+}
+
+func TestCountMessages(t *testing.T) {
+	words := TokenCounterFunc(func(text string) int { return len(text) })
+	got := CountMessages(words, "sys", []Message{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", ToolCalls: []ToolCall{{Name: "calc", Arguments: "{}"}}},
+	})
+	if want := (4 + 3) + (4 + 5) + (4 + 4 + 2); got != want {
+		t.Fatalf("CountMessages = %d, want %d", got, want)
+	}
+}
+
+func TestCounterForUsesProviderPreference(t *testing.T) {
+	fixed := TokenCounterFunc(func(string) int { return 7 })
+	RegisterProvider(ProviderSpec{Key: "counted-test", TokenCounter: func(model string) TokenCounter {
+		if model == "known" {
+			return fixed
+		}
+		return nil
+	}})
+
+	if got := CounterFor("counted-test", "known").CountTokens("anything"); got != 7 {
+		t.Fatalf("expected the provider's counter, got %d", got)
+	}
+	if CounterFor("counted-test", "other").CountTokens("abcd") != EstimateTokens("abcd") {
+		t.Fatal("expected the heuristic for models the provider can't count")
+	}
+	if CounterFor("no-such-provider", "x").CountTokens("abcd") != EstimateTokens("abcd") {
+		t.Fatal("expected the heuristic for unknown providers")
+	}
+}
@@ -0,0 +1,49 @@
+//go:build tiktoken
+
+// Exact token counts for OpenAI models. This needs the tokenizer module, which
+// is not a default dependency:
+//
+//	go get github.com/pkoukk/tiktoken-go
+//	go build -tags tiktoken .
+
+package providers
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// fallbackEncoding is used for models tiktoken doesn't map yet; it is the
+// encoding of the current GPT-4o family
+const fallbackEncoding = "o200k_base"
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = map[string]TokenCounter{} // Counters by model; loading an encoding is expensive
+)
+
+func init() {
+	encodingCounter = tiktokenCounter
+}
+
+func tiktokenCounter(model string) TokenCounter {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	if counter, ok := encodings[model]; ok {
+		return counter
+	}
+
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding(fallbackEncoding)
+	}
+	var counter TokenCounter // nil falls back to the heuristic
+	if err == nil {
+		counter = TokenCounterFunc(func(text string) int {
+			return len(enc.Encode(text, nil, nil))
+		})
+	}
+	encodings[model] = counter
+	return counter
+}
@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Tool choice values for ChatRequest.ToolChoice; any other value names a tool the model must call
+const (
+	ToolChoiceAuto     = "auto"     // The model decides (default)
+	ToolChoiceNone     = "none"     // Tools are described but must not be called
+	ToolChoiceRequired = "required" // The model must call at least one tool
+)
+
+// ToolSpec describes a function the model may call
+type ToolSpec struct {
+	Name        string          // Function name (e.g., "calculator")
+	Description string          // What the tool does, for the model
+	Parameters  json.RawMessage // JSON Schema for the arguments object
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID        string // Provider-assigned call ID, echoed back in the tool message
+	Name      string // Function name
+	Arguments string // Arguments as a JSON object
+}
+
+// ToolStreamer is implemented by providers that support tool calling. StreamChatTools
+// behaves like StreamChat, and when the model stops to call tools it sends the calls
+// on the third channel before the text channel closes. A stream that ends without
+// calls finishes the turn.
+type ToolStreamer interface {
+	StreamChatTools(ctx context.Context, req *ChatRequest) (<-chan string, <-chan error, <-chan []ToolCall)
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// traceSeq numbers traced requests across all providers so files sort in conversation order
+var traceSeq atomic.Int64
+
+// conversationKey is the context key for the conversation a request belongs to
+type conversationKey struct{}
+
+// WithConversationID tags ctx with the ID of the conversation its requests belong
+// to, so trace files and provider log lines can be matched to the run
+func WithConversationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, id)
+}
+
+// ConversationID returns the ID set by WithConversationID, or "" if there is none
+func ConversationID(ctx context.Context) string {
+	id, _ := ctx.Value(conversationKey{}).(string)
+	return id
+}
+
+// tracer writes each raw request and response to a trace directory for debugging.
+// A nil tracer is valid and records nothing, so normal runs pay no cost.
+type tracer struct {
+	dir string
+}
+
+// traceRecord is the request file written before each call
+type traceRecord struct {
+	Provider string          `json:"provider"`
+	Method   string          `json:"method,omitempty"`
+	URL      string          `json:"url,omitempty"`
+	Command  string          `json:"command,omitempty"`
+	Time     time.Time       `json:"time"`
+	Body     json.RawMessage `json:"body"`
+
+	Conversation string `json:"conversation,omitempty"` // From ConversationID
+}
+
+func newTracer(dir string) *tracer {
+	if dir == "" {
+		return nil
+	}
+	return &tracer{dir: dir}
+}
+
+// begin writes the request record and returns a writer for the raw response. File
+// names start with the time and a sequence number, and end with the first eight
+// characters of the conversation ID when the record has one.
+// Trace failures are logged and never fail the request; the returned writer is
+// then io.Discard. The caller must Close the writer when the response ends.
+func (t *tracer) begin(record traceRecord) io.WriteCloser {
+	if t == nil {
+		return nopWriteCloser{io.Discard}
+	}
+
+	record.Time = time.Now()
+	base := filepath.Join(t.dir, fmt.Sprintf("%s-%04d-%s",
+		record.Time.Format("20060102T150405.000"), traceSeq.Add(1), record.Provider))
+	if id := record.Conversation; id != "" {
+		base += "-" + id[:min(8, len(id))]
+	}
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		slog.Warn("trace disabled for request", "error", err)
+		return nopWriteCloser{io.Discard}
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err == nil {
+		err = os.WriteFile(base+".request.json", data, 0o600)
+	}
+	if err != nil {
+		slog.Warn("failed to write request trace", "error", err)
+		return nopWriteCloser{io.Discard}
+	}
+
+	f, err := os.OpenFile(base+".response", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		slog.Warn("failed to create response trace", "error", err)
+		return nopWriteCloser{io.Discard}
+	}
+	slog.Debug("tracing request", "files", base+".*")
+	return &traceFile{f: f}
+}
+
+// traceFile never reports write errors, so a full disk can't break the stream it is teeing
+type traceFile struct {
+	f      *os.File
+	failed bool
+}
+
+func (t *traceFile) Write(p []byte) (int, error) {
+	if !t.failed {
+		if _, err := t.f.Write(p); err != nil {
+			slog.Warn("response trace truncated", "error", err)
+			t.failed = true
+		}
+	}
+	return len(p), nil
+}
+
+func (t *traceFile) Close() error {
+	return t.f.Close()
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenAITraceWritesRequestAndRawResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, sseFixture)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "traces")
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "sk-trace-secret", BaseURL: server.URL, TraceDir: dir})
+
+	got, err := collectStream(p.StreamChat(context.Background(), &ChatRequest{
+		Model:    "gpt-test",
+		Messages: []Message{{Role: "user", Content: "trace me"}},
+	}))
+	if err != nil || got != "Hello, wörld 🌉" {
+		t.Fatalf("stream changed by tracing: %q, %v", got, err)
+	}
+
+	requests, _ := filepath.Glob(filepath.Join(dir, "*-openai.request.json"))
+	responses, _ := filepath.Glob(filepath.Join(dir, "*-openai.response"))
+	if len(requests) != 1 || len(responses) != 1 {
+		t.Fatalf("expected one request and one response trace, got %v %v", requests, responses)
+	}
+
+	data, _ := os.ReadFile(requests[0])
+	if strings.Contains(string(data), "sk-trace-secret") {
+		t.Fatal("API key leaked into request trace")
+	}
+	var record traceRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("request trace is not JSON: %v", err)
+	}
+	if record.Method != "POST" || !strings.Contains(string(record.Body), "trace me") {
+		t.Fatalf("unexpected request trace: %s", data)
+	}
+
+	// The stream stops at [DONE], so the trace holds everything read up to that event
+	raw, _ := os.ReadFile(responses[0])
+	if !strings.HasPrefix(sseFixture, string(raw)) || !strings.Contains(string(raw), "data: [DONE]") {
+		t.Fatalf("response trace is not the raw stream: %q", raw)
+	}
+}
+
+func TestTracerDisabledByDefault(t *testing.T) {
+	if tr := newTracer(""); tr != nil {
+		t.Fatal("expected nil tracer without a directory")
+	}
+
+	w := (*tracer)(nil).begin(traceRecord{Provider: "none"})
+	if n, err := w.Write([]byte("ignored")); n != 7 || err != nil {
+		t.Fatalf("nil tracer writer should discard, got %d, %v", n, err)
+	}
+	w.Close()
+}
+
+func TestTraceFilesNameTheConversation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, sseFixture)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	p := NewOpenAIProvider(ProviderConfig{APIKey: "sk-test", BaseURL: server.URL, TraceDir: dir})
+	ctx := WithConversationID(context.Background(), "1b4e28ba-2fa1-41d2-883f-0016d3cca427")
+	if _, err := collectStream(p.StreamChat(ctx, &ChatRequest{Model: "gpt-test", Messages: []Message{{Role: "user", Content: "hi"}}})); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, _ := filepath.Glob(filepath.Join(dir, "*-openai-1b4e28ba.request.json"))
+	if len(requests) != 1 {
+		t.Fatalf("expected the short conversation ID in the trace name, got %v", requests)
+	}
+	data, _ := os.ReadFile(requests[0])
+	var record traceRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Conversation != "1b4e28ba-2fa1-41d2-883f-0016d3cca427" {
+		t.Fatalf("expected the conversation ID in the request trace: %s", data)
+	}
+}
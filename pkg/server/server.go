@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/internal/version"
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/conversation"
+)
+
+// Request limits and defaults for remotely driven conversations
+const (
+	DefaultStarter   = "Hello! How are you today?"
+	DefaultMaxRounds = 10
+	MaxAllowedRounds = 100
+	defaultTemp      = 0.7
+)
+
+// ConversationIDHeader carries the session's conversation ID on the event stream
+// response, matching the "conversation" field of the server's log lines
+const ConversationIDHeader = "X-Conversation-ID"
+
+// ConversationRequest is the JSON body accepted by POST /conversations
+type ConversationRequest struct {
+	ProviderA      string   `json:"provider_a"`
+	ProviderB      string   `json:"provider_b"`
+	ModelA         string   `json:"model_a"`
+	ModelB         string   `json:"model_b"`
+	TempA          *float64 `json:"temp_a"`
+	TempB          *float64 `json:"temp_b"`
+	NameA          string   `json:"name_a"`
+	NameB          string   `json:"name_b"`
+	Starter        string   `json:"starter"`
+	MaxRounds      int      `json:"max_rounds"`
+	StopOnFarewell bool     `json:"stop_on_farewell"`
+	ToolsA         []string `json:"tools_a"`
+	ToolsB         []string `json:"tools_b"`
+	Mode           string   `json:"mode"`
+}
+
+// Server exposes the conversation engine over HTTP with Server-Sent Events
+type Server struct {
+	cfg    *config.Config
+	mux    *http.ServeMux
+	health *bridge.HealthCache
+}
+
+// New creates a server that builds providers from cfg. Health checks passed in the
+// last bridge.DefaultHealthCacheTTL are reused across sessions.
+func New(cfg *config.Config) *Server {
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), health: bridge.NewHealthCache(bridge.DefaultHealthCacheTTL)}
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /conversations", s.handleConversation)
+	return s
+}
+
+// SetHealthCacheTTL changes how long a passed provider health check is reused (0
+// checks before every session). Call it before serving.
+func (s *Server) SetHealthCacheTTL(ttl time.Duration) {
+	s.health = bridge.NewHealthCache(ttl)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"version": version.GetVersion(),
+	})
+}
+
+func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
+	var req ConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	applyDefaults(s.cfg, &req)
+
+	if req.MaxRounds < 1 || req.MaxRounds > MaxAllowedRounds {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("max_rounds must be between 1 and %d", MaxAllowedRounds))
+		return
+	}
+	mode, err := bridge.ParseMode(req.Mode)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	agentA, err := s.buildAgent(req.ProviderA, req.ModelA, req.NameA, req.TempA, req.ToolsA)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	agentB, err := s.buildAgent(req.ProviderB, req.ModelB, req.NameB, req.TempB, req.ToolsB)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var farewell *conversation.FarewellDetector
+	if req.StopOnFarewell {
+		farewell, _ = conversation.NewFarewellDetector(nil)
+	}
+
+	ctx := r.Context()
+	for _, agent := range []*bridge.Agent{agentA, agentB} {
+		if err := s.health.Check(ctx, agent); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	conv := bridge.New(agentA, agentB, bridge.Options{
+		Mode:      mode,
+		Starter:   req.Starter,
+		MaxRounds: req.MaxRounds,
+		Farewell:  farewell,
+
+		HealthCache: s.health,
+	})
+	go logSession(conv.ID(), conv.Subscribe(0))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set(ConversationIDHeader, conv.ID())
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The request context is cancelled when the client disconnects or the server shuts down
+	for ev := range conv.Run(ctx) {
+		name, payload := sseEvent(ev)
+		if err := writeSSE(w, name, payload); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// logSession records a session's turns and outcome in the server log, beside the
+// client's event stream
+func logSession(id string, sub *bridge.Subscription) {
+	log := slog.With("conversation", id)
+	for ev := range sub.Events() {
+		switch ev.Type {
+		case bridge.EventTurnComplete:
+			log.Info("turn completed", "round", ev.Round, "agent", ev.Agent.Name, "model", ev.Turn.Model, "chars", len(ev.Turn.Content), "duration", ev.Turn.Duration)
+		case bridge.EventWarning:
+			log.Warn(ev.Text, "round", ev.Round, "error", ev.Err)
+		case bridge.EventDone:
+			log.Info("conversation finished", "rounds", ev.Result.Rounds, "reason", ev.Result.Reason, "error", ev.Err)
+		}
+	}
+	if n := sub.Dropped(); n > 0 {
+		log.Debug("server log fell behind the session", "dropped_events", n)
+	}
+}
+
+// buildAgent resolves provider settings from configuration, like the start command
+func (s *Server) buildAgent(provider, model, name string, temp *float64, toolNames []string) (*bridge.Agent, error) {
+	// Never let remote clients launch local commands
+	if s.cfg.ProviderKey(provider) == "exec" {
+		return nil, fmt.Errorf("provider 'exec' is not available in server mode")
+	}
+
+	return bridge.NewAgent(s.cfg, bridge.AgentConfig{
+		Name:        name,
+		Provider:    provider,
+		Model:       model,
+		Temperature: temp,
+		Tools:       toolNames,
+	})
+}
+
+// applyDefaults fills unset request fields
+func applyDefaults(cfg *config.Config, req *ConversationRequest) {
+	if req.ProviderA == "" {
+		req.ProviderA = cfg.DefaultProviderA
+	}
+	if req.ProviderB == "" {
+		req.ProviderB = cfg.DefaultProviderB
+	}
+	if req.NameA == "" {
+		req.NameA = "Agent A"
+	}
+	if req.NameB == "" {
+		req.NameB = "Agent B"
+	}
+	if req.TempA == nil {
+		t := defaultTemp
+		req.TempA = &t
+	}
+	if req.TempB == nil {
+		t := defaultTemp
+		req.TempB = &t
+	}
+	if req.Starter == "" {
+		req.Starter = DefaultStarter
+	}
+	if req.MaxRounds == 0 {
+		req.MaxRounds = DefaultMaxRounds
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/config"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// scriptedProvider replies with a fixed word split into two chunks
+type scriptedProvider struct {
+	reply string
+}
+
+func (p *scriptedProvider) Name() string         { return "server-test" }
+func (p *scriptedProvider) DefaultModel() string { return "scripted" }
+func (p *scriptedProvider) Health(ctx context.Context) error {
+	return nil
+}
+func (p *scriptedProvider) Models(ctx context.Context) ([]string, error) {
+	return []string{"scripted"}, nil
+}
+
+func (p *scriptedProvider) StreamChat(ctx context.Context, req *providers.ChatRequest) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+		half := len(p.reply) / 2
+		textChan <- p.reply[:half]
+		textChan <- p.reply[half:]
+	}()
+	return textChan, errChan
+}
+
+func init() {
+	providers.RegisterProvider(providers.ProviderSpec{Key: "server-test", Name: "Server Test"})
+	providers.RegisterProviderFactory("server-test", func(cfg providers.ProviderConfig) providers.Provider {
+		return &scriptedProvider{reply: "Goodbye!"}
+	})
+}
+
+type sseMessage struct {
+	event string
+	data  map[string]interface{}
+}
+
+func readSSE(t *testing.T, resp *http.Response) []sseMessage {
+	t.Helper()
+	var messages []sseMessage
+	var current sseMessage
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.data); err != nil {
+				t.Fatalf("bad SSE data %q: %v", line, err)
+			}
+		case line == "":
+			messages = append(messages, current)
+			current = sseMessage{}
+		}
+	}
+	return messages
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	ts := httptest.NewServer(New(&config.Config{}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("GET /health: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["status"] != "ok" {
+		t.Fatalf("expected status ok, got %q", body["status"])
+	}
+}
+
+func TestConversationStreamsEvents(t *testing.T) {
+	ts := httptest.NewServer(New(&config.Config{}))
+	defer ts.Close()
+
+	body := `{"provider_a":"server-test","provider_b":"server-test","max_rounds":3,"stop_on_farewell":true,"name_a":"Ada"}`
+	resp, err := http.Post(ts.URL+"/conversations", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /conversations: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", got)
+	}
+	if resp.Header.Get(ConversationIDHeader) == "" {
+		t.Fatal("expected the conversation ID in the response headers")
+	}
+
+	messages := readSSE(t, resp)
+	var names []string
+	for _, m := range messages {
+		names = append(names, m.event)
+	}
+
+	want := "turn_start,token,token,turn,turn_start,token,token,turn,done"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("events:\n got  %s\n want %s", got, want)
+	}
+
+	first := messages[3].data
+	if first["agent"] != "Ada" || first["content"] != "Goodbye!" {
+		t.Fatalf("unexpected first turn: %v", first)
+	}
+
+	done := messages[len(messages)-1].data
+	if done["reason"] != "farewell" || done["rounds"] != float64(2) {
+		t.Fatalf("unexpected done event: %v", done)
+	}
+}
+
+func TestConversationRejectsBadRequests(t *testing.T) {
+	ts := httptest.NewServer(New(&config.Config{}))
+	defer ts.Close()
+
+	cases := []string{
+		`not json`,
+		`{"provider_a":"server-test","provider_b":"server-test","max_rounds":1000}`,
+		`{"provider_a":"nope","provider_b":"server-test"}`,
+		`{"provider_a":"exec","provider_b":"server-test"}`,
+		`{"provider_a":"server-test","provider_b":"server-test","mode":"chaos"}`,
+	}
+
+	for _, body := range cases {
+		resp, err := http.Post(ts.URL+"/conversations", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+)
+
+// SSE event names sent to clients
+const (
+	eventTurnStart = "turn_start"
+	eventToken     = "token"
+	eventTurn      = "turn"
+	eventDone      = "done"
+	eventError     = "error"
+	eventWarning   = "warning"
+	eventToolCall  = "tool_call"
+)
+
+// sseEvent converts an engine event into an SSE event name and JSON payload
+func sseEvent(ev bridge.Event) (string, interface{}) {
+	switch ev.Type {
+	case bridge.EventTurnStart:
+		return eventTurnStart, map[string]interface{}{
+			"round": ev.Round,
+			"agent": ev.Agent.Name,
+		}
+	case bridge.EventToken:
+		return eventToken, map[string]interface{}{
+			"round": ev.Round,
+			"agent": ev.Agent.Name,
+			"text":  ev.Text,
+		}
+	case bridge.EventTurnComplete:
+		payload := map[string]interface{}{
+			"round":       ev.Turn.Round,
+			"agent":       ev.Turn.Agent,
+			"model":       ev.Turn.Model,
+			"content":     ev.Turn.Content,
+			"duration_ms": ev.Turn.Duration.Milliseconds(),
+		}
+		if ev.Turn.ServedModel != "" {
+			payload["served_model"] = ev.Turn.ServedModel
+		}
+		if ev.Turn.FinishReason != "" {
+			payload["finish_reason"] = ev.Turn.FinishReason
+		}
+		if ev.Turn.Cancelled {
+			payload["cancelled"] = true
+		}
+		if ev.Turn.Refusal != "" {
+			payload["refusal"] = ev.Turn.Refusal
+		}
+		return eventTurn, payload
+	case bridge.EventWarning:
+		return eventWarning, map[string]interface{}{
+			"round":   ev.Round,
+			"message": ev.Text,
+			"error":   fmt.Sprint(ev.Err),
+		}
+	case bridge.EventToolCall:
+		return eventToolCall, map[string]interface{}{
+			"round":     ev.Round,
+			"agent":     ev.Agent.Name,
+			"tool":      ev.Tool.Name,
+			"arguments": ev.Tool.Arguments,
+			"result":    ev.Tool.Result,
+			"error":     ev.Tool.Error,
+		}
+	default:
+		if ev.Err != nil {
+			return eventError, map[string]interface{}{
+				"round": ev.Round,
+				"error": ev.Err.Error(),
+			}
+		}
+		return eventDone, map[string]interface{}{
+			"rounds":     ev.Result.Rounds,
+			"reason":     ev.Result.Reason,
+			"elapsed_ms": ev.Result.Elapsed.Milliseconds(),
+			"summary":    ev.Result.Summary,
+		}
+	}
+}
+
+// writeSSE writes a single Server-Sent Event
+func writeSSE(w io.Writer, name string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func init() {
+	Register(Tool{
+		Spec: providers.ToolSpec{
+			Name:        "calculator",
+			Description: "Evaluate an arithmetic expression using + - * / % ^ and parentheses, e.g. (3 + 4) * 2 ^ 3",
+			Parameters: json.RawMessage(`{"type":"object","properties":{"expression":{"type":"string",` +
+				`"description":"Arithmetic expression to evaluate"}},"required":["expression"]}`),
+		},
+		Handler: calculator,
+	})
+
+	Register(Tool{
+		Spec: providers.ToolSpec{
+			Name:        "current_time",
+			Description: "Get the current date and time, optionally in an IANA time zone such as Europe/Paris",
+			Parameters: json.RawMessage(`{"type":"object","properties":{"timezone":{"type":"string",` +
+				`"description":"IANA time zone name; defaults to UTC"}}}`),
+		},
+		Handler: currentTime,
+	})
+}
+
+func calculator(ctx context.Context, raw json.RawMessage) (string, error) {
+	var args struct {
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	value, err := Evaluate(args.Expression)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
+
+func currentTime(ctx context.Context, raw json.RawMessage) (string, error) {
+	var args struct {
+		Timezone string `json:"timezone"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	loc := time.UTC
+	if args.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(args.Timezone); err != nil {
+			return "", fmt.Errorf("unknown time zone %q", args.Timezone)
+		}
+	}
+	return time.Now().In(loc).Format("Monday, 2006-01-02 15:04:05 MST"), nil
+}
+
+// Evaluate computes an arithmetic expression with the usual precedence:
+// parentheses, unary minus, ^ (right-associative), then * / %, then + -.
+func Evaluate(expr string) (float64, error) {
+	p := &exprParser{input: expr}
+	value, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return value, nil
+}
+
+// exprParser is a small recursive-descent parser over the expression text
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) parseSum() (float64, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *exprParser) parseProduct() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case op == '*':
+			left *= right
+		case right == 0:
+			return 0, fmt.Errorf("division by zero")
+		case op == '/':
+			left /= right
+		default:
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err := p.parseUnary()
+		return -value, err
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *exprParser) parseAtom() (float64, error) {
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		value, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return value, nil
+
+	case c == 0:
+		if strings.TrimSpace(p.input) == "" {
+			return 0, fmt.Errorf("empty expression")
+		}
+		return 0, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+	}
+}
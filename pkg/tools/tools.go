@@ -0,0 +1,91 @@
+// Package tools holds the functions bridged agents can call.
+//
+// Tools register themselves in init(), like providers do, so adding one is a
+// matter of calling Register with a spec and a handler:
+//
+//	func init() {
+//		tools.Register(tools.Tool{
+//			Spec: providers.ToolSpec{
+//				Name:        "lookup",
+//				Description: "Look up a term in the team glossary",
+//				Parameters:  json.RawMessage(`{"type":"object","properties":{"term":{"type":"string"}},"required":["term"]}`),
+//			},
+//			Handler: func(ctx context.Context, args json.RawMessage) (string, error) { ... },
+//		})
+//	}
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+// Handler runs a tool with the model's JSON arguments and returns the result text
+type Handler func(ctx context.Context, args json.RawMessage) (string, error)
+
+// Tool pairs a tool's description with the Go function that implements it
+type Tool struct {
+	Spec    providers.ToolSpec
+	Handler Handler
+}
+
+var registry = make(map[string]Tool)
+
+// Register adds a tool to the global registry, replacing any tool with the same name
+func Register(tool Tool) {
+	registry[tool.Spec.Name] = tool
+}
+
+// Get returns a registered tool by name
+func Get(name string) (Tool, bool) {
+	tool, ok := registry[name]
+	return tool, ok
+}
+
+// List returns all registered tools sorted by name
+func List() []Tool {
+	list := make([]Tool, 0, len(registry))
+	for _, tool := range registry {
+		list = append(list, tool)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Spec.Name < list[j].Spec.Name
+	})
+	return list
+}
+
+// Names returns the registered tool names in sorted order
+func Names() []string {
+	var names []string
+	for _, tool := range List() {
+		names = append(names, tool.Spec.Name)
+	}
+	return names
+}
+
+// Lookup resolves tool names, failing on the first unknown one
+func Lookup(names []string) ([]Tool, error) {
+	list := make([]Tool, 0, len(names))
+	for _, name := range names {
+		tool, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(Names(), ", "))
+		}
+		list = append(list, tool)
+	}
+	return list, nil
+}
+
+// Specs returns the specs of the given tools, for ChatRequest.Tools
+func Specs(list []Tool) []providers.ToolSpec {
+	specs := make([]providers.ToolSpec, len(list))
+	for i, tool := range list {
+		specs[i] = tool.Spec
+	}
+	return specs
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	cases := map[string]float64{
+		"1 + 2 * 3":     7,
+		"(1 + 2) * 3":   9,
+		"2 ^ 3 ^ 2":     512,
+		"-2 ^ 2":        -4,
+		"10 % 4":        2,
+		"7 / 2":         3.5,
+		"-(3 - 5) + .5": 2.5,
+	}
+	for expr, want := range cases {
+		got, err := Evaluate(expr)
+		if err != nil || got != want {
+			t.Errorf("Evaluate(%q) = %v, %v; want %v", expr, got, err, want)
+		}
+	}
+
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 / 0", "2 ^ 10000", "3 x 4", "1..2"} {
+		if _, err := Evaluate(expr); err == nil {
+			t.Errorf("Evaluate(%q) should fail", expr)
+		}
+	}
+}
+
+func TestBuiltinTools(t *testing.T) {
+	calc, _ := Get("calculator")
+	got, err := calc.Handler(context.Background(), json.RawMessage(`{"expression":"6 * 7"}`))
+	if err != nil || got != "42" {
+		t.Fatalf("calculator = %q, %v", got, err)
+	}
+
+	clock, _ := Get("current_time")
+	got, err = clock.Handler(context.Background(), json.RawMessage(`{"timezone":"Asia/Tokyo"}`))
+	if err != nil || !strings.HasSuffix(got, "JST") {
+		t.Fatalf("current_time = %q, %v", got, err)
+	}
+	if _, err := clock.Handler(context.Background(), json.RawMessage(`{"timezone":"Mars/Olympus"}`)); err == nil {
+		t.Fatal("expected an unknown time zone to fail")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	list, err := Lookup([]string{"current_time", "calculator"})
+	if err != nil || len(list) != 2 || list[0].Spec.Name != "current_time" {
+		t.Fatalf("Lookup = %+v, %v", list, err)
+	}
+	if _, err := Lookup([]string{"nope"}); err == nil || !strings.Contains(err.Error(), "calculator") {
+		t.Fatalf("expected an error listing the available tools, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package transcript
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffOp says which side of a diff a line belongs to
+type DiffOp int
+
+const (
+	LineEqual   DiffOp = iota // In both turns
+	LineRemoved               // Only in the first transcript's turn
+	LineAdded                 // Only in the second transcript's turn
+)
+
+// DiffLine is one line of a turn diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// TurnDiff compares the turns two transcripts hold for the same round and
+// speaker. A or B is nil when only one transcript has the turn.
+type TurnDiff struct {
+	Round   int
+	Speaker int
+	A, B    *Turn
+	Lines   []DiffLine // Line diff of the contents; nil when they are identical
+}
+
+// Same reports whether both transcripts have the turn with identical content
+func (d TurnDiff) Same() bool {
+	return d.A != nil && d.B != nil && d.A.Content == d.B.Content
+}
+
+// Diff aligns the turns of a and b by round and speaker, in order, and diffs the
+// content of each pair line by line. Incomplete turns are left out.
+func Diff(a, b *Transcript) []TurnDiff {
+	type key struct{ round, speaker int }
+	index := make(map[key]int)
+	var diffs []TurnDiff
+	add := func(turns []Turn, first bool) {
+		for i := range turns {
+			turn := &turns[i]
+			if turn.Incomplete {
+				continue
+			}
+			k := key{turn.Round, turn.Speaker}
+			n, ok := index[k]
+			if !ok {
+				n = len(diffs)
+				index[k] = n
+				diffs = append(diffs, TurnDiff{Round: turn.Round, Speaker: turn.Speaker})
+			}
+			if first {
+				diffs[n].A = turn
+			} else {
+				diffs[n].B = turn
+			}
+		}
+	}
+	add(a.Turns, true)
+	add(b.Turns, false)
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Round != diffs[j].Round {
+			return diffs[i].Round < diffs[j].Round
+		}
+		return diffs[i].Speaker < diffs[j].Speaker
+	})
+	for i := range diffs {
+		if d := &diffs[i]; !d.Same() {
+			d.Lines = diffLines(turnLines(d.A), turnLines(d.B))
+		}
+	}
+	return diffs
+}
+
+// HeaderChange is a setting two transcripts' headers disagree on
+type HeaderChange struct {
+	Field string // e.g. "starter" or "agent B model"
+	A, B  string
+}
+
+// DiffHeaders lists the settings that differ between two runs: the starter and
+// context, the run's shape, and each agent's provider, model, temperature and
+// system prompt
+func DiffHeaders(a, b Header) []HeaderChange {
+	var changes []HeaderChange
+	compare := func(field, x, y string) {
+		if x != y {
+			changes = append(changes, HeaderChange{field, x, y})
+		}
+	}
+	compare("starter", a.Starter, b.Starter)
+	compare("context", a.Context, b.Context)
+	compare("max rounds", strconv.Itoa(a.MaxRounds), strconv.Itoa(b.MaxRounds))
+	compare("mode", a.Mode, b.Mode)
+	for i, side := range []string{"A", "B"} {
+		x, y := a.Agents[i], b.Agents[i]
+		compare("agent "+side+" provider", x.ProviderName(), y.ProviderName())
+		compare("agent "+side+" model", x.Model, y.Model)
+		compare("agent "+side+" temperature", formatTemperature(x.Temperature), formatTemperature(y.Temperature))
+		compare("agent "+side+" system prompt", x.SystemPrompt, y.SystemPrompt)
+	}
+	return changes
+}
+
+// formatTemperature formats an agent's temperature; an unset one is "default"
+func formatTemperature(t *float64) string {
+	if t == nil {
+		return "default"
+	}
+	return strconv.FormatFloat(*t, 'g', -1, 64)
+}
+
+// FirstDivergence returns the index of the first turn that differs, or -1 when
+// the transcripts are identical turn for turn
+func FirstDivergence(diffs []TurnDiff) int {
+	for i, d := range diffs {
+		if !d.Same() {
+			return i
+		}
+	}
+	return -1
+}
+
+// turnLines splits a turn's content into lines; a missing turn has none
+func turnLines(t *Turn) []string {
+	if t == nil || t.Content == "" {
+		return nil
+	}
+	return strings.Split(t.Content, "\n")
+}
+
+// diffLines computes a line diff from the longest common subsequence of a and b,
+// listing removed lines before added ones where both change
+func diffLines(a, b []string) []DiffLine {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, DiffLine{LineEqual, a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, DiffLine{LineRemoved, a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{LineAdded, b[j]})
+			j++
+		}
+	}
+	return lines
+}
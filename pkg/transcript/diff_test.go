@@ -0,0 +1,56 @@
+package transcript
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func TestDiffAlignsTurnsByRound(t *testing.T) {
+	a := &Transcript{Header: testHeader(), Turns: []Turn{
+		{Round: 1, Speaker: 0, Content: "Hello"},
+		{Round: 2, Speaker: 1, Content: "one\ntwo\nthree"},
+		{Round: 3, Speaker: 0, Content: "only in a"},
+	}}
+	b := &Transcript{Header: testHeader(), Turns: []Turn{
+		{Round: 1, Speaker: 0, Content: "Hello"},
+		{Round: 2, Speaker: 1, Content: "one\n2\nthree\nfour"},
+		{Round: 3, Speaker: 0, Content: "still streaming", Incomplete: true},
+	}}
+
+	diffs := Diff(a, b)
+	if len(diffs) != 3 || !diffs[0].Same() || diffs[0].Lines != nil {
+		t.Fatalf("unexpected diffs: %+v", diffs)
+	}
+	want := []DiffLine{{LineEqual, "one"}, {LineRemoved, "two"}, {LineAdded, "2"}, {LineEqual, "three"}, {LineAdded, "four"}}
+	if !reflect.DeepEqual(diffs[1].Lines, want) {
+		t.Fatalf("got %+v, want %+v", diffs[1].Lines, want)
+	}
+	if d := diffs[2]; d.Round != 3 || d.A == nil || d.B != nil || d.Same() || len(d.Lines) != 1 || d.Lines[0].Op != LineRemoved {
+		t.Fatalf("expected round 3 only in a: %+v", d)
+	}
+	if got := FirstDivergence(diffs); got != 1 {
+		t.Fatalf("FirstDivergence = %d, want 1", got)
+	}
+	if got := FirstDivergence(Diff(a, a)); got != -1 {
+		t.Fatalf("identical transcripts diverge at %d", got)
+	}
+}
+
+func TestDiffHeadersListsChangedSettings(t *testing.T) {
+	a, b := testHeader(), testHeader()
+	b.Starter = "Hi"
+	b.Agents[1].Temperature = nil
+	b.Started = b.Started.AddDate(0, 0, 1) // Not a setting
+
+	want := []HeaderChange{{"starter", "Hello", "Hi"}, {"agent B temperature", "0.9", "default"}}
+	if got := DiffHeaders(a, b); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	b = testHeader()
+	b.Agents[0].Temperature = providers.Float(0.2)
+	if got := DiffHeaders(a, b); got != nil {
+		t.Fatalf("expected no changes, got %+v", got)
+	}
+}
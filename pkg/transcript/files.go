@@ -0,0 +1,215 @@
+package transcript
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInUse means another session is recording to the same transcript
+var ErrInUse = errors.New("transcript is in use by another session")
+
+// SessionPrefix starts the name of every transcript CreateInDir writes
+const SessionPrefix = "conversation-"
+
+// OpenOptions control how Open treats an existing transcript file
+type OpenOptions struct {
+	Append  bool  // Add the session after the existing contents instead of replacing them
+	MaxSize int64 // With Append, archive a file at least this large first (0 disables)
+}
+
+// Open starts recording a session to path. The file is locked for the session's
+// lifetime (a sibling .lock file, released by Close), so concurrent sessions fail
+// with ErrInUse instead of interleaving or clobbering each other's records.
+func Open(path string, header Header, opts OpenOptions) (*Writer, error) {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Append && opts.MaxSize > 0 {
+		if _, err := Rotate(path, opts.MaxSize); err != nil {
+			os.Remove(lock)
+			return nil, err
+		}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if opts.Append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		os.Remove(lock)
+		return nil, fmt.Errorf("failed to create transcript: %w", err)
+	}
+
+	w := &Writer{f: f, path: path, lock: lock}
+	if err := w.writeHeader(header); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// CreateInDir records a session to a new file in dir named after the session's
+// start time and the first eight characters of its ID, if it has one. Names never
+// collide: a numeric suffix is added when sessions start in the same second.
+func CreateInDir(dir string, header Header) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	base := filepath.Join(dir, SessionPrefix+header.Started.Format("20060102-150405"))
+	if id := header.ID; id != "" {
+		base += "-" + id[:min(8, len(id))]
+	}
+	for n := 1; n <= 1000; n++ {
+		path := base + ".jsonl"
+		if n > 1 {
+			path = fmt.Sprintf("%s-%d.jsonl", base, n)
+		}
+
+		// O_EXCL makes the name ours even if another session picked it at the same moment
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transcript: %w", err)
+		}
+
+		w := &Writer{f: f, path: path}
+		if err := w.writeHeader(header); err != nil {
+			w.Close()
+			return nil, err
+		}
+		return w, nil
+	}
+	return nil, fmt.Errorf("failed to create transcript: too many sessions named %s", base)
+}
+
+// Rotate archives path as a gzip-compressed, timestamped sibling
+// (<name>-<time>.jsonl.gz) once it has grown to maxSize bytes, returning the
+// archive's path, or "" when no rotation was needed.
+func Rotate(path string, maxSize int64) (string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.Size() < maxSize) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate transcript: %w", err)
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path)) + "-" + time.Now().Format("20060102-150405")
+	archive := base + ".jsonl.gz"
+	for n := 2; ; n++ {
+		err = compressFile(path, archive)
+		if !errors.Is(err, os.ErrExist) {
+			break
+		}
+		archive = fmt.Sprintf("%s-%d.jsonl.gz", base, n)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate transcript: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to rotate transcript: %w", err)
+	}
+	return archive, nil
+}
+
+// Prune deletes the oldest files matching a glob pattern so that at most keep
+// remain, returning the removed paths
+func Prune(pattern string, keep int) ([]string, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		path     string
+		modified time.Time
+	}
+	var files []file
+	for _, path := range paths {
+		if strings.HasSuffix(path, lockSuffix) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, file{path, info.ModTime()})
+	}
+	if len(files) <= keep {
+		return nil, nil
+	}
+
+	// Newest first, so everything past keep is removed; names break ties
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modified.Equal(files[j].modified) {
+			return files[i].modified.After(files[j].modified)
+		}
+		return files[i].path > files[j].path
+	})
+
+	var removed []string
+	for _, f := range files[keep:] {
+		if err := os.Remove(f.path); err != nil {
+			return removed, fmt.Errorf("failed to prune %s: %w", f.path, err)
+		}
+		removed = append(removed, f.path)
+	}
+	return removed, nil
+}
+
+const lockSuffix = ".lock"
+
+// acquireLock creates path's lock file, failing with ErrInUse if it already exists
+func acquireLock(path string) (string, error) {
+	lock := path + lockSuffix
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%w: %s (remove %s if no other session is running)", ErrInUse, path, lock)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock transcript: %w", err)
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	f.Close()
+	return lock, nil
+}
+
+// compressFile writes a gzip copy of src to dst
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		zw.Close()
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,156 @@
+package transcript
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSession(t *testing.T, path, starter string, opts OpenOptions) {
+	t.Helper()
+	h := testHeader()
+	h.Starter = starter
+	w, err := Open(path, h, opts)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	w.WriteTurn(Turn{Round: 1, Content: starter + " reply"})
+	w.WriteEnd(End{Rounds: 1, Reason: "max_rounds"})
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestOpenAppendsOrOverwritesSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	writeSession(t, path, "first", OpenOptions{Append: true})
+	writeSession(t, path, "second", OpenOptions{Append: true})
+
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), `"type":"header"`); n != 2 {
+		t.Fatalf("expected 2 appended sessions, found %d:\n%s", n, data)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Header.Starter != "second" || len(got.Turns) != 1 || got.Turns[0].Content != "second reply" {
+		t.Fatalf("expected the last session, got %+v", got)
+	}
+
+	writeSession(t, path, "third", OpenOptions{})
+	data, _ = os.ReadFile(path)
+	if n := strings.Count(string(data), `"type":"header"`); n != 1 {
+		t.Fatalf("expected overwrite to leave 1 session, found %d", n)
+	}
+}
+
+func TestOpenLocksTheTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	w, err := Open(path, testHeader(), OpenOptions{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	if _, err := Open(path, testHeader(), OpenOptions{Append: true}); !errors.Is(err, ErrInUse) {
+		t.Fatalf("expected ErrInUse for a second writer, got %v", err)
+	}
+	if _, err := Append(path); !errors.Is(err, ErrInUse) {
+		t.Fatalf("expected ErrInUse when resuming a file in use, got %v", err)
+	}
+
+	w.Close()
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lock file not released: %v", err)
+	}
+	w, err = Append(path)
+	if err != nil {
+		t.Fatalf("append after close: %v", err)
+	}
+	w.Close()
+}
+
+func TestOpenRotatesLargeTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.jsonl")
+	writeSession(t, path, "old", OpenOptions{Append: true})
+
+	writeSession(t, path, "new", OpenOptions{Append: true, MaxSize: 1})
+
+	archives, _ := filepath.Glob(filepath.Join(dir, "run-*.jsonl.gz"))
+	if len(archives) != 1 {
+		t.Fatalf("expected one archive, found %v", archives)
+	}
+	old, err := Load(archives[0])
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if old.Header.Starter != "old" {
+		t.Fatalf("archive holds the wrong session: %+v", old.Header)
+	}
+
+	current, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if current.Header.Starter != "new" || strings.Count(string(data), `"type":"header"`) != 1 {
+		t.Fatalf("rotated transcript should only hold the new session:\n%s", data)
+	}
+}
+
+func TestCreateInDirNamesSessionsUniquely(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		w, err := CreateInDir(dir, testHeader())
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		w.Close()
+		if seen[w.Path()] {
+			t.Fatalf("duplicate transcript name %s", w.Path())
+		}
+		seen[w.Path()] = true
+	}
+	if !seen[filepath.Join(dir, "conversation-20250102-030405.jsonl")] || !seen[filepath.Join(dir, "conversation-20250102-030405-3.jsonl")] {
+		t.Fatalf("unexpected names: %v", seen)
+	}
+
+	header := testHeader()
+	header.ID = "1b4e28ba-2fa1-41d2-883f-0016d3cca427"
+	w, err := CreateInDir(dir, header)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	w.Close()
+	if w.Path() != filepath.Join(dir, "conversation-20250102-030405-1b4e28ba.jsonl") {
+		t.Fatalf("expected the short ID in the name, got %s", w.Path())
+	}
+}
+
+func TestPruneKeepsNewestFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"a.jsonl", "b.jsonl", "c.jsonl", "other.txt"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, nil, 0o644)
+		modified := now.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, modified, modified)
+	}
+
+	removed, err := Prune(filepath.Join(dir, "*.jsonl"), 2)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != "a.jsonl" {
+		t.Fatalf("expected only the oldest file removed, got %v", removed)
+	}
+	left, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(left) != 3 {
+		t.Fatalf("unexpected files left: %v", left)
+	}
+}
@@ -0,0 +1,144 @@
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Entry summarizes one transcript file for listing
+type Entry struct {
+	Path   string
+	Header Header
+	Rounds int  // Last recorded round
+	End    *End // How the session finished, if it did
+}
+
+// Index reads every transcript in dir, including rotated .jsonl.gz archives, newest
+// session first. Only the header, end record and each turn's round are decoded, so
+// listing doesn't parse every reply. Files that can't be read as transcripts are
+// skipped.
+func Index(dir string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcripts: %w", err)
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !(strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".jsonl.gz")) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		entry, err := readEntry(path)
+		if err != nil {
+			slog.Debug("skipping unreadable transcript", "path", path, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	// Newest first; names break ties
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if c := b.Header.Started.Compare(a.Header.Started); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return entries, nil
+}
+
+// readEntry scans a transcript for what Index lists: the last session's header and
+// end record, and the round of its last complete turn (as Transcript.Rounds)
+func readEntry(path string) (Entry, error) {
+	scanner, closeFile, err := openRecords(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer closeFile()
+
+	entry := Entry{Path: path}
+	haveHeader := false
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record struct {
+			Type       string `json:"type"`
+			Round      int    `json:"round"`
+			Incomplete bool   `json:"incomplete"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return Entry{}, fmt.Errorf("%s:%d: invalid record: %w", path, line, err)
+		}
+		switch record.Type {
+		case TypeHeader:
+			// A later header starts another session appended to the same file
+			entry = Entry{Path: path}
+			if err := json.Unmarshal(scanner.Bytes(), &entry.Header); err != nil {
+				return Entry{}, fmt.Errorf("%s:%d: invalid header: %w", path, line, err)
+			}
+			haveHeader = true
+		case TypeTurn:
+			if !record.Incomplete {
+				entry.Rounds = record.Round
+			}
+		case TypeEnd:
+			var end End
+			if err := json.Unmarshal(scanner.Bytes(), &end); err != nil {
+				return Entry{}, fmt.Errorf("%s:%d: invalid end record: %w", path, line, err)
+			}
+			entry.End = &end
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Entry{}, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if !haveHeader {
+		return Entry{}, errors.New("transcript has no header record")
+	}
+	if entry.Header.Version > Version {
+		return Entry{}, fmt.Errorf("transcript version %d is newer than supported version %d", entry.Header.Version, Version)
+	}
+	return entry, nil
+}
+
+// HasTags reports whether the session was tagged with every one of tags, ignoring case
+func (e Entry) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(e.Header.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// UsesProvider reports whether either agent's provider was name, or chosen by an
+// alias called name
+func (e Entry) UsesProvider(name string) bool {
+	for _, a := range e.Header.Agents {
+		if strings.EqualFold(a.Provider, name) || strings.EqualFold(a.Alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags trims tags and drops empty and repeated ones (ignoring case),
+// keeping the first spelling of each
+func NormalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.ContainsFunc(result, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexListsTranscriptsNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	older := testHeader()
+	older.Tags = []string{"research"}
+	newer := testHeader()
+	newer.Started = older.Started.Add(time.Hour)
+	newer.Tags = []string{"Research", "draft"}
+	newer.Agents[1].Provider, newer.Agents[1].Alias = "openai", "local"
+
+	for name, h := range map[string]Header{"older.jsonl": older, "newer.jsonl": newer} {
+		w, err := Create(filepath.Join(dir, name), h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.WriteTurn(Turn{Round: 1, Content: "hi"})
+		w.WriteTurn(Turn{Round: 2, Content: "hello"})
+		w.Close()
+	}
+	// Anything else in the directory is skipped
+	os.WriteFile(filepath.Join(dir, "notes.jsonl"), []byte("not a transcript\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("hi"), 0o644)
+
+	entries, err := Index(dir)
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if len(entries) != 2 || filepath.Base(entries[0].Path) != "newer.jsonl" || entries[0].Rounds != 2 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	if !entries[0].HasTags("research", "DRAFT") || entries[1].HasTags("research", "draft") || !entries[1].HasTags() {
+		t.Fatal("expected tags to match case-insensitively and all be required")
+	}
+	if !entries[0].UsesProvider("local") || entries[1].UsesProvider("local") || !entries[1].UsesProvider("exec") {
+		t.Fatal("expected providers to match by name or alias on either side")
+	}
+}
+
+func TestIndexReadsRoundsAndEnd(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Create(filepath.Join(dir, "run.jsonl"), testHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteTurn(Turn{Round: 1, Content: "hi"})
+	w.WriteTurn(Turn{Round: 2, Content: "hello"})
+	w.WritePartial(Turn{Round: 3, Content: "cut o"})
+	w.WriteEnd(End{Rounds: 2, Reason: "error"})
+	w.Close()
+
+	entries, err := Index(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The unfinished turn doesn't count, as with Transcript.Rounds
+	if len(entries) != 1 || entries[0].Rounds != 2 || entries[0].End == nil || entries[0].End.Reason != "error" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" research", "", "Draft", "research ", "draft"})
+	if len(got) != 2 || got[0] != "research" || got[1] != "Draft" {
+		t.Fatalf("unexpected tags: %q", got)
+	}
+}
@@ -0,0 +1,535 @@
+// Package transcript reads and writes conversation transcripts as JSON Lines.
+//
+// A transcript starts with a header record describing the session, followed by
+// one record per completed turn and optionally an end record:
+//
+//	{"type":"header","version":1,"started":"...","starter":"...","max_rounds":10,"agents":[...]}
+//	{"type":"turn","round":1,"speaker":0,"agent":"Agent A","provider":"openai","model":"gpt-4o-mini","content":"..."}
+//	{"type":"end","rounds":10,"reason":"max_rounds"}
+//
+// Turns are appended as they complete, so a transcript of an interrupted run is
+// still valid and can be resumed. Checkpoints use the same format.
+package transcript
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+)
+
+// Version is the transcript format version written in headers
+const Version = 1
+
+// Record types
+const (
+	TypeHeader = "header"
+	TypeTurn   = "turn"
+	TypeEnd    = "end"
+)
+
+// AgentInfo records how an agent was configured
+type AgentInfo struct {
+	Name         string   `json:"name"`
+	Provider     string   `json:"provider"`
+	Alias        string   `json:"alias,omitempty"` // Config alias the provider was chosen by
+	Model        string   `json:"model"`
+	Temperature  *float64 `json:"temperature,omitempty"` // Absent when left to the provider
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Color        string   `json:"color,omitempty"`     // Terminal color (ANSI index), reused by exports
+	Tools        []string `json:"tools,omitempty"`     // Tools the agent could call
+	JSONMode     bool     `json:"json_mode,omitempty"` // Replies were required to be JSON objects
+
+	// JSON schema replies were required to match, and the name it was sent under
+	Schema     json.RawMessage `json:"schema,omitempty"`
+	SchemaName string          `json:"schema_name,omitempty"`
+
+	ModelSchedule string `json:"model_schedule,omitempty"` // Models by round (bridge.ParseModelSchedule), overriding Model
+}
+
+// ProviderName returns the name the agent's provider was selected by: its alias, if any
+func (a AgentInfo) ProviderName() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	return a.Provider
+}
+
+// Header describes the session a transcript belongs to
+type Header struct {
+	Version      int          `json:"version"`
+	ID           string       `json:"id,omitempty"` // Conversation ID, as in the engine's log lines and trace files
+	Started      time.Time    `json:"started"`
+	Starter      string       `json:"starter"`
+	MaxRounds    int          `json:"max_rounds"`
+	Agents       [2]AgentInfo `json:"agents"`
+	BranchedFrom string       `json:"branched_from,omitempty"`
+	Images       []string     `json:"images,omitempty"`  // Image references attached to the starter
+	Context      string       `json:"context,omitempty"` // Background sent to both agents as a system message
+	Tags         []string     `json:"tags,omitempty"`    // Labels from --tag, for finding the session later
+
+	ReinforceEvery int    `json:"reinforce_system_every,omitempty"` // Rounds between system prompt re-injections
+	Mode           string `json:"mode,omitempty"`                   // Turn-taking mode; empty means alternating
+	CountRoundsBy  string `json:"count_rounds_by,omitempty"`        // Unit the run's rounds were shown in; max_rounds is always in turns
+	TurnPrefix     string `json:"turn_prefix,omitempty"`            // Text prepended to each incoming message
+	TurnSuffix     string `json:"turn_suffix,omitempty"`            // Text appended to each incoming message
+	RoundNote      string `json:"round_note,omitempty"`             // Template of the --inject-round note
+
+	CompactHistory  bool `json:"compact_history,omitempty"`   // Earlier turns were sent compacted
+	CompactMaxChars int  `json:"compact_max_chars,omitempty"` // Length compacted turns were cut at
+
+	MinResponseChars int    `json:"min_response_chars,omitempty"` // Shorter replies were re-prompted once
+	ElaboratePrompt  string `json:"elaborate_prompt,omitempty"`   // Re-prompt text; empty means the default
+	MaxResponseChars int    `json:"max_response_chars,omitempty"` // Longer replies were cut off here
+}
+
+// Turn is one completed response
+type Turn struct {
+	Round      int       `json:"round"`
+	Speaker    int       `json:"speaker"`
+	Agent      string    `json:"agent"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Content    string    `json:"content"`
+	Started    time.Time `json:"started"`
+	DurationMS int64     `json:"duration_ms"`
+	Reinforced bool      `json:"reinforced,omitempty"` // System prompt was re-injected before this turn
+	ToolCalls  []ToolUse `json:"tool_calls,omitempty"` // Tools called while producing this turn
+	Prompt     string    `json:"prompt,omitempty"`     // Incoming message as edited by a reviewer
+	Direction  string    `json:"direction,omitempty"`  // Director instruction sent with the request
+	Cancelled  bool      `json:"cancelled,omitempty"`  // Stopped mid-stream (e.g. --interrupt-key); content is the partial reply
+	Incomplete bool      `json:"incomplete,omitempty"` // Still streaming when last written (see Writer.WritePartial)
+
+	Regenerated bool   `json:"regenerated,omitempty"` // Replaces the previous record of the same turn
+	RawContent  string `json:"raw_content,omitempty"` // Reply as received, before its preamble was stripped
+	Refusal     string `json:"refusal,omitempty"`     // Why the agent declined to respond; the turn was skipped
+
+	ServedModel  string `json:"served_model,omitempty"`  // Model the provider reported serving the request
+	FinishReason string `json:"finish_reason,omitempty"` // Why the reply ended, as the provider reported it
+}
+
+// ToolUse records a tool call made during a turn
+type ToolUse struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// End records how the session finished
+type End struct {
+	Rounds    int    `json:"rounds"`
+	Reason    string `json:"reason"`
+	Error     string `json:"error,omitempty"`
+	ElapsedMS int64  `json:"elapsed_ms,omitempty"` // Wall-clock time of the run
+}
+
+// Transcript is a fully loaded transcript file
+type Transcript struct {
+	Header Header
+	Turns  []Turn
+	End    *End // Last end record, if the run finished
+}
+
+// FromBridgeTurn converts a completed engine turn into a transcript turn
+func FromBridgeTurn(t *bridge.Turn) Turn {
+	return Turn{
+		Round:      t.Round,
+		Speaker:    t.Speaker,
+		Agent:      t.Agent,
+		Provider:   t.Provider,
+		Model:      t.Model,
+		Content:    t.Content,
+		Started:    t.Started,
+		DurationMS: t.Duration.Milliseconds(),
+		Reinforced: t.Reinforced,
+		ToolCalls:  fromBridgeToolUses(t.ToolCalls),
+		Prompt:     t.Prompt,
+		Direction:  t.Direction,
+		Cancelled:  t.Cancelled,
+
+		Regenerated: t.Regenerated,
+		RawContent:  t.RawContent,
+		Refusal:     t.Refusal,
+
+		ServedModel:  t.ServedModel,
+		FinishReason: t.FinishReason,
+	}
+}
+
+func fromBridgeToolUses(uses []bridge.ToolUse) []ToolUse {
+	if len(uses) == 0 {
+		return nil
+	}
+	result := make([]ToolUse, len(uses))
+	for i, u := range uses {
+		result[i] = ToolUse{Name: u.Name, Arguments: u.Arguments, Result: u.Result, Error: u.Error}
+	}
+	return result
+}
+
+// AppendTurn adds turn to turns, replacing the last one instead when turn
+// regenerates it. Incomplete turns that turn supersedes are dropped: the same
+// turn's partial record, and any from an earlier round, which never completed
+// (e.g. a failed turn skipped under --continue-on-error). In simultaneous mode the
+// other agent's partial record of the same round is still pending, so it stays.
+func AppendTurn(turns []Turn, turn Turn) []Turn {
+	if n := len(turns); n > 0 && turn.Regenerated && turns[n-1].Round == turn.Round && turns[n-1].Speaker == turn.Speaker {
+		turns[n-1] = turn
+		return turns
+	}
+	kept := turns[:0]
+	for _, t := range turns {
+		superseded := t.Incomplete && (t.Round < turn.Round || t.Round == turn.Round && t.Speaker == turn.Speaker)
+		if !superseded {
+			kept = append(kept, t)
+		}
+	}
+	return append(kept, turn)
+}
+
+// Rounds returns the number of the last completed round (in simultaneous mode a
+// round holds two turns)
+func (t *Transcript) Rounds() int {
+	for i := len(t.Turns) - 1; i >= 0; i-- {
+		if !t.Turns[i].Incomplete {
+			return t.Turns[i].Round
+		}
+	}
+	return 0
+}
+
+// BridgeTurns converts transcript turns back into engine turns, e.g. for
+// bridge.Options.Prior. Incomplete turns are left out, so a resumed run asks for
+// them again.
+func (t *Transcript) BridgeTurns() []bridge.Turn {
+	var turns []bridge.Turn
+	for _, turn := range t.Turns {
+		if turn.Incomplete {
+			continue
+		}
+		turns = append(turns, bridge.Turn{
+			Round:    turn.Round,
+			Speaker:  turn.Speaker,
+			Agent:    turn.Agent,
+			Provider: turn.Provider,
+			Model:    turn.Model,
+			Content:  turn.Content,
+			Started:  turn.Started,
+			Duration: time.Duration(turn.DurationMS) * time.Millisecond,
+
+			Reinforced: turn.Reinforced,
+			ToolCalls:  toBridgeToolUses(turn.ToolCalls),
+			Prompt:     turn.Prompt,
+			Direction:  turn.Direction,
+			Cancelled:  turn.Cancelled,
+
+			Regenerated: turn.Regenerated,
+			RawContent:  turn.RawContent,
+			Refusal:     turn.Refusal,
+
+			ServedModel:  turn.ServedModel,
+			FinishReason: turn.FinishReason,
+		})
+	}
+	return turns
+}
+
+func toBridgeToolUses(uses []ToolUse) []bridge.ToolUse {
+	if len(uses) == 0 {
+		return nil
+	}
+	result := make([]bridge.ToolUse, len(uses))
+	for i, u := range uses {
+		result[i] = bridge.ToolUse{Name: u.Name, Arguments: u.Arguments, Result: u.Result, Error: u.Error}
+	}
+	return result
+}
+
+// Writer appends records to a transcript file
+type Writer struct {
+	f    *os.File
+	path string
+	lock string // Lock file removed on Close, if the writer holds one
+
+	// Records of turns still streaming end the file from offset partialAt, so
+	// each update (and the completed turn) can replace them in place
+	partials  []Turn
+	partialAt int64
+}
+
+// Create writes a new transcript at path (replacing any existing file) starting with header
+func Create(path string, header Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript: %w", err)
+	}
+
+	w := &Writer{f: f, path: path}
+	if err := w.writeHeader(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Append opens an existing transcript so a resumed run can add turns to it.
+// Like Open, it holds the transcript's lock until Close.
+func Append(path string) (*Writer, error) {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		os.Remove(lock)
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	return &Writer{f: f, path: path, lock: lock}, nil
+}
+
+// Path returns the file the writer records to
+func (w *Writer) Path() string {
+	return w.path
+}
+
+func (w *Writer) writeHeader(header Header) error {
+	if header.Version == 0 {
+		header.Version = Version
+	}
+	return w.write(TypeHeader, &header)
+}
+
+// WriteTurn appends a completed turn, replacing its WritePartial record, and
+// syncs the file
+func (w *Writer) WriteTurn(turn Turn) error {
+	turn.Incomplete = false
+	return w.writeTurn(turn)
+}
+
+// WritePartial records a turn that is still streaming, marked Incomplete, so the
+// reply so far survives a crash or a failed request. Each call replaces the turn's
+// previous partial record, as WriteTurn does once it completes. Both agents' turns
+// may be partial at once, as in simultaneous mode.
+func (w *Writer) WritePartial(turn Turn) error {
+	turn.Incomplete = true
+	return w.writeTurn(turn)
+}
+
+// writeTurn writes a turn record in place of any partial record of the same turn,
+// rewriting the other partial records after a completed one so they stay last.
+// A partial record of an earlier turn, one that never completed (e.g. a failed
+// turn skipped), is left where it is; Load drops it.
+func (w *Writer) writeTurn(turn Turn) error {
+	if len(w.partials) == 0 && !turn.Incomplete {
+		if err := w.write(TypeTurn, &turn); err != nil {
+			return err
+		}
+		return w.sync()
+	}
+
+	var settled, pending []Turn
+	for _, p := range w.partials {
+		switch {
+		case p.Round == turn.Round && p.Speaker == turn.Speaker:
+		case p.Speaker == turn.Speaker || p.Round < turn.Round:
+			settled = append(settled, p)
+		default:
+			pending = append(pending, p)
+		}
+	}
+	if turn.Incomplete {
+		pending = append(pending, turn)
+	} else {
+		settled = append(settled, turn)
+	}
+
+	if len(w.partials) > 0 {
+		if err := w.f.Truncate(w.partialAt); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+	if _, err := w.f.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	for _, t := range settled {
+		if err := w.write(TypeTurn, &t); err != nil {
+			return err
+		}
+	}
+	end, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	w.partialAt, w.partials = end, nil
+	for _, p := range pending {
+		if err := w.write(TypeTurn, &p); err != nil {
+			return err
+		}
+		w.partials = append(w.partials, p)
+	}
+	return w.sync()
+}
+
+// WriteEnd appends the end-of-session record and syncs the file. Partial records
+// still pending are kept: their turns never completed.
+func (w *Writer) WriteEnd(end End) error {
+	w.partials = nil
+	if err := w.write(TypeEnd, &end); err != nil {
+		return err
+	}
+	return w.sync()
+}
+
+// sync flushes written records to disk, so they outlast a crash
+func (w *Writer) sync() error {
+	if err := w.f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync transcript: %w", err)
+	}
+	return nil
+}
+
+// Close syncs and closes the underlying file and releases its lock. A failed sync
+// is reported when the close itself succeeds.
+func (w *Writer) Close() error {
+	synced := w.sync()
+	err := w.f.Close()
+	if w.lock != "" {
+		os.Remove(w.lock)
+		w.lock = ""
+	}
+	if err == nil {
+		err = synced
+	}
+	return err
+}
+
+func (w *Writer) write(recordType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// Inline the payload's fields after the type tag; payloads are never empty objects
+	line := append([]byte(`{"type":"`+recordType+`",`), data[1:]...)
+
+	if _, err := w.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// Save writes a complete transcript to path in one go (used for checkpoints)
+func Save(path string, t *Transcript) error {
+	w, err := Create(path, t.Header)
+	if err != nil {
+		return err
+	}
+	// Synced once, on Close
+	for _, turn := range t.Turns {
+		if err := w.write(TypeTurn, &turn); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	if t.End != nil {
+		if err := w.write(TypeEnd, t.End); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// Load reads a transcript file; .gz files (rotated logs) are decompressed. When
+// several sessions were appended to one file, the last session is returned.
+// Partial records a later record superseded are left out (see AppendTurn), so
+// only turns still unfinished at the end of the file are Incomplete.
+func Load(path string) (*Transcript, error) {
+	scanner, closeFile, err := openRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	var t Transcript
+	haveHeader := false
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var tag struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &tag); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid record: %w", path, line, err)
+		}
+
+		switch tag.Type {
+		case TypeHeader:
+			// A later header starts another session appended to the same file
+			t = Transcript{}
+			if err := json.Unmarshal(scanner.Bytes(), &t.Header); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid header: %w", path, line, err)
+			}
+			haveHeader = true
+		case TypeTurn:
+			var turn Turn
+			if err := json.Unmarshal(scanner.Bytes(), &turn); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid turn: %w", path, line, err)
+			}
+			t.Turns = AppendTurn(t.Turns, turn)
+		case TypeEnd:
+			var end End
+			if err := json.Unmarshal(scanner.Bytes(), &end); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid end record: %w", path, line, err)
+			}
+			t.End = &end
+		default:
+			// Unknown record types are skipped so newer transcripts stay readable
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	if !haveHeader {
+		return nil, errors.New("transcript has no header record")
+	}
+	if t.Header.Version > Version {
+		return nil, fmt.Errorf("transcript version %d is newer than supported version %d", t.Header.Version, Version)
+	}
+	return &t, nil
+}
+
+// openRecords opens a transcript file for reading record by record, decompressing
+// .gz archives; the returned func closes it
+func openRecords(path string) (*bufio.Scanner, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	var r io.Reader = f
+	closeFile := func() { f.Close() }
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("failed to open transcript: %w", err)
+		}
+		r = zr
+		closeFile = func() { zr.Close(); f.Close() }
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner, closeFile, nil
+}
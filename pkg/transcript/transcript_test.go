@@ -0,0 +1,220 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markjamesm/chat-bridge-go/pkg/bridge"
+	"github.com/markjamesm/chat-bridge-go/pkg/providers"
+)
+
+func testHeader() Header {
+	return Header{
+		Started:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Starter:   "Hello",
+		MaxRounds: 4,
+		Agents: [2]AgentInfo{
+			{Name: "Ada", Provider: "openai", Model: "gpt-4o-mini", Temperature: providers.Float(0.2)},
+			{Name: "Bob", Provider: "exec", Model: "external", Temperature: providers.Float(0.9), SystemPrompt: "be brief"},
+		},
+	}
+}
+
+func TestWriteThenLoadRoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+
+	w, err := Create(path, testHeader())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	bt := &bridge.Turn{Round: 1, Speaker: 0, Agent: "Ada", Provider: "openai", Model: "gpt-4o-mini",
+		Content: "line one\nline two", Started: time.Now().UTC().Truncate(time.Millisecond), Duration: 1500 * time.Millisecond}
+	if err := w.WriteTurn(FromBridgeTurn(bt)); err != nil {
+		t.Fatalf("write turn: %v", err)
+	}
+	w.Close()
+
+	// A resumed run appends to the same file
+	w, err = Append(path)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	w.WriteTurn(Turn{Round: 2, Speaker: 1, Agent: "Bob", Content: "reply", Reinforced: true, Prompt: "edited", Direction: "twist"})
+	w.WriteEnd(End{Rounds: 2, Reason: "max_rounds"})
+	w.Close()
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Header.Version != Version || got.Header.Agents[1].SystemPrompt != "be brief" || got.Header.Starter != "Hello" {
+		t.Fatalf("unexpected header: %+v", got.Header)
+	}
+	if len(got.Turns) != 2 || got.End == nil || got.End.Reason != "max_rounds" {
+		t.Fatalf("unexpected body: %+v %+v", got.Turns, got.End)
+	}
+
+	back := got.BridgeTurns()[0]
+	if back.Content != bt.Content || back.Duration != bt.Duration || !back.Started.Equal(bt.Started) || back.Provider != "openai" {
+		t.Fatalf("turn did not round-trip: %+v", back)
+	}
+	if second := got.BridgeTurns()[1]; !second.Reinforced || second.Prompt != "edited" || second.Direction != "twist" {
+		t.Fatalf("reinforcement flag, edited prompt, or direction did not round-trip: %+v", second)
+	}
+}
+
+func TestPartialTurnsSurviveTermination(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	w, err := Create(path, testHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Agent: "Ada", Content: "done"})
+	w.WritePartial(Turn{Round: 2, Speaker: 1, Agent: "Bob", Content: "Half"})
+	w.WritePartial(Turn{Round: 2, Speaker: 1, Agent: "Bob", Content: "Half a rep"})
+
+	// Killed mid-turn: the file is left as it is, without Close or an end record
+	w.f.Close()
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Turns) != 2 || got.End != nil || !got.Turns[1].Incomplete || got.Turns[1].Content != "Half a rep" {
+		t.Fatalf("expected the latest partial record only, got %+v", got.Turns)
+	}
+	if back := got.BridgeTurns(); len(back) != 1 || got.Rounds() != 1 {
+		t.Fatalf("expected a resumed run to ask for the unfinished turn again, got %+v", back)
+	}
+
+	// The resumed run completes it
+	w, err = Append(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteTurn(Turn{Round: 2, Speaker: 1, Agent: "Bob", Content: "A whole reply"})
+	w.Close()
+	if got, _ = Load(path); len(got.Turns) != 2 || got.Turns[1].Incomplete || got.Turns[1].Content != "A whole reply" {
+		t.Fatalf("expected the completed turn to replace the partial one, got %+v", got.Turns)
+	}
+}
+
+func TestCompletedTurnsReplacePartialRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	w, _ := Create(path, testHeader())
+	w.Close()
+
+	// Both agents stream at once, as in simultaneous mode, on an appended file
+	w, err := Append(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WritePartial(Turn{Round: 1, Speaker: 0, Content: "a"})
+	w.WritePartial(Turn{Round: 1, Speaker: 1, Content: "b"})
+	w.WritePartial(Turn{Round: 1, Speaker: 0, Content: "a longer"})
+	w.WriteTurn(Turn{Round: 1, Speaker: 1, Content: "b done"})
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Content: "a done"})
+	w.WriteEnd(End{Rounds: 1, Reason: "max_rounds"})
+	w.Close()
+
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != 4 || strings.Contains(string(data), "incomplete") {
+		t.Fatalf("expected header, two turns and end only, got:\n%s", data)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Turns) != 2 || got.Turns[0].Content != "b done" || got.Turns[1].Content != "a done" {
+		t.Fatalf("unexpected turns: %+v", got.Turns)
+	}
+}
+
+func TestLoadDropsSupersededPartialRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	w, _ := Create(path, testHeader())
+	// A's round 3 failed partway and was skipped; B answered in round 4
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Content: "a1"})
+	w.WriteTurn(Turn{Round: 2, Speaker: 1, Content: "b1"})
+	w.WritePartial(Turn{Round: 3, Speaker: 0, Content: "a2 cut o"})
+	w.WriteTurn(Turn{Round: 4, Speaker: 1, Content: "b2"})
+	w.Close()
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Turns) != 3 || got.Turns[2].Content != "b2" {
+		t.Fatalf("expected the skipped turn's partial record dropped, got %+v", got.Turns)
+	}
+
+	// Simultaneous mode: both partial records were pending when the run was killed,
+	// and the resumed run appended to the same file completes them
+	path = filepath.Join(t.TempDir(), "simultaneous.jsonl")
+	w, _ = Create(path, testHeader())
+	w.WritePartial(Turn{Round: 1, Speaker: 0, Content: "a"})
+	w.WritePartial(Turn{Round: 1, Speaker: 1, Content: "b"})
+	w.f.Close()
+	w, err = Append(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteTurn(Turn{Round: 1, Speaker: 1, Content: "b done"})
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Content: "a done"})
+	w.Close()
+
+	if got, _ = Load(path); len(got.Turns) != 2 || got.Turns[0].Content != "b done" || got.Turns[1].Content != "a done" {
+		t.Fatalf("expected each partial record replaced once, got %+v", got.Turns)
+	}
+}
+
+func TestLoadKeepsOnlyRegeneratedTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	w, err := Create(path, testHeader())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Content: "first"})
+	w.WriteTurn(Turn{Round: 1, Speaker: 0, Content: "second try", Regenerated: true})
+	w.WriteTurn(Turn{Round: 2, Speaker: 1, Content: "reply"})
+	w.Close()
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got.Turns) != 2 || got.Turns[0].Content != "second try" || !got.BridgeTurns()[0].Regenerated {
+		t.Fatalf("expected the regenerated turn to replace the original, got %+v", got.Turns)
+	}
+}
+
+func TestRecordsAreTaggedJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	if err := Save(path, &Transcript{Header: testHeader(), Turns: []Turn{{Round: 1, Content: "hi"}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"type":"header","version":1,`) || !strings.HasPrefix(lines[1], `{"type":"turn",`) {
+		t.Fatalf("unexpected file contents:\n%s", data)
+	}
+}
+
+func TestLoadRejectsInvalidTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"no-header.jsonl": `{"type":"turn","round":1}` + "\n",
+		"garbage.jsonl":   `{"type":"header","version":1}` + "\nnot json\n",
+		"future.jsonl":    `{"type":"header","version":99}` + "\n",
+	}
+	for name, contents := range cases {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(contents), 0o644)
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -1,27 +1,83 @@
 package ui
 
 import (
+	_ "embed"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Retro color scheme - matching the Python version's beautiful aesthetic
+// Colors of the current theme (see SetTheme), by palette name
 var (
-	// ANSI color codes
-	Cyan    = lipgloss.Color("14")  // Bright cyan
-	Green   = lipgloss.Color("10")  // Bright green
-	Yellow  = lipgloss.Color("11")  // Bright yellow
-	Red     = lipgloss.Color("9")   // Bright red
-	Magenta = lipgloss.Color("13")  // Bright magenta
-	Blue    = lipgloss.Color("12")  // Bright blue
-	White   = lipgloss.Color("15")  // Bright white
-	Dim     = lipgloss.Color("240") // Dim gray
+	Cyan    = Retro.Cyan
+	Green   = Retro.Green
+	Yellow  = Retro.Yellow
+	Red     = Retro.Red
+	Magenta = Retro.Magenta
+	Blue    = Retro.Blue
+	White   = Retro.White
+	Dim     = Retro.Dim
 )
 
-// Retro styles for different UI elements
+// Palette maps the user-facing color names accepted by the CLI to the current theme's colors
+var Palette map[string]lipgloss.Color
+
+func init() {
+	SetTheme(Retro)
+}
+
+// ColorNames returns the palette color names in sorted order
+func ColorNames() []string {
+	names := make([]string, 0, len(Palette))
+	for name := range Palette {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColor resolves a palette color name or an ANSI color index (0-255)
+func ParseColor(value string) (lipgloss.Color, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if color, ok := Palette[name]; ok {
+		return color, nil
+	}
+
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index > 255 {
+			return "", fmt.Errorf("ANSI color index %d out of range (0-255)", index)
+		}
+		return lipgloss.Color(name), nil
+	}
+
+	return "", fmt.Errorf("unknown color %q; use one of %s or an ANSI index (0-255)",
+		value, strings.Join(ColorNames(), ", "))
+}
+
+// Styles for different UI elements, built from the current theme by SetTheme
 var (
+	Banner          lipgloss.Style // Welcome banner
+	SectionHeader   lipgloss.Style // Section titles
+	MenuOption      lipgloss.Style // Menu items
+	MenuDescription lipgloss.Style // Menu item descriptions
+	AgentA          lipgloss.Style // Agent A's default label
+	AgentB          lipgloss.Style // Agent B's default label
+	Success         lipgloss.Style // Success messages
+	Error           lipgloss.Style // Error messages
+	Warning         lipgloss.Style // Warning messages
+	Info            lipgloss.Style // Info messages
+	ProviderBadge   lipgloss.Style // Provider names
+	ModelBadge      lipgloss.Style // Model names
+	SummaryBox      lipgloss.Style // Border around end-of-run reports
+)
+
+// buildStyles rebuilds the styles from the current colors
+func buildStyles() {
 	// Banner style - bold cyan for the welcome banner
 	Banner = lipgloss.NewStyle().
 		Foreground(Cyan).
@@ -81,31 +137,87 @@ var (
 	// Model badge style
 	ModelBadge = lipgloss.NewStyle().
 		Foreground(Yellow)
+
+	// Summary box style - rounded cyan border around end-of-run reports
+	SummaryBox = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Cyan).
+		Padding(0, 2)
+}
+
+// bannerText is the welcome banner; it lives in its own file so the box-drawing
+// characters stay intact, and every line has the same display width
+//
+//go:embed banner.txt
+var bannerText string
+
+// customBanner replaces bannerText when set, and bannerHidden turns the banner off
+var (
+	customBanner string
+	bannerHidden bool
 )
 
-// PrintBanner displays the beautiful retro welcome banner
+// SetBanner replaces the welcome banner with text, e.g. read from a file; "" restores
+// the default banner
+func SetBanner(text string) {
+	customBanner = strings.TrimRight(text, "\n")
+}
+
+// DisableBanner stops PrintBanner from printing anything
+func DisableBanner() {
+	bannerHidden = true
+}
+
+// PrintBanner displays the beautiful retro welcome banner. It is skipped when
+// stdout isn't a terminal, so pipelines and scripts only see the conversation.
 func PrintBanner() {
-	banner := `
-╔══════════════════════════════════════════════════════════════════╗
-║                          🌉 CHAT BRIDGE 🌉                        ║
-║                     Connect Two AI Assistants                     ║
-║                                                                    ║
-║                    🎭 Personas  ⚙️ Configurable                   ║
-╚══════════════════════════════════════════════════════════════════╝
-`
-	fmt.Println(Banner.Render(banner))
+	if bannerHidden || !IsTerminal(os.Stdout) {
+		return
+	}
+	writeBanner(os.Stdout)
+}
+
+// writeBanner writes the banner PrintBanner prints
+func writeBanner(w io.Writer) {
+	text := bannerText
+	if customBanner != "" {
+		text = customBanner + "\n"
+	}
+	fmt.Fprintln(w, Banner.Render("\n"+text))
+}
+
+// sectionRuleWidth is the width of the rules around section headers on terminals
+// at least that wide
+const sectionRuleWidth = 60
+
+// stdoutWidth reports the terminal width of stdout; replaced in tests
+var stdoutWidth = func() (int, bool) { return TerminalWidth(os.Stdout) }
+
+// sectionRule returns the rule drawn around section headers: sectionRuleWidth
+// wide, narrowed to fit a smaller terminal so it doesn't wrap
+func sectionRule() string {
+	width := sectionRuleWidth
+	if w, ok := stdoutWidth(); ok && w > 0 && w < width {
+		width = w
+	}
+	return strings.Repeat("─", width)
 }
 
 // PrintSectionHeader prints a styled section header with an icon
 func PrintSectionHeader(title, icon string) {
-	line := strings.Repeat("─", 60)
-	fmt.Println()
-	fmt.Println(lipgloss.NewStyle().Foreground(Dim).Render(line))
-	fmt.Println(
-		lipgloss.NewStyle().Foreground(Yellow).Render(icon) + " " +
+	writeSectionHeader(os.Stdout, title, icon)
+}
+
+// writeSectionHeader writes the section header PrintSectionHeader prints
+func writeSectionHeader(w io.Writer, title, icon string) {
+	line := sectionRule()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, lipgloss.NewStyle().Foreground(Dim).Render(line))
+	fmt.Fprintln(w,
+		lipgloss.NewStyle().Foreground(Yellow).Render(icon)+" "+
 			SectionHeader.Render(strings.ToUpper(title)),
 	)
-	fmt.Println(lipgloss.NewStyle().Foreground(Dim).Render(line))
+	fmt.Fprintln(w, lipgloss.NewStyle().Foreground(Dim).Render(line))
 }
 
 // PrintMenuOption prints a styled menu option with number, title, and description
@@ -175,6 +287,12 @@ func PrintInfo(message string) {
 	)
 }
 
+// PrintBox prints lines in a bordered box under a bold title
+func PrintBox(title string, lines []string) {
+	body := Colorize(title, Yellow, true) + "\n\n" + strings.Join(lines, "\n")
+	fmt.Println(SummaryBox.Render(body))
+}
+
 // Colorize applies a color to text (convenience function)
 func Colorize(text string, color lipgloss.Color, bold bool) string {
 	style := lipgloss.NewStyle().Foreground(color)
@@ -202,3 +320,33 @@ func RainbowText(text string) string {
 
 	return result.String()
 }
+
+// ansiBase holds the xterm RGB values for ANSI colors 0-15
+var ansiBase = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// HexColor converts a terminal color (ANSI index or "#rrggbb") to a CSS hex color,
+// using the standard xterm 256-color palette. Unknown values fall back to white.
+func HexColor(color lipgloss.Color) string {
+	value := string(color)
+	if strings.HasPrefix(value, "#") {
+		return value
+	}
+
+	index, err := strconv.Atoi(value)
+	switch {
+	case err != nil || index < 0 || index > 255:
+		return ansiBase[15]
+	case index < 16:
+		return ansiBase[index]
+	case index < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		index -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[index/36], levels[index/6%6], levels[index%6])
+	default:
+		gray := 8 + 10*(index-232)
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "green", want: string(Green)},
+		{input: " Magenta ", want: string(Magenta)},
+		{input: "208", want: "208"},
+		{input: "0", want: "0"},
+		{input: "256", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "chartreuse", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColor(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("ParseColor(%q): expected error, got %q", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseColor(%q): unexpected error %v", tt.input, err)
+		}
+		if string(got) != tt.want {
+			t.Fatalf("ParseColor(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := map[string]string{
+		string(Green):   "#00ff00",
+		string(Magenta): "#ff00ff",
+		"208":           "#ff8700",
+		"240":           "#585858",
+		"#123abc":       "#123abc",
+		"bogus":         "#ffffff",
+	}
+	for in, want := range tests {
+		if got := HexColor(lipgloss.Color(in)); got != want {
+			t.Errorf("HexColor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBannerIsAlignedBox(t *testing.T) {
+	if !utf8.ValidString(bannerText) || strings.Contains(bannerText, "â") {
+		t.Fatal("banner is not valid, unmangled UTF-8")
+	}
+
+	lines := strings.Split(strings.TrimRight(bannerText, "\n"), "\n")
+	first, last := lines[0], lines[len(lines)-1]
+	if !strings.HasPrefix(first, "╔═") || !strings.HasSuffix(first, "═╗") ||
+		!strings.HasPrefix(last, "╚═") || !strings.HasSuffix(last, "═╝") {
+		t.Fatalf("banner is not framed by box-drawing corners:\n%s", bannerText)
+	}
+	width := lipgloss.Width(first)
+	for _, line := range lines[1 : len(lines)-1] {
+		if !strings.HasPrefix(line, "║") || !strings.HasSuffix(line, "║") {
+			t.Fatalf("banner line has no side borders: %q", line)
+		}
+		if w := lipgloss.Width(line); w != width {
+			t.Fatalf("banner line is %d columns wide, want %d: %q", w, width, line)
+		}
+	}
+}
+
+func TestSectionHeaderFitsNarrowTerminals(t *testing.T) {
+	defer func(orig func() (int, bool)) { stdoutWidth = orig }(stdoutWidth)
+
+	for _, tc := range []struct {
+		width int
+		ok    bool
+		want  int
+	}{
+		{20, true, 20},
+		{120, true, sectionRuleWidth},
+		{0, false, sectionRuleWidth}, // Not a terminal
+	} {
+		stdoutWidth = func() (int, bool) { return tc.width, tc.ok }
+		var buf bytes.Buffer
+		writeSectionHeader(&buf, "Rules", "#")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		rule := ansi.Strip(lines[0])
+		if got := ansi.StringWidth(rule); got != tc.want || rule != ansi.Strip(lines[len(lines)-1]) {
+			t.Fatalf("width %d: expected matching %d-wide rules, got %d in %q", tc.width, tc.want, got, buf.String())
+		}
+	}
+}
+
+func TestSetBannerReplacesDefault(t *testing.T) {
+	defer SetBanner("")
+	SetBanner("== my pipeline ==\n")
+	var out strings.Builder
+	writeBanner(&out)
+	if got := out.String(); !strings.Contains(got, "== my pipeline ==") || strings.Contains(got, "╔") {
+		t.Fatalf("expected the custom banner, got:\n%s", got)
+	}
+
+	SetBanner("")
+	out.Reset()
+	writeBanner(&out)
+	if !strings.Contains(out.String(), "╔") {
+		t.Fatalf("expected the default banner back, got:\n%s", out.String())
+	}
+}
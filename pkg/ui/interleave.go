@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// minWrapWidth is the narrowest text column Interleaver wraps to; below it lines aren't wrapped
+const minWrapWidth = 20
+
+// Interleaver prints two concurrent text streams as whole lines, each prefixed with
+// its lane's label, so replies streamed at the same time stay readable. A partial
+// line is held until it ends, fills the terminal width, or its lane is flushed.
+type Interleaver struct {
+	mu     sync.Mutex
+	out    io.Writer
+	width  int // Terminal width; 0 disables wrapping
+	labels [2]string
+	lines  [2]strings.Builder
+}
+
+// NewInterleaver creates an interleaver; labels (which may be styled) prefix each lane's lines
+func NewInterleaver(out io.Writer, width int, labels [2]string) *Interleaver {
+	return &Interleaver{out: out, width: width, labels: labels}
+}
+
+// Write adds streamed text to a lane, printing every line it completes
+func (iv *Interleaver) Write(lane int, text string) {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+
+	line := &iv.lines[lane]
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		line.WriteString(text[:i])
+		iv.printLine(lane, line.String())
+		line.Reset()
+		text = text[i+1:]
+	}
+	line.WriteString(text)
+	iv.wrap(lane)
+}
+
+// Flush prints a lane's pending partial line, if any
+func (iv *Interleaver) Flush(lane int) {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+
+	if iv.lines[lane].Len() > 0 {
+		iv.printLine(lane, iv.lines[lane].String())
+		iv.lines[lane].Reset()
+	}
+}
+
+// Println prints a complete line in a lane, after any pending text of that lane
+func (iv *Interleaver) Println(lane int, text string) {
+	iv.Flush(lane)
+
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+	iv.printLine(lane, text)
+}
+
+// wrap prints the full-width lines of a lane's pending text, breaking at spaces where possible
+func (iv *Interleaver) wrap(lane int) {
+	avail := iv.width - ansi.StringWidth(iv.labels[lane]) - 1
+	if iv.width <= 0 || avail < minWrapWidth {
+		return
+	}
+
+	pending := iv.lines[lane].String()
+	if ansi.StringWidth(pending) <= avail {
+		return
+	}
+	for ansi.StringWidth(pending) > avail {
+		cut := ansi.Truncate(pending, avail, "")
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+		iv.printLine(lane, cut)
+		pending = strings.TrimLeft(pending[len(cut):], " ")
+	}
+	iv.lines[lane].Reset()
+	iv.lines[lane].WriteString(pending)
+}
+
+func (iv *Interleaver) printLine(lane int, text string) {
+	fmt.Fprintf(iv.out, "%s%s\n", iv.labels[lane], text)
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInterleaverPrintsWholeLabeledLines(t *testing.T) {
+	var out bytes.Buffer
+	iv := NewInterleaver(&out, 0, [2]string{"A| ", "B| "})
+
+	iv.Write(0, "Hello, ")
+	iv.Write(1, "Hi there.\nSecond ")
+	iv.Write(0, "world.\n")
+	iv.Write(1, "line")
+	iv.Flush(1)
+	iv.Flush(0) // Nothing pending
+
+	want := "B| Hi there.\nA| Hello, world.\nB| Second line\n"
+	if out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestInterleaverWrapsAtWordBoundaries(t *testing.T) {
+	var out bytes.Buffer
+	iv := NewInterleaver(&out, 30, [2]string{"A| ", "B| "})
+
+	// 30 columns minus the label and the cursor column leave 26 for text
+	iv.Write(0, "the quick brown fox jumps over the lazy dog")
+	iv.Println(0, "[done]")
+
+	want := "A| the quick brown fox jumps\nA| over the lazy dog\nA| [done]\n"
+	if out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+}
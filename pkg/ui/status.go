@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// Terminal control sequences used to draw the status after the cursor without moving it
+const (
+	saveCursor    = "\x1b7"
+	restoreCursor = "\x1b8"
+	clearToEOL    = "\x1b[K"
+)
+
+// statusInterval is how often the status redraws, which also paces the spinner
+const statusInterval = 100 * time.Millisecond
+
+// spinnerFrames animate the status while waiting for the first token
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(f.Fd())
+}
+
+// TerminalWidth reports the width of f when it is an interactive terminal
+func TerminalWidth(f *os.File) (int, bool) {
+	if !IsTerminal(f) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(f.Fd())
+	if err != nil || width <= 0 {
+		width = 80
+	}
+	return width, true
+}
+
+// StreamStatus prints streamed text followed by a transient status: a spinner
+// until the first token arrives, then elapsed time and an approximate token rate
+// (one chunk ≈ one token). The status is drawn to the right of the cursor and
+// erased before each write, so it never interleaves with the text. It is skipped
+// when it wouldn't fit on the line.
+type StreamStatus struct {
+	mu      sync.Mutex
+	out     io.Writer
+	width   int
+	started time.Time
+	first   time.Time // When the first token arrived
+	tokens  int
+	col     int // Cursor column, tracked so the status doesn't wrap
+	drawn   bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewStreamStatus creates a status for a terminal of the given width
+func NewStreamStatus(out io.Writer, width int) *StreamStatus {
+	return &StreamStatus{out: out, width: width}
+}
+
+// Start begins timing a turn; col is the cursor column the turn's text starts at
+func (s *StreamStatus) Start(col int) {
+	s.Finish()
+
+	s.mu.Lock()
+	s.started = time.Now()
+	s.first = time.Time{}
+	s.tokens = 0
+	s.col = col
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.draw()
+	s.mu.Unlock()
+
+	go s.tick(s.stop, s.done)
+}
+
+func (s *StreamStatus) tick(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.draw()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Print erases the status, writes a streamed chunk, and redraws the status
+func (s *StreamStatus) Print(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.first.IsZero() {
+		s.first = time.Now()
+	}
+	s.tokens++
+	s.clear()
+	io.WriteString(s.out, text)
+	s.advance(text)
+	s.draw()
+}
+
+// Finish stops updating and erases the status
+func (s *StreamStatus) Finish() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+
+	s.mu.Lock()
+	s.clear()
+	s.mu.Unlock()
+}
+
+// advance tracks the cursor column after writing text
+func (s *StreamStatus) advance(text string) {
+	if i := strings.LastIndexAny(text, "\r\n"); i >= 0 {
+		s.col = 0
+		text = text[i+1:]
+	}
+	s.col += ansi.StringWidth(strings.ReplaceAll(text, "\t", "        "))
+	if s.width > 0 {
+		s.col %= s.width
+	}
+}
+
+func (s *StreamStatus) clear() {
+	if s.drawn {
+		io.WriteString(s.out, clearToEOL)
+		s.drawn = false
+	}
+}
+
+func (s *StreamStatus) draw() {
+	status := "  " + s.text(time.Now())
+	if s.col+ansi.StringWidth(status) >= s.width {
+		s.clear()
+		return
+	}
+	io.WriteString(s.out, saveCursor+Colorize(status, Dim, false)+clearToEOL+restoreCursor)
+	s.drawn = true
+}
+
+// text formats the status for the current moment
+func (s *StreamStatus) text(now time.Time) string {
+	elapsed := now.Sub(s.started).Seconds()
+	if s.first.IsZero() {
+		frame := spinnerFrames[int(now.Sub(s.started)/statusInterval)%len(spinnerFrames)]
+		return fmt.Sprintf("%s thinking… %.1fs", frame, elapsed)
+	}
+
+	// The first token marks the start of generation, as in the bench command
+	rate := 0.0
+	if generating := now.Sub(s.first).Seconds(); s.tokens > 1 && generating > 0 {
+		rate = float64(s.tokens-1) / generating
+	}
+	return fmt.Sprintf("⚡ %.1fs · ~%.0f tok/s", elapsed, rate)
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamStatusErasesBeforeText(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStreamStatus(&buf, 80)
+	s.Start(9)
+	s.Print("Hello")
+	s.Print(" world")
+	s.Finish()
+
+	out := buf.String()
+	if !strings.Contains(out, saveCursor) || !strings.Contains(out, "tok/s") {
+		t.Fatalf("status was not drawn: %q", out)
+	}
+
+	// With the status sequences removed, only the streamed text remains
+	plain := out
+	for strings.Contains(plain, saveCursor) {
+		start := strings.Index(plain, saveCursor)
+		end := strings.Index(plain[start:], restoreCursor) + start + len(restoreCursor)
+		plain = plain[:start] + plain[end:]
+	}
+	plain = strings.ReplaceAll(plain, clearToEOL, "")
+	if plain != "Hello world" {
+		t.Errorf("text = %q, want %q", plain, "Hello world")
+	}
+
+	// Every chunk is preceded by an erase, and the turn ends with the status erased
+	if !strings.Contains(out, clearToEOL+" world") || !strings.HasSuffix(out, restoreCursor+clearToEOL) {
+		t.Errorf("status not erased around text: %q", out)
+	}
+}
+
+func TestStreamStatusSkipsWhenLineIsFull(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStreamStatus(&buf, 20)
+	s.Start(0)
+	s.Print(strings.Repeat("x", 15))
+	s.Finish()
+
+	tail := buf.String()[strings.LastIndex(buf.String(), "x")+1:]
+	if strings.Contains(tail, saveCursor) {
+		t.Errorf("status drawn past the line width: %q", tail)
+	}
+}
+
+func TestStreamStatusText(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &StreamStatus{started: start}
+
+	if got := s.text(start.Add(1500 * time.Millisecond)); got != "⠴ thinking… 1.5s" {
+		t.Errorf("waiting text = %q", got)
+	}
+	// The spinner advances one frame per redraw
+	if got := s.text(start.Add(1600 * time.Millisecond)); !strings.HasPrefix(got, "⠦ ") {
+		t.Errorf("spinner did not advance: %q", got)
+	}
+
+	s.first = start.Add(time.Second)
+	s.tokens = 21
+	if got := s.text(start.Add(3 * time.Second)); got != "⚡ 3.0s · ~10 tok/s" {
+		t.Errorf("streaming text = %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// teeBufferSize holds a long round in memory, so a slow disk is only touched on Flush
+const teeBufferSize = 256 * 1024
+
+// Tee copies the printed conversation to a file. Writes are buffered and never fail,
+// so a slow or full disk can't stall or break the live stream; the first error is
+// kept, later output is dropped, and Flush reports it.
+type Tee struct {
+	f     *os.File
+	w     *bufio.Writer
+	color bool
+	err   error
+}
+
+// CreateTee creates (or truncates) path for a copy of the output. Escape codes are
+// stripped from the copy unless color is set.
+func CreateTee(path string, color bool) (*Tee, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Tee{f: f, w: bufio.NewWriterSize(f, teeBufferSize), color: color}, nil
+}
+
+// Write buffers p for the file
+func (t *Tee) Write(p []byte) (int, error) {
+	if t.err == nil {
+		text := string(p)
+		if !t.color {
+			text = ansi.Strip(text)
+		}
+		_, t.err = io.WriteString(t.w, text)
+	}
+	return len(p), nil
+}
+
+// Flush writes buffered output to the file, returning the first error seen so far
+func (t *Tee) Flush() error {
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	return t.err
+}
+
+// Close flushes and closes the file
+func (t *Tee) Close() error {
+	err := t.Flush()
+	if cerr := t.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Path returns the file the output is copied to
+func (t *Tee) Path() string {
+	return t.f.Name()
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTeeStripsColorUnlessKept(t *testing.T) {
+	dir := t.TempDir()
+	colored := "\x1b[1;36mAgent A:\x1b[0m hello\n"
+
+	for _, keep := range []bool{false, true} {
+		path := filepath.Join(dir, "out.txt")
+		tee, err := CreateTee(path, keep)
+		if err != nil {
+			t.Fatalf("CreateTee: %v", err)
+		}
+		tee.Write([]byte(colored))
+
+		// Nothing reaches the file until a flush
+		if data, _ := os.ReadFile(path); len(data) != 0 {
+			t.Fatalf("expected buffered output, file has %q", data)
+		}
+		if err := tee.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		want := "Agent A: hello\n"
+		if keep {
+			want = colored
+		}
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("color=%v: file = %q, want %q", keep, data, want)
+		}
+	}
+}
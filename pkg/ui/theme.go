@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a terminal color scheme. Colors are named after the retro palette so
+// that palette names (e.g. --color-a green) mean the same role in every theme.
+type Theme struct {
+	Name    string
+	Cyan    lipgloss.Color // Banners and labels
+	Green   lipgloss.Color // Success and Agent A
+	Yellow  lipgloss.Color // Headings, warnings and highlights
+	Red     lipgloss.Color // Errors
+	Magenta lipgloss.Color // Agent B
+	Blue    lipgloss.Color // Info
+	White   lipgloss.Color // Emphasized text
+	Dim     lipgloss.Color // Secondary text and rules
+}
+
+// Built-in themes
+var (
+	// Retro is the default bright-on-dark look, matching the Python version
+	Retro = Theme{Name: "retro", Cyan: "14", Green: "10", Yellow: "11", Red: "9", Magenta: "13", Blue: "12", White: "15", Dim: "240"}
+
+	// Mono uses no colors, only bold text
+	Mono = Theme{Name: "mono"}
+
+	// Light uses darker shades that stay readable on a light background
+	Light = Theme{Name: "light", Cyan: "30", Green: "28", Yellow: "136", Red: "160", Magenta: "127", Blue: "25", White: "0", Dim: "245"}
+
+	// Solarized uses Ethan Schoonover's accent colors
+	Solarized = Theme{Name: "solarized", Cyan: "#2aa198", Green: "#859900", Yellow: "#b58900", Red: "#dc322f",
+		Magenta: "#d33682", Blue: "#268bd2", White: "#93a1a1", Dim: "#586e75"}
+)
+
+// Themes maps theme names to their schemes
+var Themes = map[string]Theme{
+	Retro.Name:     Retro,
+	Mono.Name:      Mono,
+	Light.Name:     Light,
+	Solarized.Name: Solarized,
+}
+
+// current is the theme set by SetTheme
+var current = Retro
+
+// ThemeNames returns the theme names in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme resolves a theme name; "" or "auto" picks one for the terminal's background
+func LookupTheme(name string) (Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		return DetectTheme(), nil
+	}
+	if theme, ok := Themes[name]; ok {
+		return theme, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q; use auto or one of %s", name, strings.Join(ThemeNames(), ", "))
+}
+
+// DetectTheme returns Light when the terminal advertises a light background and
+// Retro otherwise. It reads COLORFGBG ("fg;bg", set by rxvt, Konsole and others)
+// rather than querying the terminal, which stalls on terminals that never answer.
+func DetectTheme() Theme {
+	if lightBackground(os.Getenv("COLORFGBG")) {
+		return Light
+	}
+	return Retro
+}
+
+// lightBackground reports whether a COLORFGBG value names a light background color
+func lightBackground(colorFGBG string) bool {
+	fields := strings.Split(colorFGBG, ";")
+	if len(fields) < 2 {
+		return false
+	}
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	return err == nil && (bg == 7 || bg == 15) // White and bright white
+}
+
+// CurrentTheme returns the theme in use
+func CurrentTheme() Theme {
+	return current
+}
+
+// SetTheme switches the colors, palette and styles used by every helper in this package
+func SetTheme(theme Theme) {
+	current = theme
+	Cyan, Green, Yellow, Red = theme.Cyan, theme.Green, theme.Yellow, theme.Red
+	Magenta, Blue, White, Dim = theme.Magenta, theme.Blue, theme.White, theme.Dim
+	Palette = map[string]lipgloss.Color{
+		"cyan":    Cyan,
+		"green":   Green,
+		"yellow":  Yellow,
+		"red":     Red,
+		"magenta": Magenta,
+		"blue":    Blue,
+		"white":   White,
+		"dim":     Dim,
+	}
+	buildStyles()
+}
@@ -0,0 +1,60 @@
+package ui
+
+import "testing"
+
+func TestSetThemeRecolorsPaletteAndStyles(t *testing.T) {
+	defer SetTheme(Retro)
+
+	SetTheme(Solarized)
+	if CurrentTheme().Name != "solarized" || Green != Solarized.Green {
+		t.Fatalf("theme not applied: %+v", CurrentTheme())
+	}
+	// Agent colors chosen by palette name follow the theme
+	if got, err := ParseColor("magenta"); err != nil || got != Solarized.Magenta {
+		t.Fatalf("ParseColor(magenta) = %q, %v", got, err)
+	}
+	if Success.GetForeground() != Solarized.Green {
+		t.Fatalf("styles not rebuilt: %v", Success.GetForeground())
+	}
+
+	SetTheme(Retro)
+	if got, _ := ParseColor("green"); got != "10" {
+		t.Fatalf("expected retro green back, got %q", got)
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	for _, name := range []string{"retro", "Mono", " light ", "solarized"} {
+		if _, err := LookupTheme(name); err != nil {
+			t.Errorf("LookupTheme(%q): %v", name, err)
+		}
+	}
+	if _, err := LookupTheme("neon"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+
+	t.Setenv("COLORFGBG", "0;15")
+	if theme, _ := LookupTheme("auto"); theme.Name != "light" {
+		t.Errorf("expected light on a white background, got %s", theme.Name)
+	}
+	t.Setenv("COLORFGBG", "")
+	if theme, _ := LookupTheme(""); theme.Name != "retro" {
+		t.Errorf("expected retro when the background is unknown, got %s", theme.Name)
+	}
+}
+
+func TestLightBackground(t *testing.T) {
+	for value, want := range map[string]bool{
+		"0;15":         true,
+		"0;default;7":  true,
+		"15;0":         false,
+		"12;8":         false,
+		"":             false,
+		"garbage":      false,
+		"0;not-number": false,
+	} {
+		if got := lightBackground(value); got != want {
+			t.Errorf("lightBackground(%q) = %v, want %v", value, got, want)
+		}
+	}
+}